@@ -2,30 +2,35 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 	"todolist/internal/cli"
-	"todolist/internal/storage"
-	"todolist/internal/todolist"
+	"todolist/internal/config"
+	"todolist/internal/demo"
+	"todolist/internal/env"
+	"todolist/internal/output"
+	"todolist/internal/theme"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
 )
 
 func main() {
-	// Get home directory for default storage path
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to get home directory: %v\n", err)
-		os.Exit(1)
-	}
+	// Writes to a closed stdout pipe return EPIPE instead of killing the
+	// process; PipeWriter then ends the output quietly
+	signal.Notify(make(chan os.Signal, 1), syscall.SIGPIPE)
+	stdout := output.NewPipeWriter(os.Stdout)
 
-	// Initialize FileStorage with default path ~/.todolist.json
-	storagePath := filepath.Join(homeDir, ".todolist.json")
-	fileStorage := storage.NewFileStorage(storagePath)
-
-	// Create TodoList instance
-	tl, err := todolist.NewTodoList(fileStorage)
+	storagePath, err := config.DefaultStoragePath()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to initialize todo list: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: failed to locate the task file: %v\n", err)
+		os.Exit(apperrors.ExitFailure)
 	}
 
 	// Parse command line arguments (skip program name)
@@ -40,16 +45,263 @@ func main() {
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		fmt.Fprintln(os.Stderr, "\nUse 'todolist help' for usage information.")
-		os.Exit(1)
+		os.Exit(apperrors.ExitUsage)
 	}
 
-	// Execute command
-	output, err := cli.ExecuteCommand(cmd, tl)
+	// Warn about deprecated names that were translated
+	cli.WriteDeprecationNotices(os.Stderr, cmd)
+
+	// The demo sandbox never touches the real task file or config
+	if cmd.Name == "demo" {
+		opts := cli.DemoOptions{Seed: demo.DefaultSeed, Keep: cmd.Flags["keep"] != ""}
+		if value, ok := cmd.Flags["seed"]; ok {
+			opts.Seed, _ = strconv.ParseInt(value, 10, 64) // Already validated in ParseCommand
+		}
+		if err := cli.RunDemo(os.Stdin, stdout, os.Stderr, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(apperrors.ExitCode(err))
+		}
+		return
+	}
+
+	// Load user config (missing file is fine); --config overrides the location
+	configPath := cmd.ConfigPath
+	if configPath == "" {
+		configPath, err = config.DefaultPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to locate config file: %v\n", err)
+			os.Exit(apperrors.ExitFailure)
+		}
+	}
+
+	// init writes the config and task file itself, so neither is loaded
+	// first; init --repair needs the task file path and runs below
+	if cmd.Name == "init" && cmd.Flags["repair"] == "" {
+		opts := cli.InitOptions{ConfigPath: configPath, StoragePath: storagePath}
+		if err := cli.RunInit(os.Stdin, stdout, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(apperrors.ExitCode(err))
+		}
+		return
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(apperrors.ExitCode(err))
+	}
+	storagePath, err = cfg.ResolveStoragePath(storagePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to resolve storage path: %v\n", err)
+		os.Exit(apperrors.ExitFailure)
+	}
+	// TODOLIST_FILE (set by demo) overrides both
+	if path := env.Get(env.TaskFile); path != "" {
+		storagePath = path
+	}
+
+	// Initialize FileStorage, defaulting to the data directory; json_indent
+	// also applies to --remote, exports and snapshots
+	storageOpts := storage.FileStorageOptions{Indent: storage.DefaultIndent}
+	if cfg.JSONIndent != nil {
+		storageOpts.Indent = strings.Repeat(" ", *cfg.JSONIndent)
+	}
+	fileStorage := storage.NewFileStorageWithOptions(storagePath, storageOpts)
+	if cfg.BackupDepth != nil {
+		fileStorage.SetBackupDepth(*cfg.BackupDepth)
+	}
+
+	// Changes to a task file that cannot be written fail before any work
+	// is done; --read-only forces this
+	fileStorage.SetReadOnly(cmd.ReadOnly)
+	// Saves refuse to overwrite changes made by other processes unless
+	// --force-save is given
+	fileStorage.SetForceSave(cmd.ForceSave)
+	if cli.ModifiesTaskFile(cmd) && cmd.Remote == "" && !cmd.DryRun {
+		if err := fileStorage.CheckWritable(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: cannot run %s: %v\n", cmd.Name, err)
+			os.Exit(apperrors.ExitCode(err))
+		}
+	}
+
+	if cmd.Name == "init" {
+		if err := cli.RepairStorage(stdout, fileStorage); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(apperrors.ExitCode(err))
+		}
+		return
+	}
+
+	// --remote keeps the list on a server; undo history stays local
+	var st storage.Storage = fileStorage
+	if cmd.Remote != "" {
+		if cmd.Name == "backup" {
+			fmt.Fprintln(os.Stderr, "Error: backup copies the local task file and cannot be used with --remote")
+			os.Exit(apperrors.ExitUsage)
+		}
+		httpStorage := storage.NewHTTPStorage(cmd.Remote, env.Get(env.RemoteToken), nil)
+		httpStorage.SetIndent(storageOpts.Indent)
+		st = httpStorage
+	}
+
+	// Capture is latency sensitive: skip output setup, but keep the
+	// settings that decide whether and how a task is added
+	if cmd.Name == "capture" {
+		opts := cli.CaptureOptions{
+			Prompt:      isTerminal(os.Stdin),
+			Window:      cmd.Flags["window"] != "",
+			Config:      cfg,
+			StoragePath: storagePath,
+			Errors:      os.Stderr,
+		}
+		if err := cli.Capture(os.Stdin, stdout, st, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(apperrors.ExitCode(err))
+		}
+		return
+	}
+
+	// Reminders only read the task list
+	if cmd.Name == "remind" {
+		opts := cli.RemindOptions{Watch: cmd.Flags["watch"] != ""}
+		if value, ok := cmd.Flags["interval"]; ok {
+			opts.Interval, _ = time.ParseDuration(value) // Already validated in ParseCommand
+		}
+		if err := cli.Remind(stdout, os.Stderr, st, storagePath, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(apperrors.ExitCode(err))
+		}
+		return
+	}
+
+	// Metrics alone load the list afresh for every scrape; the task API
+	// needs the list set up below
+	if cmd.Name == "serve" && cmd.Flags["addr"] == "" {
+		opts := cli.ServeOptions{MetricsAddr: cmd.Flags["metrics-addr"]}
+		if err := cli.Serve(stdout, st, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(apperrors.ExitCode(err))
+		}
+		return
+	}
+
+	// Create TodoList instance
+	tl, err := todolist.NewTodoList(st)
+	if apperrors.IsInvalidJSON(err) && cmd.Remote == "" && !cmd.DryRun && !cmd.ReadOnly {
+		// Set a damaged task file aside, asking first unless --recover was given
+		if cmd.Recover || (isTerminal(os.Stdin) && cli.ConfirmRecovery(storagePath)) {
+			moved, moveErr := fileStorage.SetAsideCorrupt()
+			if moveErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", moveErr)
+				os.Exit(apperrors.ExitCode(moveErr))
+			}
+			fmt.Fprintf(os.Stderr, "The damaged task file was moved to %s; starting with an empty list\n", moved)
+			tl, err = todolist.NewTodoList(st)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to initialize todo list: %v\n", err)
+		if apperrors.IsInvalidJSON(err) && cmd.Remote == "" {
+			fmt.Fprintln(os.Stderr, "Run with --recover to move the damaged file aside and start with an empty list.")
+		}
+		if apperrors.IsStorageCorrupted(err) && cmd.Remote == "" {
+			fmt.Fprintln(os.Stderr, "Run 'todolist init --repair' to delete the temporary file and continue.")
+		}
+		os.Exit(apperrors.ExitCode(err))
+	}
+	cmd.Webhook = cli.ConfigureTodoList(tl, cfg, storagePath, os.Stderr)
+	if cmd.LogLevel != nil {
+		tl.SetLogger(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: cmd.LogLevel})))
+	}
+
+	// Resolve theme: --theme overrides the config file
+	themeName := cfg.Theme
+	if cmd.Theme != "" {
+		themeName = cmd.Theme
+	}
+	th, err := theme.Lookup(themeName, cfg.Themes)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(apperrors.ExitCode(err))
+	}
+	colorMode := cmd.Color
+	if colorMode == output.ColorAuto && cfg.ColourEnabled != nil && !*cfg.ColourEnabled {
+		colorMode = output.ColorNever
+	}
+	output.SetColorMode(colorMode)
+	output.SetTheme(th)
+	cmd.Formatter = output.Formatter()
+	cmd.DateFormat = cfg.DateLayout()
+	cmd.StoragePath = storagePath
+	if cmd.ArchivePath, err = config.DefaultArchivePath(storagePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to locate the archive file: %v\n", err)
+		os.Exit(apperrors.ExitFailure)
+	}
+	cmd.ListColumns = cfg.ListColumns
+	cmd.DefaultSort = cfg.DefaultSort
+	cmd.WeekStart = cfg.WeekStart
+	cmd.ConfirmDelete = cfg.ConfirmDelete
+	cmd.Width = output.TerminalWidth()
+
+	if cmd.Name == "list" && cmd.Flags["watch"] != "" {
+		if err := cli.WatchList(stdout, os.Stderr, cmd, tl); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(apperrors.ExitCode(err))
+		}
+		return
+	}
+	if cmd.Name == "serve" {
+		opts := cli.ServeOptions{Addr: cmd.Flags["addr"], MetricsAddr: cmd.Flags["metrics-addr"], TodoList: tl}
+		if err := cli.Serve(stdout, st, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(apperrors.ExitCode(err))
+		}
+		return
+	}
+
+	// Execute command
+	result, err := cli.ExecuteCommand(cmd, tl)
+	if cmd.Webhook != nil {
+		// Let the command's webhook deliveries finish before exiting
+		cmd.Webhook.Wait()
+	}
+	if err != nil {
+		// Partial results (e.g. a bulk done with some missing IDs) still get shown
+		if result != "" {
+			printResult(stdout, result)
+		}
+		// due reports overdue tasks through the exit code alone
+		if !apperrors.IsTasksOverdue(err) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(apperrors.ExitCode(err))
 	}
 
 	// Display result
-	fmt.Println(output)
+	printResult(stdout, result)
+
+	// Say once that this or an earlier run upgraded the task file format
+	if cmd.Remote == "" {
+		if err := cli.WriteUpgradeNotice(os.Stderr, cmd, fileStorage, isTerminal(os.Stderr)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
+// printResult writes the command output to stdout. A reader that went away
+// is not an error; any other write failure is.
+func printResult(stdout io.Writer, result string) {
+	if _, err := fmt.Fprintln(stdout, result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write output: %v\n", err)
+		os.Exit(apperrors.ExitFailure)
+	}
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
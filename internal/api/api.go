@@ -0,0 +1,178 @@
+// Package api exposes a task list as a JSON REST API for todolist serve.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/todolist"
+)
+
+// maxBodySize limits the size of a request body
+const maxBodySize = 1 << 20
+
+// NewTask is the body of POST /tasks. Only the description is required.
+type NewTask struct {
+	Description string     `json:"description"`
+	Priority    string     `json:"priority,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+}
+
+// errorBody is the body of every error response
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// Handler serves tl under /tasks:
+//
+//	GET    /tasks               list every task
+//	POST   /tasks               add a task from a NewTask body
+//	GET    /tasks/{id}          show one task
+//	POST   /tasks/{id}/complete mark a task as completed
+//	DELETE /tasks/{id}          delete a task
+//
+// An {id} is a task ID or a UID prefix, as on the command line. TodoList
+// locks around every call, so requests may run concurrently; changes made
+// to the task file by other processes are picked up before each request.
+func Handler(tl *todolist.TodoList) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, tl.ListTasks())
+	})
+	mux.HandleFunc("POST /tasks", func(w http.ResponseWriter, r *http.Request) {
+		addTask(w, r, tl)
+	})
+	mux.HandleFunc("GET /tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := tl.ResolveID(r.PathValue("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		task, err := tl.GetTask(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, task)
+	})
+	mux.HandleFunc("POST /tasks/{id}/complete", func(w http.ResponseWriter, r *http.Request) {
+		id, err := tl.ResolveID(r.PathValue("id"))
+		if err == nil {
+			err = tl.CompleteTask(id)
+		}
+		// The change was saved even if recording it for undo failed
+		if err != nil && !apperrors.IsNotRecorded(err) {
+			writeError(w, err)
+			return
+		}
+		task, err := tl.GetTask(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, task)
+	})
+	mux.HandleFunc("DELETE /tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := tl.ResolveID(r.PathValue("id"))
+		if err == nil {
+			err = tl.DeleteTask(id)
+		}
+		// The change was saved even if recording it for undo failed
+		if err != nil && !apperrors.IsNotRecorded(err) {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return refresh(tl, mux)
+}
+
+// refresh reloads tl before passing the request on if another process
+// changed the task file since it was last loaded or saved
+func refresh(tl *todolist.TodoList, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stale, err := tl.Stale()
+		if err == nil && stale {
+			err = tl.Reload()
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// addTask handles POST /tasks
+func addTask(w http.ResponseWriter, r *http.Request, tl *todolist.TodoList) {
+	var body NewTask
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, errorBody{Error: err.Error()})
+			return
+		}
+		writeError(w, fmt.Errorf("%w: invalid request body: %v", apperrors.ErrInvalidCommand, err))
+		return
+	}
+
+	var opts []todolist.TaskOption
+	if body.Priority != "" {
+		priority, ok := models.ParsePriority(body.Priority)
+		if !ok {
+			writeError(w, fmt.Errorf("%w: priority must be one of low, medium or high", apperrors.ErrInvalidCommand))
+			return
+		}
+		opts = append(opts, todolist.WithPriority(priority))
+	}
+	if body.DueDate != nil {
+		opts = append(opts, todolist.WithDueDate(*body.DueDate))
+	}
+	if body.Notes != "" {
+		opts = append(opts, todolist.WithNotes(body.Notes))
+	}
+
+	task, err := tl.AddTask(body.Description, opts...)
+	if task == nil {
+		writeError(w, err)
+		return
+	}
+	// The task was saved even if recording it for undo failed
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", task.ID))
+	writeJSON(w, http.StatusCreated, task)
+}
+
+// statusCode maps an error to an HTTP status the way ExitCode maps it to
+// an exit code: bad input is the client's fault, a missing task is 404, a
+// save that lost a race is 409 and anything else is the server's.
+func statusCode(err error) int {
+	switch {
+	case apperrors.IsConcurrentModification(err):
+		return http.StatusConflict
+	case apperrors.ExitCode(err) == apperrors.ExitUsage:
+		return http.StatusBadRequest
+	case apperrors.ExitCode(err) == apperrors.ExitNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError sends err as a JSON error body with its status code
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusCode(err), errorBody{Error: err.Error()})
+}
+
+// writeJSON sends v as the JSON body of a response with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// request sends a request to h and returns the status and body
+func request(t *testing.T, h http.Handler, method, path, body string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	data, _ := io.ReadAll(rec.Result().Body)
+	return rec.Code, string(data)
+}
+
+// newHandler returns a Handler over an empty list in a temporary file
+func newHandler(t *testing.T) (http.Handler, *storage.FileStorage) {
+	t.Helper()
+	fs := storage.NewFileStorage(filepath.Join(t.TempDir(), "todo.json"))
+	tl, err := todolist.NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	return Handler(tl), fs
+}
+
+// TestHandler tests every route and the status codes errors map to
+func TestHandler(t *testing.T) {
+	h, fs := newHandler(t)
+
+	testCases := []struct {
+		method   string
+		path     string
+		body     string
+		status   int
+		contains string
+	}{
+		{"GET", "/tasks", "", http.StatusOK, "[]"},
+		{"POST", "/tasks", `{"description": "buy milk", "priority": "high"}`, http.StatusCreated, `"description":"buy milk"`},
+		{"POST", "/tasks", `{"description": "call Sam #phone"}`, http.StatusCreated, `"tags":["phone"]`},
+		{"POST", "/tasks", `{"description": "  "}`, http.StatusBadRequest, "description"},
+		{"POST", "/tasks", `{"description": "x", "priority": "urgent"}`, http.StatusBadRequest, "priority"},
+		{"POST", "/tasks", `{"title": "x"}`, http.StatusBadRequest, "unknown field"},
+		{"POST", "/tasks", `not json`, http.StatusBadRequest, "invalid request body"},
+		{"GET", "/tasks/1", "", http.StatusOK, `"priority":3`},
+		{"GET", "/tasks/9", "", http.StatusNotFound, "9"},
+		{"GET", "/tasks/zz", "", http.StatusBadRequest, "zz"},
+		{"POST", "/tasks/1/complete", "", http.StatusOK, `"completed":true`},
+		{"POST", "/tasks/9/complete", "", http.StatusNotFound, "9"},
+		{"DELETE", "/tasks/2", "", http.StatusNoContent, ""},
+		{"DELETE", "/tasks/2", "", http.StatusNotFound, "2"},
+		{"PUT", "/tasks", "", http.StatusMethodNotAllowed, ""},
+	}
+	for _, tc := range testCases {
+		status, body := request(t, h, tc.method, tc.path, tc.body)
+		if status != tc.status || !strings.Contains(body, tc.contains) {
+			t.Errorf("%s %s: expected %d containing %q, got %d: %s", tc.method, tc.path, tc.status, tc.contains, status, body)
+		}
+	}
+
+	saved, err := fs.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(saved.Tasks) != 1 || !saved.Tasks[0].Completed {
+		t.Errorf("Expected one completed task saved, got %+v", saved.Tasks)
+	}
+
+	// Tasks can be addressed by UID prefix too
+	status, body := request(t, h, "GET", "/tasks/"+saved.Tasks[0].UID[:8], "")
+	if status != http.StatusOK || !strings.Contains(body, "buy milk") {
+		t.Errorf("Expected the task by UID prefix, got %d: %s", status, body)
+	}
+}
+
+// TestHandlerUnrecordedChanges tests that a change saved without being
+// recorded for undo is reported as done, as it is in the task file
+func TestHandlerUnrecordedChanges(t *testing.T) {
+	dir := t.TempDir()
+	fs := storage.NewFileStorage(filepath.Join(dir, "todo.json"))
+	tl, err := todolist.NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	// The history file cannot be created inside a regular file
+	tl.SetHistory(storage.NewFileHistory(filepath.Join(fs.Path(), "history.json")))
+	h := Handler(tl)
+
+	testCases := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"POST", "/tasks", `{"description": "first"}`, http.StatusCreated},
+		{"POST", "/tasks", `{"description": "second"}`, http.StatusCreated},
+		{"POST", "/tasks/1/complete", "", http.StatusOK},
+		{"DELETE", "/tasks/2", "", http.StatusNoContent},
+	}
+	for _, tc := range testCases {
+		if status, body := request(t, h, tc.method, tc.path, tc.body); status != tc.status {
+			t.Errorf("%s %s: expected %d, got %d: %s", tc.method, tc.path, tc.status, status, body)
+		}
+	}
+
+	saved, err := fs.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(saved.Tasks) != 1 || !saved.Tasks[0].Completed {
+		t.Errorf("Expected one completed task saved, got %+v", saved.Tasks)
+	}
+}
+
+// TestHandlerPicksUpFileChanges tests that edits made by another process
+// are served rather than overwritten
+func TestHandlerPicksUpFileChanges(t *testing.T) {
+	h, fs := newHandler(t)
+	request(t, h, "POST", "/tasks", `{"description": "first"}`)
+
+	// Another process adds a task behind the server's back
+	other, err := todolist.NewTodoList(storage.NewFileStorage(fs.Path()))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	if _, err := other.AddTask("from the CLI"); err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+
+	status, body := request(t, h, "POST", "/tasks/2/complete", "")
+	if status != http.StatusOK || !strings.Contains(body, "from the CLI") {
+		t.Fatalf("Expected task 2 from the file to be completed, got %d: %s", status, body)
+	}
+	saved, err := fs.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(saved.Tasks) != 2 || !saved.Tasks[1].Completed {
+		t.Errorf("Expected both tasks kept with task 2 completed, got %+v", saved.Tasks)
+	}
+}
+
+// TestHandlerConcurrentAdds tests that simultaneous requests neither lose
+// tasks nor hand out an ID twice
+func TestHandlerConcurrentAdds(t *testing.T) {
+	h, fs := newHandler(t)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := strings.NewReader(fmt.Sprintf(`{"description": "task %d"}`, i))
+			resp, err := http.Post(server.URL+"/tasks", "application/json", body)
+			if err != nil {
+				t.Errorf("POST failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusCreated {
+				t.Errorf("Expected 201, got %d", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	resp, err := http.Get(server.URL + "/tasks")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var tasks []models.Task
+	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	ids := make(map[int]bool)
+	for _, task := range tasks {
+		ids[task.ID] = true
+	}
+	if len(tasks) != n || len(ids) != n {
+		t.Errorf("Expected %d tasks with distinct IDs, got %+v", n, tasks)
+	}
+	if saved, err := fs.Load(); err != nil || len(saved.Tasks) != n {
+		t.Errorf("Expected %d tasks saved, got %v", n, err)
+	}
+}
@@ -0,0 +1,13 @@
+// Package buildinfo holds version metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X todolist/internal/buildinfo.Version=1.0.0" ./cmd/todolist
+//
+// See the Makefile for the full set of flags.
+package buildinfo
+
+// Build metadata, set via -ldflags "-X ..."; the defaults identify a plain go build
+var (
+	Version   = "0.0.0-dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
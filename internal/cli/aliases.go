@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	apperrors "todolist/pkg/errors"
+)
+
+// commandAliases maps each short alias to its command, in help order
+var commandAliases = []struct {
+	alias, command string
+}{
+	{"a", "add"},
+	{"ls", "list"},
+	{"d", "done"},
+	{"rm", "delete"},
+}
+
+// maxSuggestionDistance is the largest edit distance still worth a suggestion
+const maxSuggestionDistance = 2
+
+// resolveAlias returns the command an alias stands for, or name unchanged
+func resolveAlias(name string) string {
+	for _, a := range commandAliases {
+		if a.alias == name {
+			return a.command
+		}
+	}
+	return name
+}
+
+// aliasHelp renders the alias table for the help text
+func aliasHelp() string {
+	parts := make([]string, len(commandAliases))
+	for i, a := range commandAliases {
+		parts[i] = a.alias + " = " + a.command
+	}
+	return "Aliases: " + strings.Join(parts, ", ")
+}
+
+// unknownCommandError returns ErrInvalidCommand, with a "did you mean"
+// hint when name is a likely typo of a command
+func unknownCommandError(name string) error {
+	if suggestion, ok := suggestCommand(name); ok {
+		return fmt.Errorf("%w: unknown command '%s', did you mean '%s'?", apperrors.ErrInvalidCommand, name, suggestion)
+	}
+	return apperrors.ErrInvalidCommand
+}
+
+// suggestCommand returns the command closest to name if it is close enough:
+// at most maxSuggestionDistance edits, and fewer edits than half of name so
+// short unrelated words are not matched
+func suggestCommand(name string) (string, bool) {
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, command := range commandNames {
+		if d := editDistance(name, command); d < bestDistance {
+			best, bestDistance = command, d
+		}
+	}
+	if best == "" || bestDistance*2 > len([]rune(name)) {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
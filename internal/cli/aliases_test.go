@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	apperrors "todolist/pkg/errors"
+)
+
+// TestAliasesParseAsCommands tests that each alias parses like its command
+func TestAliasesParseAsCommands(t *testing.T) {
+	testCases := []struct {
+		args []string
+		name string
+	}{
+		{[]string{"a", "Buy", "milk"}, "add"},
+		{[]string{"ls"}, "list"},
+		{[]string{"LS", "--sort", "id"}, "list"},
+		{[]string{"d", "1"}, "done"},
+		{[]string{"rm", "2", "3"}, "delete"},
+	}
+
+	for _, tc := range testCases {
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		if cmd.Name != tc.name {
+			t.Errorf("ParseCommand(%v): expected %s, got %s", tc.args, tc.name, cmd.Name)
+		}
+	}
+
+	for _, a := range commandAliases {
+		if !strings.Contains(getHelpText(), a.alias+" = "+a.command) {
+			t.Errorf("Expected help text to list alias %s", a.alias)
+		}
+	}
+}
+
+// TestUnknownCommandSuggestions tests the "did you mean" threshold
+func TestUnknownCommandSuggestions(t *testing.T) {
+	testCases := []struct {
+		input      string
+		suggestion string
+	}{
+		{"lst", "list"},
+		{"deleete", "delete"},
+		{"dne", "done"},
+		{"shwo", "show"},
+		{"improt", "import"},
+		{"xyz", ""},
+		{"dn", ""},
+		{"frobnicate", ""},
+		{"q", ""},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseCommand([]string{tc.input})
+		if !apperrors.IsInvalidCommand(err) {
+			t.Fatalf("Expected ErrInvalidCommand for %q, got %v", tc.input, err)
+		}
+		hint := "did you mean '" + tc.suggestion + "'?"
+		switch {
+		case tc.suggestion == "" && strings.Contains(err.Error(), "did you mean"):
+			t.Errorf("Expected no suggestion for %q, got %v", tc.input, err)
+		case tc.suggestion != "" && !strings.Contains(err.Error(), hint):
+			t.Errorf("Expected %q for %q, got %v", hint, tc.input, err)
+		}
+	}
+}
+
+// TestEditDistance tests the Levenshtein distance helper
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"list", "list", 0},
+		{"lst", "list", 1},
+		{"shwo", "show", 2},
+		{"", "add", 3},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tc := range testCases {
+		if got := editDistance(tc.a, tc.b); got != tc.distance {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.distance)
+		}
+	}
+}
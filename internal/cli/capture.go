@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"todolist/internal/config"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// CaptureWindow is how long `capture --window` keeps the terminal open
+// after printing the confirmation
+const CaptureWindow = 1500 * time.Millisecond
+
+// sleep is replaced in tests to avoid real delays
+var sleep = time.Sleep
+
+// CaptureOptions controls the capture fast path
+type CaptureOptions struct {
+	// Prompt shows a minimal prompt before reading (for interactive terminals)
+	Prompt bool
+	// Window keeps the popup open for CaptureWindow after confirming
+	Window bool
+	// Config, if set, applies its settings to the list as every other
+	// command does (see ConfigureTodoList), with the files kept next to
+	// StoragePath; webhook warnings go to Errors
+	Config      *config.Config
+	StoragePath string
+	Errors      io.Writer
+}
+
+// Capture reads a single line from in and adds it as a task. It is the
+// hotkey fast path: storage is loaded once and saved once, and nothing is
+// loaded at all when the input is empty. The task is checked against the
+// same limits as add.
+func Capture(in io.Reader, out io.Writer, st storage.Storage, opts CaptureOptions) error {
+	if opts.Prompt {
+		fmt.Fprint(out, "> ")
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	description := strings.TrimSpace(line)
+	if description == "" {
+		// Nothing typed: exit quietly without touching storage
+		return nil
+	}
+
+	tl, err := todolist.NewTodoList(st)
+	if err != nil {
+		return err
+	}
+	if opts.Config != nil {
+		errOut := opts.Errors
+		if errOut == nil {
+			errOut = io.Discard
+		}
+		if notifier := ConfigureTodoList(tl, opts.Config, opts.StoragePath, errOut); notifier != nil {
+			defer notifier.Wait()
+		}
+	}
+
+	task, err := tl.AddTask(description)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "✓ Task added: [%d] %s\n", task.ID, task.Description)
+	if opts.Window {
+		sleep(CaptureWindow)
+	}
+	return nil
+}
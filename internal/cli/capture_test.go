@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"todolist/internal/config"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// countingStorage is an in-memory storage that counts Load and Save calls
+type countingStorage struct {
+	data  *models.TaskList
+	loads int
+	saves int
+}
+
+func (cs *countingStorage) Load() (*models.TaskList, error) {
+	cs.loads++
+	if cs.data == nil {
+		return &models.TaskList{Tasks: []models.Task{}, NextID: 1}, nil
+	}
+	tasks := make([]models.Task, len(cs.data.Tasks))
+	copy(tasks, cs.data.Tasks)
+	return &models.TaskList{Tasks: tasks, NextID: cs.data.NextID}, nil
+}
+
+func (cs *countingStorage) Save(list *models.TaskList) error {
+	cs.saves++
+	tasks := make([]models.Task, len(list.Tasks))
+	copy(tasks, list.Tasks)
+	cs.data = &models.TaskList{Tasks: tasks, NextID: list.NextID}
+	return nil
+}
+
+// TestCaptureLoadsAndSavesOnce tests that capture performs exactly one load and one save
+func TestCaptureLoadsAndSavesOnce(t *testing.T) {
+	st := &countingStorage{}
+	var out bytes.Buffer
+
+	err := Capture(strings.NewReader("  Call the dentist  \nignored second line\n"), &out, st, CaptureOptions{})
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+
+	if st.loads != 1 || st.saves != 1 {
+		t.Errorf("Expected 1 load and 1 save, got %d loads and %d saves", st.loads, st.saves)
+	}
+	if len(st.data.Tasks) != 1 || st.data.Tasks[0].Description != "Call the dentist" {
+		t.Errorf("Unexpected stored tasks: %+v", st.data.Tasks)
+	}
+	if got := out.String(); got != "✓ Task added: [1] Call the dentist\n" {
+		t.Errorf("Unexpected confirmation %q", got)
+	}
+}
+
+// TestCaptureEmptyInput tests that empty input creates nothing and touches no storage
+func TestCaptureEmptyInput(t *testing.T) {
+	for _, input := range []string{"", "\n", "   \n"} {
+		st := &countingStorage{}
+		var out bytes.Buffer
+
+		if err := Capture(strings.NewReader(input), &out, st, CaptureOptions{}); err != nil {
+			t.Fatalf("Capture(%q) failed: %v", input, err)
+		}
+		if st.loads != 0 || st.saves != 0 {
+			t.Errorf("Capture(%q): expected no storage access, got %d loads and %d saves", input, st.loads, st.saves)
+		}
+		if out.Len() != 0 {
+			t.Errorf("Capture(%q): expected no output, got %q", input, out.String())
+		}
+	}
+}
+
+// TestCaptureWindowAndPrompt tests the --window pause and the interactive prompt
+func TestCaptureWindowAndPrompt(t *testing.T) {
+	var slept time.Duration
+	sleep = func(d time.Duration) { slept = d }
+	defer func() { sleep = time.Sleep }()
+
+	st := &countingStorage{}
+	var out bytes.Buffer
+	err := Capture(strings.NewReader("Water plants"), &out, st, CaptureOptions{Prompt: true, Window: true})
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+
+	if slept != CaptureWindow {
+		t.Errorf("Expected window of %v, got %v", CaptureWindow, slept)
+	}
+	if !strings.HasPrefix(out.String(), "> ✓ Task added") {
+		t.Errorf("Expected prompt before confirmation, got %q", out.String())
+	}
+}
+
+// TestCaptureRecordsHistory tests that undo after capture removes only the
+// captured task
+func TestCaptureRecordsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	history := storage.NewFileHistory(path + ".undo")
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.SetHistory(history)
+	tl.AddTask("added")
+
+	opts := CaptureOptions{Config: &config.Config{}, StoragePath: path}
+	for _, line := range []string{"captured one\n", "captured two\n"} {
+		err := Capture(strings.NewReader(line), &bytes.Buffer{}, storage.NewFileStorage(path), opts)
+		if err != nil {
+			t.Fatalf("Capture failed: %v", err)
+		}
+	}
+
+	tl, _ = todolist.NewTodoList(storage.NewFileStorage(path))
+	tl.SetHistory(history)
+	if err := tl.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	tasks := tl.ListTasks()
+	if len(tasks) != 2 || tasks[0].Description != "added" || tasks[1].Description != "captured one" {
+		t.Errorf("Expected only the last capture undone, got %+v", tasks)
+	}
+}
+
+// TestCaptureAppliesConfig tests that capture keeps to max_tasks and
+// no_duplicates like add, and logs what it adds
+func TestCaptureAppliesConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	opts := CaptureOptions{Config: &config.Config{MaxTasks: 2, NoDuplicates: true}, StoragePath: path}
+	capture := func(line string) error {
+		return Capture(strings.NewReader(line), &bytes.Buffer{}, storage.NewFileStorage(path), opts)
+	}
+
+	if err := capture("Call the dentist"); err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	if err := capture("call the dentist "); !apperrors.IsDuplicateDescription(err) {
+		t.Errorf("Expected ErrDuplicateDescription, got %v", err)
+	}
+	if err := capture("Water plants"); err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	if err := capture("Book flights"); !apperrors.IsMaxTasksReached(err) {
+		t.Errorf("Expected ErrMaxTasksReached, got %v", err)
+	}
+
+	tl, _ := todolist.NewTodoList(storage.NewFileStorage(path))
+	if tasks := tl.ListTasks(); len(tasks) != 2 {
+		t.Errorf("Expected 2 tasks, got %+v", tasks)
+	}
+	events, err := storage.NewFileEventLog(path + ".events").Events()
+	if err != nil || len(events) != 2 {
+		t.Errorf("Expected 2 logged additions, got %+v, %v", events, err)
+	}
+}
@@ -1,27 +1,255 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
-	apperrors "todolist/internal/errors"
-	"todolist/internal/todolist"
+	"time"
+	"todolist/internal/buildinfo"
+	"todolist/internal/config"
+	"todolist/internal/env"
+	"todolist/internal/markdown"
+	"todolist/internal/output"
+	"todolist/internal/schema"
+	"todolist/internal/theme"
+	"todolist/internal/todotxt"
+	"todolist/internal/webhook"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/recurrence"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
 )
 
 // Command represents a parsed CLI command
 type Command struct {
 	Name string
 	Args []string
+	// Flags holds command-specific options such as "due"
+	Flags map[string]string
+
+	// GlobalFlags is never nil for a parsed command
+	*GlobalFlags
+
+	// Formatter styles output; nil means plain output
+	Formatter *theme.Formatter
+	// DateFormat is the layout used to display dates; empty means the default
+	DateFormat string
+	// StoragePath is the active task file, used by commands that work on files
+	StoragePath string
+	// ArchivePath is the default archive file; archive --file overrides it
+	ArchivePath string
+	// DefaultSort is the configured sort key for list; empty means list order
+	DefaultSort string
+	// WeekStart is the configured first day of the week for the stats
+	// trend (see config.ParseWeekday); empty means Monday
+	WeekStart string
+	// ListColumns is the configured default column set for list; empty means none
+	ListColumns string
+	// ConfirmDelete makes delete ask before deleting tasks by ID
+	ConfirmDelete bool
+	// Width is the terminal width for tables; 0 means unlimited
+	Width int
+	// Webhook posts task changes to the configured URL; nil when none is set
+	Webhook *webhook.Notifier
+	// Deprecations lists the deprecated names used on the command line
+	Deprecations []Deprecation
+}
+
+// GlobalFlags holds flags that apply to every command
+type GlobalFlags struct {
+	// Theme is the theme name given with --theme, empty if not set
+	Theme string
+	// ConfigPath is the config file given with --config, empty if not set
+	ConfigPath string
+	// Remote is the task server given with --remote, empty for the local file
+	Remote string
+	// Color is the --color / --no-color preference
+	Color output.ColorMode
+	// DryRun previews changes without saving them (--dry-run)
+	DryRun bool
+	// Recover sets aside a task file that is not valid JSON without asking (--recover)
+	Recover bool
+	// ReadOnly refuses every change to the task file (--read-only)
+	ReadOnly bool
+	// ForceSave overwrites the task file even if another process changed it (--force-save)
+	ForceSave bool
+	// LogLevel is the --log-level for change logs on stderr, nil for no logging
+	LogLevel *slog.Level
+}
+
+// colorFlag is a switch that sets the color preference to mode, so that
+// --color and --no-color share one destination
+type colorFlag struct {
+	dest *output.ColorMode
+	mode output.ColorMode
+}
+
+// String is required by flag.Value; the flags have no default to show
+func (f colorFlag) String() string { return "" }
+
+// IsBoolFlag lets the flag be given without a value
+func (f colorFlag) IsBoolFlag() bool { return true }
+
+// Set applies the mode when the switch is on
+func (f colorFlag) Set(value string) error {
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	if on {
+		*f.dest = f.mode
+	}
+	return nil
+}
+
+// newGlobalFlagSet returns a FlagSet that parses every global flag into g
+func newGlobalFlagSet(g *GlobalFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("todolist", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&g.Theme, "theme", "", "color theme")
+	fs.StringVar(&g.ConfigPath, "config", "", "config file")
+	fs.StringVar(&g.Remote, "remote", "", "task server URL")
+	fs.Var(colorFlag{&g.Color, output.ColorAlways}, "color", "force colored output")
+	fs.Var(colorFlag{&g.Color, output.ColorNever}, "no-color", "disable colored output")
+	fs.BoolVar(&g.DryRun, "dry-run", false, "preview changes without saving")
+	fs.BoolVar(&g.Recover, "recover", false, "set aside a damaged task file")
+	fs.BoolVar(&g.ReadOnly, "read-only", false, "refuse changes to the task file")
+	fs.BoolVar(&g.ForceSave, "force-save", false, "overwrite changes made by other processes")
+	fs.Func("log-level", "log changes to stderr", func(value string) error {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			return errors.New("must be debug, info, warn or error")
+		}
+		g.LogLevel = &level
+		return nil
+	})
+	return fs
 }
 
 // ParseCommand parses command line arguments into a Command structure
 func ParseCommand(args []string) (*Command, error) {
+	// Deprecated names are translated first so the rest of parsing only
+	// sees current ones
+	var tracker deprecationTracker
+	args, globals, err := extractGlobalFlags(tracker.translateFlags(args))
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := parseSubcommand(tracker.translateCommand(args))
+	if err != nil {
+		return nil, err
+	}
+	cmd.GlobalFlags = globals
+	cmd.Deprecations = tracker.used
+
+	if cmd.DryRun && !supportsDryRun(cmd) {
+		return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--dry-run is not supported by "+cmd.Name)
+	}
+	return cmd, nil
+}
+
+// supportsDryRun reports whether cmd only changes files through TodoList
+// saves, which --dry-run can skip. Commands that write other files (backups,
+// config, undo history) or only read are rejected rather than silently run.
+func supportsDryRun(cmd *Command) bool {
+	switch cmd.Name {
+	case "add", "done", "delete", "import", "merge", "move", "note", "copy", "prune", "renumber":
+		return true
+	case "restore":
+		return len(cmd.Args) == 1
+	case "archive":
+		return len(cmd.Args) == 0
+	case "trash":
+		return len(cmd.Args) != 0 || cmd.Flags["empty"] != ""
+	default:
+		return false
+	}
+}
+
+// ModifiesTaskFile reports whether cmd may write the task file, so that it
+// can be refused before doing anything when the file is read-only. Commands
+// that only read it, or write other files such as backups, exports or the
+// config, do not.
+func ModifiesTaskFile(cmd *Command) bool {
+	switch cmd.Name {
+	case "add", "done", "delete", "import", "merge", "move", "note", "copy", "prune", "renumber", "undo", "restore", "time-track", "capture":
+		return true
+	case "archive":
+		return len(cmd.Args) == 0
+	case "trash":
+		return len(cmd.Args) != 0 || cmd.Flags["empty"] != ""
+	case "init":
+		return cmd.Flags["repair"] != ""
+	default:
+		return false
+	}
+}
+
+// extractGlobalFlags removes global flags from args, wherever they appear,
+// and parses them with the global FlagSet. Only the --name forms are taken
+// so that command options such as add -i are left alone.
+func extractGlobalFlags(args []string) ([]string, *GlobalFlags, error) {
+	globals := &GlobalFlags{}
+	fs := newGlobalFlagSet(globals)
+	remaining := make([]string, 0, len(args))
+	flagArgs := []string{}
+
+	for i := 0; i < len(args); i++ {
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		f := fs.Lookup(name)
+		if !strings.HasPrefix(args[i], "--") || f == nil {
+			remaining = append(remaining, args[i])
+			continue
+		}
+
+		b, isBool := f.Value.(interface{ IsBoolFlag() bool })
+		if (!isBool || !b.IsBoolFlag()) && !hasValue {
+			// The value is the next argument, whatever it looks like
+			if i+1 >= len(args) {
+				return nil, globals, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--"+name+" requires a value")
+			}
+			flagArgs = append(flagArgs, "--"+name+"="+args[i+1])
+			i++
+			continue
+		}
+		flagArgs = append(flagArgs, args[i])
+	}
+
+	if err := fs.Parse(flagArgs); err != nil {
+		return nil, globals, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, err.Error())
+	}
+	return remaining, globals, nil
+}
+
+// parseSubcommand parses the command name and its arguments
+func parseSubcommand(args []string) (*Command, error) {
 	// Need at least one argument (the command name)
 	if len(args) == 0 {
 		return nil, apperrors.ErrInvalidCommand
 	}
 
-	cmdName := strings.ToLower(args[0])
+	cmdName := resolveAlias(strings.ToLower(args[0]))
+	if _, ok := lookupCommand(cmdName); !ok {
+		return nil, unknownCommandError(cmdName)
+	}
+
+	// <command> --help is help <command>
+	if isHelpRequest(args[1:]) {
+		return &Command{
+			Name: "help",
+			Args: []string{cmdName},
+		}, nil
+	}
 
 	// Validate command name
 	switch cmdName {
@@ -30,135 +258,1829 @@ func ParseCommand(args []string) (*Command, error) {
 		if len(args) < 2 {
 			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "add command requires a description")
 		}
-		// Join all remaining args as the description
-		description := strings.Join(args[1:], " ")
+		// Pull out --due, --priority, --recur, --estimate, --parse and -i, then join all remaining args as the description
+		flags := map[string]string{}
+		words := []string{}
+		for i := 1; i < len(args); i++ {
+			if args[i] == "-i" || args[i] == "--interactive" {
+				flags["interactive"] = "true"
+				continue
+			}
+			if args[i] == "--parse" {
+				flags["parse"] = "true"
+				continue
+			}
+			if args[i] == "--priority" {
+				if i+1 >= len(args) {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--priority requires high, medium or low")
+				}
+				if _, ok := models.ParsePriority(strings.ToLower(args[i+1])); !ok {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "priority must be high, medium, low or none")
+				}
+				flags["priority"] = strings.ToLower(args[i+1])
+				i++
+				continue
+			}
+			if args[i] == "--recur" {
+				if i+1 >= len(args) {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--recur requires daily, weekly, monthly or cron:<expr>")
+				}
+				if err := recurrence.Validate(args[i+1]); err != nil {
+					return nil, apperrors.WrapCommandError(err, "add")
+				}
+				flags["recur"] = args[i+1]
+				i++
+				continue
+			}
+			if args[i] == "--estimate" {
+				if i+1 >= len(args) {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--estimate requires a duration such as 30m, 2h or 1h30m")
+				}
+				if _, err := ParseEstimate(args[i+1]); err != nil {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, err.Error())
+				}
+				flags["estimate"] = args[i+1]
+				i++
+				continue
+			}
+			if args[i] == "--due" {
+				if i+1 >= len(args) {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--due requires a date (YYYY-MM-DD)")
+				}
+				if _, err := parseDueDate(args[i+1]); err != nil {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "due date must be YYYY-MM-DD or \"YYYY-MM-DD HH:MM\"")
+				}
+				flags["due"] = args[i+1]
+				i++
+				continue
+			}
+			words = append(words, args[i])
+		}
+		// With -i the description comes from the editor instead
+		if flags["interactive"] != "" {
+			if len(words) > 0 {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "add -i takes no description; write it in the editor")
+			}
+			return &Command{Name: "add", Flags: flags}, nil
+		}
+		if len(words) == 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "add command requires a description")
+		}
+		description := strings.Join(words, " ")
 		return &Command{
-			Name: "add",
-			Args: []string{description},
+			Name:  "add",
+			Args:  []string{description},
+			Flags: flags,
 		}, nil
 
 	case "list":
-		// list command takes no arguments
+		// list [--columns <names> | --format <template>] [--sort <key>] [--reverse] [--group] [--limit N] [--offset M]
+		//      [--since <date>] [--until <date>] [--verbose] [--watch [--interval <duration>]]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "list takes no positional arguments")
+		}
+		if value, ok := flags["columns"]; ok {
+			if _, err := config.ParseColumns(value); err != nil {
+				return nil, apperrors.WrapCommandError(fmt.Errorf("%w: %w", apperrors.ErrInvalidCommand, err), "list")
+			}
+		}
+		if value, ok := flags["format"]; ok {
+			if _, hasColumns := flags["columns"]; hasColumns {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "list takes --columns or --format, not both")
+			}
+			if flags["group"] != "" {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "list takes --group or --format, not both")
+			}
+			// Catch template errors before the task file is read
+			if _, err := newRenderer(&Command{}).listTemplate(value); err != nil {
+				return nil, apperrors.WrapCommandError(fmt.Errorf("%w: %w", apperrors.ErrInvalidCommand, err), "list")
+			}
+		}
+		for _, name := range []string{"limit", "offset"} {
+			if value, ok := flags[name]; ok {
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--"+name+" must be a number")
+				}
+				if n < 0 {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidPagination, "list")
+				}
+			}
+		}
+		if _, _, err := parseDateRange(flags["since"], flags["until"]); err != nil {
+			return nil, apperrors.WrapCommandError(err, "list")
+		}
+		if value, ok := flags["sort"]; ok {
+			if _, ok := todolist.ParseSortKey(value); !ok {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "unknown sort key '"+value+"' (keys: "+strings.Join(config.SortKeys, ", ")+")")
+			}
+		}
+		if value, ok := flags["interval"]; ok {
+			if flags["watch"] == "" {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--interval requires --watch")
+			}
+			if d, err := time.ParseDuration(value); err != nil || d < time.Second {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "interval must be a duration of at least 1s, e.g. 30s or 5m")
+			}
+		}
+		return &Command{
+			Name:  "list",
+			Args:  positional,
+			Flags: flags,
+		}, nil
+
+	case "overdue", "stats", "undo", "schema", "version", "deprecations":
+		// overdue, stats, undo, schema, version and deprecations take no arguments
+		return &Command{
+			Name: cmdName,
+			Args: []string{},
+		}, nil
+
+	case "count":
+		// count [--completed | --all | --status pending|done|all] [--json | --quiet]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "count takes no positional arguments")
+		}
+		if status, ok := flags["status"]; ok && status != "pending" && status != "done" && status != "all" {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--status must be pending, done or all")
+		}
+		selectors := 0
+		for _, name := range []string{"status", "completed", "all"} {
+			if _, ok := flags[name]; ok {
+				selectors++
+			}
+		}
+		if selectors > 1 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "count takes one of --completed, --all and --status")
+		}
+		// --completed and --all are shorthands for --status
+		if flags["completed"] != "" {
+			flags["status"] = "done"
+		}
+		if flags["all"] != "" {
+			flags["status"] = "all"
+		}
+		if flags["json"] != "" && flags["quiet"] != "" {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "count takes --json or --quiet, not both")
+		}
+		return &Command{
+			Name:  "count",
+			Args:  []string{},
+			Flags: flags,
+		}, nil
+
+	case "due":
+		// due takes no arguments
+		if len(args) != 1 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "due takes no arguments")
+		}
 		return &Command{
-			Name: "list",
+			Name: "due",
 			Args: []string{},
 		}, nil
 
+	case "upcoming":
+		// upcoming [--days N]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "upcoming takes no positional arguments")
+		}
+		if value, ok := flags["days"]; ok {
+			if n, err := strconv.Atoi(value); err != nil || n < 0 {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--days must be a non-negative number")
+			}
+		}
+		return &Command{
+			Name:  "upcoming",
+			Args:  []string{},
+			Flags: flags,
+		}, nil
+
+	case "stale":
+		// stale [--older-than <age>]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "stale takes no positional arguments")
+		}
+		if value, ok := flags["older-than"]; ok {
+			if _, err := parseAge(value, time.Now()); err != nil {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, err.Error())
+			}
+		}
+		return &Command{
+			Name:  "stale",
+			Args:  []string{},
+			Flags: flags,
+		}, nil
+
 	case "done":
-		// done command requires exactly one argument (task ID)
-		if len(args) != 2 {
+		// done takes one or more task IDs, or --all
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if flags["all"] != "" && len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "done --all cannot be combined with task IDs")
+		}
+		if flags["all"] == "" && len(positional) == 0 {
 			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "done command requires a task ID")
 		}
-		// Validate that the argument is a valid integer
-		if _, err := strconv.Atoi(args[1]); err != nil {
-			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "task ID must be a valid number")
+		if err := checkTaskRefsOrText(positional); err != nil {
+			return nil, err
 		}
 		return &Command{
-			Name: "done",
-			Args: []string{args[1]},
+			Name:  "done",
+			Args:  positional,
+			Flags: flags,
 		}, nil
 
 	case "delete":
-		// delete command requires exactly one argument (task ID)
-		if len(args) != 2 {
-			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "delete command requires a task ID")
+		// delete takes one or more task IDs, or --all [--yes]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if flags["all"] != "" {
+			if len(positional) != 0 {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "delete --all cannot be combined with task IDs")
+			}
+		} else {
+			if len(positional) == 0 {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "delete command requires a task ID")
+			}
 		}
-		// Validate that the argument is a valid integer
-		if _, err := strconv.Atoi(args[1]); err != nil {
-			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "task ID must be a valid number")
+		if err := checkTaskRefsOrText(positional); err != nil {
+			return nil, err
 		}
 		return &Command{
-			Name: "delete",
-			Args: []string{args[1]},
+			Name:  "delete",
+			Args:  positional,
+			Flags: flags,
 		}, nil
 
-	case "help":
-		// help command takes no arguments
+	case "trash":
+		// trash | trash restore <id> | trash --empty
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		usage := apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "usage: trash | trash restore <id> | trash --empty")
+		switch {
+		case len(positional) == 0:
+		case len(positional) == 2 && positional[0] == "restore" && flags["empty"] == "":
+			if id, err := strconv.Atoi(positional[1]); err != nil || id <= 0 {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidID, "trash restore needs the numeric ID the task had when deleted")
+			}
+		default:
+			return nil, usage
+		}
 		return &Command{
-			Name: "help",
-			Args: []string{},
+			Name:  "trash",
+			Args:  positional,
+			Flags: flags,
 		}, nil
 
-	default:
-		return nil, apperrors.ErrInvalidCommand
-	}
-}
+	case "move":
+		// move <id> before <before-id>; a before-id of 0 moves to the end.
+		// move <id> --to <position> | --up | --down moves by position.
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		moves := 0
+		for _, name := range []string{"to", "up", "down"} {
+			if _, ok := flags[name]; ok {
+				moves++
+			}
+		}
+		if moves > 0 {
+			if moves > 1 || len(positional) != 1 {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "move command requires <id> with one of --to <position>, --up or --down")
+			}
+			if !isTaskRef(positional[0]) {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "task ID must be a number or a UID prefix")
+			}
+			if value, ok := flags["to"]; ok {
+				if _, err := strconv.Atoi(value); err != nil {
+					return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--to requires a position number")
+				}
+			}
+			return &Command{
+				Name:  "move",
+				Args:  positional,
+				Flags: flags,
+			}, nil
+		}
+		if len(positional) != 3 || positional[1] != "before" {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "move command requires <id> before <before-id>")
+		}
+		for _, arg := range []string{positional[0], positional[2]} {
+			if !isTaskRef(arg) {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "task ID must be a number or a UID prefix")
+			}
+		}
+		return &Command{
+			Name: "move",
+			Args: []string{positional[0], positional[2]},
+		}, nil
 
-// ExecuteCommand executes a parsed command and returns formatted output
-func ExecuteCommand(cmd *Command, tl *todolist.TodoList) (string, error) {
-	switch cmd.Name {
-	case "add":
-		// Add a new task
-		task, err := tl.AddTask(cmd.Args[0])
+	case "note":
+		// note <id> [--append] [text...]; no text clears the note
+		flags, positional, err := parseFlags(cmdName, args[1:])
 		if err != nil {
-			return "", apperrors.WrapCommandError(err, "add")
+			return nil, err
 		}
-		return fmt.Sprintf("✓ Task added: [%d] %s", task.ID, task.Description), nil
+		if len(positional) == 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "note command requires a task ID")
+		}
+		if !isTaskRef(positional[0]) {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "task ID must be a number or a UID prefix")
+		}
+		return &Command{
+			Name:  "note",
+			Args:  []string{positional[0], strings.Join(positional[1:], " ")},
+			Flags: flags,
+		}, nil
 
-	case "list":
-		// List all tasks
-		tasks := tl.ListTasks()
-		if len(tasks) == 0 {
-			return "No tasks found. Add a task with: todolist add <description>", nil
+	case "copy":
+		// copy <id>
+		_, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 1 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "copy command requires a task ID")
 		}
+		if !isTaskRef(positional[0]) {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "task ID must be a number or a UID prefix")
+		}
+		return &Command{
+			Name: "copy",
+			Args: positional,
+		}, nil
 
-		var output strings.Builder
-		output.WriteString("Your tasks:\n")
-		for _, task := range tasks {
-			status := "[ ]"
-			if task.Completed {
-				status = "[✓]"
+	case "renumber":
+		// renumber [--yes]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "renumber takes no positional arguments")
+		}
+		return &Command{
+			Name:  "renumber",
+			Args:  positional,
+			Flags: flags,
+		}, nil
+
+	case "show":
+		// show <id> [--json] or show --desc <text> [--json]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := flags["desc"]; ok {
+			if len(positional) != 0 {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "show takes a task ID or --desc, not both")
 			}
-			output.WriteString(fmt.Sprintf("%s [%d] %s (created: %s)\n",
-				status,
-				task.ID,
-				task.Description,
-				task.CreatedAt.Format("2006-01-02 15:04:05")))
+			return &Command{
+				Name:  "show",
+				Args:  []string{},
+				Flags: flags,
+			}, nil
 		}
-		return strings.TrimSpace(output.String()), nil
+		if len(positional) != 1 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "show command requires a task ID")
+		}
+		if err := checkTaskRefsOrText(positional); err != nil {
+			return nil, err
+		}
+		return &Command{
+			Name:  "show",
+			Args:  positional,
+			Flags: flags,
+		}, nil
 
-	case "done":
-		// Mark task as completed
-		id, _ := strconv.Atoi(cmd.Args[0]) // Already validated in ParseCommand
-		if err := tl.CompleteTask(id); err != nil {
-			return "", apperrors.WrapCommandError(err, "done")
+	case "env":
+		// env [--json]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
 		}
-		return fmt.Sprintf("✓ Task %d marked as completed", id), nil
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "env takes no arguments")
+		}
+		return &Command{
+			Name:  "env",
+			Args:  positional,
+			Flags: flags,
+		}, nil
 
-	case "delete":
-		// Delete a task
-		id, _ := strconv.Atoi(cmd.Args[0]) // Already validated in ParseCommand
-		if err := tl.DeleteTask(id); err != nil {
-			return "", apperrors.WrapCommandError(err, "delete")
+	case "restore":
+		// restore takes an optional backup file and --yes to skip confirmation
+		cmd := &Command{
+			Name:  "restore",
+			Args:  []string{},
+			Flags: map[string]string{},
+		}
+		for _, arg := range args[1:] {
+			switch {
+			case arg == "--yes" || arg == "-y":
+				cmd.Flags["yes"] = "true"
+			case strings.HasPrefix(arg, "-"):
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "restore only accepts a backup file and --yes")
+			case len(cmd.Args) == 1:
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "restore takes at most one backup file")
+			default:
+				cmd.Args = append(cmd.Args, arg)
+			}
 		}
-		return fmt.Sprintf("✓ Task %d deleted", id), nil
+		return cmd, nil
 
-	case "help":
-		// Display help information
-		return getHelpText(), nil
+	case "capture":
+		// capture takes an optional --window flag
+		cmd := &Command{
+			Name:  "capture",
+			Args:  []string{},
+			Flags: map[string]string{},
+		}
+		for _, arg := range args[1:] {
+			if arg != "--window" {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "capture only accepts --window")
+			}
+			cmd.Flags["window"] = "true"
+		}
+		return cmd, nil
 
-	default:
-		return "", apperrors.ErrInvalidCommand
-	}
-}
+	case "remind":
+		// remind [--watch] [--interval <duration>]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "remind takes no positional arguments")
+		}
+		if value, ok := flags["interval"]; ok {
+			if flags["watch"] == "" {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--interval requires --watch")
+			}
+			if d, err := time.ParseDuration(value); err != nil || d < time.Second {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "interval must be a duration of at least 1s, e.g. 30s or 5m")
+			}
+		}
+		return &Command{
+			Name:  "remind",
+			Args:  positional,
+			Flags: flags,
+		}, nil
+
+	case "serve":
+		// serve [--addr <host:port>] [--metrics-addr <host:port>]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "serve takes no positional arguments")
+		}
+		if len(flags) == 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "usage: serve [--addr <host:port>] [--metrics-addr <host:port>]")
+		}
+		for _, name := range []string{"addr", "metrics-addr"} {
+			addr, ok := flags[name]
+			if !ok {
+				continue
+			}
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, fmt.Sprintf("--%s must be host:port: %v", name, err))
+			}
+		}
+		return &Command{
+			Name:  "serve",
+			Args:  positional,
+			Flags: flags,
+		}, nil
+
+	case "demo":
+		// demo [--seed N] [--keep]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "demo takes no positional arguments")
+		}
+		if value, ok := flags["seed"]; ok {
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--seed must be a number")
+			}
+		}
+		return &Command{
+			Name:  "demo",
+			Args:  positional,
+			Flags: flags,
+		}, nil
+
+	case "init":
+		// init [--repair]; without --repair it asks for the settings
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "init takes no arguments")
+		}
+		return &Command{
+			Name:  "init",
+			Args:  []string{},
+			Flags: flags,
+		}, nil
+
+	case "config":
+		// config | config set <key> <value>
+		if len(args) == 1 {
+			return &Command{Name: "config", Args: []string{}}, nil
+		}
+		if len(args) != 4 || strings.ToLower(args[1]) != "set" {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "usage: config | config set <key> <value>")
+		}
+		return &Command{
+			Name: "config",
+			Args: []string{args[2], args[3]},
+		}, nil
+
+	case "webhook":
+		// webhook test
+		if len(args) != 2 || strings.ToLower(args[1]) != "test" {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "usage: webhook test")
+		}
+		return &Command{
+			Name: "webhook",
+			Args: []string{"test"},
+		}, nil
 
-// getHelpText returns the help message
-func getHelpText() string {
-	return `Todo List CLI - A simple command-line todo list manager
+	case "report":
+		// report [--week YYYY-WN] [--json | --format text|md]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "report takes no positional arguments")
+		}
+		if value, ok := flags["week"]; ok {
+			if _, err := parseISOWeek(value); err != nil {
+				return nil, apperrors.WrapCommandError(err, "report")
+			}
+		}
+		if format, ok := flags["format"]; ok {
+			if format != "text" && format != "md" {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "report --format must be text or md")
+			}
+			if flags["json"] != "" {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "report takes --json or --format, not both")
+			}
+		}
+		return &Command{
+			Name:  "report",
+			Args:  []string{},
+			Flags: flags,
+		}, nil
 
-Usage:
-  todolist <command> [arguments]
+	case "time-track":
+		// time-track start|stop <id>
+		if len(args) != 3 || (strings.ToLower(args[1]) != "start" && strings.ToLower(args[1]) != "stop") {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "usage: time-track start|stop <id>")
+		}
+		if !isTaskRef(args[2]) {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "task ID must be a number or a UID prefix")
+		}
+		return &Command{
+			Name: "time-track",
+			Args: []string{strings.ToLower(args[1]), args[2]},
+		}, nil
 
-Commands:
-  add <description>    Add a new task
-  list                 List all tasks
-  done <id>            Mark a task as completed
-  delete <id>          Delete a task
-  help                 Show this help message
+	case "prune":
+		// prune --older-than <age>; required so prune never deletes every completed task by default
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "prune takes no positional arguments")
+		}
+		value, ok := flags["older-than"]
+		if !ok {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "prune requires --older-than <age>, e.g. --older-than 30d")
+		}
+		if _, err := parseAge(value, time.Now()); err != nil {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, err.Error())
+		}
+		return &Command{
+			Name:  "prune",
+			Args:  positional,
+			Flags: flags,
+		}, nil
 
-Examples:
-  todolist add "Buy groceries"
-  todolist list
-  todolist done 1
-  todolist delete 2`
+	case "archive":
+		// archive [list] [--file <path>]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) > 1 || (len(positional) == 1 && strings.ToLower(positional[0]) != "list") {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "usage: archive [list] [--file <path>]")
+		}
+		if len(positional) == 1 {
+			positional[0] = "list"
+		}
+		return &Command{
+			Name:  "archive",
+			Args:  positional,
+			Flags: flags,
+		}, nil
+
+	case "snapshot":
+		// snapshot <name> | snapshot list | snapshot delete <name>, each [--dir <dir>]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		usage := apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "usage: snapshot <name> | snapshot list | snapshot delete <name> [--dir <dir>]")
+		switch {
+		case len(positional) == 1 && positional[0] == "list":
+		case len(positional) == 2 && positional[0] == "delete":
+			if err := storage.ValidateSnapshotName(positional[1]); err != nil {
+				return nil, apperrors.WrapCommandError(err, "snapshot")
+			}
+		case len(positional) == 1 && positional[0] != "delete":
+			if err := storage.ValidateSnapshotName(positional[0]); err != nil {
+				return nil, apperrors.WrapCommandError(err, "snapshot")
+			}
+		default:
+			return nil, usage
+		}
+		return &Command{
+			Name:  "snapshot",
+			Args:  positional,
+			Flags: flags,
+		}, nil
+
+	case "export":
+		// export --format <format> [--output <path>]; an output of "-" is stdout
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "export takes no positional arguments")
+		}
+		if err := validateFormat(flags["format"]); err != nil {
+			return nil, err
+		}
+		if path, ok := flags["output"]; ok && path == "" {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--output needs a file path")
+		}
+		return &Command{
+			Name:  "export",
+			Args:  []string{},
+			Flags: flags,
+		}, nil
+
+	case "import":
+		// import --format <format> [file | --stdin]; no file or "-" reads stdin too
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) > 1 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "import takes at most one file")
+		}
+		if flags["stdin"] != "" && len(positional) == 1 && positional[0] != "-" {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "import takes a file or --stdin, not both")
+		}
+		if err := validateFormat(flags["format"]); err != nil {
+			return nil, err
+		}
+		return &Command{
+			Name:  "import",
+			Args:  positional,
+			Flags: flags,
+		}, nil
+
+	case "merge":
+		// merge <file>
+		_, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 1 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "merge command requires a task file")
+		}
+		return &Command{
+			Name: "merge",
+			Args: positional,
+		}, nil
+
+	case "backup":
+		// backup [--dir <dir>] [--max-backups N]
+		flags, positional, err := parseFlags(cmdName, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "backup takes no positional arguments")
+		}
+		if value, ok := flags["max-backups"]; ok {
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--max-backups must be a positive number")
+			}
+		}
+		return &Command{
+			Name:  "backup",
+			Args:  []string{},
+			Flags: flags,
+		}, nil
+
+	case "help":
+		// help [command]
+		if len(args) > 2 {
+			return nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "usage: help [command]")
+		}
+		if len(args) == 2 {
+			if _, err := commandHelp(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		return &Command{
+			Name: "help",
+			Args: args[1:],
+		}, nil
+
+	default:
+		return nil, unknownCommandError(cmdName)
+	}
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// writeFile creates path, replacing any existing file, and fills it with
+// write. It returns the number of bytes written; failures are storage
+// write errors.
+func writeFile(path string, write func(io.Writer) error) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+	cw := &countingWriter{w: file}
+	err = errors.Join(write(cw), file.Close())
+	if err != nil {
+		return cw.n, apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+	return cw.n, nil
+}
+
+// formats lists the supported import/export formats
+var formats = []string{"todotxt", "markdown", "json"}
+
+// validateFormat checks the --format value of import and export
+func validateFormat(format string) error {
+	for _, f := range formats {
+		if format == f {
+			return nil
+		}
+	}
+	if format == "" {
+		return apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--format is required (formats: "+strings.Join(formats, ", ")+")")
+	}
+	return apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "unknown format '"+format+"' (formats: "+strings.Join(formats, ", ")+")")
+}
+
+// parseFlags splits command arguments into --flag options and positional
+// arguments, taking the command's flags from commandSpecs. Flags with a
+// value take it as "--name value" or "--name=value"; both kinds are stored
+// in the returned map by name.
+func parseFlags(command string, args []string) (map[string]string, []string, error) {
+	valueFlags, boolFlags := commandFlags(command)
+	flags := map[string]string{}
+	positional := []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") || arg == "--" {
+			positional = append(positional, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		switch {
+		case contains(valueFlags, name):
+			if !hasValue {
+				if i+1 >= len(args) {
+					return nil, nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "--"+name+" requires a value")
+				}
+				value = args[i+1]
+				i++
+			}
+			flags[name] = value
+		case contains(boolFlags, name) && !hasValue:
+			flags[name] = "true"
+		default:
+			return nil, nil, apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "unknown flag '"+arg+"' for "+command)
+		}
+	}
+
+	return flags, positional, nil
+}
+
+// addFromInput adds a task for every non-blank line of Input (add -).
+// AddTasks rather than BulkAddTasks takes them so that the add options and
+// #tags apply to every line and a bad line adds nothing.
+func addFromInput(tl *todolist.TodoList, opts []todolist.TaskOption) (string, error) {
+	descriptions, err := ReadFromStdin(Input)
+	if err != nil {
+		return "", apperrors.WrapCommandError(err, "add")
+	}
+
+	tasks, err := tl.AddTasks(descriptions, opts...)
+	if err != nil {
+		return "", apperrors.WrapCommandError(err, "add")
+	}
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = strconv.Itoa(task.ID)
+	}
+	return fmt.Sprintf("✓ Added %d tasks: %s", len(tasks), strings.Join(ids, ", ")), nil
+}
+
+// forEachID applies op to every task ID in cmd.Args. Each success adds a
+// line built from format; the failures are returned together so that every
+// failing ID is reported.
+func forEachID(cmd *Command, op func(id int) error, format string) (string, error) {
+	// A single ID keeps the plain error path
+	if len(cmd.Args) == 1 {
+		id, _ := strconv.Atoi(cmd.Args[0]) // Resolved by resolveTaskRefs
+		if err := op(id); err != nil {
+			return "", apperrors.WrapCommandError(err, cmd.Name)
+		}
+		return fmt.Sprintf(format, id), nil
+	}
+
+	var lines []string
+	var failures []error
+	for _, arg := range cmd.Args {
+		id, _ := strconv.Atoi(arg) // Resolved by resolveTaskRefs
+		if err := op(id); err != nil {
+			failures = append(failures, err)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf(format, id))
+	}
+
+	output := strings.Join(lines, "\n")
+	if len(failures) == 0 {
+		return output, nil
+	}
+
+	// One %w per failure keeps every error matchable with errors.Is
+	args := make([]any, len(failures))
+	for i, err := range failures {
+		args[i] = err
+	}
+	verbs := strings.TrimSuffix(strings.Repeat("%w; ", len(failures)), "; ")
+	err := fmt.Errorf("%d of %d tasks failed: "+verbs, append([]any{len(failures), len(cmd.Args)}, args...)...)
+	return output, apperrors.WrapCommandError(err, cmd.Name)
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecuteCommand executes a parsed command and returns formatted output.
+// With --dry-run nothing is saved and every output line is prefixed with
+// "[dry-run]".
+func ExecuteCommand(cmd *Command, tl *todolist.TodoList) (string, error) {
+	if !cmd.DryRun {
+		return executeCommand(cmd, tl)
+	}
+
+	tl.SetDryRun(true)
+	result, err := executeCommand(cmd, tl)
+	if result != "" {
+		result = "[dry-run] " + strings.ReplaceAll(result, "\n", "\n[dry-run] ")
+	}
+	return result, err
+}
+
+// parseDateRange parses the --since and --until dates of list. The returned
+// until is the start of the day after, so that the whole day is included;
+// an empty value gives a zero time, leaving that end open. since must not
+// be after until.
+func parseDateRange(sinceValue, untilValue string) (since, until time.Time, err error) {
+	if sinceValue != "" {
+		if since, err = time.Parse("2006-01-02", sinceValue); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: --since must be a date in YYYY-MM-DD format", apperrors.ErrInvalidCommand)
+		}
+	}
+	if untilValue != "" {
+		if until, err = time.Parse("2006-01-02", untilValue); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: --until must be a date in YYYY-MM-DD format", apperrors.ErrInvalidCommand)
+		}
+		if since.After(until) {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: --since %s is after --until %s", apperrors.ErrInvalidDateRange, sinceValue, untilValue)
+		}
+		until = until.AddDate(0, 0, 1)
+	}
+	return since, until, nil
+}
+
+// taskRefArgs lists, for each command taking task IDs, the positions in
+// Args that hold one; nil means every argument
+var taskRefArgs = map[string][]int{
+	"done":       nil,
+	"delete":     nil,
+	"move":       {0, 1},
+	"note":       {0},
+	"copy":       {0},
+	"show":       {0},
+	"time-track": {1},
+}
+
+// isTaskRef reports whether arg can name a task: a number, or a prefix of
+// a task's UID (see models.ParseUIDRef)
+func isTaskRef(arg string) bool {
+	if _, err := strconv.Atoi(arg); err == nil {
+		return true
+	}
+	_, ok := models.ParseUIDRef(arg)
+	return ok
+}
+
+// textRefCommands lists the commands whose task arguments may also be text
+// matched against pending task descriptions (see resolveTaskRef)
+var textRefCommands = map[string]bool{"done": true, "delete": true, "show": true}
+
+// checkTaskRefsOrText rejects blank task arguments; anything else is an ID,
+// a UID prefix or text to match against descriptions
+func checkTaskRefsOrText(args []string) error {
+	for _, arg := range args {
+		if strings.TrimSpace(arg) == "" {
+			return apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "task ID or text cannot be blank")
+		}
+	}
+	return nil
+}
+
+// resolveTaskRef returns the ID of the task ref names: ref itself if it is
+// a number or written as uid:<prefix>, else the one pending task whose
+// description contains it, ignoring case, and only when none does the task
+// whose UID starts with it. Descriptions come first so that words made of
+// hex letters, like "ad", name the task they appear in. Text matching
+// several tasks is rejected with ErrInvalidID listing them, text matching
+// none with ErrTaskNotFound.
+func resolveTaskRef(tl *todolist.TodoList, ref string) (int, error) {
+	if _, err := strconv.Atoi(ref); err == nil || models.HasUIDRefPrefix(ref) {
+		return tl.ResolveID(ref)
+	}
+
+	matches := tl.FindPending(ref)
+	switch {
+	case len(matches) == 1:
+		return matches[0].ID, nil
+	case len(matches) > 1:
+		candidates := make([]string, len(matches))
+		for i, task := range matches {
+			candidates[i] = fmt.Sprintf("  [%d] %s", task.ID, task.Description)
+		}
+		return 0, fmt.Errorf("%w: %q matches %d pending tasks, use an ID:\n%s",
+			apperrors.ErrInvalidID, ref, len(matches), strings.Join(candidates, "\n"))
+	case isTaskRef(ref):
+		return tl.ResolveID(ref)
+	default:
+		return 0, fmt.Errorf("%w: no pending task matches %q", apperrors.ErrTaskNotFound, ref)
+	}
+}
+
+// resolveTaskRefs replaces the UID prefixes among the task ID arguments of
+// cmd with the IDs of the tasks they match, and for textRefCommands also
+// text with the task it matches
+func resolveTaskRefs(cmd *Command, tl *todolist.TodoList) error {
+	positions, ok := taskRefArgs[cmd.Name]
+	if !ok {
+		return nil
+	}
+	if positions == nil {
+		for i := range cmd.Args {
+			positions = append(positions, i)
+		}
+	}
+	for _, i := range positions {
+		if i >= len(cmd.Args) {
+			continue // Not given, as in show --desc
+		}
+		if _, err := strconv.Atoi(cmd.Args[i]); err == nil {
+			continue
+		}
+		resolve := tl.ResolveID
+		if textRefCommands[cmd.Name] {
+			resolve = func(ref string) (int, error) { return resolveTaskRef(tl, ref) }
+		}
+		id, err := resolve(cmd.Args[i])
+		if err != nil {
+			return err
+		}
+		cmd.Args[i] = strconv.Itoa(id)
+	}
+	return nil
+}
+
+// executeCommand runs a single command
+func executeCommand(cmd *Command, tl *todolist.TodoList) (string, error) {
+	if err := resolveTaskRefs(cmd, tl); err != nil {
+		return "", apperrors.WrapCommandError(err, cmd.Name)
+	}
+
+	switch cmd.Name {
+	case "add":
+		// Add a new task
+		var opts []todolist.TaskOption
+		// Inline tokens go first so explicit flags override them
+		if cmd.Flags["parse"] != "" {
+			opts = append(opts, todolist.WithQuickAdd(time.Now()))
+		}
+		if value, ok := cmd.Flags["due"]; ok {
+			due, _ := parseDueDate(value) // Already validated in ParseCommand
+			opts = append(opts, todolist.WithDueDate(due))
+		}
+		if value, ok := cmd.Flags["priority"]; ok {
+			priority, _ := models.ParsePriority(value) // Already validated in ParseCommand
+			opts = append(opts, todolist.WithPriority(priority))
+		}
+		if value, ok := cmd.Flags["recur"]; ok {
+			opts = append(opts, todolist.WithRecurrence(value))
+		}
+		if value, ok := cmd.Flags["estimate"]; ok {
+			minutes, _ := ParseEstimate(value) // Already validated in ParseCommand
+			opts = append(opts, todolist.WithEstimate(minutes))
+		}
+		description := ""
+		if cmd.Flags["interactive"] != "" {
+			var notes string
+			var err error
+			description, notes, err = OpenEditorForInput()
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "add")
+			}
+			if notes != "" {
+				opts = append(opts, todolist.WithNotes(notes))
+			}
+		} else {
+			description = cmd.Args[0]
+		}
+		if description == "-" {
+			return addFromInput(tl, opts)
+		}
+		task, err := tl.AddTask(description, opts...)
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "add")
+		}
+		return fmt.Sprintf("✓ Task added: [%d] %s", task.ID, task.Description), nil
+
+	case "list":
+		// List all tasks, or those created within --since and --until
+		tasks := tl.ListTasks()
+		if cmd.Flags["since"] != "" || cmd.Flags["until"] != "" {
+			if len(tasks) > 0 {
+				since, until, _ := parseDateRange(cmd.Flags["since"], cmd.Flags["until"]) // Already validated in ParseCommand
+				tasks = tl.ListTasksInRange(since, until)
+			}
+			if len(tasks) == 0 && cmd.Flags["format"] == "" {
+				return "No tasks created in that range", nil
+			}
+		}
+		if len(tasks) == 0 && cmd.Flags["format"] == "" {
+			return "No tasks found. Add a task with: todolist add <description>", nil
+		}
+		// --sort, else default_sort from the config, else list order
+		sortName := cmd.Flags["sort"]
+		if sortName == "" {
+			sortName = cmd.DefaultSort
+		}
+		key, ok := todolist.ParseSortKey(sortName)
+		if !ok {
+			key = todolist.SortByManual
+		}
+		tasks = todolist.SortTasks(tasks, key, cmd.Flags["reverse"] != "")
+
+		// Paginate after sorting so pages follow the displayed order
+		footer := ""
+		if cmd.Flags["limit"] != "" || cmd.Flags["offset"] != "" {
+			offset, _ := strconv.Atoi(cmd.Flags["offset"]) // Already validated in ParseCommand
+			limit, _ := strconv.Atoi(cmd.Flags["limit"])
+			total := len(tasks)
+			page, err := todolist.Paginate(tasks, offset, limit)
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "list")
+			}
+			if len(page) == 0 {
+				return fmt.Sprintf("No tasks at offset %d (%d tasks in total)", offset, total), nil
+			}
+			tasks = page
+			footer = fmt.Sprintf("\nShowing %d–%d of %d tasks", offset+1, offset+len(page), total)
+		}
+
+		// A template prints exactly what it describes, without header or footer
+		if format := cmd.Flags["format"]; format != "" {
+			r := newRenderer(cmd)
+			tmpl, err := r.listTemplate(format)
+			if err != nil {
+				return "", apperrors.WrapCommandError(fmt.Errorf("%w: %w", apperrors.ErrInvalidCommand, err), "list")
+			}
+			result, err := r.templateList(tmpl, tasks)
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "list")
+			}
+			return result, nil
+		}
+
+		// A column set (--columns, else list_columns from the config) selects the table
+		spec := cmd.Flags["columns"]
+		if spec == "" {
+			spec = cmd.ListColumns
+		}
+		if spec != "" {
+			names, err := config.ParseColumns(spec)
+			if err != nil {
+				return "", apperrors.WrapCommandError(fmt.Errorf("%w: %w", apperrors.ErrInvalidCommand, err), "list")
+			}
+			if cmd.Flags["verbose"] != "" && !contains(names, "completed") {
+				names = append(names, "completed")
+			}
+			r := newRenderer(cmd)
+			if cmd.Flags["group"] != "" {
+				return r.taskGroups(todolist.GroupTasks(tasks, todolist.GroupByStatus), func(tasks []models.Task) string {
+					return r.taskTable(names, tasks, cmd.Width)
+				}) + footer, nil
+			}
+			return r.taskTable(names, tasks, cmd.Width) + footer, nil
+		}
+		r := newRenderer(cmd)
+		if cmd.Flags["group"] != "" {
+			return r.taskGroups(todolist.GroupTasks(tasks, todolist.GroupByStatus), r.taskLines) + footer, nil
+		}
+		return r.taskList("Your tasks:", tasks) + footer, nil
+
+	case "overdue":
+		// List pending tasks past their due date
+		tasks := tl.ListOverdue()
+		if len(tasks) == 0 {
+			return "No overdue tasks.", nil
+		}
+		return newRenderer(cmd).taskList("Overdue tasks:", tasks), nil
+
+	case "count":
+		// A bare number for shell prompts and status bars; counting never
+		// saves, so it also works on a read-only task file
+		pending, completed := false, true
+		total := tl.Count(storage.StorageFilter{})
+		open := tl.Count(storage.StorageFilter{Status: &pending})
+		done := tl.Count(storage.StorageFilter{Status: &completed})
+		if cmd.Flags["json"] != "" {
+			data, err := json.Marshal(struct {
+				Total     int `json:"total"`
+				Pending   int `json:"pending"`
+				Completed int `json:"completed"`
+			}{total, open, done})
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "count")
+			}
+			return string(data), nil
+		}
+		// --quiet is accepted for older scripts; the output is always bare
+		switch cmd.Flags["status"] {
+		case "done":
+			return strconv.Itoa(done), nil
+		case "all":
+			return strconv.Itoa(total), nil
+		default:
+			return strconv.Itoa(open), nil
+		}
+
+	case "due":
+		// List pending tasks due today or earlier; overdue ones set the exit code
+		now := time.Now()
+		tasks := tl.DueBefore(dayStart(now).AddDate(0, 0, 1))
+		if len(tasks) == 0 {
+			return "Nothing due today.", nil
+		}
+		result := newRenderer(cmd).taskList("Due today or earlier:", tasks)
+		overdue := 0
+		for _, task := range tasks {
+			if task.IsOverdue(now) {
+				overdue++
+			}
+		}
+		if overdue > 0 {
+			return result, fmt.Errorf("%w: %d of them", apperrors.ErrTasksOverdue, overdue)
+		}
+		return result, nil
+
+	case "upcoming":
+		// List pending tasks due from now to the end of the day --days ahead
+		days := 7
+		if value, ok := cmd.Flags["days"]; ok {
+			days, _ = strconv.Atoi(value) // Already validated in ParseCommand
+		}
+		now := time.Now()
+		tasks := tl.DueBetween(now, dayStart(now).AddDate(0, 0, days+1))
+		if len(tasks) == 0 {
+			return fmt.Sprintf("Nothing due in the next %d days.", days), nil
+		}
+		return newRenderer(cmd).taskList(fmt.Sprintf("Due in the next %d days:", days), tasks), nil
+
+	case "stale":
+		// List pending tasks created at least --older-than ago, oldest first
+		age := defaultStaleAge
+		if value, ok := cmd.Flags["older-than"]; ok {
+			age = value
+		}
+		now := time.Now()
+		cutoff, _ := parseAge(age, now) // Already validated in ParseCommand
+		tasks := tl.ListStale(now.Sub(cutoff))
+		if len(tasks) == 0 {
+			return "No tasks pending for " + age + " or more.", nil
+		}
+		return newRenderer(cmd).staleList(fmt.Sprintf("Pending for %s or more:", age), tasks), nil
+
+	case "stats":
+		// Show task counts
+		stats := tl.Stats()
+		output := fmt.Sprintf("Total: %d\nPending: %d\nCompleted: %d\nOverdue: %d",
+			stats.Total, stats.Pending, stats.Completed, stats.OverdueCount)
+		// Weeks begin on Monday unless week_start names another weekday
+		start, err := config.ParseWeekday(cmd.WeekStart)
+		if err != nil {
+			start = time.Monday
+		}
+		for _, line := range trendLines(tl.CompletionTrend(start)) {
+			output += "\n" + line
+		}
+		// Estimates only show once some task has one
+		if stats.TotalEstimatedMinutes > 0 {
+			output += fmt.Sprintf("\nEstimated: %s (%s completed)",
+				formatEstimate(stats.TotalEstimatedMinutes), formatEstimate(stats.CompletedEstimatedMinutes))
+		}
+		return output, nil
+
+	case "done":
+		// Complete every pending task
+		if cmd.Flags["all"] != "" {
+			count, err := tl.CompleteAll()
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "done")
+			}
+			if count == 0 {
+				return "All tasks are already completed", nil
+			}
+			return fmt.Sprintf("✓ Marked %d tasks as completed", count), nil
+		}
+
+		// Mark tasks as completed
+		return forEachID(cmd, tl.CompleteTask, "✓ Task %d marked as completed")
+
+	case "delete":
+		// Delete every task after confirmation
+		if cmd.Flags["all"] != "" {
+			if cmd.Flags["yes"] == "" && !cmd.DryRun && !confirm(fmt.Sprintf("Delete all %d tasks?", len(tl.ListTasks()))) {
+				return "Delete cancelled", nil
+			}
+			deleteAll := tl.DeleteAll
+			if cmd.Flags["force"] != "" {
+				deleteAll = tl.PurgeAll
+			}
+			count, err := deleteAll()
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "delete")
+			}
+			return fmt.Sprintf("✓ Deleted %d tasks", count), nil
+		}
+
+		// With confirm_delete set, ask first unless --yes is given
+		if cmd.ConfirmDelete && cmd.Flags["yes"] == "" && !cmd.DryRun {
+			noun := "task"
+			if len(cmd.Args) > 1 {
+				noun = "tasks"
+			}
+			if !confirm(fmt.Sprintf("Delete %s %s?", noun, strings.Join(cmd.Args, ", "))) {
+				return "Delete cancelled", nil
+			}
+		}
+
+		// Delete tasks, keeping them in the trash unless --force is given
+		if cmd.Flags["force"] != "" {
+			return forEachID(cmd, tl.PurgeTask, "✓ Task %d deleted permanently")
+		}
+		return forEachID(cmd, tl.DeleteTask, "✓ Task %d deleted")
+
+	case "trash":
+		// Deleted tasks, kept until restored, purged or TrashRetention old
+		if cmd.Flags["empty"] != "" {
+			count, err := tl.EmptyTrash()
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "trash")
+			}
+			if count == 0 {
+				return "The trash is already empty", nil
+			}
+			return fmt.Sprintf("✓ Permanently deleted %d tasks from the trash", count), nil
+		}
+		if len(cmd.Args) == 2 {
+			id, _ := strconv.Atoi(cmd.Args[1]) // Already validated in ParseCommand
+			task, err := tl.RestoreTask(id)
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "trash")
+			}
+			if task.ID != id {
+				return fmt.Sprintf("✓ Task %d restored as task %d (its ID was taken): %s", id, task.ID, task.Description), nil
+			}
+			return fmt.Sprintf("✓ Task %d restored: %s", id, task.Description), nil
+		}
+		trash := tl.Trash()
+		if len(trash) == 0 {
+			return "The trash is empty", nil
+		}
+		return newRenderer(cmd).trashList("Trash:", trash), nil
+
+	case "move":
+		// Reorder a task without changing its ID
+		id, _ := strconv.Atoi(cmd.Args[0]) // Resolved by resolveTaskRefs
+		if len(cmd.Args) == 1 {
+			var err error
+			switch {
+			case cmd.Flags["up"] != "":
+				err = tl.MoveTaskBy(id, -1)
+			case cmd.Flags["down"] != "":
+				err = tl.MoveTaskBy(id, 1)
+			default:
+				position, _ := strconv.Atoi(cmd.Flags["to"]) // Already validated in ParseCommand
+				err = tl.MoveTaskTo(id, position-1)
+			}
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "move")
+			}
+			for i, task := range tl.ListTasks() {
+				if task.ID == id {
+					return fmt.Sprintf("✓ Moved task %d to position %d", id, i+1), nil
+				}
+			}
+			return fmt.Sprintf("✓ Moved task %d", id), nil
+		}
+		beforeID, _ := strconv.Atoi(cmd.Args[1]) // Resolved by resolveTaskRefs
+		if err := tl.MoveTask(id, beforeID); err != nil {
+			return "", apperrors.WrapCommandError(err, "move")
+		}
+		if beforeID == 0 {
+			return fmt.Sprintf("✓ Moved task %d to the end", id), nil
+		}
+		return fmt.Sprintf("✓ Moved task %d before task %d", id, beforeID), nil
+
+	case "note":
+		// Replace, append to or clear a task's notes
+		id, _ := strconv.Atoi(cmd.Args[0]) // Resolved by resolveTaskRefs
+		appendNote := cmd.Flags["append"] != ""
+		if err := tl.SetNote(id, cmd.Args[1], appendNote); err != nil {
+			return "", apperrors.WrapCommandError(err, "note")
+		}
+		switch {
+		case appendNote:
+			return fmt.Sprintf("✓ Note added to task %d", id), nil
+		case cmd.Args[1] == "":
+			return fmt.Sprintf("✓ Note cleared from task %d", id), nil
+		default:
+			return fmt.Sprintf("✓ Note set on task %d", id), nil
+		}
+
+	case "copy":
+		// Add a pending copy of a task
+		id, _ := strconv.Atoi(cmd.Args[0]) // Resolved by resolveTaskRefs
+		task, err := tl.DuplicateTask(id)
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "copy")
+		}
+		return fmt.Sprintf("✓ Task %d duplicated as task %d", id, task.ID), nil
+
+	case "renumber":
+		// Close the gaps in the IDs after confirmation
+		if len(tl.ListTasks()) == 0 {
+			return "No tasks to renumber", nil
+		}
+		if cmd.Flags["yes"] == "" && !cmd.DryRun && !confirm("Renumber every task? IDs in scripts and notes will no longer match") {
+			return "Renumber cancelled", nil
+		}
+		if err := tl.RenumberIDs(); err != nil {
+			return "", apperrors.WrapCommandError(err, "renumber")
+		}
+		return fmt.Sprintf("✓ Renumbered tasks as 1-%d", len(tl.ListTasks())), nil
+
+	case "show":
+		// Show every field of a single task, found by ID or description
+		var task models.Task
+		if desc, ok := cmd.Flags["desc"]; ok {
+			found, err := tl.GetTaskByDescription(desc)
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "show")
+			}
+			task = *found
+		} else {
+			id, _ := strconv.Atoi(cmd.Args[0]) // Resolved by resolveTaskRefs
+			var err error
+			if task, err = tl.GetTask(id); err != nil {
+				return "", apperrors.WrapCommandError(err, "show")
+			}
+		}
+		if cmd.Flags["json"] != "" {
+			data, err := json.MarshalIndent(task, "", "  ")
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "show")
+			}
+			return string(data), nil
+		}
+		return newRenderer(cmd).taskDetail(task), nil
+
+	case "report":
+		// Summarize a week of changes, the current one by default
+		from := weekStart(time.Now())
+		if value, ok := cmd.Flags["week"]; ok {
+			from, _ = parseISOWeek(value) // Already validated in ParseCommand
+		}
+		report, err := tl.WeeklyReport(from)
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "report")
+		}
+		switch {
+		case cmd.Flags["json"] != "":
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "report")
+			}
+			return string(data), nil
+		case cmd.Flags["format"] == "md":
+			return weeklyReportMarkdown(report), nil
+		default:
+			return newRenderer(cmd).weeklyReport(report), nil
+		}
+
+	case "time-track":
+		// Start or stop the timer on a task
+		id, _ := strconv.Atoi(cmd.Args[1]) // Resolved by resolveTaskRefs
+		if cmd.Args[0] == "start" {
+			if err := tl.StartTimeTracking(id); err != nil {
+				return "", apperrors.WrapCommandError(err, "time-track")
+			}
+			return fmt.Sprintf("✓ Timer started on task %d", id), nil
+		}
+		if err := tl.StopTimeTracking(id); err != nil {
+			return "", apperrors.WrapCommandError(err, "time-track")
+		}
+		return fmt.Sprintf("✓ Timer stopped on task %d (total %s)", id, formatDuration(tl.TaskTimeSpent(id))), nil
+
+	case "undo":
+		// Revert the most recent change
+		if err := tl.Undo(); err != nil {
+			if apperrors.IsNothingToUndo(err) {
+				return "Nothing to undo", nil
+			}
+			return "", apperrors.WrapCommandError(err, "undo")
+		}
+		return "✓ Last change undone", nil
+
+	case "schema":
+		// Print the JSON Schema of the storage format
+		data, err := schema.Generate()
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "schema")
+		}
+		return string(data), nil
+
+	case "deprecations":
+		// List deprecated names with their replacements
+		return deprecationList(), nil
+
+	case "env":
+		// Report the environment variables todolist reads
+		if cmd.Flags["json"] != "" {
+			data, err := json.MarshalIndent(env.Statuses(), "", "  ")
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "env")
+			}
+			return string(data), nil
+		}
+		return newRenderer(cmd).envList(env.Statuses()), nil
+
+	case "version":
+		// Show the build metadata embedded at link time
+		return fmt.Sprintf("todolist v%s (commit %s, built %s)",
+			strings.TrimPrefix(buildinfo.Version, "v"), buildinfo.Commit, buildinfo.BuildDate), nil
+
+	case "restore":
+		confirmed := cmd.Flags["yes"] != ""
+
+		// Replace the task list with a given backup file
+		if len(cmd.Args) == 1 {
+			if !confirmed && !cmd.DryRun && !confirm(fmt.Sprintf("Replace the current task list with %s?", cmd.Args[0])) {
+				return "Restore cancelled", nil
+			}
+			if err := tl.RestoreFromFile(cmd.Args[0]); err != nil {
+				return "", apperrors.WrapCommandError(err, "restore")
+			}
+			return fmt.Sprintf("✓ Restored %d tasks from %s", len(tl.ListTasks()), cmd.Args[0]), nil
+		}
+
+		// Swap the most recent backup back in
+		if !confirmed && !confirm("Replace the current task list with the most recent backup?") {
+			return "Restore cancelled", nil
+		}
+		if err := tl.RestoreBackup(); err != nil {
+			return "", apperrors.WrapCommandError(err, "restore")
+		}
+		return fmt.Sprintf("✓ Backup restored (%d tasks)", len(tl.ListTasks())), nil
+
+	case "config":
+		// Show the settings in effect, or update a single key in the config file
+		path := cmd.ConfigPath
+		if path == "" {
+			var err error
+			if path, err = config.DefaultPath(); err != nil {
+				return "", apperrors.WrapCommandError(err, "config")
+			}
+		}
+		cfg, err := config.LoadConfig(path)
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "config")
+		}
+		if len(cmd.Args) == 0 {
+			return formatSettings(path, effectiveSettings(cmd, cfg, path)), nil
+		}
+		if err := cfg.Set(cmd.Args[0], cmd.Args[1]); err != nil {
+			return "", apperrors.WrapCommandError(fmt.Errorf("%w: %w", apperrors.ErrInvalidCommand, err), "config")
+		}
+		if err := config.SaveConfig(path, cfg); err != nil {
+			return "", apperrors.WrapCommandError(err, "config")
+		}
+		return fmt.Sprintf("✓ Set %s = %s in %s", cmd.Args[0], cmd.Args[1], path), nil
+
+	case "prune":
+		// Delete tasks completed longer ago than the given age
+		cutoff, _ := parseAge(cmd.Flags["older-than"], time.Now()) // Already validated in ParseCommand
+		count, err := tl.PruneCompleted(cutoff)
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "prune")
+		}
+		if count == 0 {
+			return "No completed tasks older than " + cmd.Flags["older-than"], nil
+		}
+		return fmt.Sprintf("✓ Pruned %d completed tasks", count), nil
+
+	case "archive":
+		path := cmd.Flags["file"]
+		if path == "" {
+			path = cmd.ArchivePath
+		}
+		if len(cmd.Args) == 1 {
+			// Show the archived tasks
+			archived, err := storage.NewFileStorage(path).Load()
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "archive")
+			}
+			if len(archived.Tasks) == 0 {
+				return "No archived tasks.", nil
+			}
+			return newRenderer(cmd).taskList("Archived tasks:", archived.Tasks), nil
+		}
+
+		// Move completed tasks out of the active list
+		count, err := tl.Archive(path)
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "archive")
+		}
+		if count == 0 {
+			return "No completed tasks to archive.", nil
+		}
+		return fmt.Sprintf("✓ Archived %d completed tasks to %s", count, path), nil
+
+	case "snapshot":
+		// Named copies of the list, kept next to the task file by default
+		dir := cmd.Flags["dir"]
+		if dir == "" {
+			dir = cmd.StoragePath + ".snapshots"
+		}
+		switch cmd.Args[0] {
+		case "list":
+			snapshots, err := storage.ListSnapshots(dir)
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "snapshot")
+			}
+			if len(snapshots) == 0 {
+				return "No snapshots in " + dir, nil
+			}
+			r := newRenderer(cmd)
+			lines := []string{r.f.Paint(theme.RoleHeader, "Snapshots in "+dir+":")}
+			for _, s := range snapshots {
+				lines = append(lines, fmt.Sprintf("  %s  (created %s, %d tasks)", s.Name, s.Created.Format(r.dateFormat), s.Tasks))
+			}
+			return strings.Join(lines, "\n"), nil
+		case "delete":
+			if err := storage.DeleteSnapshot(cmd.Args[1], dir); err != nil {
+				return "", apperrors.WrapCommandError(err, "snapshot")
+			}
+			return fmt.Sprintf("✓ Deleted snapshot %q", cmd.Args[1]), nil
+		default:
+			list := tl.TaskList()
+			if err := storage.SaveSnapshot(cmd.Args[0], dir, list, tl.JSONIndent()); err != nil {
+				return "", apperrors.WrapCommandError(err, "snapshot")
+			}
+			return fmt.Sprintf("✓ Snapshot %q saved with %d tasks to %s", cmd.Args[0], len(list.Tasks),
+				filepath.Join(dir, cmd.Args[0]+".json")), nil
+		}
+
+	case "export":
+		// Write all tasks to stdout, or to --output, in the requested format
+		tasks := tl.ListTasks()
+		var write func(io.Writer) error
+		switch cmd.Flags["format"] {
+		case "json":
+			// The whole list, next ID included, in the task file format
+			write = tl.SaveToWriter
+		case "markdown":
+			write = func(w io.Writer) error { return markdown.Write(w, tasks) }
+		default:
+			write = func(w io.Writer) error { return todotxt.Write(w, tasks) }
+		}
+		if path := cmd.Flags["output"]; path != "" && path != "-" {
+			n, err := writeFile(path, write)
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "export")
+			}
+			return fmt.Sprintf("✓ Exported %d tasks (%d bytes) to %s", len(tasks), n, path), nil
+		}
+		var out strings.Builder
+		if err := write(&out); err != nil {
+			return "", apperrors.WrapCommandError(err, "export")
+		}
+		return strings.TrimSuffix(out.String(), "\n"), nil
+
+	case "import":
+		// Read tasks from a file or stdin
+		in, name := Input, "stdin"
+		if len(cmd.Args) == 1 && cmd.Args[0] != "-" {
+			file, err := os.Open(cmd.Args[0])
+			if err != nil {
+				return "", apperrors.WrapCommandError(apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), cmd.Args[0]), "import")
+			}
+			defer file.Close()
+			in, name = file, cmd.Args[0]
+		}
+
+		result := &todotxt.Result{}
+		switch cmd.Flags["format"] {
+		case "json":
+			// A whole task list, as written by export --format json; its
+			// tasks are added with new IDs like those of the other formats
+			list, err := storage.Decode(in, name)
+			if err == nil {
+				err = list.Validate()
+			}
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "import")
+			}
+			result.Tasks = list.Tasks
+		case "markdown":
+			// Markdown has no lines to reject: anything but a checklist
+			// item is skipped
+			tasks, err := markdown.Parse(in, time.Now())
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "import")
+			}
+			result.Tasks = tasks
+		default:
+			parsed, err := todotxt.Parse(in, time.Now())
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "import")
+			}
+			result = parsed
+		}
+		imported, err := tl.ImportTasks(result.Tasks)
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "import")
+		}
+
+		var out strings.Builder
+		fmt.Fprintf(&out, "✓ Imported %d tasks", len(imported))
+		if duplicates := len(result.Tasks) - len(imported); duplicates > 0 {
+			fmt.Fprintf(&out, "\nSkipped %d tasks already in the list (same UID)", duplicates)
+		}
+		for _, issue := range result.Warnings {
+			fmt.Fprintf(&out, "\nWarning: %s", issue)
+		}
+		for _, issue := range result.Errors {
+			fmt.Fprintf(&out, "\nSkipped: %s", issue)
+		}
+		return out.String(), nil
+
+	case "merge":
+		// Fold the tasks of another task file into this one
+		file, err := os.Open(cmd.Args[0])
+		if err != nil {
+			return "", apperrors.WrapCommandError(apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), cmd.Args[0]), "merge")
+		}
+		defer file.Close()
+		list, err := storage.Decode(file, cmd.Args[0])
+		if err == nil {
+			err = list.Validate()
+		}
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "merge")
+		}
+		result, err := tl.MergeTasks(list.Tasks)
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "merge")
+		}
+		return mergeSummary(cmd.Args[0], result), nil
+
+	case "backup":
+		// Copy the storage file to a timestamped backup
+		dir := cmd.Flags["dir"]
+		if dir == "" {
+			dir = filepath.Dir(cmd.StoragePath)
+		}
+		path, err := storage.BackupStorage(cmd.StoragePath, dir)
+		if err != nil {
+			return "", apperrors.WrapCommandError(err, "backup")
+		}
+		result := "✓ Backup created: " + path
+
+		if value, ok := cmd.Flags["max-backups"]; ok {
+			max, _ := strconv.Atoi(value) // Already validated in ParseCommand
+			removed, err := storage.PruneBackups(dir, max)
+			if err != nil {
+				return "", apperrors.WrapCommandError(err, "backup")
+			}
+			if len(removed) > 0 {
+				result += fmt.Sprintf("\nRemoved %d old backup(s)", len(removed))
+			}
+		}
+		return result, nil
+
+	case "webhook":
+		// Send a ping so the receiving end can be checked
+		if cmd.Webhook == nil {
+			return "", apperrors.WrapCommandError(apperrors.ErrInvalidCommand, "no webhook configured; set one with 'todolist config set webhook.url <url>'")
+		}
+		if err := cmd.Webhook.Send(webhook.TaskEvent{Event: webhook.PingEvent, Timestamp: time.Now()}); err != nil {
+			return "", apperrors.WrapCommandError(err, "webhook")
+		}
+		return "✓ Sent a test event to " + cmd.Webhook.URL(), nil
+
+	case "help":
+		// Display help information, for one command if given
+		if len(cmd.Args) == 1 {
+			return commandHelp(cmd.Args[0]) // Already validated in ParseCommand
+		}
+		return getHelpText(), nil
+
+	default:
+		return "", apperrors.ErrInvalidCommand
+	}
 }
@@ -0,0 +1,1320 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"todolist/internal/config"
+	"todolist/internal/output"
+	"todolist/internal/webhook"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// TestDryRunLeavesFileUnchanged tests that --dry-run reports changes without saving them
+func TestDryRunLeavesFileUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	fs := storage.NewFileStorage(path)
+	tl, err := todolist.NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("first")
+	tl.AddTask("second")
+
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read task file: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--dry-run", "add", "third"},
+		{"--dry-run", "done", "1"},
+		{"delete", "1", "2", "--dry-run"},
+	} {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		tl, err := todolist.NewTodoList(fs)
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+
+		output, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", args, err)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			if !strings.HasPrefix(line, "[dry-run] ✓") {
+				t.Errorf("Expected dry-run success line for %v, got %q", args, line)
+			}
+		}
+
+		after, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read task file: %v", err)
+		}
+		if !bytes.Equal(before, after) {
+			t.Errorf("Expected task file to be unchanged after %v", args)
+		}
+	}
+}
+
+// TestDryRunRejectedForOtherCommands tests that commands writing other files refuse --dry-run
+func TestDryRunRejectedForOtherCommands(t *testing.T) {
+	for _, args := range [][]string{
+		{"--dry-run", "undo"},
+		{"--dry-run", "backup"},
+		{"--dry-run", "restore"},
+		{"--dry-run", "capture"},
+		{"--dry-run", "config", "set", "theme", "mono"},
+	} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestGlobalFlags tests that global flags are taken from anywhere on the
+// command line, in both --name value and --name=value forms
+func TestGlobalFlags(t *testing.T) {
+	cmd, err := ParseCommand([]string{"--theme", "mono", "add", "buy", "--priority", "high", "--no-color", "milk", "--config=c.json", "--dry-run"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	want := GlobalFlags{Theme: "mono", ConfigPath: "c.json", Color: output.ColorNever, DryRun: true}
+	if *cmd.GlobalFlags != want {
+		t.Errorf("Expected %+v, got %+v", want, *cmd.GlobalFlags)
+	}
+	if cmd.Name != "add" || cmd.Args[0] != "buy milk" || cmd.Flags["priority"] != "high" {
+		t.Errorf("Expected the command arguments to be left alone, got %+v", cmd)
+	}
+
+	// A value flag takes the next argument even if it looks like a flag
+	if cmd, err := ParseCommand([]string{"list", "--remote", "--color"}); err != nil || cmd.Remote != "--color" || cmd.Color != output.ColorAuto {
+		t.Errorf("Expected --color as the remote value, got %+v, %v", cmd, err)
+	}
+	if cmd, err := ParseCommand([]string{"list"}); err != nil || cmd.GlobalFlags == nil || *cmd.GlobalFlags != (GlobalFlags{}) {
+		t.Errorf("Expected empty global flags, got %+v, %v", cmd, err)
+	}
+
+	if cmd, err := ParseCommand([]string{"--log-level", "WARN", "list"}); err != nil || cmd.LogLevel == nil || *cmd.LogLevel != slog.LevelWarn {
+		t.Errorf("Expected log level warn, got %+v, %v", cmd, err)
+	}
+
+	for _, args := range [][]string{{"list", "--theme"}, {"--dry-run=maybe", "add", "x"}, {"--log-level", "loud", "list"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestModifiesTaskFile tests which commands are refused on a read-only
+// task file
+func TestModifiesTaskFile(t *testing.T) {
+	testCases := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"--read-only", "add", "x"}, true},
+		{[]string{"done", "1"}, true},
+		{[]string{"undo"}, true},
+		{[]string{"archive"}, true},
+		{[]string{"init", "--repair"}, true},
+		{[]string{"list"}, false},
+		{[]string{"count"}, false},
+		{[]string{"export", "--format", "todotxt", "--output", "todo.txt"}, false},
+		{[]string{"archive", "list"}, false},
+		{[]string{"backup"}, false},
+	}
+	for _, tc := range testCases {
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		if got := ModifiesTaskFile(cmd); got != tc.want {
+			t.Errorf("%v: expected %v, got %v", tc.args, tc.want, got)
+		}
+	}
+
+	if cmd, _ := ParseCommand([]string{"list", "--read-only"}); !cmd.ReadOnly {
+		t.Error("Expected --read-only to be parsed as a global flag")
+	}
+	if cmd, _ := ParseCommand([]string{"done", "3", "--force-save"}); !cmd.ForceSave {
+		t.Error("Expected --force-save to be parsed as a global flag")
+	}
+}
+
+// TestDeleteAllConfirmation tests that delete --all asks first unless --yes is given
+func TestDeleteAllConfirmation(t *testing.T) {
+	savedInput, savedOutput := Input, PromptOutput
+	t.Cleanup(func() { Input, PromptOutput = savedInput, savedOutput })
+	PromptOutput = &bytes.Buffer{}
+
+	testCases := []struct {
+		args    []string
+		answer  string
+		deleted bool
+	}{
+		{[]string{"delete", "--all"}, "n\n", false},
+		{[]string{"delete", "--all"}, "", false},
+		{[]string{"delete", "--all"}, "yes\n", true},
+		{[]string{"delete", "--all", "--yes"}, "", true},
+	}
+
+	for _, tc := range testCases {
+		tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+		tl.AddTask("keep me?")
+
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		Input = strings.NewReader(tc.answer)
+		if _, err := ExecuteCommand(cmd, tl); err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", tc.args, err)
+		}
+		if deleted := len(tl.ListTasks()) == 0; deleted != tc.deleted {
+			t.Errorf("%v with answer %q: expected deleted=%v", tc.args, tc.answer, tc.deleted)
+		}
+	}
+
+	for _, args := range [][]string{{"delete", "--all", "3"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestConfirmDelete tests that confirm_delete makes delete by ID ask first
+// unless --yes is given
+func TestConfirmDelete(t *testing.T) {
+	savedInput, savedOutput := Input, PromptOutput
+	t.Cleanup(func() { Input, PromptOutput = savedInput, savedOutput })
+	prompt := &bytes.Buffer{}
+	PromptOutput = prompt
+
+	testCases := []struct {
+		args    []string
+		confirm bool
+		answer  string
+		deleted bool
+	}{
+		{[]string{"delete", "1"}, false, "", true},
+		{[]string{"delete", "1"}, true, "n\n", false},
+		{[]string{"delete", "1", "2"}, true, "y\n", true},
+		{[]string{"delete", "1", "--yes"}, true, "", true},
+	}
+	for _, tc := range testCases {
+		tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+		tl.AddTask("keep me?")
+		tl.AddTask("me too?")
+
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		cmd.ConfirmDelete = tc.confirm
+		Input = strings.NewReader(tc.answer)
+		prompt.Reset()
+		if _, err := ExecuteCommand(cmd, tl); err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", tc.args, err)
+		}
+		if _, err := tl.GetTask(1); (err != nil) != tc.deleted {
+			t.Errorf("%v with confirm_delete=%v and answer %q: expected deleted=%v", tc.args, tc.confirm, tc.answer, tc.deleted)
+		}
+		if asked := prompt.Len() > 0; asked != (tc.confirm && tc.answer != "") {
+			t.Errorf("%v with confirm_delete=%v: unexpected prompt %q", tc.args, tc.confirm, prompt.String())
+		}
+		if len(tc.args) == 3 && tc.answer != "" && !strings.Contains(prompt.String(), "Delete tasks 1, 2?") {
+			t.Errorf("Expected the prompt to name both tasks, got %q", prompt.String())
+		}
+	}
+}
+
+// TestMoveCommand tests that move reorders list output without changing IDs
+func TestMoveCommand(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("first")
+	tl.AddTask("second")
+	tl.AddTask("third")
+
+	testCases := []struct {
+		args     []string
+		expected string
+		order    string
+	}{
+		{[]string{"move", "3", "before", "1"}, "✓ Moved task 3 before task 1", "third,first,second"},
+		{[]string{"move", "1", "before", "0"}, "✓ Moved task 1 to the end", "third,second,first"},
+		{[]string{"move", "1", "--to", "2"}, "✓ Moved task 1 to position 2", "third,first,second"},
+		{[]string{"move", "3", "--to", "9"}, "✓ Moved task 3 to position 3", "first,second,third"},
+		{[]string{"move", "2", "--up"}, "✓ Moved task 2 to position 1", "second,first,third"},
+		{[]string{"move", "1", "--down"}, "✓ Moved task 1 to position 3", "second,third,first"},
+	}
+	for _, tc := range testCases {
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		result, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", tc.args, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, result)
+		}
+
+		list, _ := ParseCommand([]string{"list", "--format", "{{.Description}}"})
+		listed, err := ExecuteCommand(list, tl)
+		if err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+		if got := strings.ReplaceAll(listed, "\n", ","); got != tc.order {
+			t.Errorf("%v: expected list order %q, got %q", tc.args, tc.order, got)
+		}
+	}
+
+	cmd, _ := ParseCommand([]string{"move", "1", "before", "7"})
+	if _, err := ExecuteCommand(cmd, tl); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound for a missing before task, got %v", err)
+	}
+	for _, args := range [][]string{{"move", "1"}, {"move", "1", "after", "2"}, {"move", "x", "before", "2"}, {"move", "1", "before", "y"},
+		{"move", "1", "--up", "--down"}, {"move", "1", "before", "2", "--up"}, {"move", "1", "--to", "top"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestNoteCommand tests setting, appending to and clearing notes from the command line
+func TestNoteCommand(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("call the landlord")
+
+	steps := []struct {
+		args     []string
+		expected string
+		notes    string
+	}{
+		{[]string{"note", "1", "ask", "about", "rent"}, "✓ Note set on task 1", "ask about rent"},
+		{[]string{"note", "1", "--append", "and the boiler"}, "✓ Note added to task 1", "ask about rent\nand the boiler"},
+		{[]string{"note", "1"}, "✓ Note cleared from task 1", ""},
+	}
+	for _, step := range steps {
+		cmd, err := ParseCommand(step.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", step.args, err)
+		}
+		result, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", step.args, err)
+		}
+		if result != step.expected {
+			t.Errorf("%v: expected %q, got %q", step.args, step.expected, result)
+		}
+		if task, _ := tl.GetTask(1); task.Notes != step.notes {
+			t.Errorf("%v: expected notes %q, got %q", step.args, step.notes, task.Notes)
+		}
+	}
+
+	for _, args := range [][]string{{"note"}, {"note", "x", "text"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestCopyCommand tests the copy command's output and argument checks
+func TestCopyCommand(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("ticket for Alex")
+
+	cmd, err := ParseCommand([]string{"copy", "1"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	result, err := ExecuteCommand(cmd, tl)
+	if err != nil {
+		t.Fatalf("ExecuteCommand failed: %v", err)
+	}
+	if result != "✓ Task 1 duplicated as task 2" {
+		t.Errorf("Unexpected output: %q", result)
+	}
+
+	cmd, _ = ParseCommand([]string{"copy", "9"})
+	if _, err := ExecuteCommand(cmd, tl); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+	for _, args := range [][]string{{"copy"}, {"copy", "x"}, {"copy", "1", "2"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestListDateRange tests that list --since and --until include both days
+// and reject a reversed or malformed range
+func TestListDateRange(t *testing.T) {
+	fs := storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json"))
+	if err := fs.Save(&models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, Description: "february", CreatedAt: time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)},
+			{ID: 2, Description: "march", CreatedAt: time.Date(2026, 3, 31, 18, 0, 0, 0, time.UTC)},
+		},
+		NextID: 3,
+	}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	tl, err := todolist.NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"list", "--since", "2026-03-01", "--until", "2026-03-31", "--format", "{{.ID}}"}, "2"},
+		{[]string{"list", "--until", "2026-02-27", "--format", "{{.ID}}"}, "1"},
+		{[]string{"list", "--since", "2026-02-01", "--format", "{{.ID}}"}, "1\n2"},
+		{[]string{"list", "--since", "2026-04-01"}, "No tasks created in that range"},
+	}
+	for _, tc := range testCases {
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		result, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", tc.args, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, result)
+		}
+	}
+
+	if _, err := ParseCommand([]string{"list", "--since", "2026-03-02", "--until", "2026-03-01"}); !apperrors.IsInvalidDateRange(err) {
+		t.Errorf("Expected ErrInvalidDateRange, got %v", err)
+	}
+	for _, args := range [][]string{{"list", "--since", "03/01/2026"}, {"list", "--until", "tomorrow"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestUIDPrefixArguments tests that commands taking task IDs accept UID
+// prefixes
+func TestUIDPrefixArguments(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("first")
+	second, _ := tl.AddTask("second")
+	prefix := second.UID[:8]
+
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"move", prefix, "before", "1"}, "✓ Moved task 2 before task 1"},
+		{[]string{"done", "1", strings.ToUpper(prefix)}, "✓ Task 1 marked as completed\n✓ Task 2 marked as completed"},
+	}
+	for _, tc := range testCases {
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		result, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", tc.args, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, result)
+		}
+	}
+
+	cmd, _ := ParseCommand([]string{"show", "ffffffff-0"})
+	if _, err := ExecuteCommand(cmd, tl); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound for an unknown UID, got %v", err)
+	}
+}
+
+// TestShowByDescription tests show --desc
+func TestShowByDescription(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("Buy milk")
+	tl.AddTask("Buy groceries")
+
+	cmd, err := ParseCommand([]string{"show", "--desc", "Buy groceries", "--json"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	output, err := ExecuteCommand(cmd, tl)
+	if err != nil || !strings.Contains(output, `"id": 2`) {
+		t.Errorf("Expected task 2, got %q, %v", output, err)
+	}
+
+	cmd, _ = ParseCommand([]string{"show", "--desc", "buy groceries"})
+	if _, err := ExecuteCommand(cmd, tl); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound for a different case, got %v", err)
+	}
+	if _, err := ParseCommand([]string{"show", "1", "--desc", "Buy milk"}); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected an ID and --desc together to be rejected, got %v", err)
+	}
+}
+
+// TestTaskRefsByText tests that done, delete and show resolve text to the
+// one pending task whose description contains it, and list the candidates
+// when several do
+func TestTaskRefsByText(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("Buy milk")
+	tl.AddTask("Oat MILK for coffee")
+	tl.AddTask("Call the bank")
+	tl.AddTask("Bank holiday plans")
+	run := func(args ...string) (string, error) {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		return ExecuteCommand(cmd, tl)
+	}
+
+	// Two pending tasks match, in any case: both are listed with their IDs
+	for _, name := range []string{"done", "delete", "show"} {
+		_, err := run(name, "milk")
+		if !apperrors.IsInvalidID(err) || !strings.Contains(err.Error(), "[1] Buy milk") ||
+			!strings.Contains(err.Error(), "[2] Oat MILK for coffee") {
+			t.Errorf("%s: expected both candidates listed, got %v", name, err)
+		}
+	}
+	if tasks := tl.ListTasks(); len(tasks) != 4 || tasks[0].Completed || tasks[1].Completed {
+		t.Fatalf("Expected an ambiguous match to change nothing, got %+v", tasks)
+	}
+
+	if output, err := run("done", "oat"); err != nil || output != "✓ Task 2 marked as completed" {
+		t.Errorf("Unexpected done result: %q, %v", output, err)
+	}
+	// Only pending tasks are matched, so milk now names task 1
+	if output, err := run("show", "MILK"); err != nil || !strings.Contains(output, "Buy milk") {
+		t.Errorf("Unexpected show result: %q, %v", output, err)
+	}
+	if output, err := run("delete", "call the", "holiday"); err != nil || output != "✓ Task 3 deleted\n✓ Task 4 deleted" {
+		t.Errorf("Unexpected delete result: %q, %v", output, err)
+	}
+	if _, err := run("done", "bread"); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+	if _, err := ParseCommand([]string{"done", " "}); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected blank text to be rejected, got %v", err)
+	}
+}
+
+// TestTaskRefsPreferDescriptions tests that a word made of hex letters
+// names the task whose description contains it rather than one whose UID
+// starts with it, and that uid: still reaches the UID
+func TestTaskRefsPreferDescriptions(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.ImportTasks([]models.Task{
+		{Description: "Post the ad", UID: "11111111-0000-4000-8000-000000000000"},
+		{Description: "Water plants", UID: "adccb05d-0000-4000-8000-000000000000"},
+		{Description: "Feed beefcafe", UID: "22222222-0000-4000-8000-000000000000"},
+		{Description: "Renew passport", UID: "beefcafe-0000-4000-8000-000000000000"},
+	})
+	run := func(args ...string) (string, error) {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		return ExecuteCommand(cmd, tl)
+	}
+
+	if output, err := run("done", "ad"); err != nil || output != "✓ Task 1 marked as completed" {
+		t.Errorf("Expected done ad to complete task 1, got %q, %v", output, err)
+	}
+	if output, err := run("delete", "beefcafe"); err != nil || output != "✓ Task 3 deleted" {
+		t.Errorf("Expected delete beefcafe to delete task 3, got %q, %v", output, err)
+	}
+	// With no description left to match, a long enough prefix is a UID
+	if output, err := run("done", "beefcafe"); err != nil || output != "✓ Task 4 marked as completed" {
+		t.Errorf("Expected done beefcafe to complete task 4 by UID, got %q, %v", output, err)
+	}
+	// A short word matching no description is not taken for a UID prefix
+	if _, err := run("delete", "adc"); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound for a short word, got %v", err)
+	}
+	if output, err := run("delete", "uid:adc"); err != nil || output != "✓ Task 2 deleted" {
+		t.Errorf("Expected delete uid:adc to delete task 2, got %q, %v", output, err)
+	}
+}
+
+// TestRenumberConfirmation tests that renumber asks first unless --yes is given
+func TestRenumberConfirmation(t *testing.T) {
+	savedInput, savedOutput := Input, PromptOutput
+	t.Cleanup(func() { Input, PromptOutput = savedInput, savedOutput })
+	PromptOutput = &bytes.Buffer{}
+
+	testCases := []struct {
+		args       []string
+		answer     string
+		expected   string
+		renumbered bool
+	}{
+		{[]string{"renumber"}, "n\n", "Renumber cancelled", false},
+		{[]string{"renumber"}, "yes\n", "✓ Renumbered tasks as 1-2", true},
+		{[]string{"renumber", "--yes"}, "", "✓ Renumbered tasks as 1-2", true},
+	}
+
+	for _, tc := range testCases {
+		tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+		tl.AddTask("gone")
+		tl.AddTask("second")
+		tl.AddTask("third")
+		tl.DeleteTask(1)
+
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		Input = strings.NewReader(tc.answer)
+		result, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", tc.args, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%v with answer %q: expected %q, got %q", tc.args, tc.answer, tc.expected, result)
+		}
+		if task, err := tl.GetTask(1); (err == nil && task.Description == "second") != tc.renumbered {
+			t.Errorf("%v with answer %q: expected renumbered=%v", tc.args, tc.answer, tc.renumbered)
+		}
+	}
+
+	if _, err := ParseCommand([]string{"renumber", "3"}); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected ErrInvalidCommand, got %v", err)
+	}
+}
+
+// TestAddFromStdin tests that add - adds one task per non-blank input line
+func TestAddFromStdin(t *testing.T) {
+	savedInput := Input
+	t.Cleanup(func() { Input = savedInput })
+
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("existing")
+
+	cmd, err := ParseCommand([]string{"add", "-"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+
+	Input = strings.NewReader("  Buy milk \n\n\t\nCall Alice\r\nFile taxes")
+	output, err := ExecuteCommand(cmd, tl)
+	if err != nil {
+		t.Fatalf("ExecuteCommand failed: %v", err)
+	}
+	if output != "✓ Added 3 tasks: 2, 3, 4" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	tasks := tl.ListTasks()
+	if len(tasks) != 4 || tasks[1].Description != "Buy milk" || tasks[2].Description != "Call Alice" {
+		t.Errorf("Expected trimmed descriptions, got %+v", tasks)
+	}
+
+	Input = strings.NewReader("\n  \n")
+	if _, err = ExecuteCommand(cmd, tl); !apperrors.IsEmptyDescription(err) {
+		t.Errorf("Expected ErrEmptyDescription for empty input, got %v", err)
+	}
+	if len(tl.ListTasks()) != 4 {
+		t.Errorf("Expected empty input to add nothing")
+	}
+
+	// Lines longer than a bufio.Scanner buffer are read whole
+	long := strings.Repeat("x", 70000)
+	tl.SetMaxDescriptionLength(len(long))
+	Input = strings.NewReader(long + "\nshort")
+	if output, err := ExecuteCommand(cmd, tl); err != nil || output != "✓ Added 2 tasks: 5, 6" {
+		t.Errorf("Expected a long line to be added, got %q, %v", output, err)
+	}
+}
+
+// TestAddParse tests that add --parse reads inline tokens and flags override them
+func TestAddParse(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"add", "--parse", "pay", "rent", "!high", "due:2026-02-01"},
+		{"add", "call", "!high", "--priority", "low", "--parse"},
+		{"add", "keep", "!high"},
+	} {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		if _, err := ExecuteCommand(cmd, tl); err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", args, err)
+		}
+	}
+
+	tasks := tl.ListTasks()
+	if tasks[0].Description != "pay rent" || tasks[0].Priority != models.PriorityHigh || tasks[0].DueDate == nil {
+		t.Errorf("Expected the tokens to be parsed, got %+v", tasks[0])
+	}
+	if tasks[1].Description != "call" || tasks[1].Priority != models.PriorityLow {
+		t.Errorf("Expected --priority to override !high, got %+v", tasks[1])
+	}
+	if tasks[2].Description != "keep !high" || tasks[2].Priority != models.PriorityNone {
+		t.Errorf("Expected no parsing without --parse, got %+v", tasks[2])
+	}
+}
+
+// TestAddParseTags tests that add and add --parse take the same tags from
+// the same input
+func TestAddParseTags(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	input := "fix #front-end #ok2 #work/reports due:tomorrow"
+	for _, args := range [][]string{{"add", input}, {"add", "--parse", input}} {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		if _, err := ExecuteCommand(cmd, tl); err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", args, err)
+		}
+	}
+
+	tasks := tl.ListTasks()
+	want := []string{"front-end", "ok2", "work/reports"}
+	for _, task := range tasks {
+		if fmt.Sprint(task.Tags) != fmt.Sprint(want) {
+			t.Errorf("Expected tags %v for %q, got %v", want, task.Description, task.Tags)
+		}
+	}
+	if tasks[0].Description != "fix due:tomorrow" || tasks[1].Description != "fix" || tasks[1].DueDate == nil {
+		t.Errorf("Expected only --parse to read the due date, got %+v", tasks)
+	}
+}
+
+// TestTimeTrackCommand tests parsing and running time-track start and stop
+func TestTimeTrackCommand(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("write report")
+
+	run := func(args ...string) (string, error) {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		return ExecuteCommand(cmd, tl)
+	}
+
+	if output, err := run("time-track", "START", "1"); err != nil || output != "✓ Timer started on task 1" {
+		t.Errorf("Unexpected start result: %q, %v", output, err)
+	}
+	if _, err := run("time-track", "start", "1"); !apperrors.IsTimerRunning(err) {
+		t.Errorf("Expected ErrTimerRunning, got %v", err)
+	}
+	if output, err := run("time-track", "stop", "1"); err != nil || output != "✓ Timer stopped on task 1 (total 0m)" {
+		t.Errorf("Unexpected stop result: %q, %v", output, err)
+	}
+	if _, err := run("time-track", "stop", "1"); !apperrors.IsNoActiveTimer(err) {
+		t.Errorf("Expected ErrNoActiveTimer, got %v", err)
+	}
+	if output, _ := run("show", "1"); !strings.Contains(output, "Time spent:  0m") {
+		t.Errorf("Expected show to include the time spent, got:\n%s", output)
+	}
+
+	for _, args := range [][]string{{"time-track"}, {"time-track", "pause", "1"}, {"time-track", "start", "x"}, {"time-track", "stop", "1", "2"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestArchiveCommand tests moving completed tasks to the archive and listing it
+func TestArchiveCommand(t *testing.T) {
+	dir := t.TempDir()
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(dir, "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("done already")
+	tl.AddTask("still open")
+	tl.CompleteTask(1)
+
+	run := func(args ...string) (string, error) {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		cmd.ArchivePath = filepath.Join(dir, "archive.json")
+		return ExecuteCommand(cmd, tl)
+	}
+
+	if output, _ := run("archive", "list"); output != "No archived tasks." {
+		t.Errorf("Expected an empty archive, got %q", output)
+	}
+	if output, err := run("archive"); err != nil || !strings.HasPrefix(output, "✓ Archived 1 completed tasks to ") {
+		t.Errorf("Unexpected archive result: %q, %v", output, err)
+	}
+	if tasks := tl.ListTasks(); len(tasks) != 1 || tasks[0].ID != 2 {
+		t.Errorf("Expected only the open task to remain, got %+v", tasks)
+	}
+	if output, _ := run("archive", "list"); !strings.Contains(output, "done already") {
+		t.Errorf("Expected the archived task to be listed, got:\n%s", output)
+	}
+	if output, _ := run("archive"); output != "No completed tasks to archive." {
+		t.Errorf("Unexpected second archive result: %q", output)
+	}
+
+	other := filepath.Join(dir, "other.json")
+	tl.CompleteTask(2)
+	if _, err := run("archive", "--file", other); err != nil {
+		t.Fatalf("archive --file failed: %v", err)
+	}
+	if output, _ := run("archive", "list", "--file", other); !strings.Contains(output, "still open") || strings.Contains(output, "done already") {
+		t.Errorf("Expected --file to use a separate archive, got:\n%s", output)
+	}
+
+	for _, args := range [][]string{{"archive", "show"}, {"archive", "list", "extra"}, {"archive", "--file"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestDueAndUpcomingCommands tests the due and upcoming views and the
+// overdue error that sets due's exit code
+func TestDueAndUpcomingCommands(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	run := func(args ...string) (string, error) {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		return ExecuteCommand(cmd, tl)
+	}
+
+	today := dayStart(time.Now())
+	tl.AddTask("later today", todolist.WithDueDate(today.Add(24*time.Hour-time.Second)))
+	tl.AddTask("in five days", todolist.WithDueDate(today.AddDate(0, 0, 5).Add(12*time.Hour)))
+	tl.AddTask("in ten days", todolist.WithDueDate(today.AddDate(0, 0, 10).Add(12*time.Hour)))
+
+	if output, err := run("due"); err != nil || !strings.Contains(output, "later today") || strings.Contains(output, "five") {
+		t.Errorf("Unexpected due result without overdue tasks: %q, %v", output, err)
+	}
+	if output, _ := run("upcoming"); !strings.Contains(output, "five") || strings.Contains(output, "ten") {
+		t.Errorf("Expected the next 7 days, got:\n%s", output)
+	}
+	if output, _ := run("upcoming", "--days", "10"); !strings.Contains(output, "ten") {
+		t.Errorf("Expected --days 10 to reach the last task, got:\n%s", output)
+	}
+	if output, _ := run("upcoming", "--days", "0"); !strings.Contains(output, "later today") || strings.Contains(output, "five") {
+		t.Errorf("Expected --days 0 to show today only, got:\n%s", output)
+	}
+
+	tl.AddTask("yesterday", todolist.WithDueDate(today.Add(-time.Hour)))
+	output, err := run("due")
+	if !apperrors.IsTasksOverdue(err) || apperrors.ExitCode(err) != apperrors.ExitOverdue {
+		t.Errorf("Expected ErrTasksOverdue, got %v", err)
+	}
+	if !strings.Contains(output, "yesterday") || !strings.Contains(output, "later today") {
+		t.Errorf("Expected the overdue task listed with today's, got:\n%s", output)
+	}
+	if output, _ := run("upcoming"); strings.Contains(output, "yesterday") {
+		t.Errorf("Expected upcoming to leave out overdue tasks, got:\n%s", output)
+	}
+
+	for _, args := range [][]string{{"due", "today"}, {"upcoming", "--days", "-1"}, {"upcoming", "--days", "x"}, {"upcoming", "7"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestStaleCommand tests that stale lists old pending tasks, oldest first
+func TestStaleCommand(t *testing.T) {
+	fs := storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json"))
+	now := time.Now()
+	err := fs.Save(&models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, Description: "ten days old", CreatedAt: now.AddDate(0, 0, -10)},
+			{ID: 2, Description: "yesterday", CreatedAt: now.AddDate(0, 0, -1)},
+			{ID: 3, Description: "a month old", CreatedAt: now.AddDate(0, -1, 0)},
+			{ID: 4, Description: "finished", Completed: true, CreatedAt: now.AddDate(0, -2, 0)},
+		},
+		NextID: 5,
+	})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	tl, err := todolist.NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	run := func(args ...string) string {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		output, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", args, err)
+		}
+		return output
+	}
+
+	output := run("stale")
+	lines := strings.Split(output, "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "[STALE]") || !strings.Contains(lines[1], "a month old") ||
+		!strings.Contains(lines[2], "ten days old") || !strings.Contains(lines[2], "(age: 10d)") {
+		t.Errorf("Expected the two old pending tasks, oldest first, got:\n%s", output)
+	}
+	if output := run("stale", "--older-than", "14d"); strings.Contains(output, "ten days") || !strings.Contains(output, "a month old") {
+		t.Errorf("Expected --older-than 14d to leave out the newer task, got:\n%s", output)
+	}
+	if output := run("stale", "--older-than", "1y"); output != "No tasks pending for 1y or more." {
+		t.Errorf("Unexpected result without stale tasks: %q", output)
+	}
+
+	for _, args := range [][]string{{"stale", "7"}, {"stale", "--older-than", "soon"}, {"stale", "--older-than"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestEstimates tests add --estimate, the list suffix and the stats totals
+func TestEstimates(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	run := func(args ...string) string {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		output, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", args, err)
+		}
+		return output
+	}
+
+	if output := run("stats"); strings.Contains(output, "Estimated") {
+		t.Errorf("Expected no estimate line without estimates, got:\n%s", output)
+	}
+	run("add", "write", "report", "--estimate", "1h30m")
+	run("add", "file", "expenses", "--estimate", "30m")
+	run("add", "no", "estimate")
+	run("done", "2")
+
+	output := run("list")
+	if !strings.Contains(output, "write report (est: 1h30m)") || strings.Contains(output, "no estimate (est") {
+		t.Errorf("Expected the estimate suffix on estimated tasks only, got:\n%s", output)
+	}
+	if output := run("stats"); !strings.HasSuffix(output, "\nEstimated: 2h (30m completed)") {
+		t.Errorf("Expected estimate totals, got:\n%s", output)
+	}
+
+	for _, args := range [][]string{{"add", "x", "--estimate"}, {"add", "x", "--estimate", "soon"}, {"add", "x", "--estimate", "0m"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestTrashCommand tests listing, restoring and emptying the trash, and
+// that delete --force bypasses it
+func TestTrashCommand(t *testing.T) {
+	fs := storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json"))
+	tl, err := todolist.NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	run := func(args ...string) string {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		output, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", args, err)
+		}
+		return output
+	}
+	tl.AddTask("buy milk")
+	tl.AddTask("call the bank")
+
+	if output := run("trash"); output != "The trash is empty" {
+		t.Errorf("Unexpected empty trash output: %q", output)
+	}
+	run("delete", "1")
+	if output := run("delete", "2", "--force"); output != "✓ Task 2 deleted permanently" {
+		t.Errorf("Unexpected delete --force output: %q", output)
+	}
+	output := run("trash")
+	if !strings.Contains(output, "[1] buy milk (deleted ") || strings.Contains(output, "call the bank") {
+		t.Errorf("Expected only task 1 in the trash, got:\n%s", output)
+	}
+
+	if output := run("trash", "restore", "1"); output != "✓ Task 1 restored: buy milk" {
+		t.Errorf("Unexpected restore output: %q", output)
+	}
+	if task, err := tl.GetTask(1); err != nil || task.Description != "buy milk" {
+		t.Errorf("Expected task 1 back in the list, got %+v, %v", task, err)
+	}
+
+	run("delete", "1")
+	if output := run("trash", "--empty"); output != "✓ Permanently deleted 1 tasks from the trash" {
+		t.Errorf("Unexpected trash --empty output: %q", output)
+	}
+	if len(tl.Trash()) != 0 {
+		t.Errorf("Expected an empty trash, got %+v", tl.Trash())
+	}
+
+	for _, args := range [][]string{{"trash", "restore"}, {"trash", "restore", "abc"}, {"trash", "1"}, {"trash", "restore", "1", "--empty"}} {
+		if _, err := ParseCommand(args); err == nil {
+			t.Errorf("Expected %v to be rejected", args)
+		}
+	}
+}
+
+// TestWebhookCommand tests that webhook test sends a ping and needs a
+// configured webhook
+func TestWebhookCommand(t *testing.T) {
+	var got webhook.TaskEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	cmd, err := ParseCommand([]string{"webhook", "test"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	if _, err := ExecuteCommand(cmd, nil); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected ErrInvalidCommand without a webhook, got %v", err)
+	}
+	cmd.Webhook = webhook.NewNotifier(server.URL, "", nil)
+	if output, err := ExecuteCommand(cmd, nil); err != nil || !strings.Contains(output, server.URL) || got.Event != webhook.PingEvent {
+		t.Errorf("Expected a ping, got %q, %v and %+v", output, err, got)
+	}
+
+	for _, args := range [][]string{{"webhook"}, {"webhook", "send"}, {"webhook", "test", "now"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestConfigCommand tests that config shows every key with its value and
+// where the value came from
+func TestConfigCommand(t *testing.T) {
+	t.Setenv("TODOLIST_FILE", "")
+	t.Setenv("NO_COLOR", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	run := func(args ...string) string {
+		cmd, err := ParseCommand(append([]string{"--config", path}, args...))
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		cmd.StoragePath = "/data/todolist.json"
+		output, err := ExecuteCommand(cmd, nil)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", args, err)
+		}
+		return output
+	}
+
+	output := run("config")
+	if !strings.Contains(output, "(not found, using defaults)") {
+		t.Errorf("Expected a missing config file to be reported, got:\n%s", output)
+	}
+	for _, key := range config.Keys() {
+		if !strings.Contains(output, "\n  "+key+" ") {
+			t.Errorf("Expected a row for %s, got:\n%s", key, output)
+		}
+	}
+
+	run("config", "set", "default_sort", "created")
+	run("config", "set", "webhook.secret", "s3cret")
+	output = run("--theme", "mono", "--no-color", "config")
+	lines := map[string]string{}
+	for _, line := range strings.Split(output, "\n")[1:] {
+		fields := strings.Fields(line)
+		lines[fields[0]] = strings.Join(fields[1:], " ")
+	}
+	want := map[string]string{
+		"default_sort":   "created (config file)",
+		"date_format":    config.DefaultDateFormat + " (default)",
+		"theme":          "mono (--theme)",
+		"colour_enabled": "false (--no-color)",
+		"storage_path":   "/data/todolist.json (default)",
+		"webhook.secret": "(set) (config file)",
+	}
+	for key, value := range want {
+		if lines[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, lines[key])
+		}
+	}
+	if strings.Contains(output, "s3cret") {
+		t.Errorf("Expected the webhook secret to be hidden, got:\n%s", output)
+	}
+
+	for _, args := range [][]string{{"config", "show"}, {"config", "set", "theme"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestCountCommand tests the count output formats
+func TestCountCommand(t *testing.T) {
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	for _, description := range []string{"a", "b", "c", "d", "e"} {
+		tl.AddTask(description)
+	}
+	tl.CompleteTask(1)
+	tl.CompleteTask(2)
+
+	testCases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"count"}, "3"},
+		{[]string{"count", "--completed"}, "2"},
+		{[]string{"count", "--all"}, "5"},
+		{[]string{"count", "--json"}, `{"total":5,"pending":3,"completed":2}`},
+		{[]string{"count", "--quiet"}, "3"},
+		{[]string{"count", "--status", "pending", "--quiet"}, "3"},
+		{[]string{"count", "--status", "done"}, "2"},
+		{[]string{"count", "--status", "all"}, "5"},
+	}
+	for _, tc := range testCases {
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		if output, err := ExecuteCommand(cmd, tl); err != nil || output != tc.want {
+			t.Errorf("%v: expected %q, got %q, %v", tc.args, tc.want, output, err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"count", "--status", "open"}, {"count", "--json", "--quiet"}, {"count", "3"},
+		{"count", "--completed", "--all"}, {"count", "--all", "--status", "done"},
+	} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+
+	// A task file that does not exist yet counts as empty and is not created
+	path := filepath.Join(t.TempDir(), "missing.json")
+	empty, err := todolist.NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	cmd, _ := ParseCommand([]string{"count"})
+	if output, err := ExecuteCommand(cmd, empty); err != nil || output != "0" {
+		t.Errorf("Expected 0 for a missing file, got %q, %v", output, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected count not to create the task file, got %v", err)
+	}
+}
+
+// TestExportOutput tests that export --output writes the file and reports
+// the task and byte counts, and that stdout export is unchanged
+func TestExportOutput(t *testing.T) {
+	dir := t.TempDir()
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(dir, "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("write report")
+	tl.AddTask("pay rent")
+	tl.CompleteTask(2)
+
+	cmd, _ := ParseCommand([]string{"export", "--format", "markdown"})
+	stdout, err := ExecuteCommand(cmd, tl)
+	task, _ := tl.GetTask(2)
+	completed := task.CompletedAt.Format("2006-01-02")
+	if err != nil || stdout != "- [ ] write report\n- [x] pay rent (completed "+completed+")" {
+		t.Fatalf("Unexpected stdout export %q, %v", stdout, err)
+	}
+
+	path := filepath.Join(dir, "tasks.md")
+	cmd, _ = ParseCommand([]string{"export", "--format", "markdown", "--output", path})
+	output, err := ExecuteCommand(cmd, tl)
+	if err != nil {
+		t.Fatalf("export --output failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != stdout+"\n" {
+		t.Errorf("Expected the file to hold the stdout export, got %q", data)
+	}
+	if want := fmt.Sprintf("✓ Exported 2 tasks (%d bytes) to %s", len(data), path); output != want {
+		t.Errorf("Expected %q, got %q", want, output)
+	}
+
+	cmd, _ = ParseCommand([]string{"export", "--format", "todotxt", "--output", filepath.Join(dir, "missing", "todo.txt")})
+	if _, err := ExecuteCommand(cmd, tl); !apperrors.IsStorageError(err) {
+		t.Errorf("Expected a storage error for an uncreatable file, got %v", err)
+	}
+	if _, err := ParseCommand([]string{"export", "--format", "todotxt", "--output="}); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected ErrInvalidCommand for an empty --output, got %v", err)
+	}
+}
+
+// TestJSONExportImport tests that a JSON export to stdout imports into
+// another list through stdin
+func TestJSONExportImport(t *testing.T) {
+	savedInput := Input
+	t.Cleanup(func() { Input = savedInput })
+
+	dir := t.TempDir()
+	source, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(dir, "source.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	source.AddTask("write report", todolist.WithNotes("by Friday"))
+	source.AddTask("pay rent")
+	source.CompleteTask(2)
+
+	cmd, _ := ParseCommand([]string{"export", "--format", "json", "--output", "-"})
+	exported, err := ExecuteCommand(cmd, source)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if !strings.Contains(exported, `"next_id": 3`) {
+		t.Errorf("Expected the whole list in the task file format, got %s", exported)
+	}
+
+	target, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(dir, "target.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	target.AddTask("existing")
+	Input = strings.NewReader(exported)
+	cmd, err = ParseCommand([]string{"import", "--format", "json", "--stdin"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	if result, err := ExecuteCommand(cmd, target); err != nil || result != "✓ Imported 2 tasks" {
+		t.Fatalf("Unexpected import result %q, %v", result, err)
+	}
+	tasks := target.ListTasks()
+	if len(tasks) != 3 || tasks[1].Notes != "by Friday" || !tasks[2].Completed || tasks[2].ID != 3 {
+		t.Errorf("Expected both tasks appended with new IDs, got %+v", tasks)
+	}
+
+	// A list that fails validation is rejected as a whole
+	Input = strings.NewReader(`{"tasks": [{"id": 1, "description": "a"}, {"id": 1, "description": "b"}], "next_id": 2}`)
+	if _, err := ExecuteCommand(cmd, target); !apperrors.IsInvalidTaskList(err) {
+		t.Errorf("Expected ErrInvalidTaskList for duplicate IDs, got %v", err)
+	}
+	if _, err := ParseCommand([]string{"import", "--format", "json", "--stdin", "tasks.json"}); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected ErrInvalidCommand for a file with --stdin, got %v", err)
+	}
+}
+
+// TestSnapshotCommand tests saving, listing and deleting snapshots from the
+// command line
+func TestSnapshotCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("write report")
+
+	run := func(args ...string) (string, error) {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			return "", err
+		}
+		cmd.StoragePath = path
+		return ExecuteCommand(cmd, tl)
+	}
+
+	output, err := run("snapshot", "sprint 3")
+	if err != nil || !strings.Contains(output, `Snapshot "sprint 3" saved with 1 tasks to `+path+".snapshots") {
+		t.Fatalf("Unexpected snapshot output %q, %v", output, err)
+	}
+	if output, err := run("snapshot", "list"); err != nil || !strings.Contains(output, "sprint 3  (created ") || !strings.Contains(output, "1 tasks)") {
+		t.Errorf("Unexpected list output %q, %v", output, err)
+	}
+	if output, err := run("snapshot", "delete", "sprint 3"); err != nil || output != `✓ Deleted snapshot "sprint 3"` {
+		t.Errorf("Unexpected delete output %q, %v", output, err)
+	}
+	if output, err := run("snapshot", "list"); err != nil || !strings.HasPrefix(output, "No snapshots") {
+		t.Errorf("Expected no snapshots, got %q, %v", output, err)
+	}
+
+	for _, args := range [][]string{{"snapshot"}, {"snapshot", "delete"}, {"snapshot", "a", "b"}, {"snapshot", "../x"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todolist/internal/theme"
+	"todolist/pkg/models"
+)
+
+// minDescriptionWidth keeps the description readable on narrow terminals
+const minDescriptionWidth = 10
+
+// columnSeparator goes between table columns
+const columnSeparator = "  "
+
+// column describes one table column of list --columns
+type column struct {
+	header string
+	// rightAlign pads the cell on the left (numbers)
+	rightAlign bool
+	// cell returns the plain text of the cell and the role to paint it with
+	cell func(r *renderer, task models.Task) (string, theme.Role)
+}
+
+// columns maps every name in config.ListColumns to its column
+var columns = map[string]column{
+	"id": {
+		header:     "ID",
+		rightAlign: true,
+		cell: func(r *renderer, task models.Task) (string, theme.Role) {
+			return fmt.Sprint(task.ID), ""
+		},
+	},
+	"status": {
+		header: "Status",
+		cell: func(r *renderer, task models.Task) (string, theme.Role) {
+			switch {
+			case task.Completed:
+				return "done", theme.RoleStatusDone
+			case task.IsOverdue(r.now):
+				return "overdue", theme.RoleOverdue
+			default:
+				return "pending", theme.RoleStatusPending
+			}
+		},
+	},
+	"priority": {
+		header: "Priority",
+		cell: func(r *renderer, task models.Task) (string, theme.Role) {
+			switch task.Priority {
+			case models.PriorityNone:
+				return "", ""
+			case models.PriorityHigh:
+				return task.Priority.String(), theme.RolePriorityHigh
+			case models.PriorityLow:
+				return task.Priority.String(), theme.RolePriorityLow
+			default:
+				return task.Priority.String(), ""
+			}
+		},
+	},
+	"due": {
+		header: "Due",
+		cell: func(r *renderer, task models.Task) (string, theme.Role) {
+			if task.DueDate == nil {
+				return "", ""
+			}
+			due := task.DueDate.Format(r.dateFormat)
+			switch {
+			case task.IsOverdue(r.now):
+				return due, theme.RoleOverdue
+			case task.DueWithin(r.now, dueSoonWindow):
+				return due, theme.RoleDueSoon
+			default:
+				return due, ""
+			}
+		},
+	},
+	"created": {
+		header: "Created",
+		cell: func(r *renderer, task models.Task) (string, theme.Role) {
+			return task.CreatedAt.Format(r.dateFormat), theme.RoleDim
+		},
+	},
+	"completed": {
+		header: "Completed",
+		cell: func(r *renderer, task models.Task) (string, theme.Role) {
+			if task.CompletedAt == nil {
+				return "", ""
+			}
+			return task.CompletedAt.Format(r.dateFormat), theme.RoleDim
+		},
+	},
+	"age": {
+		header:     "Age",
+		rightAlign: true,
+		cell: func(r *renderer, task models.Task) (string, theme.Role) {
+			return formatAge(r.now.Sub(task.CreatedAt)), theme.RoleDim
+		},
+	},
+	"description": {
+		header: "Description",
+		cell: func(r *renderer, task models.Task) (string, theme.Role) {
+			return task.Description, ""
+		},
+	},
+}
+
+// taskTable renders tasks as a table with the given columns. Every column
+// is as wide as its widest cell except description, which takes the space
+// left in width; width 0 means unlimited.
+func (r *renderer) taskTable(names []string, tasks []models.Task, width int) string {
+	cells := make([][]string, len(tasks))
+	roles := make([][]theme.Role, len(tasks))
+	widths := make([]int, len(names))
+	for i, name := range names {
+		widths[i] = displayWidth(columns[name].header)
+	}
+	for row, task := range tasks {
+		cells[row] = make([]string, len(names))
+		roles[row] = make([]theme.Role, len(names))
+		for i, name := range names {
+			text, role := columns[name].cell(r, task)
+			cells[row][i], roles[row][i] = text, role
+			widths[i] = max(widths[i], displayWidth(text))
+		}
+	}
+
+	// Description absorbs whatever the other columns leave over
+	if width > 0 {
+		for i, name := range names {
+			if name != "description" {
+				continue
+			}
+			used := len(columnSeparator) * (len(names) - 1)
+			for j, w := range widths {
+				if j != i {
+					used += w
+				}
+			}
+			widths[i] = min(widths[i], max(width-used, minDescriptionWidth))
+		}
+	}
+
+	headers := make([]string, len(names))
+	for i, name := range names {
+		headers[i] = r.f.Paint(theme.RoleHeader, pad(columns[name].header, widths[i], columns[name].rightAlign))
+	}
+	lines := []string{strings.TrimRight(strings.Join(headers, columnSeparator), " ")}
+
+	for row := range tasks {
+		line := make([]string, len(names))
+		for i, name := range names {
+			text := truncate(cells[row][i], widths[i])
+			padded := pad(text, widths[i], columns[name].rightAlign)
+			if role := roles[row][i]; role != "" && text != "" {
+				// Paint only the text so padding stays unstyled
+				padded = strings.Replace(padded, text, r.f.Paint(role, text), 1)
+			}
+			line[i] = padded
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(line, columnSeparator), " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatAge renders a duration as a short age such as 5m, 3h or 12d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", max(int(d/time.Minute), 0))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
+
+// pad pads s with spaces to width terminal cells
+func pad(s string, width int, rightAlign bool) string {
+	padding := strings.Repeat(" ", max(width-displayWidth(s), 0))
+	if rightAlign {
+		return padding + s
+	}
+	return s + padding
+}
+
+// truncate shortens s to at most width terminal cells, marking the cut with …
+func truncate(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	var out strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		out.WriteRune(r)
+		used += w
+	}
+	return out.String() + "…"
+}
+
+// displayWidth returns the number of terminal cells s occupies
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth treats East Asian wide characters as two cells and everything
+// else as one
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115F, // Hangul Jamo
+		r >= 0x2E80 && r <= 0xA4CF, // CJK radicals to Yi
+		r >= 0xAC00 && r <= 0xD7A3, // Hangul syllables
+		r >= 0xF900 && r <= 0xFAFF, // CJK compatibility ideographs
+		r >= 0xFE30 && r <= 0xFE4F, // CJK compatibility forms
+		r >= 0xFF00 && r <= 0xFF60, // Fullwidth forms
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Input and PromptOutput are used for interactive confirmations.
+// Tests may replace them.
+var (
+	Input        io.Reader = os.Stdin
+	PromptOutput io.Writer = os.Stderr
+)
+
+// confirm asks a yes/no question and reports whether the user agreed.
+// Anything other than "y" or "yes" (including EOF) counts as no.
+func confirm(question string) bool {
+	fmt.Fprintf(PromptOutput, "%s [y/N]: ", question)
+
+	answer, err := bufio.NewReader(Input).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// ConfirmRecovery asks whether to set aside a task file that is not valid JSON
+func ConfirmRecovery(path string) bool {
+	return confirm(fmt.Sprintf("%s is damaged and cannot be read. Move it aside and start with an empty list?", path))
+}
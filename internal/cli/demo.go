@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+	"todolist/internal/demo"
+	"todolist/internal/env"
+	"todolist/pkg/storage"
+)
+
+// TaskFileEnv overrides the task file location; demo sets it for its shell
+const TaskFileEnv = env.TaskFile
+
+// DemoOptions controls the demo sandbox
+type DemoOptions struct {
+	// Seed selects the sample data; the same seed gives the same list
+	Seed int64
+	// Keep leaves the sample list on disk after the shell exits
+	Keep bool
+}
+
+// RunDemo writes a sample task list to a temporary directory and starts a
+// shell with TODOLIST_FILE pointing at it, so every todolist command run
+// in that shell uses the sample list and never the real one. The directory
+// is removed when the shell exits unless opts.Keep is set.
+func RunDemo(in io.Reader, out, errOut io.Writer, opts DemoOptions) error {
+	dir, err := os.MkdirTemp("", "todolist-demo-")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "todolist.json")
+	if !opts.Keep {
+		defer os.RemoveAll(dir)
+	}
+
+	if err := storage.NewFileStorage(path).Save(demo.Tasks(opts.Seed, time.Now())); err != nil {
+		return err
+	}
+
+	shell := demoShell()
+	fmt.Fprintf(out, "Demo list: %s\n", path)
+	fmt.Fprintf(out, "Starting %s with %s set; exit the shell to leave the demo.\n", shell, TaskFileEnv)
+
+	c := exec.Command(shell)
+	c.Stdin, c.Stdout, c.Stderr = in, out, errOut
+	c.Env = append(os.Environ(), TaskFileEnv+"="+path)
+	err = c.Run()
+
+	if opts.Keep {
+		fmt.Fprintf(out, "Demo list kept at %s\n", path)
+	} else {
+		fmt.Fprintln(out, "Demo list removed")
+	}
+
+	// A non-zero status only reflects the last command run in the shell
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
+
+// demoShell returns the user's shell
+func demoShell() string {
+	if shell := env.Get(env.Shell); shell != "" {
+		return shell
+	}
+	if runtime.GOOS == "windows" {
+		if comspec := env.Get(env.ComSpec); comspec != "" {
+			return comspec
+		}
+		return "cmd.exe"
+	}
+	return "/bin/sh"
+}
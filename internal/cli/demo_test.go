@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todolist/pkg/storage"
+)
+
+// demoPath extracts the sample list path from the demo output
+func demoPath(t *testing.T, output string) string {
+	first, _, _ := strings.Cut(output, "\n")
+	path, ok := strings.CutPrefix(first, "Demo list: ")
+	if !ok {
+		t.Fatalf("Expected the demo list path first, got %q", output)
+	}
+	return path
+}
+
+// TestRunDemoSandbox tests that the shell sees the sample list and that it is cleaned up
+func TestRunDemoSandbox(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh available")
+	}
+	t.Setenv("SHELL", "sh")
+
+	for _, keep := range []bool{false, true} {
+		var out bytes.Buffer
+		// The shell reports the file it was given, then fails: neither is fatal
+		script := strings.NewReader("echo \"file=$" + TaskFileEnv + "\"\nexit 3\n")
+		if err := RunDemo(script, &out, &out, DemoOptions{Seed: 1, Keep: keep}); err != nil {
+			t.Fatalf("RunDemo failed: %v", err)
+		}
+
+		path := demoPath(t, out.String())
+		if !strings.Contains(out.String(), "file="+path+"\n") {
+			t.Errorf("Expected the shell to get %s=%s, got %q", TaskFileEnv, path, out.String())
+		}
+
+		_, err := os.Stat(path)
+		switch {
+		case keep && err != nil:
+			t.Errorf("Expected --keep to leave %s behind: %v", path, err)
+		case !keep && !os.IsNotExist(err):
+			t.Errorf("Expected %s to be removed, got %v", path, err)
+		}
+		if keep {
+			list, err := storage.NewFileStorage(path).Load()
+			if err != nil || len(list.Tasks) < 15 {
+				t.Errorf("Expected the kept list to hold the sample tasks, got %v (%v)", list, err)
+			}
+			os.RemoveAll(filepath.Dir(path))
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"todolist/internal/env"
+)
+
+// SuppressDeprecationsEnv silences deprecation notices when set to any non-empty value
+const SuppressDeprecationsEnv = env.SuppressDeprecations
+
+// Kinds of deprecated names
+const (
+	DeprecatedFlag      = "flag"
+	DeprecatedCommand   = "command"
+	DeprecatedConfigKey = "config key"
+)
+
+// Deprecation describes a renamed flag, command or config key
+type Deprecation struct {
+	// Kind is DeprecatedFlag, DeprecatedCommand or DeprecatedConfigKey
+	Kind string
+	// Old is the deprecated name, e.g. "--multi"
+	Old string
+	// Replacement is the name Old is translated to, e.g. "--split"
+	Replacement string
+	// Since is the version that deprecated Old
+	Since string
+	// RemovedIn is the version that will stop accepting Old
+	RemovedIn string
+}
+
+// Notice formats the one-line stderr message for the deprecation
+func (d Deprecation) Notice() string {
+	return fmt.Sprintf("Warning: %s '%s' is deprecated and will be removed in %s; use '%s' instead",
+		d.Kind, d.Old, d.RemovedIn, d.Replacement)
+}
+
+// deprecations lists every deprecated name with its replacement. Old
+// names keep working: ParseCommand translates them and records a notice.
+var deprecations = []Deprecation{}
+
+// lookupDeprecation returns the deprecation registered for name, if any
+func lookupDeprecation(kind, name string) (Deprecation, bool) {
+	for _, d := range deprecations {
+		if d.Kind == kind && d.Old == name {
+			return d, true
+		}
+	}
+	return Deprecation{}, false
+}
+
+// deprecationTracker collects the deprecations used by one invocation,
+// keeping each only once
+type deprecationTracker struct {
+	used []Deprecation
+}
+
+// note records d unless it was already recorded
+func (dt *deprecationTracker) note(d Deprecation) {
+	for _, u := range dt.used {
+		if u == d {
+			return
+		}
+	}
+	dt.used = append(dt.used, d)
+}
+
+// translateFlags replaces deprecated flags (including --old=value) with
+// their replacements
+func (dt *deprecationTracker) translateFlags(args []string) []string {
+	translated := make([]string, len(args))
+	for i, arg := range args {
+		translated[i] = arg
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if d, ok := lookupDeprecation(DeprecatedFlag, name); ok {
+			dt.note(d)
+			translated[i] = d.Replacement
+			if hasValue {
+				translated[i] += "=" + value
+			}
+		}
+	}
+	return translated
+}
+
+// translateCommand replaces a deprecated command name, and a deprecated
+// key in `config set <key>`, with their replacements
+func (dt *deprecationTracker) translateCommand(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	translated := append([]string{}, args...)
+	if d, ok := lookupDeprecation(DeprecatedCommand, strings.ToLower(translated[0])); ok {
+		dt.note(d)
+		translated[0] = d.Replacement
+	}
+	if strings.ToLower(translated[0]) == "config" && len(translated) > 2 {
+		if d, ok := lookupDeprecation(DeprecatedConfigKey, translated[2]); ok {
+			dt.note(d)
+			translated[2] = d.Replacement
+		}
+	}
+	return translated
+}
+
+// WriteDeprecationNotices prints one line per deprecated name cmd used,
+// unless TODOLIST_SUPPRESS_DEPRECATIONS is set
+func WriteDeprecationNotices(w io.Writer, cmd *Command) {
+	if env.Get(env.SuppressDeprecations) != "" {
+		return
+	}
+	for _, d := range cmd.Deprecations {
+		fmt.Fprintln(w, d.Notice())
+	}
+}
+
+// deprecationList renders the output of the deprecations command
+func deprecationList() string {
+	if len(deprecations) == 0 {
+		return "Nothing is currently deprecated."
+	}
+
+	lines := []string{"Deprecated names:"}
+	for _, d := range deprecations {
+		lines = append(lines, fmt.Sprintf("  %s %s → %s (deprecated in %s, removed in %s)",
+			d.Kind, d.Old, d.Replacement, d.Since, d.RemovedIn))
+	}
+	return strings.Join(lines, "\n")
+}
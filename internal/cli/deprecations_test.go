@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withDeprecations installs a deprecation registry for the duration of a test
+func withDeprecations(t *testing.T, entries []Deprecation) {
+	saved := deprecations
+	deprecations = entries
+	t.Cleanup(func() { deprecations = saved })
+}
+
+var testDeprecations = []Deprecation{
+	{Kind: DeprecatedFlag, Old: "--confirm", Replacement: "--yes", Since: "v1.1", RemovedIn: "v2.0"},
+	{Kind: DeprecatedFlag, Old: "--style", Replacement: "--theme", Since: "v1.1", RemovedIn: "v2.0"},
+	{Kind: DeprecatedCommand, Old: "remove", Replacement: "delete", Since: "v1.1", RemovedIn: "v2.0"},
+	{Kind: DeprecatedConfigKey, Old: "color_theme", Replacement: "theme", Since: "v1.1", RemovedIn: "v2.0"},
+}
+
+// TestDeprecatedNamesTranslate tests that old invocations parse exactly like their replacements
+func TestDeprecatedNamesTranslate(t *testing.T) {
+	withDeprecations(t, testDeprecations)
+
+	testCases := []struct {
+		old, current []string
+	}{
+		{[]string{"remove", "3"}, []string{"delete", "3"}},
+		{[]string{"restore", "backup.json", "--confirm"}, []string{"restore", "backup.json", "--yes"}},
+		{[]string{"--style=mono", "list"}, []string{"--theme=mono", "list"}},
+		{[]string{"--style", "mono", "remove", "1", "2"}, []string{"--theme", "mono", "delete", "1", "2"}},
+		{[]string{"config", "set", "color_theme", "mono"}, []string{"config", "set", "theme", "mono"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.old, " "), func(t *testing.T) {
+			got, err := ParseCommand(tc.old)
+			if err != nil {
+				t.Fatalf("ParseCommand(%v) failed: %v", tc.old, err)
+			}
+			want, err := ParseCommand(tc.current)
+			if err != nil {
+				t.Fatalf("ParseCommand(%v) failed: %v", tc.current, err)
+			}
+			if len(got.Deprecations) == 0 {
+				t.Error("Expected the deprecated name to be recorded")
+			}
+			got.Deprecations = nil
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+// TestDeprecationNoticeShownOnce tests that each notice appears once per invocation
+func TestDeprecationNoticeShownOnce(t *testing.T) {
+	withDeprecations(t, testDeprecations)
+	t.Setenv(SuppressDeprecationsEnv, "")
+
+	cmd, err := ParseCommand([]string{"--style", "mono", "restore", "x.json", "--confirm", "--confirm"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	WriteDeprecationNotices(&out, cmd)
+	if n := strings.Count(out.String(), "'--confirm' is deprecated"); n != 1 {
+		t.Errorf("Expected the --confirm notice exactly once, got %d in %q", n, out.String())
+	}
+	if n := strings.Count(out.String(), "\n"); n != 2 {
+		t.Errorf("Expected 2 notice lines, got %d in %q", n, out.String())
+	}
+
+	t.Setenv(SuppressDeprecationsEnv, "1")
+	out.Reset()
+	WriteDeprecationNotices(&out, cmd)
+	if out.Len() != 0 {
+		t.Errorf("Expected notices to be suppressed, got %q", out.String())
+	}
+}
+
+// TestDescriptionWordsNotTranslated tests that only the command position is translated
+func TestDescriptionWordsNotTranslated(t *testing.T) {
+	withDeprecations(t, testDeprecations)
+
+	cmd, err := ParseCommand([]string{"add", "remove", "old", "files"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	if cmd.Name != "add" || cmd.Args[0] != "remove old files" || len(cmd.Deprecations) != 0 {
+		t.Errorf("Expected description left alone, got %+v", cmd)
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseEstimate converts an effort estimate such as 30m, 2h or 1h30m to
+// whole minutes. Estimates must be positive and in whole minutes.
+func ParseEstimate(s string) (int, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Minute || d%time.Minute != 0 {
+		return 0, fmt.Errorf("invalid estimate %q, expected e.g. 30m, 2h or 1h30m", s)
+	}
+	return int(d / time.Minute), nil
+}
+
+// formatEstimate renders minutes the way ParseEstimate reads them, e.g.
+// 2h, 45m or 1h30m
+func formatEstimate(minutes int) string {
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+	}
+}
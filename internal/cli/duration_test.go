@@ -0,0 +1,34 @@
+package cli
+
+import "testing"
+
+// TestParseEstimate tests the accepted estimate forms and that formatting
+// reads back the same
+func TestParseEstimate(t *testing.T) {
+	testCases := []struct {
+		input    string
+		minutes  int
+		rendered string
+	}{
+		{"30m", 30, "30m"},
+		{"2h", 120, "2h"},
+		{"1h30m", 90, "1h30m"},
+		{"90m", 90, "1h30m"},
+		{"1.5h", 90, "1h30m"},
+	}
+	for _, tc := range testCases {
+		minutes, err := ParseEstimate(tc.input)
+		if err != nil || minutes != tc.minutes {
+			t.Errorf("ParseEstimate(%q): expected %d, got %d, %v", tc.input, tc.minutes, minutes, err)
+		}
+		if got := formatEstimate(minutes); got != tc.rendered {
+			t.Errorf("formatEstimate(%d): expected %q, got %q", minutes, tc.rendered, got)
+		}
+	}
+
+	for _, input := range []string{"", "2", "soon", "0m", "-1h", "90s", "1m30s"} {
+		if _, err := ParseEstimate(input); err == nil {
+			t.Errorf("Expected ParseEstimate(%q) to fail", input)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"todolist/internal/env"
+	apperrors "todolist/pkg/errors"
+)
+
+// editorTemplate is written to the file before the editor opens; lines
+// starting with # are dropped when the file is read back
+const editorTemplate = `
+# Write the task description on the first line.
+# Anything after it is saved as the task's notes.
+# Lines starting with # are ignored; an empty file cancels the task.
+`
+
+// OpenEditorForInput opens $EDITOR (vi if unset) on a temporary file and
+// returns the first non-blank line as the description and the remaining
+// lines as notes. An empty file yields ErrEmptyDescription.
+func OpenEditorForInput() (description, notes string, err error) {
+	file, err := os.CreateTemp("", "todolist-add-*.txt")
+	if err != nil {
+		return "", "", apperrors.WrapWithContext(err, "failed to create editor file")
+	}
+	path := file.Name()
+	defer os.Remove(path)
+
+	_, err = file.WriteString(editorTemplate)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", "", apperrors.WrapWithContext(err, "failed to write editor file")
+	}
+
+	// $EDITOR may carry arguments, e.g. "code --wait"
+	argv := append(strings.Fields(editorCommand()), path)
+	c := exec.Command(argv[0], argv[1:]...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", "", apperrors.WrapWithContext(err, fmt.Sprintf("editor %s failed", argv[0]))
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", apperrors.WrapWithContext(err, "failed to read editor file")
+	}
+	return parseEditorInput(string(content))
+}
+
+// parseEditorInput splits edited text into a description and notes
+func parseEditorInput(content string) (description, notes string, err error) {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(lines) == 0 && strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return "", "", apperrors.ErrEmptyDescription
+	}
+	return strings.TrimSpace(lines[0]), strings.TrimSpace(strings.Join(lines[1:], "\n")), nil
+}
+
+// editorCommand returns the user's editor
+func editorCommand() string {
+	if editor := strings.TrimSpace(env.Get(env.Editor)); editor != "" {
+		return editor
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	apperrors "todolist/pkg/errors"
+)
+
+// fakeEditor points $EDITOR at a script that replaces the file with content
+func fakeEditor(t *testing.T, content string) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh available")
+	}
+	dir := t.TempDir()
+	contentPath := filepath.Join(dir, "content")
+	if err := os.WriteFile(contentPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "editor.sh")
+	body := "cp '" + contentPath + "' \"$1\"\necho \"$1\" > '" + filepath.Join(dir, "edited") + "'\n"
+	if err := os.WriteFile(script, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", "sh "+script)
+}
+
+// editedPath returns the temporary file the fake editor was given
+func editedPath(t *testing.T) string {
+	t.Helper()
+	script := os.Getenv("EDITOR")[len("sh "):]
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(script), "edited"))
+	if err != nil {
+		t.Fatalf("Expected the editor to run: %v", err)
+	}
+	return string(data[:len(data)-1])
+}
+
+// TestOpenEditorForInput tests that the first line becomes the description and the rest notes
+func TestOpenEditorForInput(t *testing.T) {
+	fakeEditor(t, "\n  Plan the offsite  \n# a comment\nBook the venue\n\nSend invites\n")
+
+	description, notes, err := OpenEditorForInput()
+	if err != nil {
+		t.Fatalf("OpenEditorForInput failed: %v", err)
+	}
+	if description != "Plan the offsite" {
+		t.Errorf("Expected description 'Plan the offsite', got %q", description)
+	}
+	if notes != "Book the venue\n\nSend invites" {
+		t.Errorf("Expected the remaining lines as notes, got %q", notes)
+	}
+	if _, err := os.Stat(editedPath(t)); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary file to be removed, got %v", err)
+	}
+}
+
+// TestOpenEditorForInputEmpty tests that saving an empty file aborts
+func TestOpenEditorForInputEmpty(t *testing.T) {
+	fakeEditor(t, "\n# only comments\n   \n")
+
+	if _, _, err := OpenEditorForInput(); !apperrors.IsEmptyDescription(err) {
+		t.Errorf("Expected ErrEmptyDescription, got %v", err)
+	}
+	if _, err := os.Stat(editedPath(t)); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary file to be removed, got %v", err)
+	}
+}
+
+// TestAddInteractiveParse tests that add -i takes no description
+func TestAddInteractiveParse(t *testing.T) {
+	cmd, err := ParseCommand([]string{"add", "-i", "--priority", "high"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	if cmd.Flags["interactive"] == "" || cmd.Flags["priority"] != "high" {
+		t.Errorf("Expected interactive and priority flags, got %v", cmd.Flags)
+	}
+	if _, err := ParseCommand([]string{"add", "--interactive", "Buy milk"}); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected add -i with a description to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todolist/internal/config"
+	"todolist/internal/env"
+	"todolist/internal/theme"
+	"todolist/pkg/models"
+	"todolist/pkg/todolist"
+)
+
+// dueSoonWindow is how far ahead a due date is highlighted as due soon
+const dueSoonWindow = 24 * time.Hour
+
+// notePreviewLength is how many characters of a task's notes list shows
+const notePreviewLength = 40
+
+// renderer formats tasks for terminal output
+type renderer struct {
+	f          *theme.Formatter
+	now        time.Time
+	dateFormat string
+	// verbose adds when each task was completed to list output
+	verbose bool
+}
+
+// newRenderer creates a renderer from the command's output settings
+func newRenderer(cmd *Command) *renderer {
+	r := &renderer{
+		f:          cmd.Formatter,
+		now:        time.Now(),
+		dateFormat: cmd.DateFormat,
+		verbose:    cmd.Flags["verbose"] != "",
+	}
+	if r.f == nil {
+		r.f = theme.Plain()
+	}
+	if r.dateFormat == "" {
+		r.dateFormat = config.DefaultDateFormat
+	}
+	return r
+}
+
+// taskList renders tasks under a header, one task per line
+func (r *renderer) taskList(header string, tasks []models.Task) string {
+	return strings.TrimSpace(r.f.Paint(theme.RoleHeader, header) + "\n" + r.taskLines(tasks))
+}
+
+// taskLines renders tasks one per line, as shown by list
+func (r *renderer) taskLines(tasks []models.Task) string {
+	lines := make([]string, len(tasks))
+	for i, task := range tasks {
+		lines[i] = r.taskLine(task)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// staleList renders tasks under a header as list does, each marked
+// [STALE] and followed by its age
+func (r *renderer) staleList(header string, tasks []models.Task) string {
+	lines := make([]string, len(tasks))
+	for i, task := range tasks {
+		age := r.f.Paint(theme.RoleDim, "(age: "+formatAge(r.now.Sub(task.CreatedAt))+")")
+		lines[i] = r.f.Paint(theme.RoleOverdue, "[STALE]") + " " + r.taskLine(task) + " " + age
+	}
+	return r.f.Paint(theme.RoleHeader, header) + "\n" + strings.Join(lines, "\n")
+}
+
+// trashList renders deleted tasks with their original IDs and when they
+// were deleted
+func (r *renderer) trashList(header string, trash []models.TrashedTask) string {
+	lines := make([]string, len(trash))
+	for i, entry := range trash {
+		deleted := r.f.Paint(theme.RoleDim, "(deleted "+entry.DeletedAt.Format(r.dateFormat)+")")
+		lines[i] = fmt.Sprintf("  [%d] %s %s", entry.Task.ID, entry.Task.Description, deleted)
+	}
+	return r.f.Paint(theme.RoleHeader, header) + "\n" + strings.Join(lines, "\n")
+}
+
+// taskGroups renders each group under a header with its task count, the
+// tasks rendered by body, with a blank line between groups
+func (r *renderer) taskGroups(groups []todolist.TaskGroup, body func([]models.Task) string) string {
+	sections := make([]string, len(groups))
+	for i, group := range groups {
+		header := r.f.Paint(theme.RoleHeader, fmt.Sprintf("%s (%d)", group.Name, len(group.Tasks)))
+		sections[i] = header + "\n" + body(group.Tasks)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// taskLine renders a single task as shown by list
+func (r *renderer) taskLine(task models.Task) string {
+	var status string
+	switch {
+	case task.Completed:
+		status = r.f.Paint(theme.RoleStatusDone, "[✓]")
+	case task.IsOverdue(r.now):
+		status = r.f.Paint(theme.RoleOverdue, "[ ]")
+	default:
+		status = r.f.Paint(theme.RoleStatusPending, "[ ]")
+	}
+
+	description := task.Description
+	switch task.Priority {
+	case models.PriorityHigh:
+		description = r.f.Paint(theme.RolePriorityHigh, description)
+	case models.PriorityLow:
+		description = r.f.Paint(theme.RolePriorityLow, description)
+	}
+
+	line := fmt.Sprintf("%s [%d] %s", status, task.ID, description)
+	if len(task.Tags) > 0 {
+		line += " " + r.f.Paint(theme.RoleDim, formatTags(task.Tags))
+	}
+	if task.DueDate != nil {
+		due := "(due: " + task.DueDate.Format(r.dateFormat) + ")"
+		switch {
+		case task.IsOverdue(r.now):
+			due = r.f.Paint(theme.RoleOverdue, due)
+		case task.DueWithin(r.now, dueSoonWindow):
+			due = r.f.Paint(theme.RoleDueSoon, due)
+		}
+		line += " " + due
+	}
+	if task.EstimateMinutes > 0 {
+		line += " " + r.f.Paint(theme.RoleDim, "(est: "+formatEstimate(task.EstimateMinutes)+")")
+	}
+	line += " " + r.f.Paint(theme.RoleDim, "(created: "+task.CreatedAt.Format(r.dateFormat)+")")
+	if r.verbose && task.CompletedAt != nil {
+		line += " " + r.f.Paint(theme.RoleDim, "(completed: "+task.CompletedAt.Format(r.dateFormat)+")")
+	}
+	if task.Notes != "" {
+		line += " " + r.f.Paint(theme.RoleDim, "— "+notePreview(task.Notes))
+	}
+
+	return line
+}
+
+// formatTags renders tags as they are typed, e.g. "#home #errand"
+func formatTags(tags []string) string {
+	return "#" + strings.Join(tags, " #")
+}
+
+// notePreview returns the first line of notes, cut to notePreviewLength
+// characters, with an ellipsis if anything was left out
+func notePreview(notes string) string {
+	first, _, more := strings.Cut(notes, "\n")
+	runes := []rune(first)
+	if len(runes) > notePreviewLength {
+		runes, more = runes[:notePreviewLength], true
+	}
+	preview := strings.TrimSpace(string(runes))
+	if more {
+		preview += "…"
+	}
+	return preview
+}
+
+// taskDetail renders every field of a task as a key-value block, as shown by show
+func (r *renderer) taskDetail(task models.Task) string {
+	status := r.f.Paint(theme.RoleStatusPending, "pending")
+	switch {
+	case task.Completed:
+		status = r.f.Paint(theme.RoleStatusDone, "completed")
+	case task.IsOverdue(r.now):
+		status = r.f.Paint(theme.RoleOverdue, "overdue")
+	}
+
+	due := r.f.Paint(theme.RoleDim, "-")
+	if task.DueDate != nil {
+		due = task.DueDate.Format(r.dateFormat)
+	}
+
+	priority := task.Priority.String()
+	if task.Priority == models.PriorityNone {
+		priority = r.f.Paint(theme.RoleDim, "none")
+	}
+
+	fields := [][2]string{{"ID", fmt.Sprint(task.ID)}}
+	if task.UID != "" {
+		fields = append(fields, [2]string{"UID", task.UID})
+	}
+	fields = append(fields, [2]string{"Description", task.Description})
+	if len(task.Tags) > 0 {
+		fields = append(fields, [2]string{"Tags", formatTags(task.Tags)})
+	}
+	fields = append(fields, [][2]string{
+		{"Status", status},
+		{"Priority", priority},
+		{"Due", due},
+		{"Created", task.CreatedAt.Format(r.dateFormat)},
+	}...)
+	if task.CompletedAt != nil {
+		fields = append(fields, [2]string{"Completed", task.CompletedAt.Format(r.dateFormat)})
+	}
+
+	if task.Recurrence != "" {
+		fields = append(fields, [2]string{"Repeats", task.Recurrence})
+	}
+	if len(task.TimeEntries) > 0 {
+		spent := formatDuration(task.TimeSpent(r.now))
+		if task.TrackingTime() {
+			spent += " (timer running)"
+		}
+		fields = append(fields, [2]string{"Time spent", spent})
+	}
+	if task.Notes != "" {
+		// Continuation lines line up under the first
+		fields = append(fields, [2]string{"Notes", strings.ReplaceAll(task.Notes, "\n", "\n"+strings.Repeat(" ", 13))})
+	}
+
+	lines := make([]string, len(fields))
+	for i, field := range fields {
+		lines[i] = r.f.Paint(theme.RoleHeader, fmt.Sprintf("%-12s", field[0]+":")) + " " + field[1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatDuration renders tracked time in hours and minutes, such as 45m or 2h 05m
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+// envList renders the output of env: each variable with its value, then
+// what it changes
+func (r *renderer) envList(statuses []env.Status) string {
+	width := 0
+	for _, s := range statuses {
+		width = max(width, len(s.Name))
+	}
+
+	lines := make([]string, 0, 2*len(statuses))
+	for _, s := range statuses {
+		value := r.f.Paint(theme.RoleDim, "(not set)")
+		if s.Set {
+			value = s.Value
+		}
+		lines = append(lines,
+			r.f.Paint(theme.RoleHeader, fmt.Sprintf("%-*s", width, s.Name))+"  "+value,
+			"  "+r.f.Paint(theme.RoleDim, s.Effect))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseDueDate parses a due date given on the command line. A bare date
+// means the end of that day in local time.
+func parseDueDate(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.Add(24*time.Hour - time.Second), nil
+}
@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"todolist/internal/config"
+	"todolist/internal/output"
+	"todolist/internal/theme"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// testRenderer builds a renderer whose color decision is injected
+func testRenderer(t *testing.T, isTerminal bool, mode output.ColorMode, noColor string) (*renderer, *theme.Formatter) {
+	th, err := theme.Lookup("default", nil)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	f := theme.NewFormatter(th, output.ShouldColor(isTerminal, mode, noColor))
+	r := &renderer{
+		f:          f,
+		now:        time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+		dateFormat: "2006-01-02",
+	}
+	return r, f
+}
+
+// sampleTasks returns one completed, one pending and one overdue task
+func sampleTasks(now time.Time) []models.Task {
+	past := now.Add(-48 * time.Hour)
+	future := now.Add(72 * time.Hour)
+	return []models.Task{
+		{ID: 1, Description: "done", Completed: true, CreatedAt: now},
+		{ID: 2, Description: "pending", DueDate: &future, CreatedAt: now},
+		{ID: 3, Description: "late", DueDate: &past, CreatedAt: now},
+	}
+}
+
+// TestListColorsStatus tests that list colors completed, pending and overdue tasks differently
+func TestListColorsStatus(t *testing.T) {
+	r, f := testRenderer(t, true, output.ColorAuto, "")
+	lines := strings.Split(r.taskList("Your tasks:", sampleTasks(r.now)), "\n")
+
+	expectations := []string{
+		f.Paint(theme.RoleStatusDone, "[✓]") + " [1]",
+		f.Paint(theme.RoleStatusPending, "[ ]") + " [2]",
+		f.Paint(theme.RoleOverdue, "[ ]") + " [3]",
+	}
+	for i, expected := range expectations {
+		if !strings.HasPrefix(lines[i+1], expected) {
+			t.Errorf("Line %d: expected prefix %q, got %q", i+1, expected, lines[i+1])
+		}
+	}
+
+	// The three status markers must actually differ
+	if expectations[0] == expectations[1] || expectations[1] == expectations[2] {
+		t.Error("Expected distinct styles for done, pending and overdue")
+	}
+}
+
+// TestListPlainWhenColorDisabled tests that no styling is emitted for pipes, NO_COLOR or --no-color
+func TestListPlainWhenColorDisabled(t *testing.T) {
+	testCases := []struct {
+		name       string
+		isTerminal bool
+		mode       output.ColorMode
+		noColor    string
+	}{
+		{"piped", false, output.ColorAuto, ""},
+		{"piped with --color", false, output.ColorAlways, ""},
+		{"NO_COLOR", true, output.ColorAuto, "1"},
+		{"--no-color", true, output.ColorNever, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _ := testRenderer(t, tc.isTerminal, tc.mode, tc.noColor)
+			got := r.taskList("Your tasks:", sampleTasks(r.now))
+			expected := strings.Join([]string{
+				"Your tasks:",
+				"[✓] [1] done (created: 2026-01-15)",
+				"[ ] [2] pending (due: 2026-01-18) (created: 2026-01-15)",
+				"[ ] [3] late (due: 2026-01-13) (created: 2026-01-15)",
+			}, "\n")
+			if got != expected {
+				t.Errorf("Expected plain output:\n%s\ngot:\n%s", expected, got)
+			}
+		})
+	}
+}
+
+// TestTaskGroups tests the headed sections printed by list --group
+func TestTaskGroups(t *testing.T) {
+	r, _ := testRenderer(t, false, output.ColorAuto, "")
+	groups := todolist.GroupTasks(sampleTasks(r.now), todolist.GroupByStatus)
+
+	expected := strings.Join([]string{
+		"Pending (2)",
+		"[ ] [2] pending (due: 2026-01-18) (created: 2026-01-15)",
+		"[ ] [3] late (due: 2026-01-13) (created: 2026-01-15)",
+		"",
+		"Completed (1)",
+		"[✓] [1] done (created: 2026-01-15)",
+	}, "\n")
+	if got := r.taskGroups(groups, r.taskLines); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	// list --group leaves out empty groups and keeps the chosen sort
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("banana")
+	tl.AddTask("apple")
+	cmd, err := ParseCommand([]string{"list", "--group", "--sort", "description"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	result, err := ExecuteCommand(cmd, tl)
+	if err != nil {
+		t.Fatalf("ExecuteCommand failed: %v", err)
+	}
+	lines := strings.Split(result, "\n")
+	if len(lines) != 3 || lines[0] != "Pending (2)" || !strings.Contains(lines[1], "apple") || !strings.Contains(lines[2], "banana") {
+		t.Errorf("Expected one sorted Pending group, got:\n%s", result)
+	}
+
+	if _, err := ParseCommand([]string{"list", "--group", "--format", "short"}); err == nil {
+		t.Error("Expected --group with --format to be rejected")
+	}
+}
+
+// TestTaskDetailShowsEveryField tests the key-value block printed by show
+func TestTaskDetailShowsEveryField(t *testing.T) {
+	r, _ := testRenderer(t, false, output.ColorAuto, "")
+	task := sampleTasks(r.now)[2]
+	task.Priority = models.PriorityHigh
+	task.Notes = "call first\nthen email"
+	stopped := r.now.Add(-time.Hour)
+	task.TimeEntries = []models.TimeEntry{
+		{Start: r.now.Add(-3 * time.Hour), End: &stopped},
+		{Start: r.now.Add(-5 * time.Minute)},
+	}
+
+	expected := strings.Join([]string{
+		"ID:          3",
+		"Description: late",
+		"Status:      overdue",
+		"Priority:    high",
+		"Due:         2026-01-13",
+		"Created:     2026-01-15",
+		"Time spent:  2h 05m (timer running)",
+		"Notes:       call first",
+		"             then email",
+	}, "\n")
+	if got := r.taskDetail(task); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+// TestTaskLineNotePreview tests that list shows the first line of a task's notes
+func TestTaskLineNotePreview(t *testing.T) {
+	r, _ := testRenderer(t, false, output.ColorAuto, "")
+	task := models.Task{ID: 1, Description: "call", CreatedAt: r.now}
+
+	testCases := []struct {
+		notes    string
+		expected string
+	}{
+		{"", "[ ] [1] call (created: 2026-01-15)"},
+		{"ask about rent", "[ ] [1] call (created: 2026-01-15) — ask about rent"},
+		{"ask about rent\nand the boiler", "[ ] [1] call (created: 2026-01-15) — ask about rent…"},
+		{strings.Repeat("x", 45), "[ ] [1] call (created: 2026-01-15) — " + strings.Repeat("x", 40) + "…"},
+	}
+	for _, tc := range testCases {
+		task.Notes = tc.notes
+		if got := r.taskLine(task); got != tc.expected {
+			t.Errorf("Notes %q: expected %q, got %q", tc.notes, tc.expected, got)
+		}
+	}
+
+	task.Notes, task.Tags = "", []string{"home", "errand"}
+	if got, expected := r.taskLine(task), "[ ] [1] call #home #errand (created: 2026-01-15)"; got != expected {
+		t.Errorf("Tags: expected %q, got %q", expected, got)
+	}
+
+	// The completion time only shows with --verbose
+	completed := r.now.Add(24 * time.Hour)
+	task.Tags, task.Completed, task.CompletedAt = nil, true, &completed
+	if got, expected := r.taskLine(task), "[✓] [1] call (created: 2026-01-15)"; got != expected {
+		t.Errorf("Completed: expected %q, got %q", expected, got)
+	}
+	r.verbose = true
+	if got, expected := r.taskLine(task), "[✓] [1] call (created: 2026-01-15) (completed: 2026-01-16)"; got != expected {
+		t.Errorf("Verbose: expected %q, got %q", expected, got)
+	}
+	if got := r.taskDetail(task); !strings.HasSuffix(got, "\nCompleted:   2026-01-16") {
+		t.Errorf("Expected show to include the completion date, got:\n%s", got)
+	}
+}
+
+// tableTasks returns tasks covering every column, including a long and a wide description
+func tableTasks(now time.Time) []models.Task {
+	tasks := sampleTasks(now)
+	tasks[0].Priority = models.PriorityLow
+	tasks[0].CreatedAt = now.Add(-50 * time.Hour)
+	tasks[1].Priority = models.PriorityHigh
+	tasks[1].Description = "Write the quarterly report covering revenue, hiring, infrastructure costs and the roadmap for next year"
+	tasks[2].Description = "学习 Go 语言并完成项目文档"
+	tasks[2].CreatedAt = now.Add(-90 * time.Minute)
+	return append(tasks, models.Task{ID: 10, Description: "short", CreatedAt: now.Add(-5 * time.Minute)})
+}
+
+// TestTaskTableColumns tests column selection and sizing at several terminal widths
+func TestTaskTableColumns(t *testing.T) {
+	testCases := []struct {
+		columns  []string
+		width    int
+		expected []string
+	}{
+		{
+			[]string{"id", "priority", "due", "description"},
+			80,
+			[]string{
+				"ID  Priority  Due         Description",
+				" 1  low                   done",
+				" 2  high      2026-01-18  Write the quarterly report covering revenue, hiring, …",
+				" 3            2026-01-13  学习 Go 语言并完成项目文档",
+				"10                        short",
+			},
+		},
+		{
+			[]string{"status", "id", "description", "age", "created"},
+			80,
+			[]string{
+				"Status   ID  Description                                         Age  Created",
+				"done      1  done                                                 2d  2026-01-13",
+				"pending   2  Write the quarterly report covering revenue, hiri…   0m  2026-01-15",
+				"overdue   3  学习 Go 语言并完成项目文档                           1h  2026-01-15",
+				"pending  10  short                                                5m  2026-01-15",
+			},
+		},
+		{
+			[]string{"id", "priority", "due", "description"},
+			120,
+			[]string{
+				"ID  Priority  Due         Description",
+				" 1  low                   done",
+				" 2  high      2026-01-18  Write the quarterly report covering revenue, hiring, infrastructure costs and the roadmap for…",
+				" 3            2026-01-13  学习 Go 语言并完成项目文档",
+				"10                        short",
+			},
+		},
+		{
+			[]string{"id", "description"},
+			120,
+			[]string{
+				"ID  Description",
+				" 1  done",
+				" 2  Write the quarterly report covering revenue, hiring, infrastructure costs and the roadmap for next year",
+				" 3  学习 Go 语言并完成项目文档",
+				"10  short",
+			},
+		},
+		{
+			[]string{"description", "id"},
+			0,
+			[]string{
+				"Description                                                                                              ID",
+				"done                                                                                                      1",
+				"Write the quarterly report covering revenue, hiring, infrastructure costs and the roadmap for next year   2",
+				"学习 Go 语言并完成项目文档                                                                                3",
+				"short                                                                                                    10",
+			},
+		},
+	}
+
+	r, _ := testRenderer(t, false, output.ColorAuto, "")
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s@%d", strings.Join(tc.columns, ","), tc.width), func(t *testing.T) {
+			got := r.taskTable(tc.columns, tableTasks(r.now), tc.width)
+			expected := strings.Join(tc.expected, "\n")
+			if got != expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+			}
+			if tc.width > 0 {
+				for _, line := range strings.Split(got, "\n") {
+					if displayWidth(line) > tc.width {
+						t.Errorf("Line wider than %d: %q", tc.width, line)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestTaskTableColorsCellsOnly tests that styling wraps cell text but not padding
+func TestTaskTableColorsCellsOnly(t *testing.T) {
+	r, f := testRenderer(t, true, output.ColorAuto, "")
+	got := r.taskTable([]string{"status", "id"}, sampleTasks(r.now), 0)
+	if !strings.Contains(got, f.Paint(theme.RoleOverdue, "overdue")+"  ") {
+		t.Errorf("Expected painted overdue status followed by plain padding, got %q", got)
+	}
+}
+
+// TestEveryConfigColumnRenders tests that each column accepted by the config has a renderer
+func TestEveryConfigColumnRenders(t *testing.T) {
+	for _, name := range config.ListColumns {
+		if _, ok := columns[name]; !ok {
+			t.Errorf("Column %q has no renderer", name)
+		}
+	}
+}
@@ -0,0 +1,844 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	apperrors "todolist/pkg/errors"
+)
+
+// flagSpec describes one --flag of a command
+type flagSpec struct {
+	name   string // Without the leading --
+	value  string // Placeholder for the value, empty for a flag without one
+	help   string
+	hidden bool // Accepted but left out of the help, e.g. kept for old scripts
+}
+
+// commandForm is one way of calling a command, as listed in the help
+type commandForm struct {
+	usage   []string // Synopsis lines; continuation lines start with spaces
+	summary string   // Description, already wrapped
+}
+
+// commandSpec describes a command: its forms and flags for the help and
+// for parseFlags, and examples for help <command>
+type commandSpec struct {
+	name     string
+	forms    []commandForm
+	flags    []flagSpec
+	examples []string
+	details  string // Shown only by help <command>
+}
+
+// commandSpecs lists every command ParseCommand accepts, in help order
+var commandSpecs = []commandSpec{
+	{
+		name: "add",
+		forms: []commandForm{{
+			usage: []string{
+				"add <description> [--due <date>] [--priority high|medium|low]",
+				"    [--recur <spec>] [--estimate <duration>] [--parse]",
+				"add -i [--due <date>] [--priority high|medium|low] [--recur <spec>]",
+				"add - [--due <date>] [--priority high|medium|low] [--recur <spec>]",
+			},
+			summary: `Add a new task, optionally with a due date
+(YYYY-MM-DD) and priority; -i writes it in $EDITOR,
+first line the description, the rest notes;
+- adds one task per non-blank line of stdin;
+--recur (daily, weekly, monthly or cron:<expr>)
+adds the next occurrence when the task is done;
+--estimate records the expected effort (30m, 2h,
+1h30m), shown by list and totalled by stats;
+--parse reads due:<date> and !high|!medium|!low
+from the description (\ escapes a word); #tag
+words become tags, shown apart from the description`,
+		}},
+		flags: []flagSpec{
+			{name: "due", value: "<date>", help: "Due date (YYYY-MM-DD)"},
+			{name: "priority", value: "high|medium|low", help: "Task priority"},
+			{name: "recur", value: "<spec>", help: "Repeat daily, weekly, monthly or on cron:<expr>"},
+			{name: "estimate", value: "<duration>", help: "Estimated effort, e.g. 30m, 2h or 1h30m"},
+			{name: "parse", help: "Read due:<date> and !priority from the description"},
+		},
+		examples: []string{
+			`add "Buy groceries"`,
+			`add "Submit report" --due 2026-01-31 --priority high`,
+			`add "Water plants" --recur weekly`,
+			`add "Write report" --estimate 1h30m`,
+		},
+	},
+	{
+		name: "list",
+		forms: []commandForm{
+			{
+				usage: []string{
+					"list [--columns <names> | --format <template>] [--sort <key>]",
+					"     [--reverse] [--group] [--limit N] [--offset M] [--verbose]",
+				},
+				summary: `List all tasks; --columns shows a table with the
+given comma-separated columns (id, status, priority,
+due, created, completed, age, description); --format prints
+each task with a template (short, long or your own,
+see help list); --sort orders by manual (list
+order, the default; see move), created, id,
+description or status; --group shows pending and
+completed tasks under separate headers; --limit N --offset M
+show N tasks after skipping M; --verbose adds when
+each task was completed`,
+			},
+			{
+				usage: []string{"list --watch [--interval <duration>]"},
+				summary: `Keep the list on screen, redrawing it whenever the
+task file changes and at least every interval
+(default 1m), until Ctrl+C; other list flags apply`,
+			},
+			{
+				usage: []string{"list --since <date> --until <date>"},
+				summary: `List only tasks created from --since to --until
+(YYYY-MM-DD, both days included); either may be
+left out for an open-ended range, and the other
+list flags still apply`,
+			},
+		},
+		flags: []flagSpec{
+			{name: "columns", value: "<names>", help: "Show a table with these comma-separated columns"},
+			{name: "format", value: "<template>", help: "Print each task with a template"},
+			{name: "sort", value: "<key>", help: "Order by manual, created, id, description or status"},
+			{name: "reverse", help: "Reverse the order"},
+			{name: "group", help: "Show pending and completed tasks apart"},
+			{name: "limit", value: "N", help: "Show at most N tasks"},
+			{name: "offset", value: "M", help: "Skip the first M tasks"},
+			{name: "verbose", help: "Show when each task was completed"},
+			{name: "watch", help: "Redraw the list until Ctrl+C"},
+			{name: "interval", value: "<duration>", help: "Redraw at least this often with --watch"},
+			{name: "since", value: "<date>", help: "Only tasks created on or after this day"},
+			{name: "until", value: "<date>", help: "Only tasks created on or before this day"},
+		},
+		examples: []string{
+			"list",
+			"list --sort created --reverse --limit 10",
+			"list --columns id,due,description --group",
+			"list --watch --interval 30s",
+		},
+		details: listTemplateHelp,
+	},
+	{
+		name: "done",
+		forms: []commandForm{
+			{
+				usage: []string{"done <id>..."},
+				summary: `Mark one or more tasks as completed; text that is
+not an ID or uid:<prefix> picks the one pending task
+whose description contains it (ignoring case), or
+if none does, the task whose UID starts with it`,
+			},
+			{usage: []string{"done --all"}, summary: "Mark every pending task as completed"},
+		},
+		flags: []flagSpec{
+			{name: "all", help: "Complete every pending task"},
+		},
+		examples: []string{"done 1", "done 2 3 5", "done milk", "done --all"},
+	},
+	{
+		name: "delete",
+		forms: []commandForm{
+			{
+				usage: []string{"delete <id>... [--yes] [--force]"},
+				summary: `Move one or more tasks to the trash, named by ID,
+UID prefix or description text as with done;
+--force deletes them permanently instead; asks
+first when confirm_delete is set, unless --yes
+is given`,
+			},
+			{
+				usage: []string{"delete --all [--yes] [--force]"},
+				summary: `Delete every task and restart IDs at 1 (asks for
+confirmation unless --yes is given)`,
+			},
+		},
+		flags: []flagSpec{
+			{name: "all", help: "Delete every task"},
+			{name: "yes", help: "Do not ask for confirmation"},
+			{name: "force", help: "Delete permanently, bypassing the trash"},
+		},
+		examples: []string{"delete 2", "delete 4 7", "delete 3 --force", "delete --all --yes"},
+	},
+	{
+		name: "trash",
+		forms: []commandForm{
+			{
+				usage: []string{"trash"},
+				summary: `List deleted tasks with their original IDs and when
+they were deleted; they are purged after 30 days`,
+			},
+			{
+				usage: []string{"trash restore <id>"},
+				summary: `Bring the task deleted with this ID back to the end
+of the list, with a new ID if its old one is taken`,
+			},
+			{usage: []string{"trash --empty"}, summary: "Delete every task in the trash permanently"},
+		},
+		flags: []flagSpec{
+			{name: "empty", help: "Purge the trash"},
+		},
+		examples: []string{"trash", "trash restore 4", "trash --empty"},
+	},
+	{
+		name: "overdue",
+		forms: []commandForm{{
+			usage:   []string{"overdue"},
+			summary: "List pending tasks past their due date",
+		}},
+		examples: []string{"overdue"},
+	},
+	{
+		name: "due",
+		forms: []commandForm{{
+			usage: []string{"due"},
+			summary: `List pending tasks due today or earlier; exits with
+status 6 if any are overdue (for shell prompts)`,
+		}},
+		examples: []string{"due"},
+	},
+	{
+		name: "upcoming",
+		forms: []commandForm{{
+			usage: []string{"upcoming [--days N]"},
+			summary: `List pending tasks due from now to the end of the
+day N days ahead (default 7)`,
+		}},
+		flags: []flagSpec{
+			{name: "days", value: "N", help: "How many days ahead to look (default 7)"},
+		},
+		examples: []string{"upcoming", "upcoming --days 14"},
+	},
+	{
+		name: "stale",
+		forms: []commandForm{{
+			usage: []string{"stale [--older-than <age>]"},
+			summary: `List pending tasks created at least age ago (7d by
+default; 4w, 6m for months, 1y or 36h also work),
+oldest first and marked [STALE]`,
+		}},
+		flags: []flagSpec{
+			{name: "older-than", value: "<age>", help: "Only tasks created at least this long ago (default 7d)"},
+		},
+		examples: []string{"stale", "stale --older-than 30d"},
+	},
+	{
+		name: "stats",
+		forms: []commandForm{{
+			usage: []string{"stats"},
+			summary: `Show task counts, completions this week against
+the 4-week average and a note when the backlog grew
+3 weeks in a row (weeks start on week_start)`,
+		}},
+		examples: []string{"stats"},
+	},
+	{
+		name: "count",
+		forms: []commandForm{{
+			usage: []string{"count [--completed | --all | --status pending|done|all] [--json]"},
+			summary: `Print the number of pending tasks (completed or all
+tasks with --completed or --all) and nothing else,
+for shell prompts; --status pending|done|all also
+selects, and --json prints all three counts`,
+		}},
+		flags: []flagSpec{
+			{name: "completed", help: "Count completed tasks"},
+			{name: "all", help: "Count all tasks"},
+			{name: "status", value: "pending|done|all", help: "Count the tasks with this status"},
+			{name: "json", help: "Print pending, completed and total counts as JSON"},
+			{name: "quiet", hidden: true},
+		},
+		examples: []string{"count", "count --status done", "count --json"},
+	},
+	{
+		name: "show",
+		forms: []commandForm{
+			{
+				usage: []string{"show <id> [--json]"},
+				summary: `Show every field of a task, including time spent
+and its UID; wherever a command takes <id>, a
+prefix of the UID naming a single task works too
+(8 or more characters, or uid:<prefix> for any
+length), and show, done and delete also take text
+matching one pending task's description`,
+			},
+			{
+				usage: []string{"show --desc <text> [--json]"},
+				summary: `Show the first task whose description is exactly
+text (case-sensitive), for scripts that know a task
+by name`,
+			},
+		},
+		flags: []flagSpec{
+			{name: "json", help: "Print the task as JSON"},
+			{name: "desc", value: "<text>", help: "Find the task by its exact description"},
+		},
+		examples: []string{"show 3", "show 3 --json", `show --desc "Buy groceries"`},
+	},
+	{
+		name: "report",
+		forms: []commandForm{{
+			usage: []string{"report [--week YYYY-WN] [--json | --format text|md]"},
+			summary: `Summarize tasks added, completed and deleted in a
+week (this one by default) by +project and priority,
+with the completion trend as in stats`,
+		}},
+		flags: []flagSpec{
+			{name: "week", value: "YYYY-WN", help: "Report on this ISO week"},
+			{name: "json", help: "Print the report as JSON"},
+			{name: "format", value: "text|md", help: "Print the report as text or Markdown"},
+		},
+		examples: []string{"report", "report --week 2026-W03 --format md"},
+	},
+	{
+		name: "time-track",
+		forms: []commandForm{{
+			usage:   []string{"time-track start|stop <id>"},
+			summary: "Start or stop a timer recording time spent on a task",
+		}},
+		examples: []string{"time-track start 3", "time-track stop 3"},
+	},
+	{
+		name: "move",
+		forms: []commandForm{
+			{
+				usage: []string{"move <id> before <before-id>"},
+				summary: `Move a task before another in the list order, or to
+the end if before-id is 0; IDs do not change`,
+			},
+			{
+				usage: []string{"move <id> --to <position> | --up | --down"},
+				summary: `Move a task to a position in the list order (1 is
+the top), or one place up or down; positions past
+either end move it to the top or the end`,
+			},
+		},
+		flags: []flagSpec{
+			{name: "to", value: "<position>", help: "Move to this position, counting from 1"},
+			{name: "up", help: "Move one place up"},
+			{name: "down", help: "Move one place down"},
+		},
+		examples: []string{"move 5 before 1", "move 2 before 0", "move 7 --to 2", "move 3 --up"},
+	},
+	{
+		name: "note",
+		forms: []commandForm{{
+			usage: []string{"note <id> [--append] [text]"},
+			summary: `Replace a task's notes with text, or add it on a new
+line with --append; no text clears them. show prints
+the notes in full, list their first line`,
+		}},
+		flags: []flagSpec{
+			{name: "append", help: "Add text on a new line instead of replacing the notes"},
+		},
+		examples: []string{`note 3 "Call before noon"`, `note 3 --append "Bring the receipt"`, "note 3"},
+	},
+	{
+		name: "renumber",
+		forms: []commandForm{{
+			usage: []string{"renumber [--yes]"},
+			summary: `Renumber tasks as 1, 2, 3, ... in list order to close
+gaps left by deletions (asks for confirmation
+unless --yes is given). Refused if the event log
+or the reminder state refers to an ID that would
+change`,
+		}},
+		flags: []flagSpec{
+			{name: "yes", help: "Do not ask for confirmation"},
+		},
+		examples: []string{"renumber", "renumber --yes"},
+	},
+	{
+		name: "copy",
+		forms: []commandForm{{
+			usage: []string{"copy <id>"},
+			summary: `Add a pending copy of a task with the same
+description, priority and notes`,
+		}},
+		examples: []string{"copy 3"},
+	},
+	{
+		name: "undo",
+		forms: []commandForm{{
+			usage: []string{"undo"},
+			summary: `Revert the most recent add/done/delete; run again
+to step further back (there is no redo). Refused
+if the list has changed since in a way the undo
+history did not record, such as by archive`,
+		}},
+		examples: []string{"undo"},
+	},
+	{
+		name: "prune",
+		forms: []commandForm{{
+			usage: []string{"prune --older-than <age>"},
+			summary: `Delete tasks completed more than age ago (7d, 4w,
+6m for months, 1y or 36h); the age is required.
+Tasks without a completion time go by their
+creation time`,
+		}},
+		flags: []flagSpec{
+			{name: "older-than", value: "<age>", help: "Only tasks completed more than this long ago"},
+		},
+		examples: []string{"prune --older-than 30d", "prune --older-than 1y"},
+	},
+	{
+		name: "archive",
+		forms: []commandForm{
+			{
+				usage: []string{"archive [--file <path>]"},
+				summary: `Move completed tasks to the archive file
+(todolist-archive.json next to the task file by
+default); cannot be undone`,
+			},
+			{usage: []string{"archive list [--file <path>]"}, summary: "List the archived tasks"},
+		},
+		flags: []flagSpec{
+			{name: "file", value: "<path>", help: "Use this archive file"},
+		},
+		examples: []string{"archive", "archive list"},
+	},
+	{
+		name: "snapshot",
+		forms: []commandForm{
+			{
+				usage: []string{"snapshot <name> [--dir <dir>]"},
+				summary: `Save a named copy of the task list to
+<dir>/<name>.json (<task file>.snapshots by default);
+restore <dir>/<name>.json brings it back`,
+			},
+			{
+				usage:   []string{"snapshot list [--dir <dir>]"},
+				summary: "List snapshots with their creation dates and sizes",
+			},
+			{usage: []string{"snapshot delete <name> [--dir <dir>]"}, summary: "Delete a snapshot"},
+		},
+		flags: []flagSpec{
+			{name: "dir", value: "<dir>", help: "Keep snapshots in this directory"},
+		},
+		examples: []string{"snapshot before-cleanup", "snapshot list", "snapshot delete before-cleanup"},
+	},
+	{
+		name: "export",
+		forms: []commandForm{{
+			usage: []string{"export --format todotxt|markdown|json [--output <file>]"},
+			summary: `Print all tasks in todo.txt format, as a Markdown
+checklist (- [ ] / - [x]) or as JSON in the task
+file format; --output writes them to a file instead
+(- for stdout)`,
+		}},
+		flags: []flagSpec{
+			{name: "format", value: "todotxt|markdown|json", help: "Output format (required)"},
+			{name: "output", value: "<file>", help: "Write to this file instead of stdout"},
+		},
+		examples: []string{"export --format todotxt", "export --format markdown --output tasks.md"},
+	},
+	{
+		name: "import",
+		forms: []commandForm{{
+			usage: []string{"import --format todotxt|markdown|json [file | --stdin]"},
+			summary: `Add tasks from a todo.txt file, the checklist items
+of a Markdown file or a JSON task list (or stdin)`,
+		}},
+		flags: []flagSpec{
+			{name: "format", value: "todotxt|markdown|json", help: "Input format (required)"},
+			{name: "stdin", help: "Read the tasks from standard input"},
+		},
+		examples: []string{"import --format todotxt todo.txt", "import --format markdown --stdin"},
+	},
+	{
+		name: "merge",
+		forms: []commandForm{{
+			usage: []string{"merge <file>"},
+			summary: `Fold another task file into this one: tasks in both
+(same UID, or same description and creation time)
+are completed if done in either and reported if
+their details differ; the others are added`,
+		}},
+		examples: []string{"merge laptop-todos.json"},
+	},
+	{
+		name: "schema",
+		forms: []commandForm{{
+			usage:   []string{"schema"},
+			summary: "Print the JSON Schema of the data file",
+		}},
+		examples: []string{"schema"},
+	},
+	{
+		name: "init",
+		forms: []commandForm{
+			{
+				usage: []string{"init"},
+				summary: `Set up the config file (task file, date format,
+colour) by answering questions, and create an empty
+task list`,
+			},
+			{
+				usage: []string{"init --repair"},
+				summary: `Delete the temporary file an interrupted save left
+next to the task file, then check the list loads`,
+			},
+		},
+		flags: []flagSpec{
+			{name: "repair", help: "Clean up after an interrupted save"},
+		},
+		examples: []string{"init", "init --repair"},
+	},
+	{
+		name: "config",
+		forms: []commandForm{
+			{
+				usage: []string{"config"},
+				summary: `Show every setting in effect and whether it comes
+from a flag, an environment variable, the config
+file or the default`,
+			},
+			{
+				usage: []string{"config set <key> <value>"},
+				summary: `Change a setting in the config file (keys:
+storage_path, date_format, colour_enabled,
+default_sort, week_start, theme, backup_depth,
+json_indent, list_columns, no_duplicates,
+confirm_delete, max_description_length, max_tasks,
+webhook.url, webhook.secret)`,
+			},
+		},
+		examples: []string{"config", "config set date_format 2006-01-02", "config set confirm_delete true"},
+	},
+	{
+		name: "webhook",
+		forms: []commandForm{{
+			usage: []string{"webhook test"},
+			summary: `Post a ping event to the configured webhook and
+report whether it answered with a 2xx status; once
+webhook.url is set, every add, completion and deletion
+is posted there as {"event":"task.added","task":...,
+"timestamp":...}, signed with webhook.secret in the
+X-Signature header (sha256=<hex HMAC-SHA256 of body>)`,
+		}},
+		examples: []string{"webhook test"},
+	},
+	{
+		name: "capture",
+		forms: []commandForm{{
+			usage: []string{"capture [--window]"},
+			summary: `Read one line from stdin and add it as a task
+(for hotkey popups; --window pauses before exit)`,
+		}},
+		flags: []flagSpec{
+			{name: "window", help: "Pause before exiting so a popup stays readable"},
+		},
+		examples: []string{"capture", "capture --window"},
+	},
+	{
+		name: "remind",
+		forms: []commandForm{{
+			usage: []string{"remind [--watch [--interval <duration>]]"},
+			summary: `Send desktop notifications for tasks due within an
+hour, due now or a day overdue, once each; --watch
+keeps running and checks every minute (or interval)`,
+		}},
+		flags: []flagSpec{
+			{name: "watch", help: "Keep running and check again every interval"},
+			{name: "interval", value: "<duration>", help: "How often to check with --watch (default 1m)"},
+		},
+		examples: []string{"remind", "remind --watch --interval 5m"},
+	},
+	{
+		name: "serve",
+		forms: []commandForm{{
+			usage: []string{"serve [--addr <host:port>] [--metrics-addr <host:port>]"},
+			summary: `Serve a JSON API at --addr (GET/POST /tasks,
+GET/DELETE /tasks/{id}, POST /tasks/{id}/complete)
+and task counts for Prometheus at --metrics-addr
+/metrics (todolist_tasks_total, _pending,
+_completed, _overdue); stops on Ctrl+C or SIGTERM`,
+		}},
+		flags: []flagSpec{
+			{name: "addr", value: "<host:port>", help: "Serve the task API on this address"},
+			{name: "metrics-addr", value: "<host:port>", help: "Serve Prometheus metrics on this address"},
+		},
+		examples: []string{"serve --addr localhost:8080", "serve --metrics-addr :9090"},
+	},
+	{
+		name: "backup",
+		forms: []commandForm{{
+			usage: []string{"backup [--dir <dir>] [--max-backups N]"},
+			summary: `Copy the task file to a timestamped backup,
+keeping at most N backups in the directory`,
+		}},
+		flags: []flagSpec{
+			{name: "dir", value: "<dir>", help: "Keep backups in this directory"},
+			{name: "max-backups", value: "N", help: "Delete the oldest backups beyond N"},
+		},
+		examples: []string{"backup", "backup --dir ~/backups --max-backups 10"},
+	},
+	{
+		name: "restore",
+		forms: []commandForm{{
+			usage: []string{"restore [<file>] [--yes]"},
+			summary: `Replace the task list with a backup file (the current
+list is backed up first); without a file, swap the
+most recent backup back in (run again to undo)`,
+		}},
+		flags: []flagSpec{
+			{name: "yes", help: "Do not ask for confirmation"},
+		},
+		examples: []string{"restore", "restore todos.json.bak --yes"},
+	},
+	{
+		name: "version",
+		forms: []commandForm{{
+			usage:   []string{"version"},
+			summary: "Show the version, commit and build date",
+		}},
+		examples: []string{"version"},
+	},
+	{
+		name: "deprecations",
+		forms: []commandForm{{
+			usage: []string{"deprecations"},
+			summary: `List deprecated flags, commands and config keys
+(silence notices with TODOLIST_SUPPRESS_DEPRECATIONS=1)`,
+		}},
+		examples: []string{"deprecations"},
+	},
+	{
+		name: "env",
+		forms: []commandForm{{
+			usage: []string{"env [--json]"},
+			summary: `List the environment variables todolist reads, their
+current values and what they change`,
+		}},
+		flags: []flagSpec{
+			{name: "json", help: "Print the variables as JSON"},
+		},
+		examples: []string{"env", "env --json"},
+	},
+	{
+		name: "demo",
+		forms: []commandForm{{
+			usage: []string{"demo [--seed N] [--keep]"},
+			summary: `Start a shell using a throwaway list of sample tasks
+(--keep leaves the list on disk afterwards)`,
+		}},
+		flags: []flagSpec{
+			{name: "seed", value: "N", help: "Generate the same sample tasks every time"},
+			{name: "keep", help: "Leave the list on disk afterwards"},
+		},
+		examples: []string{"demo", "demo --seed 42 --keep"},
+	},
+	{
+		name: "help",
+		forms: []commandForm{{
+			usage:   []string{"help [command]"},
+			summary: "Show this help message, or the help for one command",
+		}},
+		examples: []string{"help", "help add", "add --help"},
+	},
+}
+
+// commandNames lists every command ParseCommand accepts, used for suggestions
+var commandNames = func() []string {
+	names := make([]string, len(commandSpecs))
+	for i, spec := range commandSpecs {
+		names[i] = spec.name
+	}
+	return names
+}()
+
+// helpColumn is where descriptions start in the help text
+const helpColumn = 23
+
+// lookupCommand returns the spec of a command (not an alias)
+func lookupCommand(name string) (*commandSpec, bool) {
+	for i := range commandSpecs {
+		if commandSpecs[i].name == name {
+			return &commandSpecs[i], true
+		}
+	}
+	return nil, false
+}
+
+// commandFlags returns the names of a command's flags that take a value
+// and of those that do not, for parseFlags
+func commandFlags(name string) (valueFlags, boolFlags []string) {
+	spec, _ := lookupCommand(name)
+	if spec == nil {
+		return nil, nil
+	}
+	for _, f := range spec.flags {
+		if f.value != "" {
+			valueFlags = append(valueFlags, f.name)
+		} else {
+			boolFlags = append(boolFlags, f.name)
+		}
+	}
+	return valueFlags, boolFlags
+}
+
+// isHelpRequest reports whether command arguments ask for help with
+// --help or -h; anything after "--" is left alone
+func isHelpRequest(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "--":
+			return false
+		case "--help", "-h":
+			return true
+		}
+	}
+	return false
+}
+
+// writeEntry writes lines with text beside the last one from helpColumn,
+// or on the lines below when it does not fit
+func writeEntry(b *strings.Builder, lines []string, text string) {
+	textLines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i < len(lines)-1 || len(line) > helpColumn-3 || text == "" {
+			b.WriteString(strings.TrimRight("  "+line, " ") + "\n")
+			continue
+		}
+		fmt.Fprintf(b, "  %-*s%s\n", helpColumn-2, line, textLines[0])
+		textLines = textLines[1:]
+	}
+	if text == "" {
+		return
+	}
+	for _, line := range textLines {
+		b.WriteString(strings.Repeat(" ", helpColumn) + line + "\n")
+	}
+}
+
+// usageText renders the forms of a command as in the Commands section
+func (spec *commandSpec) usageText() string {
+	var b strings.Builder
+	for _, form := range spec.forms {
+		writeEntry(&b, form.usage, form.summary)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// commandsText renders the Commands section of the help text
+func commandsText() string {
+	parts := make([]string, len(commandSpecs))
+	for i := range commandSpecs {
+		parts[i] = commandSpecs[i].usageText()
+	}
+	return strings.Join(parts, "\n")
+}
+
+// commandHelp returns the help for one command (or alias): its forms,
+// flags, examples and any details kept for help <command>
+func commandHelp(name string) (string, error) {
+	spec, ok := lookupCommand(resolveAlias(strings.ToLower(name)))
+	if !ok {
+		return "", unknownHelpTopicError(name)
+	}
+
+	var b strings.Builder
+	b.WriteString("Usage:\n" + spec.usageText() + "\n")
+	var flags []string
+	for _, f := range spec.flags {
+		if !f.hidden {
+			flags = append(flags, f.name)
+		}
+	}
+	if len(flags) > 0 {
+		b.WriteString("\nFlags:\n")
+		for _, f := range spec.flags {
+			if f.hidden {
+				continue
+			}
+			synopsis := "--" + f.name
+			if f.value != "" {
+				synopsis += " " + f.value
+			}
+			writeEntry(&b, []string{synopsis}, f.help)
+		}
+	}
+	b.WriteString("\nExamples:\n")
+	for _, example := range spec.examples {
+		b.WriteString("  todolist " + example + "\n")
+	}
+	if spec.details != "" {
+		b.WriteString("\n" + spec.details + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// unknownHelpTopicError returns ErrInvalidCommand for help on something
+// that is not a command, listing the commands there is help for
+func unknownHelpTopicError(name string) error {
+	if suggestion, ok := suggestCommand(name); ok {
+		return fmt.Errorf("%w: no help for '%s', did you mean '%s'? (commands: %s)",
+			apperrors.ErrInvalidCommand, name, suggestion, strings.Join(commandNames, ", "))
+	}
+	return fmt.Errorf("%w: no help for '%s' (commands: %s)",
+		apperrors.ErrInvalidCommand, name, strings.Join(commandNames, ", "))
+}
+
+// getHelpText returns the help message
+func getHelpText() string {
+	return `Todo List CLI - A simple command-line todo list manager
+
+Usage:
+  todolist [global flags] <command> [arguments]
+
+Commands:
+` + commandsText() + `
+
+` + aliasHelp() + `
+
+Global flags:
+  --config <path>      Use this config file instead of
+                       $XDG_CONFIG_HOME/todolist/config.json
+  --remote <url>       Keep tasks on a server (GET/PUT <url>/tasks) instead
+                       of the local file; TODOLIST_REMOTE_TOKEN is sent as
+                       a bearer token
+  --color              Force colored output on a terminal
+  --no-color           Disable colored output (so does NO_COLOR=1);
+                       output that is not a terminal is never colored
+  --theme <name>       Color theme: default, colorblind, mono, or a theme
+                       defined in the config file
+  --dry-run            Show what add, done, delete, move, note, copy,
+                       prune, renumber, import, merge, archive or
+                       restore <file> would do without saving anything
+  --recover            If the task file is not valid JSON, rename it to
+                       <file>.corrupt-<time> and start with an empty list
+                       (asked interactively when stdin is a terminal)
+  --read-only          Refuse commands that change the task file; this is
+                       also the case when the file or its directory is not
+                       writable, while list, show, count, stats, export
+                       and other reading commands keep working
+  --force-save         Save even if another process changed the task file
+                       since it was read, overwriting that change; without
+                       it such a save fails with exit code 4, except that
+                       added tasks are appended to the other change
+  --log-level <level>  Log every change to the task list as JSON on
+                       stderr; changes are logged at info, so debug and
+                       info show them and warn or error hide them
+
+Exit codes:
+  0  success
+  1  other failure
+  2  invalid command, arguments or task ID
+  3  task not found
+  4  storage error (reading or writing files, read-only task file,
+     task file changed by another process)
+  5  invalid JSON or corrupt data
+  6  due found overdue tasks
+
+Examples:
+  todolist add "Buy groceries"
+  todolist add "Submit report" --due 2026-01-31
+  todolist list
+  todolist done 1
+  todolist delete 2
+  todolist help add`
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	apperrors "todolist/pkg/errors"
+)
+
+// TestCommandSpecs tests that every command has help: a usage line and
+// summary for each form, its flags in the usage, and examples that parse
+func TestCommandSpecs(t *testing.T) {
+	for _, spec := range commandSpecs {
+		if len(spec.forms) == 0 || len(spec.examples) == 0 {
+			t.Errorf("%s: expected usage and examples", spec.name)
+			continue
+		}
+		var usage []string
+		for _, form := range spec.forms {
+			if len(form.usage) == 0 || form.summary == "" {
+				t.Errorf("%s: expected a usage line and summary for every form", spec.name)
+			}
+			usage = append(usage, form.usage...)
+		}
+		for _, line := range usage {
+			if !strings.HasPrefix(line, spec.name) && !strings.HasPrefix(line, " ") {
+				t.Errorf("%s: usage line %q does not start with the command", spec.name, line)
+			}
+		}
+		for _, f := range spec.flags {
+			if f.hidden {
+				continue
+			}
+			if f.help == "" || !strings.Contains(strings.Join(usage, " "), "--"+f.name) {
+				t.Errorf("%s: flag --%s needs help and a place in the usage", spec.name, f.name)
+			}
+		}
+		for _, example := range spec.examples {
+			args := splitExample(example)
+			if _, err := ParseCommand(args); err != nil {
+				t.Errorf("%s: example %q does not parse: %v", spec.name, example, err)
+			}
+		}
+
+		help, err := commandHelp(spec.name)
+		if err != nil || !strings.Contains(help, "Examples:\n  todolist "+spec.examples[0]) {
+			t.Errorf("%s: expected help with examples, got %q, %v", spec.name, help, err)
+		}
+		if !strings.Contains(getHelpText(), "\n  "+spec.forms[0].usage[0]) {
+			t.Errorf("%s: missing from the help text", spec.name)
+		}
+	}
+}
+
+// splitExample splits an example command line into arguments as a shell
+// would, for the double and single quotes the examples use
+func splitExample(example string) []string {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range example {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// TestHelpFlag tests that <command> --help and -h show that command's help
+func TestHelpFlag(t *testing.T) {
+	for _, args := range [][]string{
+		{"add", "--help"},
+		{"add", "Buy milk", "-h"},
+		{"a", "--help"},
+	} {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		if cmd.Name != "help" || len(cmd.Args) != 1 || cmd.Args[0] != "add" {
+			t.Errorf("Expected help add for %v, got %+v", args, cmd)
+		}
+	}
+
+	// After -- it is part of the arguments
+	cmd, err := ParseCommand([]string{"add", "--", "--help"})
+	if err == nil && cmd.Name == "help" {
+		t.Errorf("Expected --help after -- not to ask for help")
+	}
+
+	cmd, err = ParseCommand([]string{"done", "--help"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+	got, _ := ExecuteCommand(cmd, nil)
+	want, _ := commandHelp("done")
+	if got != want {
+		t.Errorf("Expected done --help to match help done, got %q", got)
+	}
+}
+
+// TestUnknownHelpTopic tests that help on an unknown topic lists the commands
+func TestUnknownHelpTopic(t *testing.T) {
+	_, err := ParseCommand([]string{"help", "frobnicate"})
+	if !apperrors.IsInvalidCommand(err) {
+		t.Fatalf("Expected an invalid command error, got %v", err)
+	}
+	for _, name := range []string{"add", "list", "backup", "help"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected the commands to be listed, got %v", err)
+		}
+	}
+
+	_, err = ParseCommand([]string{"help", "lsit"})
+	if err == nil || !strings.Contains(err.Error(), "did you mean 'list'") {
+		t.Errorf("Expected a suggestion, got %v", err)
+	}
+}
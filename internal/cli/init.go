@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+	"todolist/internal/config"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+)
+
+// InitOptions controls the init command
+type InitOptions struct {
+	// ConfigPath is the config file to write
+	ConfigPath string
+	// StoragePath is the task file offered as the default
+	StoragePath string
+}
+
+// RunInit asks for the storage path, date format and colour preference,
+// writes them to a new config file and creates an empty task list at the
+// chosen path. An existing config file is only replaced after asking; an
+// existing task file is kept as it is.
+func RunInit(in io.Reader, out io.Writer, opts InitOptions) error {
+	p := NewPrompter(in, out)
+
+	if _, err := os.Stat(opts.ConfigPath); err == nil {
+		replace, err := p.Confirm(fmt.Sprintf("%s already exists. Replace it?", opts.ConfigPath), false)
+		if err != nil {
+			return err
+		}
+		if !replace {
+			fmt.Fprintln(out, "Nothing changed.")
+			return nil
+		}
+	}
+
+	cfg := &config.Config{}
+	storagePath, err := p.Ask("Task file", opts.StoragePath, nil)
+	if err != nil {
+		return err
+	}
+	dateFormat, err := p.Ask("Date format (Go layout, e.g. 2006-01-02 or Jan 2 15:04)", config.DefaultDateFormat, validateDateFormat)
+	if err != nil {
+		return err
+	}
+	colour, err := p.Confirm("Use colour output on terminals?", true)
+	if err != nil {
+		return err
+	}
+	for key, value := range map[string]string{
+		"storage_path":   storagePath,
+		"date_format":    dateFormat,
+		"colour_enabled": strconv.FormatBool(colour),
+	} {
+		if err := cfg.Set(key, value); err != nil {
+			return err
+		}
+	}
+
+	if err := config.SaveConfig(opts.ConfigPath, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✓ Config written to %s\n", opts.ConfigPath)
+
+	path, err := cfg.ResolveStoragePath(opts.StoragePath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		fmt.Fprintf(out, "✓ Using the existing task list at %s\n", path)
+		return nil
+	}
+	if err := storage.NewFileStorage(path).Save(&models.TaskList{Tasks: []models.Task{}, NextID: 1}); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✓ Created an empty task list at %s\n", path)
+	return nil
+}
+
+// RepairStorage deletes the temporary file an interrupted save left next to
+// the task file, then loads the list to show it is usable again
+func RepairStorage(out io.Writer, fs *storage.FileStorage) error {
+	removed, err := fs.RemoveTemp()
+	if err != nil {
+		return err
+	}
+	if removed {
+		fmt.Fprintf(out, "✓ Removed %s\n", fs.TempPath())
+	} else {
+		fmt.Fprintf(out, "No leftover temporary file at %s\n", fs.TempPath())
+	}
+
+	list, err := fs.Load()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✓ The task list loads (%d tasks)\n", len(list.Tasks))
+	return nil
+}
+
+// validateDateFormat rejects layouts without any date or time fields,
+// which would show every date as the same text
+func validateDateFormat(layout string) error {
+	if err := (&config.Config{}).Set("date_format", layout); err != nil {
+		return err
+	}
+	if time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC).Format(layout) == layout {
+		return fmt.Errorf("%q has no date or time fields; use Go layout values such as 2006, 01, 02, 15 and 04", layout)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todolist/internal/config"
+	"todolist/pkg/storage"
+)
+
+// TestRunInit tests writing the config and task file from the answers and
+// asking before replacing an existing config
+func TestRunInit(t *testing.T) {
+	dir := t.TempDir()
+	opts := InitOptions{
+		ConfigPath:  filepath.Join(dir, "config", "config.json"),
+		StoragePath: filepath.Join(dir, "default.json"),
+	}
+	taskFile := filepath.Join(dir, "tasks", "todo.json")
+
+	// A layout without fields is asked again
+	var out strings.Builder
+	if err := RunInit(strings.NewReader(taskFile+"\nnothing\n02/01/2006\nn\n"), &out, opts); err != nil {
+		t.Fatalf("RunInit failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "has no date or time fields") {
+		t.Errorf("Expected the bad layout to be rejected, got:\n%s", out.String())
+	}
+
+	cfg, err := config.LoadConfig(opts.ConfigPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.StoragePath != taskFile || cfg.DateFormat != "02/01/2006" || cfg.ColourEnabled == nil || *cfg.ColourEnabled {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	list, err := storage.NewFileStorage(taskFile).Load()
+	if err != nil || len(list.Tasks) != 0 || list.NextID != 1 {
+		t.Errorf("Expected an empty task list, got %+v, %v", list, err)
+	}
+	if _, err := os.Stat(taskFile); err != nil {
+		t.Errorf("Expected the task file to be created: %v", err)
+	}
+
+	// Declining leaves the config alone
+	out.Reset()
+	if err := RunInit(strings.NewReader("\n"), &out, opts); err != nil {
+		t.Fatalf("RunInit failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Nothing changed.") {
+		t.Errorf("Expected nothing to change, got:\n%s", out.String())
+	}
+
+	// Replacing takes the defaults and keeps the existing task file
+	os.WriteFile(opts.StoragePath, []byte(`{"tasks": [{"id": 1, "description": "keep"}], "next_id": 2}`), 0644)
+	out.Reset()
+	if err := RunInit(strings.NewReader("y\n"), &out, opts); err != nil {
+		t.Fatalf("RunInit failed: %v", err)
+	}
+	cfg, _ = config.LoadConfig(opts.ConfigPath)
+	if cfg.StoragePath != opts.StoragePath || cfg.DateFormat != config.DefaultDateFormat || !*cfg.ColourEnabled {
+		t.Errorf("Expected the defaults, got %+v", cfg)
+	}
+	if list, _ := storage.NewFileStorage(opts.StoragePath).Load(); len(list.Tasks) != 1 {
+		t.Errorf("Expected the existing task list to be kept, got %+v", list)
+	}
+	if !strings.Contains(out.String(), "Using the existing task list") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+}
+
+// TestRepairStorage tests that init --repair removes a leftover temporary
+// file and reports the list that loads afterwards
+func TestRepairStorage(t *testing.T) {
+	fs := storage.NewFileStorage(filepath.Join(t.TempDir(), "todo.json"))
+	os.WriteFile(fs.TempPath(), []byte("partial"), 0644)
+	if _, err := fs.Load(); err == nil {
+		t.Fatal("Expected Load to fail with a leftover temporary file")
+	}
+
+	var out strings.Builder
+	if err := RepairStorage(&out, fs); err != nil {
+		t.Fatalf("RepairStorage failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "✓ Removed "+fs.TempPath()) || !strings.Contains(out.String(), "(0 tasks)") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := RepairStorage(&out, fs); err != nil || !strings.Contains(out.String(), "No leftover temporary file") {
+		t.Errorf("Expected nothing to repair, got %v:\n%s", err, out.String())
+	}
+
+	if cmd, err := ParseCommand([]string{"init", "--repair"}); err != nil || cmd.Flags["repair"] == "" {
+		t.Errorf("Expected init --repair to parse, got %+v, %v", cmd, err)
+	}
+}
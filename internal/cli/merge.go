@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"todolist/pkg/todolist"
+)
+
+// mergeSummary reports what merging the task file at path did: the counts,
+// then a line for every task added, completed or left in conflict
+func mergeSummary(path string, result todolist.MergeResult) string {
+	lines := []string{fmt.Sprintf("✓ Merged %s: %d added, %d updated, %d conflicts",
+		path, len(result.Added), len(result.Updated), len(result.Conflicts))}
+	for _, task := range result.Added {
+		lines = append(lines, fmt.Sprintf("Added: task %d %q", task.ID, task.Description))
+	}
+	for _, task := range result.Updated {
+		lines = append(lines, fmt.Sprintf("Updated: task %d %q marked as completed", task.ID, task.Description))
+	}
+	for _, conflict := range result.Conflicts {
+		lines = append(lines, fmt.Sprintf("Conflict: task %d %q differs from %q in %s; kept this version",
+			conflict.Ours.ID, conflict.Ours.Description, conflict.Theirs.Description, path))
+	}
+	return strings.Join(lines, "\n")
+}
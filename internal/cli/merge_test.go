@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// TestMergeCommand tests that merge reports what it adds, completes and
+// leaves in conflict, and that --dry-run saves nothing
+func TestMergeCommand(t *testing.T) {
+	dir := t.TempDir()
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	fs := storage.NewFileStorage(filepath.Join(dir, "todos.json"))
+	if err := fs.Save(&models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, UID: "uid-1", Description: "pay rent", CreatedAt: created},
+			{ID: 2, UID: "uid-2", Description: "call Sam", CreatedAt: created},
+		},
+		NextID: 3,
+	}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	other := filepath.Join(dir, "other.json")
+	if err := storage.NewFileStorage(other).Save(&models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, UID: "uid-1", Description: "pay the rent", CreatedAt: created},
+			{ID: 2, UID: "uid-2", Description: "call Sam", CreatedAt: created, Completed: true},
+			{ID: 3, UID: "uid-3", Description: "buy milk", CreatedAt: created},
+		},
+		NextID: 4,
+	}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	summary := "✓ Merged " + other + ": 1 added, 1 updated, 1 conflicts\n" +
+		"Added: task 3 \"buy milk\"\n" +
+		"Updated: task 2 \"call Sam\" marked as completed\n" +
+		"Conflict: task 1 \"pay rent\" differs from \"pay the rent\" in " + other + "; kept this version"
+	testCases := []struct {
+		args      []string
+		expected  string
+		completed bool
+		tasks     int
+	}{
+		{[]string{"--dry-run", "merge", other}, "[dry-run] " + strings.ReplaceAll(summary, "\n", "\n[dry-run] "), false, 2},
+		{[]string{"merge", other}, summary, true, 3},
+	}
+	for _, tc := range testCases {
+		// Each run loads the file afresh, as the command line does
+		tl, err := todolist.NewTodoList(fs)
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		result, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", tc.args, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, result)
+		}
+		saved, err := fs.Load()
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if len(saved.Tasks) != tc.tasks || saved.Tasks[1].Completed != tc.completed {
+			t.Errorf("%v: expected %d tasks with task 2 completed=%v, got %+v", tc.args, tc.tasks, tc.completed, saved.Tasks)
+		}
+	}
+
+	for _, args := range [][]string{{"merge"}, {"merge", "a.json", "b.json"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+	tl, _ := todolist.NewTodoList(fs)
+	cmd, _ := ParseCommand([]string{"merge", filepath.Join(dir, "missing.json")})
+	if _, err := ExecuteCommand(cmd, tl); !apperrors.IsStorageError(err) {
+		t.Errorf("Expected a storage error for a missing file, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prompter asks questions on out and reads one answer per line from in.
+// It buffers in, so use a single Prompter for every question asked of the
+// same input.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompter creates a Prompter reading answers from in and writing
+// questions to out
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// readLine returns the next answer without surrounding whitespace. At the
+// end of the input it returns what was read, with io.EOF if that is nothing.
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Ask asks for a value, showing def as the default taken for a blank
+// answer or the end of the input. An answer rejected by validate (which
+// may be nil) is reported and the question asked again.
+func (p *Prompter) Ask(question, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+
+		answer, err := p.readLine()
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(p.out)
+			return def, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = def
+		}
+		if validate == nil {
+			return answer, nil
+		}
+		if err := validate(answer); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// Confirm asks a yes/no question. A blank answer or the end of the input
+// gives def; anything other than y, yes, n or no is asked again.
+func (p *Prompter) Confirm(question string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, choices)
+
+		answer, err := p.readLine()
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(p.out)
+			return def, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "  Please answer y or n")
+	}
+}
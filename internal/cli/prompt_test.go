@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestPrompterAsk tests defaults, validation retries and the end of input
+func TestPrompterAsk(t *testing.T) {
+	var out strings.Builder
+	p := NewPrompter(strings.NewReader("\n  bad \ngood\nlast"), &out)
+	validate := func(s string) error {
+		if s == "bad" {
+			return errors.New("not that one")
+		}
+		return nil
+	}
+
+	// Blank takes the default, "bad" is asked again, the last line has no
+	// newline, and once the input is used up every question takes its default
+	var got []string
+	for _, def := range []string{"first", "", "", "fallback"} {
+		answer, err := p.Ask("Name", def, validate)
+		if err != nil {
+			t.Fatalf("Ask failed: %v", err)
+		}
+		got = append(got, answer)
+	}
+
+	if want := []string{"first", "good", "last", "fallback"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if !strings.Contains(out.String(), "Name [first]: ") || !strings.Contains(out.String(), "  not that one\n") {
+		t.Errorf("Unexpected prompts:\n%s", out.String())
+	}
+}
+
+// TestPrompterConfirm tests yes/no answers, defaults and retries
+func TestPrompterConfirm(t *testing.T) {
+	var out strings.Builder
+	p := NewPrompter(strings.NewReader("YES\nn\n\nmaybe\ny\n"), &out)
+
+	var got []bool
+	for _, def := range []bool{false, true, true, false, false} {
+		ok, err := p.Confirm("Sure?", def)
+		if err != nil {
+			t.Fatalf("Confirm failed: %v", err)
+		}
+		got = append(got, ok)
+	}
+
+	// "maybe" is asked again and answered y; the input then runs out
+	if want := []bool{true, false, true, true, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if !strings.Contains(out.String(), "Sure? [Y/n]: ") || !strings.Contains(out.String(), "Please answer y or n") {
+		t.Errorf("Unexpected prompts:\n%s", out.String())
+	}
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ageUnits maps the calendar suffixes of parseAge to years, months and days
+var ageUnits = map[byte][3]int{
+	'd': {0, 0, 1},
+	'w': {0, 0, 7},
+	'm': {0, 1, 0},
+	'y': {1, 0, 0},
+}
+
+// defaultStaleAge is how long a task must be pending for stale to list it
+// when --older-than is not given
+const defaultStaleAge = "7d"
+
+// parseAge returns the time the given age before now. Besides the units of
+// time.ParseDuration such as 36h, it accepts a whole number of days (7d),
+// weeks (4w), calendar months (6m) or years (1y). Unlike ParseDuration, m
+// means months. Negative ages are rejected.
+func parseAge(value string, now time.Time) (time.Time, error) {
+	if len(value) >= 2 {
+		if unit, ok := ageUnits[value[len(value)-1]]; ok {
+			n, err := strconv.Atoi(value[:len(value)-1])
+			if err == nil && n >= 0 {
+				return now.AddDate(-n*unit[0], -n*unit[1], -n*unit[2]), nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid age %q, expected e.g. 7d, 4w, 6m, 1y or 36h", value)
+	}
+	return now.Add(-d), nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// TestParseAge tests the calendar suffixes and the time.ParseDuration fallback
+func TestParseAge(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"7d", time.Date(2026, 3, 24, 12, 0, 0, 0, time.UTC)},
+		{"4w", time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)},
+		{"6m", time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)}, // September 31st normalizes
+		{"1y", time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)},
+		{"0d", now},
+		{"36h", time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC)},
+		{"90m0s", time.Date(2026, 3, 31, 10, 30, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		got, err := parseAge(tc.value, now)
+		if err != nil {
+			t.Errorf("parseAge(%q) failed: %v", tc.value, err)
+			continue
+		}
+		if !got.Equal(tc.expected) {
+			t.Errorf("parseAge(%q): expected %v, got %v", tc.value, tc.expected, got)
+		}
+	}
+
+	for _, value := range []string{"", "d", "-3d", "1.5w", "30", "soon", "-1h"} {
+		if _, err := parseAge(value, now); err == nil {
+			t.Errorf("Expected parseAge(%q) to fail", value)
+		}
+	}
+}
+
+// TestPruneCommand tests that prune deletes only old completed tasks and
+// refuses to run without --older-than
+func TestPruneCommand(t *testing.T) {
+	now := time.Now()
+	recently := now.AddDate(0, 0, -2)
+	fs := storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json"))
+	if err := fs.Save(&models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, Description: "old and done", Completed: true, CreatedAt: now.AddDate(0, 0, -40)},
+			{ID: 2, Description: "old but pending", CreatedAt: now.AddDate(0, 0, -40)},
+			{ID: 3, Description: "recently done", Completed: true, CreatedAt: now.AddDate(0, 0, -40), CompletedAt: &recently},
+		},
+		NextID: 4,
+	}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	testCases := []struct {
+		args      []string
+		expected  string
+		remaining int
+	}{
+		{[]string{"prune", "--older-than", "30d", "--dry-run"}, "[dry-run] ✓ Pruned 1 completed tasks", 3},
+		{[]string{"prune", "--older-than", "30d"}, "✓ Pruned 1 completed tasks", 2},
+		{[]string{"prune", "--older-than", "30d"}, "No completed tasks older than 30d", 2},
+	}
+	for _, tc := range testCases {
+		// Each run loads the file afresh, as the command line does
+		tl, err := todolist.NewTodoList(fs)
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+		cmd, err := ParseCommand(tc.args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", tc.args, err)
+		}
+		result, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", tc.args, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, result)
+		}
+		saved, err := fs.Load()
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if len(saved.Tasks) != tc.remaining {
+			t.Errorf("%v: expected %d tasks saved, got %d", tc.args, tc.remaining, len(saved.Tasks))
+		}
+	}
+
+	for _, args := range [][]string{{"prune"}, {"prune", "--older-than", "soon"}, {"prune", "old", "--older-than", "7d"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"todolist/internal/remind"
+	"todolist/pkg/storage"
+)
+
+// RemindStateSuffix is appended to the task file path to get the file that
+// records which reminders were sent
+const RemindStateSuffix = ".reminders"
+
+// RemindOptions controls the remind command
+type RemindOptions struct {
+	// Watch keeps checking every Interval until interrupted
+	Watch bool
+	// Interval between checks in watch mode; 0 means remind.DefaultInterval
+	Interval time.Duration
+}
+
+// Remind notifies about tasks that are due soon, due or overdue. Without
+// Watch it checks once, for use from cron; with Watch it runs until SIGINT
+// or SIGTERM, reloading a task file whenever it changes. path also names
+// the reminder state file.
+func Remind(out, errOut io.Writer, st storage.Storage, path string, opts RemindOptions) error {
+	reminder, err := remind.NewReminder(remind.NewDesktopNotifier(out), path+RemindStateSuffix)
+	if err != nil {
+		return err
+	}
+	w := &remind.Watcher{
+		Storage:  st,
+		Reminder: reminder,
+		Interval: opts.Interval,
+		Log:      errOut,
+	}
+	// Only a local file can be watched for changes; other storages reload
+	// on every check
+	if _, ok := st.(*storage.FileStorage); ok {
+		w.Path = path
+	}
+
+	if !opts.Watch {
+		return w.Check(remind.SystemClock.Now())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return w.Run(ctx)
+}
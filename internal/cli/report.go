@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"todolist/internal/theme"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/todolist"
+)
+
+// isoWeekPattern matches an ISO 8601 week such as 2026-W03
+var isoWeekPattern = regexp.MustCompile(`^(\d{4})-[Ww](\d{1,2})$`)
+
+// parseISOWeek returns local midnight on the Monday starting an ISO week
+// written as YYYY-WN or YYYY-WNN
+func parseISOWeek(value string) (time.Time, error) {
+	m := isoWeekPattern.FindStringSubmatch(value)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("%w: week must be YYYY-WN, e.g. 2026-W3", apperrors.ErrInvalidCommand)
+	}
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+
+	// December 28th is always in the last week of its ISO year
+	if _, weeks := time.Date(year, 12, 28, 0, 0, 0, 0, time.Local).ISOWeek(); week < 1 || week > weeks {
+		return time.Time{}, fmt.Errorf("%w: %d has weeks 1 to %d", apperrors.ErrInvalidCommand, year, weeks)
+	}
+
+	// January 4th is always in week 1
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.Local)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	return monday.AddDate(0, 0, 7*(week-1)), nil
+}
+
+// dayStart returns local midnight at the start of the day containing t
+func dayStart(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// weekStart returns local midnight on the Monday of the week containing t
+func weekStart(t time.Time) time.Time {
+	day := dayStart(t)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// reportTitle names the week a report covers
+func reportTitle(report models.WeeklyReport) string {
+	year, week := report.Period.Start.ISOWeek()
+	last := report.Period.End.AddDate(0, 0, -1)
+	return fmt.Sprintf("Week %d-W%02d (%s to %s)", year, week,
+		report.Period.Start.Format("2006-01-02"), last.Format("2006-01-02"))
+}
+
+// trendLines describes a completion trend: completions this week against
+// the weekly average, once there are any, and a growing backlog
+func trendLines(trend models.CompletionTrend) []string {
+	var lines []string
+	switch {
+	case trend.Change != nil:
+		arrow := "→"
+		if *trend.Change > 0 {
+			arrow = "↑"
+		} else if *trend.Change < 0 {
+			arrow = "↓"
+		}
+		lines = append(lines, fmt.Sprintf("This week: %d completed, %s %.0f%% vs. the %d-week average (%.1f)",
+			trend.ThisWeek, arrow, math.Abs(*trend.Change), todolist.TrendWeeks, trend.Average))
+	case trend.ThisWeek > 0:
+		lines = append(lines, fmt.Sprintf("This week: %d completed (none in the %d weeks before)",
+			trend.ThisWeek, todolist.TrendWeeks))
+	}
+	if trend.BacklogGrowing {
+		lines = append(lines, fmt.Sprintf("Note: the backlog has grown for %d weeks in a row", todolist.BacklogGrowthWeeks))
+	}
+	return lines
+}
+
+// reportHeaders are the column headers of both report tables after the group name
+var reportHeaders = []string{"Added", "Completed", "Deleted"}
+
+// reportRows turns groups into table cells
+func reportRows(groups []models.ReportGroup) [][]string {
+	rows := make([][]string, len(groups))
+	for i, g := range groups {
+		rows[i] = []string{g.Name, strconv.Itoa(g.Added), strconv.Itoa(g.Completed), strconv.Itoa(g.Deleted)}
+	}
+	return rows
+}
+
+// weeklyReport renders a report as plain text tables
+func (r *renderer) weeklyReport(report models.WeeklyReport) string {
+	lines := []string{
+		r.f.Paint(theme.RoleHeader, reportTitle(report)),
+		fmt.Sprintf("Added: %d  Completed: %d  Deleted: %d", report.TasksAdded, report.TasksCompleted, report.TasksDeleted),
+	}
+	lines = append(lines, trendLines(report.Trend)...)
+	if len(report.Projects) == 0 {
+		return strings.Join(append(lines, "", "No changes this week."), "\n")
+	}
+
+	for _, table := range []struct {
+		name   string
+		groups []models.ReportGroup
+	}{{"Project", report.Projects}, {"Priority", report.Priorities}} {
+		headers := append([]string{table.name}, reportHeaders...)
+		rows := reportRows(table.groups)
+		widths := make([]int, len(headers))
+		for i, h := range headers {
+			widths[i] = displayWidth(h)
+			for _, row := range rows {
+				widths[i] = max(widths[i], displayWidth(row[i]))
+			}
+		}
+
+		cells := make([]string, len(headers))
+		for i, h := range headers {
+			cells[i] = r.f.Paint(theme.RoleHeader, pad(h, widths[i], i > 0))
+		}
+		lines = append(lines, "", strings.TrimRight(strings.Join(cells, columnSeparator), " "))
+		for _, row := range rows {
+			for i, cell := range row {
+				cells[i] = pad(cell, widths[i], i > 0)
+			}
+			lines = append(lines, strings.TrimRight(strings.Join(cells, columnSeparator), " "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// weeklyReportMarkdown renders a report as Markdown tables
+func weeklyReportMarkdown(report models.WeeklyReport) string {
+	lines := []string{
+		"## " + reportTitle(report),
+		"",
+		fmt.Sprintf("- Added: %d", report.TasksAdded),
+		fmt.Sprintf("- Completed: %d", report.TasksCompleted),
+		fmt.Sprintf("- Deleted: %d", report.TasksDeleted),
+	}
+	for _, line := range trendLines(report.Trend) {
+		lines = append(lines, "- "+line)
+	}
+
+	for _, table := range []struct {
+		name   string
+		groups []models.ReportGroup
+	}{{"Project", report.Projects}, {"Priority", report.Priorities}} {
+		if len(table.groups) == 0 {
+			continue
+		}
+		lines = append(lines, "",
+			"| "+table.name+" | "+strings.Join(reportHeaders, " | ")+" |",
+			"| --- | ---: | ---: | ---: |")
+		for _, row := range reportRows(table.groups) {
+			row[0] = strings.ReplaceAll(row[0], "|", `\|`)
+			lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
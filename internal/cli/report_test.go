@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// TestParseISOWeek tests week numbers, including years with 53 weeks and
+// weeks that start in the previous calendar year
+func TestParseISOWeek(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2026-W01", time.Date(2025, 12, 29, 0, 0, 0, 0, time.Local)},
+		{"2026-W3", time.Date(2026, 1, 12, 0, 0, 0, 0, time.Local)},
+		{"2026-w52", time.Date(2026, 12, 21, 0, 0, 0, 0, time.Local)},
+		{"2020-W53", time.Date(2020, 12, 28, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tc := range testCases {
+		got, err := parseISOWeek(tc.value)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("parseISOWeek(%q): expected %v, got %v, %v", tc.value, tc.want, got, err)
+		}
+	}
+
+	for _, value := range []string{"2026-W0", "2025-W53", "2026-03", "W3", "2026-W123"} {
+		if _, err := parseISOWeek(value); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %q, got %v", value, err)
+		}
+	}
+
+	// Sunday belongs to the week that started on the Monday before
+	if got := weekStart(time.Date(2026, 1, 18, 23, 0, 0, 0, time.Local)); !got.Equal(time.Date(2026, 1, 12, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Unexpected week start %v", got)
+	}
+}
+
+// TestReportCommand tests the text, Markdown and JSON output of report
+func TestReportCommand(t *testing.T) {
+	dir := t.TempDir()
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(filepath.Join(dir, "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.SetEventLog(storage.NewFileEventLog(filepath.Join(dir, "todos.json.events")))
+	created := time.Date(2026, 1, 13, 9, 0, 0, 0, time.Local)
+	tl.ImportTasks([]models.Task{
+		{Description: "pay rent +home", Priority: models.PriorityHigh, CreatedAt: created},
+		{Description: "read", CreatedAt: created},
+	})
+
+	run := func(args ...string) string {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		output, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", args, err)
+		}
+		return output
+	}
+
+	// Imports are logged now, so the past week only sees creation dates
+	expected := strings.Join([]string{
+		"Week 2026-W03 (2026-01-12 to 2026-01-18)",
+		"Added: 2  Completed: 0  Deleted: 0",
+		"",
+		"Project  Added  Completed  Deleted",
+		"home         1          0        0",
+		"(none)       1          0        0",
+		"",
+		"Priority  Added  Completed  Deleted",
+		"high          1          0        0",
+		"none          1          0        0",
+	}, "\n")
+	if got := run("report", "--week", "2026-W03"); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	markdown := run("report", "--week", "2026-W3", "--format", "md")
+	for _, want := range []string{"## Week 2026-W03", "- Added: 2", "| Project | Added | Completed | Deleted |", "| home | 1 | 0 | 0 |"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+
+	var report models.WeeklyReport
+	if err := json.Unmarshal([]byte(run("report", "--week", "2026-W03", "--json")), &report); err != nil || report.TasksAdded != 2 {
+		t.Errorf("Expected the report as JSON, got %+v, %v", report, err)
+	}
+
+	if got := run("report", "--week", "2020-W10"); !strings.HasSuffix(got, "No changes this week.") {
+		t.Errorf("Expected an empty week to say so, got:\n%s", got)
+	}
+
+	for _, args := range [][]string{{"report", "x"}, {"report", "--week", "2026-W60"}, {"report", "--format", "html"}, {"report", "--json", "--format", "md"}} {
+		if _, err := ParseCommand(args); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestTrendLines tests the wording of the completion trend
+func TestTrendLines(t *testing.T) {
+	up, down, even := 50.0, -25.0, 0.0
+	testCases := []struct {
+		trend models.CompletionTrend
+		want  []string
+	}{
+		{models.CompletionTrend{}, nil},
+		{models.CompletionTrend{ThisWeek: 3, Average: 2, Change: &up}, []string{"This week: 3 completed, ↑ 50% vs. the 4-week average (2.0)"}},
+		{models.CompletionTrend{ThisWeek: 3, Average: 4, Change: &down}, []string{"This week: 3 completed, ↓ 25% vs. the 4-week average (4.0)"}},
+		{models.CompletionTrend{ThisWeek: 1, Average: 1, Change: &even}, []string{"This week: 1 completed, → 0% vs. the 4-week average (1.0)"}},
+		{models.CompletionTrend{ThisWeek: 2, BacklogGrowing: true}, []string{
+			"This week: 2 completed (none in the 4 weeks before)",
+			"Note: the backlog has grown for 3 weeks in a row",
+		}},
+	}
+	for _, tc := range testCases {
+		if got := trendLines(tc.trend); strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("trendLines(%+v): expected %q, got %q", tc.trend, tc.want, got)
+		}
+	}
+}
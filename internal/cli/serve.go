@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"todolist/internal/api"
+	"todolist/internal/metrics"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// ServeOptions controls the serve command. At least one address is set.
+type ServeOptions struct {
+	// Addr is the host:port the task API listens on
+	Addr string
+	// MetricsAddr is the host:port the metrics server listens on
+	MetricsAddr string
+	// TodoList is the list the task API serves; it is needed only with Addr
+	TodoList *todolist.TodoList
+}
+
+// serveShutdownTimeout bounds how long serve waits for open requests on exit
+const serveShutdownTimeout = 5 * time.Second
+
+// Serve runs the task API at opts.Addr and the Prometheus metrics at
+// opts.MetricsAddr/metrics until SIGINT or SIGTERM. The API works on
+// opts.TodoList; the metrics load the list from st on every scrape.
+func Serve(out io.Writer, st storage.Storage, opts ServeOptions) error {
+	type server struct {
+		ln      net.Listener
+		handler http.Handler
+	}
+	var servers []server
+	defer func() {
+		for _, s := range servers {
+			s.ln.Close()
+		}
+	}()
+	if opts.Addr != "" {
+		ln, err := net.Listen("tcp", opts.Addr)
+		if err != nil {
+			return err
+		}
+		servers = append(servers, server{ln, api.Handler(opts.TodoList)})
+		fmt.Fprintf(out, "Serving the task API on http://%s/tasks\n", ln.Addr())
+	}
+	if opts.MetricsAddr != "" {
+		ln, err := net.Listen("tcp", opts.MetricsAddr)
+		if err != nil {
+			return err
+		}
+		servers = append(servers, server{ln, metrics.Handler(st)})
+		fmt.Fprintf(out, "Serving metrics on http://%s/metrics\n", ln.Addr())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	done := make(chan error, len(servers))
+	for _, s := range servers {
+		go func() {
+			err := serveHTTP(ctx, s.ln, s.handler)
+			// One server failing takes the other down with it
+			stop()
+			done <- err
+		}()
+	}
+	var errs []error
+	for range servers {
+		errs = append(errs, <-done)
+	}
+	return errors.Join(errs...)
+}
+
+// serveHTTP serves handler on ln until ctx is done, then lets open
+// requests finish
+func serveHTTP(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		done <- server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todolist/internal/metrics"
+	"todolist/pkg/storage"
+)
+
+// TestServeMetrics tests that serveHTTP answers scrapes and stops
+// cleanly when its context is cancelled
+func TestServeMetrics(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	st := storage.NewFileStorage(filepath.Join(t.TempDir(), "todo.json"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serveHTTP(ctx, ln, metrics.Handler(st)) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "todolist_tasks_pending 0") {
+		t.Errorf("Unexpected scrape %d:\n%s", resp.StatusCode, body)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected a clean shutdown, got %v", err)
+	}
+}
+
+// TestParseServe tests serve argument validation
+func TestParseServe(t *testing.T) {
+	cmd, err := ParseCommand([]string{"serve", "--metrics-addr", ":9090"})
+	if err != nil || cmd.Flags["metrics-addr"] != ":9090" {
+		t.Fatalf("Expected serve with :9090, got %+v, %v", cmd, err)
+	}
+	cmd, err = ParseCommand([]string{"serve", "--addr", "127.0.0.1:8080"})
+	if err != nil || cmd.Flags["addr"] != "127.0.0.1:8080" {
+		t.Fatalf("Expected serve with 127.0.0.1:8080, got %+v, %v", cmd, err)
+	}
+	for _, args := range [][]string{
+		{"serve"},
+		{"serve", "--metrics-addr", "9090"},
+		{"serve", "--metrics-addr", ":9090", "extra"},
+		{"serve", "--addr", "8080"},
+	} {
+		if _, err := ParseCommand(args); err == nil {
+			t.Errorf("Expected %v to be rejected", args)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"todolist/internal/config"
+	"todolist/internal/env"
+	"todolist/internal/output"
+	"todolist/internal/remind"
+	"todolist/internal/theme"
+	"todolist/internal/webhook"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// ConfigureTodoList sets up tl with the files kept next to the task file at
+// storagePath (undo history, event log and reminder state) and with the
+// limits and webhook cfg asks for. It returns the webhook notifier, or nil
+// when none is configured; callers Wait for it before exiting. Failed
+// deliveries are reported to errOut.
+func ConfigureTodoList(tl *todolist.TodoList, cfg *config.Config, storagePath string, errOut io.Writer) *webhook.Notifier {
+	tl.SetHistory(storage.NewFileHistory(storagePath + ".undo"))
+	tl.SetEventLog(storage.NewFileEventLog(storagePath + ".events"))
+	tl.SetIDReferrer("the reminder state", remind.NewState(storagePath+RemindStateSuffix))
+	tl.SetNoDuplicates(cfg.NoDuplicates)
+	tl.SetMaxDescriptionLength(cfg.MaxDescriptionLength)
+	tl.SetMaxTasks(cfg.MaxTasks)
+	if cfg.Webhook == nil || cfg.Webhook.URL == "" {
+		return nil
+	}
+	notifier := webhook.NewNotifier(cfg.Webhook.URL, cfg.Webhook.Secret, nil)
+	notifier.OnError = func(err error) {
+		fmt.Fprintf(errOut, "Warning: %v\n", err)
+	}
+	tl.SetNotifier(notifier)
+	return notifier
+}
+
+// setting is one row of the config command: a key, the value in effect
+// and where that value came from
+type setting struct {
+	key, value, source string
+}
+
+// effectiveSettings returns every config key with the value cmd runs with.
+// Values come from a flag or environment variable when one was given, then
+// from cfg loaded from path, then from the built-in default.
+func effectiveSettings(cmd *Command, cfg *config.Config, path string) []setting {
+	file := "config file"
+	// fromFile reports value as set in the config file when set is true,
+	// otherwise as the default
+	fromFile := func(key string, set bool, value, fallback string) setting {
+		if set {
+			return setting{key, value, file}
+		}
+		return setting{key, fallback, "default"}
+	}
+
+	storagePath := setting{"storage_path", cmd.StoragePath, "default"}
+	switch {
+	case env.Get(env.TaskFile) != "":
+		storagePath.source = env.TaskFile
+	case cfg.StoragePath != "":
+		storagePath.source = file
+	}
+
+	colour := setting{"colour_enabled", "auto", "default"}
+	switch {
+	case cmd.Color == output.ColorAlways:
+		colour = setting{"colour_enabled", "true", "--color"}
+	case cmd.Color == output.ColorNever:
+		colour = setting{"colour_enabled", "false", "--no-color"}
+	case cfg.ColourEnabled != nil:
+		colour = setting{"colour_enabled", strconv.FormatBool(*cfg.ColourEnabled), file}
+	case env.Get(env.NoColor) != "":
+		colour = setting{"colour_enabled", "false", env.NoColor}
+	}
+
+	themeName := fromFile("theme", cfg.Theme != "", cfg.Theme, theme.DefaultName)
+	if cmd.Theme != "" {
+		themeName = setting{"theme", cmd.Theme, "--theme"}
+	}
+
+	backupDepth := fromFile("backup_depth", false, "", strconv.Itoa(storage.DefaultBackupDepth))
+	if cfg.BackupDepth != nil {
+		backupDepth = setting{"backup_depth", strconv.Itoa(*cfg.BackupDepth), file}
+	}
+
+	jsonIndent := fromFile("json_indent", false, "", strconv.Itoa(len(storage.DefaultIndent)))
+	if cfg.JSONIndent != nil {
+		jsonIndent = setting{"json_indent", strconv.Itoa(*cfg.JSONIndent), file}
+	}
+
+	webhook := &config.WebhookConfig{}
+	if cfg.Webhook != nil {
+		webhook = cfg.Webhook
+	}
+
+	return []setting{
+		storagePath,
+		fromFile("date_format", cfg.DateFormat != "", cfg.DateFormat, config.DefaultDateFormat),
+		colour,
+		fromFile("default_sort", cfg.DefaultSort != "", cfg.DefaultSort, string(todolist.SortByManual)),
+		fromFile("week_start", cfg.WeekStart != "", cfg.WeekStart, "monday"),
+		themeName,
+		backupDepth,
+		jsonIndent,
+		fromFile("list_columns", cfg.ListColumns != "", cfg.ListColumns, "(none)"),
+		fromFile("no_duplicates", cfg.NoDuplicates, "true", "false"),
+		fromFile("confirm_delete", cfg.ConfirmDelete, "true", "false"),
+		fromFile("max_description_length", cfg.MaxDescriptionLength > 0,
+			strconv.Itoa(cfg.MaxDescriptionLength), strconv.Itoa(todolist.DefaultMaxDescriptionLength)),
+		fromFile("max_tasks", cfg.MaxTasks > 0, strconv.Itoa(cfg.MaxTasks), "0 (no limit)"),
+		fromFile("webhook.url", webhook.URL != "", webhook.URL, "(none)"),
+		// The secret itself is never printed
+		fromFile("webhook.secret", webhook.Secret != "", "(set)", "(none)"),
+	}
+}
+
+// formatSettings renders the config command output: the config file, then
+// one aligned row per key
+func formatSettings(path string, settings []setting) string {
+	header := "Config file: " + path
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		header += " (not found, using defaults)"
+	}
+	keyWidth, valueWidth := 0, 0
+	for _, s := range settings {
+		keyWidth = max(keyWidth, len(s.key))
+		valueWidth = max(valueWidth, displayWidth(s.value))
+	}
+	lines := []string{header}
+	for _, s := range settings {
+		lines = append(lines, fmt.Sprintf("  %s  %s  (%s)", pad(s.key, keyWidth, false), pad(s.value, valueWidth, false), s.source))
+	}
+	return strings.Join(lines, "\n")
+}
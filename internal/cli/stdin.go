@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"io"
+	"strings"
+	apperrors "todolist/pkg/errors"
+)
+
+// ReadFromStdin reads all of r and returns its non-blank lines, trimmed,
+// as task descriptions for add -. Input without any such line yields
+// ErrEmptyDescription.
+func ReadFromStdin(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, apperrors.WrapWithContext(err, "failed to read tasks from standard input")
+	}
+	var descriptions []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			descriptions = append(descriptions, line)
+		}
+	}
+	if len(descriptions) == 0 {
+		return nil, apperrors.WrapWithContext(apperrors.ErrEmptyDescription, "standard input has no tasks")
+	}
+	return descriptions, nil
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	apperrors "todolist/pkg/errors"
+)
+
+// failingReader fails every read
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+// TestReadFromStdin tests splitting input into descriptions
+func TestReadFromStdin(t *testing.T) {
+	lines, err := ReadFromStdin(strings.NewReader("\n Buy milk\r\n\n\tCall Alice \nFile taxes"))
+	if err != nil || strings.Join(lines, "|") != "Buy milk|Call Alice|File taxes" {
+		t.Errorf("Unexpected descriptions %q, %v", lines, err)
+	}
+	for _, input := range []string{"", "\n", " \r\n\t\n"} {
+		if _, err := ReadFromStdin(strings.NewReader(input)); !apperrors.IsEmptyDescription(err) {
+			t.Errorf("Expected ErrEmptyDescription for %q, got %v", input, err)
+		}
+	}
+	if _, err := ReadFromStdin(failingReader{}); err == nil || apperrors.IsEmptyDescription(err) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"todolist/pkg/models"
+)
+
+// listTemplates are the built-in list --format templates
+var listTemplates = map[string]string{
+	"short": `{{.ID}} {{.Description}}`,
+	"long":  `{{.ID}}\t{{status .}}\t{{.Priority}}\t{{date .DueDate}}\t{{date .CreatedAt}}\t{{.Description}}`,
+}
+
+// templateEscapes turns the escapes a shell passes through literally into
+// the characters they stand for
+var templateEscapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+// listTemplateHelp documents list --format for help list
+const listTemplateHelp = `Templates (list --format):
+  --format short       {{.ID}} {{.Description}}
+  --format long        ID, status, priority, due date, created date and
+                       description, separated by tabs
+  --format '<template>'
+                       A Go text/template rendered once per task; \t, \n
+                       and \\ stand for a tab, a newline and a backslash
+
+  Fields:    .ID .Description .Completed .CreatedAt .DueDate .Priority
+             .Notes .Recurrence .TimeEntries
+  Functions: date <time>   the time in the configured date format, empty
+                           for a task without a due date
+             status .      done, overdue or pending
+  Example:   todolist list --format '{{.ID}}\t{{status .}}\t{{.Description}}'`
+
+// templateFuncs returns the functions available to list templates, using
+// the renderer's date format and clock
+func (r *renderer) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"date": func(v any) (string, error) {
+			switch t := v.(type) {
+			case time.Time:
+				return t.Format(r.dateFormat), nil
+			case *time.Time:
+				if t == nil {
+					return "", nil
+				}
+				return t.Format(r.dateFormat), nil
+			default:
+				return "", fmt.Errorf("date expects a time, got %T", v)
+			}
+		},
+		"status": func(task models.Task) string {
+			switch {
+			case task.Completed:
+				return "done"
+			case task.IsOverdue(r.now):
+				return "overdue"
+			default:
+				return "pending"
+			}
+		},
+	}
+}
+
+// listTemplate parses a list --format value: the name of a built-in
+// template or a template of its own. The template is also run once on an
+// empty task so unknown fields are reported before any task is loaded.
+func (r *renderer) listTemplate(format string) (*template.Template, error) {
+	text, ok := listTemplates[format]
+	if !ok {
+		if !strings.Contains(format, "{{") {
+			names := make([]string, 0, len(listTemplates))
+			for name := range listTemplates {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown format %q (built-in formats: %s, or a template such as '{{.ID}} {{.Description}}')",
+				format, strings.Join(names, ", "))
+		}
+		text = format
+	}
+
+	tmpl, err := template.New("list").Funcs(r.templateFuncs()).Parse(templateEscapes.Replace(text))
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, models.Task{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// templateList renders each task with tmpl, one per line
+func (r *renderer) templateList(tmpl *template.Template, tasks []models.Task) (string, error) {
+	lines := make([]string, len(tasks))
+	for i, task := range tasks {
+		var line strings.Builder
+		if err := tmpl.Execute(&line, task); err != nil {
+			return "", err
+		}
+		lines[i] = line.String()
+	}
+	return strings.Join(lines, "\n"), nil
+}
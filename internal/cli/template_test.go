@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// TestListTemplates tests built-in and custom list templates over sample tasks
+func TestListTemplates(t *testing.T) {
+	now := time.Date(2026, 1, 14, 10, 30, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	tomorrow := now.AddDate(0, 0, 1)
+	tasks := []models.Task{
+		{ID: 1, Description: "write report", CreatedAt: now, DueDate: &tomorrow, Priority: models.PriorityHigh},
+		{ID: 2, Description: "pay rent", CreatedAt: now, DueDate: &yesterday},
+		{ID: 7, Description: "call mum", CreatedAt: now, Completed: true, Notes: "Sunday"},
+	}
+	r := &renderer{now: now, dateFormat: "2006-01-02"}
+
+	testCases := []struct {
+		name   string
+		format string
+		tasks  []models.Task
+		want   string
+	}{
+		{"short", "short", tasks, "1 write report\n2 pay rent\n7 call mum"},
+		{"long", "long", tasks[:2],
+			"1\tpending\thigh\t2026-01-15\t2026-01-14\twrite report\n2\toverdue\tnone\t2026-01-13\t2026-01-14\tpay rent"},
+		{"escapes", `{{.ID}}\t{{.Description}}\\`, tasks[:1], "1\twrite report\\"},
+		{"status and missing date", `{{status .}}:{{date .DueDate}}`, tasks, "pending:2026-01-15\noverdue:2026-01-13\ndone:"},
+		{"conditionals", `{{.ID}}{{if .Notes}} ({{.Notes}}){{end}}`, tasks, "1\n2\n7 (Sunday)"},
+		{"newline escape", `{{.ID}}\n  {{.Description}}`, tasks[2:], "7\n  call mum"},
+		{"no tasks", "short", nil, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := r.listTemplate(tc.format)
+			if err != nil {
+				t.Fatalf("listTemplate failed: %v", err)
+			}
+			got, err := r.templateList(tmpl, tc.tasks)
+			if err != nil {
+				t.Fatalf("templateList failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestListTemplateErrors tests that bad formats are rejected when parsing
+// the command, before any task is loaded
+func TestListTemplateErrors(t *testing.T) {
+	testCases := []struct {
+		format string
+		want   string
+	}{
+		{"shrot", `unknown format "shrot"`},
+		{"{{.ID", "unclosed action"},
+		{"{{.Title}}", "can't evaluate field Title"},
+		{"{{date .Description}}", "date expects a time"},
+		{"{{nope .}}", `function "nope" not defined`},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseCommand([]string{"list", "--format", tc.format})
+		if !apperrors.IsInvalidCommand(err) || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: expected ErrInvalidCommand mentioning %q, got %v", tc.format, tc.want, err)
+		}
+	}
+
+	if _, err := ParseCommand([]string{"list", "--format=short", "--columns", "id"}); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected --format with --columns to be rejected, got %v", err)
+	}
+}
+
+// TestCommandHelp tests help for a single command
+func TestCommandHelp(t *testing.T) {
+	help, err := commandHelp("ls")
+	if err != nil {
+		t.Fatalf("commandHelp failed: %v", err)
+	}
+	if !strings.Contains(help, "list [--columns") || !strings.Contains(help, "Fields:") || strings.Contains(help, "overdue  ") {
+		t.Errorf("Expected the list entry and template fields only, got:\n%s", help)
+	}
+
+	help, _ = commandHelp("done")
+	if strings.Count(help, "\n  done ") != 2 || strings.Contains(help, "delete") {
+		t.Errorf("Expected both done entries, got:\n%s", help)
+	}
+
+	if _, err := ParseCommand([]string{"help", "nosuch"}); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected ErrInvalidCommand for an unknown command, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"todolist/pkg/storage"
+)
+
+// WriteUpgradeNotice tells the user, once, that the task file was saved
+// in a newer format version and where the old file was kept. Nothing is
+// written when interactive is false or the command's output is meant for
+// programs (--json, --quiet or --format json); the notice then waits for
+// a later run.
+func WriteUpgradeNotice(w io.Writer, cmd *Command, fs *storage.FileStorage, interactive bool) error {
+	if !interactive || cmd.Flags["json"] != "" || cmd.Flags["quiet"] != "" || cmd.Flags["format"] == "json" {
+		return nil
+	}
+	upgrade, err := fs.PendingUpgrade()
+	if err != nil || upgrade == nil {
+		return err
+	}
+	fmt.Fprintf(w, "Note: storage upgraded from v%d to v%d; a pre-migration backup was written to %s; older todolist versions can no longer read this file\n",
+		upgrade.From, upgrade.To, upgrade.Backup)
+	return fs.MarkUpgradeNotified()
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// TestUpgradeNotice tests the migrate, notice and no-repeat sequence, and
+// that the notice waits while output is not for a person
+func TestUpgradeNotice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	os.WriteFile(path, []byte(`{"tasks": [{"id": 1, "description": "old", "created_at": "2026-01-14T10:30:00Z"}], "next_id": 2}`), 0644)
+	fs := storage.NewFileStorage(path)
+	tl, err := todolist.NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	run := func(args ...string) *Command {
+		cmd, err := ParseCommand(args)
+		if err != nil {
+			t.Fatalf("ParseCommand(%v) failed: %v", args, err)
+		}
+		if _, err := ExecuteCommand(cmd, tl); err != nil {
+			t.Fatalf("ExecuteCommand(%v) failed: %v", args, err)
+		}
+		return cmd
+	}
+
+	// Reading alone does not upgrade the file
+	var out bytes.Buffer
+	if err := WriteUpgradeNotice(&out, run("list"), fs, true); err != nil || out.Len() != 0 {
+		t.Fatalf("Expected no notice before a save, got %q, %v", out.String(), err)
+	}
+
+	// The first save upgrades it; machine output and non-interactive
+	// runs leave the notice for later
+	cmd := run("add", "new")
+	if err := WriteUpgradeNotice(&out, cmd, fs, false); err != nil || out.Len() != 0 {
+		t.Fatalf("Expected no notice outside a terminal, got %q, %v", out.String(), err)
+	}
+	if err := WriteUpgradeNotice(&out, run("count", "--json"), fs, true); err != nil || out.Len() != 0 {
+		t.Fatalf("Expected no notice with --json, got %q, %v", out.String(), err)
+	}
+
+	if err := WriteUpgradeNotice(&out, run("list"), fs, true); err != nil {
+		t.Fatalf("WriteUpgradeNotice failed: %v", err)
+	}
+	want := fmt.Sprintf("storage upgraded from v0 to v%d; a pre-migration backup was written to %s", models.FormatVersion, fs.PreMigrationPath(0))
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	out.Reset()
+	if err := WriteUpgradeNotice(&out, run("list"), fs, true); err != nil || out.Len() != 0 {
+		t.Errorf("Expected the notice only once, got %q, %v", out.String(), err)
+	}
+}
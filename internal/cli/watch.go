@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"todolist/internal/theme"
+	"todolist/pkg/todolist"
+)
+
+// DefaultListWatchInterval is how often `list --watch` redraws when the
+// task file does not change, so that due dates and ages stay current
+const DefaultListWatchInterval = time.Minute
+
+// listWatchPoll is how often `list --watch` checks the task file for changes
+const listWatchPoll = time.Second
+
+// WatchList runs a `list --watch` command: it draws the list, then redraws
+// it whenever the task file changes and at least every --interval, until
+// SIGINT or SIGTERM. It only reads the task file, so it never conflicts
+// with saves made by other processes.
+func WatchList(out, errOut io.Writer, cmd *Command, tl *todolist.TodoList) error {
+	interval := DefaultListWatchInterval
+	if value, ok := cmd.Flags["interval"]; ok {
+		interval, _ = time.ParseDuration(value) // Already validated in ParseCommand
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return watchList(ctx, out, errOut, cmd, tl, interval, listWatchPoll)
+}
+
+// watchList draws the list and redraws it until ctx is done. Every poll it
+// reloads the list if the storage reports a change; every interval it
+// reloads regardless, which also covers storages that cannot tell. A
+// failed reload (for example while the file is being replaced) is reported
+// on errOut and retried on the next poll, leaving the last drawing up.
+func watchList(ctx context.Context, out, errOut io.Writer, cmd *Command, tl *todolist.TodoList, interval, poll time.Duration) error {
+	draw := func() {
+		result, err := ExecuteCommand(cmd, tl)
+		if err != nil {
+			result = fmt.Sprintf("Error: %v", err)
+		}
+		fmt.Fprintf(out, "%s%s\n\nLast refresh: %s (Ctrl+C to exit)\n", theme.ClearScreen, result, time.Now().Format("15:04:05"))
+	}
+	reload := func(force bool) bool {
+		if !force {
+			stale, err := tl.Stale()
+			if err != nil {
+				fmt.Fprintf(errOut, "Warning: %v\n", err)
+				return false
+			}
+			if !stale {
+				return false
+			}
+		}
+		if err := tl.Reload(); err != nil {
+			fmt.Fprintf(errOut, "Warning: %v\n", err)
+			return false
+		}
+		return true
+	}
+
+	draw()
+	pollTicker := time.NewTicker(poll)
+	defer pollTicker.Stop()
+	redraw := time.NewTimer(interval)
+	defer redraw.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-pollTicker.C:
+			if !reload(false) {
+				continue
+			}
+		case <-redraw.C:
+			reload(true)
+		}
+		draw()
+		redraw.Reset(interval)
+	}
+}
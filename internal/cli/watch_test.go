@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todolist/internal/theme"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from one goroutine while
+// another reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestWatchList tests that list --watch redraws when another process
+// changes the task file and stops cleanly when cancelled
+func TestWatchList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	tl, err := todolist.NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("first")
+	cmd, err := ParseCommand([]string{"list", "--watch"})
+	if err != nil {
+		t.Fatalf("ParseCommand failed: %v", err)
+	}
+
+	var out, errOut syncBuffer
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- watchList(ctx, &out, &errOut, cmd, tl, time.Hour, 10*time.Millisecond) }()
+
+	waitFor := func(what string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(out.String(), what) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %q, got:\n%s", what, out.String())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor("first")
+	if !strings.HasPrefix(out.String(), theme.ClearScreen) || !strings.Contains(out.String(), "Last refresh: ") {
+		t.Errorf("Expected a cleared screen and a refresh time, got %q", out.String())
+	}
+
+	// Another process adds a task
+	other, err := todolist.NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	if _, err := other.AddTask("from elsewhere"); err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	waitFor("from elsewhere")
+	if got := strings.Count(out.String(), theme.ClearScreen); got != 2 {
+		t.Errorf("Expected 2 drawings, got %d", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected a clean exit, got %v", err)
+	}
+	if errOut.String() != "" {
+		t.Errorf("Expected no warnings, got %q", errOut.String())
+	}
+}
+
+// TestParseListWatch tests list --watch argument validation
+func TestParseListWatch(t *testing.T) {
+	cmd, err := ParseCommand([]string{"list", "--watch", "--interval", "5s", "--group"})
+	if err != nil || cmd.Flags["interval"] != "5s" || cmd.Flags["watch"] == "" {
+		t.Fatalf("Expected list --watch --interval 5s, got %+v, %v", cmd, err)
+	}
+	for _, args := range [][]string{
+		{"list", "--interval", "5s"},
+		{"list", "--watch", "--interval", "500ms"},
+		{"list", "--watch", "--interval", "soon"},
+	} {
+		if _, err := ParseCommand(args); err == nil {
+			t.Errorf("Expected %v to be rejected", args)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todolist/internal/env"
+	apperrors "todolist/pkg/errors"
+)
+
+// Config holds user preferences loaded from the config file
+type Config struct {
+	// StoragePath overrides the task file location (~ is expanded)
+	StoragePath string `json:"storage_path,omitempty"`
+	// DateFormat is a Go time layout used when displaying dates
+	DateFormat string `json:"date_format,omitempty"`
+	// ColourEnabled turns colored output off when false; nil means automatic
+	ColourEnabled *bool `json:"colour_enabled,omitempty"`
+	// DefaultSort is the sort order used by list when none is given
+	DefaultSort string `json:"default_sort,omitempty"`
+	// WeekStart is the weekday weeks begin on in the stats trend, e.g.
+	// "sunday"; empty means Monday
+	WeekStart string `json:"week_start,omitempty"`
+	// Theme selects the output color theme by name
+	Theme string `json:"theme,omitempty"`
+	// Themes defines custom themes, mapping theme name to role to style spec
+	Themes map[string]map[string]string `json:"themes,omitempty"`
+	// BackupDepth is the number of rotated backups kept on save; nil means the default
+	BackupDepth *int `json:"backup_depth,omitempty"`
+	// JSONIndent is the number of spaces per level in the saved task file,
+	// exports and snapshots; 0 saves compact JSON and nil means the default
+	JSONIndent *int `json:"json_indent,omitempty"`
+	// ListColumns is the comma-separated column set list shows as a table
+	// when --columns is not given; empty keeps the one-line-per-task layout
+	ListColumns string `json:"list_columns,omitempty"`
+	// NoDuplicates makes add, and every other way of adding tasks, refuse a
+	// description another task already has
+	NoDuplicates bool `json:"no_duplicates,omitempty"`
+	// MaxDescriptionLength is the longest description add accepts, in
+	// bytes; 0 means the default
+	MaxDescriptionLength int `json:"max_description_length,omitempty"`
+	// MaxTasks caps the number of tasks in the list; 0 means no limit
+	MaxTasks int `json:"max_tasks,omitempty"`
+	// ConfirmDelete makes delete ask before deleting tasks by ID, as it
+	// always does for delete --all
+	ConfirmDelete bool `json:"confirm_delete,omitempty"`
+	// Webhook posts task changes to a URL; nil or an empty URL means none
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+}
+
+// WebhookConfig is where task changes are posted and the secret that signs them
+type WebhookConfig struct {
+	URL    string `json:"url,omitempty"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// DefaultDateFormat is used when the config does not set date_format
+const DefaultDateFormat = "2006-01-02 15:04:05"
+
+// MaxJSONIndent is the largest json_indent accepted
+const MaxJSONIndent = 8
+
+// SortKeys lists the accepted values of default_sort
+var SortKeys = []string{"manual", "created", "id", "description", "status"}
+
+// ListColumns lists the columns accepted by list_columns and list --columns
+var ListColumns = []string{"id", "status", "priority", "due", "created", "completed", "age", "description"}
+
+// ParseColumns splits a comma-separated column list and validates every name
+func ParseColumns(spec string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		valid := false
+		for _, column := range ListColumns {
+			if name == column {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown column %q (valid columns: %s)", name, strings.Join(ListColumns, ", "))
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no columns given")
+	}
+	return names, nil
+}
+
+// setters maps each settable key to a function applying a value
+var setters = map[string]func(c *Config, value string) error{
+	"storage_path": func(c *Config, value string) error {
+		c.StoragePath = value
+		return nil
+	},
+	"date_format": func(c *Config, value string) error {
+		if strings.TrimSpace(value) == "" {
+			return errors.New("date_format cannot be empty")
+		}
+		c.DateFormat = value
+		return nil
+	},
+	"colour_enabled": func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("colour_enabled must be true or false, got %q", value)
+		}
+		c.ColourEnabled = &enabled
+		return nil
+	},
+	"default_sort": func(c *Config, value string) error {
+		for _, key := range SortKeys {
+			if value == key {
+				c.DefaultSort = value
+				return nil
+			}
+		}
+		return fmt.Errorf("default_sort must be one of %s, got %q", strings.Join(SortKeys, ", "), value)
+	},
+	"week_start": func(c *Config, value string) error {
+		if _, err := ParseWeekday(value); err != nil {
+			return err
+		}
+		c.WeekStart = strings.ToLower(value)
+		return nil
+	},
+	"theme": func(c *Config, value string) error {
+		c.Theme = value
+		return nil
+	},
+	"list_columns": func(c *Config, value string) error {
+		columns, err := ParseColumns(value)
+		if err != nil {
+			return err
+		}
+		c.ListColumns = strings.Join(columns, ",")
+		return nil
+	},
+	"no_duplicates": func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("no_duplicates must be true or false, got %q", value)
+		}
+		c.NoDuplicates = enabled
+		return nil
+	},
+	"confirm_delete": func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("confirm_delete must be true or false, got %q", value)
+		}
+		c.ConfirmDelete = enabled
+		return nil
+	},
+	"max_description_length": func(c *Config, value string) error {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return fmt.Errorf("max_description_length must be a positive integer, got %q", value)
+		}
+		c.MaxDescriptionLength = limit
+		return nil
+	},
+	"max_tasks": func(c *Config, value string) error {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("max_tasks must be 0 (no limit) or a positive integer, got %q", value)
+		}
+		c.MaxTasks = limit
+		return nil
+	},
+	"webhook.url": func(c *Config, value string) error {
+		if value != "" {
+			u, err := url.Parse(value)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("webhook.url must be an http or https URL, got %q", value)
+			}
+		}
+		if c.Webhook == nil {
+			c.Webhook = &WebhookConfig{}
+		}
+		c.Webhook.URL = value
+		return nil
+	},
+	"webhook.secret": func(c *Config, value string) error {
+		if c.Webhook == nil {
+			c.Webhook = &WebhookConfig{}
+		}
+		c.Webhook.Secret = value
+		return nil
+	},
+	"backup_depth": func(c *Config, value string) error {
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 0 {
+			return fmt.Errorf("backup_depth must be a non-negative integer, got %q", value)
+		}
+		c.BackupDepth = &depth
+		return nil
+	},
+	"json_indent": func(c *Config, value string) error {
+		spaces, err := strconv.Atoi(value)
+		if err != nil || spaces < 0 || spaces > MaxJSONIndent {
+			return fmt.Errorf("json_indent must be an integer from 0 (compact) to %d, got %q", MaxJSONIndent, value)
+		}
+		c.JSONIndent = &spaces
+		return nil
+	},
+}
+
+// Keys returns the keys accepted by Set in sorted order
+func Keys() []string {
+	keys := make([]string, 0, len(setters))
+	for key := range setters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set validates and assigns a single config value by key
+func (c *Config) Set(key, value string) error {
+	setter, ok := setters[key]
+	if !ok {
+		return fmt.Errorf("unknown config key %q (valid keys: %s)", key, strings.Join(Keys(), ", "))
+	}
+	return setter(c, value)
+}
+
+// ParseWeekday converts a weekday name, or its first three letters, to a
+// weekday; "" is Monday, the default week_start
+func ParseWeekday(name string) (time.Weekday, error) {
+	if name == "" {
+		return time.Monday, nil
+	}
+	lower := strings.ToLower(name)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if lower == full || lower == full[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("week_start must be a weekday such as monday or sun, got %q", name)
+}
+
+// DateLayout returns the configured date format or the default one
+func (c *Config) DateLayout() string {
+	if c.DateFormat == "" {
+		return DefaultDateFormat
+	}
+	return c.DateFormat
+}
+
+// ResolveStoragePath returns StoragePath with a leading ~ expanded, or
+// fallback when no storage path is configured
+func (c *Config) ResolveStoragePath(fallback string) (string, error) {
+	if c.StoragePath == "" {
+		return fallback, nil
+	}
+	if c.StoragePath == "~" || strings.HasPrefix(c.StoragePath, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(homeDir, strings.TrimPrefix(c.StoragePath, "~")), nil
+	}
+	return c.StoragePath, nil
+}
+
+// DefaultPath returns the config file location, honouring XDG_CONFIG_HOME
+// and falling back to ~/.config/todolist/config.json
+func DefaultPath() (string, error) {
+	if dir := env.Get(env.XDGConfigHome); dir != "" {
+		return filepath.Join(dir, "todolist", "config.json"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "todolist", "config.json"), nil
+}
+
+// LegacyStorageName is the task file in the home directory used before the
+// default moved to the data directory
+const LegacyStorageName = ".todolist.json"
+
+// DefaultStoragePath returns the task file used when none is configured.
+// An existing ~/.todolist.json is kept so that nobody loses their list;
+// otherwise it is todolist/todolist.json in the data directory. The
+// directory is created by the first save.
+func DefaultStoragePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(homeDir, LegacyStorageName)
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+
+	dir, err := dataDir(homeDir, runtime.GOOS)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "todolist", "todolist.json"), nil
+}
+
+// LegacyArchiveName is the archive file in the home directory used before
+// the default task file moved to the data directory
+const LegacyArchiveName = ".todolist-archive.json"
+
+// DefaultArchivePath returns the archive file for the task file at
+// storagePath: todolist-archive.json next to it, or ~/.todolist-archive.json
+// when that file exists or the task file is itself in the home directory.
+func DefaultArchivePath(storagePath string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(homeDir, LegacyArchiveName)
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+	dir := filepath.Dir(storagePath)
+	if dir == filepath.Clean(homeDir) {
+		return legacy, nil
+	}
+	return filepath.Join(dir, "todolist-archive.json"), nil
+}
+
+// dataDir returns the base directory for application data: XDG_DATA_HOME
+// when set, the user's application data directory on macOS and Windows,
+// and ~/.local/share elsewhere
+func dataDir(homeDir, goos string) (string, error) {
+	if dir := env.Get(env.XDGDataHome); dir != "" {
+		return dir, nil
+	}
+	switch goos {
+	case "darwin", "windows":
+		return os.UserConfigDir()
+	default:
+		return filepath.Join(homeDir, ".local", "share"), nil
+	}
+}
+
+// LoadConfig reads the config file at path. A missing file yields an empty config.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), path)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, locate(data, err)), path)
+	}
+
+	return &cfg, nil
+}
+
+// locate prefixes a JSON decoding error with the line and column it was
+// found at, so that a hand-edited config file can be fixed
+func locate(data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+	// The offset counts the byte the decoder stopped at
+	before := data[:max(min(int(offset)-1, len(data)), 0)]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Errorf("line %d, column %d: %w", line, column, err)
+}
+
+// SaveConfig writes the config file, creating its directory if needed. A
+// file holding a webhook secret is readable by its owner only.
+func SaveConfig(path string, c *Config) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+
+	perm := os.FileMode(0644)
+	if c.Webhook != nil && c.Webhook.Secret != "" {
+		perm = 0600
+	}
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, perm); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+
+	return nil
+}
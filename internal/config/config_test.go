@@ -0,0 +1,221 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	apperrors "todolist/pkg/errors"
+)
+
+// TestLoadConfigMissingFile tests that a missing config file yields defaults
+func TestLoadConfigMissingFile(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Expected no error for missing config, got: %v", err)
+	}
+	if cfg.DateLayout() != DefaultDateFormat {
+		t.Errorf("Expected default date format, got %q", cfg.DateLayout())
+	}
+}
+
+// TestLoadConfigInvalidJSON tests that a malformed config file is reported
+func TestLoadConfigInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"theme": `), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	if _, err := LoadConfig(path); !errors.Is(err, apperrors.ErrInvalidJSON) {
+		t.Errorf("Expected ErrInvalidJSON, got: %v", err)
+	}
+
+	// The error points at the offending line
+	testCases := map[string]string{
+		"{\n  \"theme\": \"mono\",\n  \"date_format\" \"02 Jan\"\n}": "line 3, column 17",
+		"{\n  \"no_duplicates\": \"yes\"\n}":                         "line 2, column 24",
+	}
+	for content, want := range testCases {
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := LoadConfig(path); !errors.Is(err, apperrors.ErrInvalidJSON) || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected ErrInvalidJSON at %s for %q, got: %v", want, content, err)
+		}
+	}
+}
+
+// TestSaveAndLoadConfigRoundTrip tests that Set values survive a save and load
+func TestSaveAndLoadConfigRoundTrip(t *testing.T) {
+	// Parent directories are created on save
+	path := filepath.Join(t.TempDir(), "todolist", "config.json")
+
+	cfg := &Config{}
+	settings := map[string]string{
+		"storage_path":           "/tmp/tasks.json",
+		"date_format":            "02 Jan 2006",
+		"colour_enabled":         "false",
+		"default_sort":           "description",
+		"backup_depth":           "5",
+		"json_indent":            "0",
+		"week_start":             "Sun",
+		"list_columns":           "ID, due ,description",
+		"no_duplicates":          "true",
+		"max_description_length": "200",
+		"max_tasks":              "50",
+		"confirm_delete":         "true",
+	}
+	for key, value := range settings {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatalf("Set(%s, %s) failed: %v", key, value, err)
+		}
+	}
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if loaded.StoragePath != "/tmp/tasks.json" || loaded.DateLayout() != "02 Jan 2006" ||
+		loaded.DefaultSort != "description" || loaded.ColourEnabled == nil || *loaded.ColourEnabled ||
+		loaded.BackupDepth == nil || *loaded.BackupDepth != 5 || loaded.JSONIndent == nil || *loaded.JSONIndent != 0 ||
+		loaded.WeekStart != "sun" || loaded.ListColumns != "id,due,description" ||
+		!loaded.NoDuplicates || loaded.MaxDescriptionLength != 200 || loaded.MaxTasks != 50 || !loaded.ConfirmDelete {
+		t.Errorf("Unexpected loaded config: %+v", loaded)
+	}
+}
+
+// TestWebhookConfig tests the webhook keys and that a config holding the
+// secret is saved readable by its owner only
+func TestWebhookConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &Config{}
+	if err := cfg.Set("webhook.url", "https://example.com/hook"); err != nil {
+		t.Fatalf("Set webhook.url failed: %v", err)
+	}
+	if err := cfg.Set("webhook.secret", "s3cret"); err != nil {
+		t.Fatalf("Set webhook.secret failed: %v", err)
+	}
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v, %v", info.Mode(), err)
+	}
+	loaded, err := LoadConfig(path)
+	if err != nil || loaded.Webhook == nil || loaded.Webhook.URL != "https://example.com/hook" || loaded.Webhook.Secret != "s3cret" {
+		t.Errorf("Unexpected loaded webhook: %+v, %v", loaded, err)
+	}
+
+	for _, value := range []string{"example.com/hook", "ftp://example.com", "https://"} {
+		if err := cfg.Set("webhook.url", value); err == nil {
+			t.Errorf("Expected webhook.url %q to be rejected", value)
+		}
+	}
+}
+
+// TestSetRejectsInvalidValues tests validation of config keys and values
+func TestSetRejectsInvalidValues(t *testing.T) {
+	testCases := []struct {
+		key   string
+		value string
+	}{
+		{"unknown_key", "x"},
+		{"colour_enabled", "maybe"},
+		{"no_duplicates", "yes please"},
+		{"max_description_length", "0"},
+		{"max_tasks", "-1"},
+		{"max_tasks", "lots"},
+		{"default_sort", "priority-ish"},
+		{"backup_depth", "-1"},
+		{"json_indent", "-1"},
+		{"json_indent", "tab"},
+		{"json_indent", "9"},
+		{"week_start", "someday"},
+		{"date_format", "  "},
+		{"list_columns", "id,tags"},
+		{"list_columns", " , "},
+	}
+
+	for _, tc := range testCases {
+		cfg := &Config{}
+		if err := cfg.Set(tc.key, tc.value); err == nil {
+			t.Errorf("Expected Set(%s, %q) to fail", tc.key, tc.value)
+		}
+	}
+}
+
+// TestResolveStoragePath tests tilde expansion and the fallback path
+func TestResolveStoragePath(t *testing.T) {
+	cfg := &Config{}
+	if path, _ := cfg.ResolveStoragePath("/fallback.json"); path != "/fallback.json" {
+		t.Errorf("Expected fallback path, got %q", path)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("No home directory available")
+	}
+	cfg.StoragePath = "~/tasks/todo.json"
+	path, err := cfg.ResolveStoragePath("/fallback.json")
+	if err != nil {
+		t.Fatalf("ResolveStoragePath failed: %v", err)
+	}
+	if path != filepath.Join(homeDir, "tasks", "todo.json") {
+		t.Errorf("Expected expanded path, got %q", path)
+	}
+}
+
+// TestDefaultStoragePath tests the data directory default and that an
+// existing ~/.todolist.json keeps being used
+func TestDefaultStoragePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+
+	if dir, _ := dataDir(home, "linux"); dir != filepath.Join(home, ".local", "share") {
+		t.Errorf("Expected ~/.local/share, got %q", dir)
+	}
+	configDir, _ := os.UserConfigDir()
+	if dir, _ := dataDir(home, "darwin"); dir != configDir {
+		t.Errorf("Expected the user config directory %q on macOS, got %q", configDir, dir)
+	}
+
+	data := filepath.Join(t.TempDir(), "data")
+	t.Setenv("XDG_DATA_HOME", data)
+	path, err := DefaultStoragePath()
+	if err != nil {
+		t.Fatalf("DefaultStoragePath failed: %v", err)
+	}
+	if want := filepath.Join(data, "todolist", "todolist.json"); path != want {
+		t.Errorf("Expected %q, got %q", want, path)
+	}
+
+	legacy := filepath.Join(home, LegacyStorageName)
+	os.WriteFile(legacy, []byte(`{"tasks": [], "next_id": 1}`), 0644)
+	if path, err := DefaultStoragePath(); err != nil || path != legacy {
+		t.Errorf("Expected the legacy file %q to be kept, got %q, %v", legacy, path, err)
+	}
+}
+
+// TestDefaultArchivePath tests that the archive sits next to the task file
+// unless the legacy archive in the home directory is in use
+func TestDefaultArchivePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	data := filepath.Join(t.TempDir(), "todolist")
+	if path, err := DefaultArchivePath(filepath.Join(data, "todolist.json")); err != nil || path != filepath.Join(data, "todolist-archive.json") {
+		t.Errorf("Expected the archive next to the task file, got %q, %v", path, err)
+	}
+	legacy := filepath.Join(home, LegacyArchiveName)
+	if path, err := DefaultArchivePath(filepath.Join(home, LegacyStorageName)); err != nil || path != legacy {
+		t.Errorf("Expected %q for a task file in the home directory, got %q, %v", legacy, path, err)
+	}
+
+	os.WriteFile(legacy, []byte(`{"tasks": [], "next_id": 1}`), 0644)
+	if path, err := DefaultArchivePath(filepath.Join(data, "todolist.json")); err != nil || path != legacy {
+		t.Errorf("Expected the legacy archive %q to be kept, got %q, %v", legacy, path, err)
+	}
+}
@@ -0,0 +1,94 @@
+// Package demo generates the sample task list used by `todolist demo`.
+package demo
+
+import (
+	"math/rand"
+	"time"
+	"todolist/pkg/models"
+)
+
+// DefaultSeed is used when no --seed is given, so screenshots are reproducible
+const DefaultSeed = 1
+
+// descriptions is the pool of sample task descriptions
+var descriptions = []string{
+	"Renew passport",
+	"Book dentist appointment",
+	"Write quarterly report",
+	"Review pull requests",
+	"Plan team offsite",
+	"Buy birthday present for Sam",
+	"Fix leaking kitchen tap",
+	"学习 Go 语言",
+	"Call the insurance company",
+	"Back up laptop",
+	"Prepare slides for Monday demo",
+	"Water the plants",
+	"Read \"The Pragmatic Programmer\"",
+	"Cancel unused subscriptions",
+	"Update CV",
+}
+
+// profile is the shape of one sample task; every feature shown by list
+// appears in at least one profile
+type profile struct {
+	completed bool
+	priority  models.Priority
+	// due is the offset of the due date from now; nil means no due date
+	due *time.Duration
+}
+
+// in returns a pointer to the duration d, for profile literals
+func in(d time.Duration) *time.Duration {
+	return &d
+}
+
+var profiles = []profile{
+	{priority: models.PriorityHigh, due: in(-48 * time.Hour)}, // overdue
+	{priority: models.PriorityHigh, due: in(6 * time.Hour)},   // due soon
+	{priority: models.PriorityMedium, due: in(72 * time.Hour)},
+	{priority: models.PriorityLow},
+	{},
+	{completed: true, priority: models.PriorityHigh},
+	{completed: true, due: in(-24 * time.Hour)}, // done before its due date passed
+	{due: in(-3 * time.Hour)},                   // overdue without priority
+	{priority: models.PriorityMedium},
+	{completed: true},
+	{priority: models.PriorityLow, due: in(14 * 24 * time.Hour)},
+	{},
+	{completed: true, priority: models.PriorityLow},
+	{due: in(30 * time.Hour)},
+	{priority: models.PriorityMedium, due: in(20 * time.Hour)}, // due soon
+}
+
+// Tasks returns the sample task list. The same seed and now always give
+// the same list; the seed shuffles descriptions and creation times while
+// the mix of statuses, priorities and due dates stays fixed.
+func Tasks(seed int64, now time.Time) *models.TaskList {
+	rng := rand.New(rand.NewSource(seed))
+	order := rng.Perm(len(descriptions))
+
+	tasks := make([]models.Task, len(profiles))
+	created := now.Add(-21 * 24 * time.Hour)
+	for i, p := range profiles {
+		// Creation times move forward so IDs follow creation order
+		created = created.Add(time.Duration(1+rng.Intn(30)) * time.Hour)
+		task := models.Task{
+			ID:          i + 1,
+			Description: descriptions[order[i%len(order)]],
+			Completed:   p.completed,
+			CreatedAt:   created,
+			Priority:    p.priority,
+		}
+		if p.due != nil {
+			due := now.Add(*p.due)
+			task.DueDate = &due
+		}
+		tasks[i] = task
+	}
+
+	return &models.TaskList{
+		Tasks:  tasks,
+		NextID: len(tasks) + 1,
+	}
+}
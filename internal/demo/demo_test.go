@@ -0,0 +1,65 @@
+package demo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todolist/pkg/models"
+)
+
+// TestTasksDeterministic tests that a seed always produces the same list
+func TestTasksDeterministic(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	if !reflect.DeepEqual(Tasks(42, now), Tasks(42, now)) {
+		t.Error("Expected the same seed to produce the same tasks")
+	}
+	if reflect.DeepEqual(Tasks(1, now), Tasks(2, now)) {
+		t.Error("Expected different seeds to produce different tasks")
+	}
+}
+
+// TestTasksCoverFeatures tests that every feature shown by list has a sample task
+func TestTasksCoverFeatures(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, seed := range []int64{DefaultSeed, 7, 1234} {
+		list := Tasks(seed, now)
+		if len(list.Tasks) < 15 || list.NextID != len(list.Tasks)+1 {
+			t.Fatalf("Seed %d: expected at least 15 tasks and NextID after them, got %d and %d", seed, len(list.Tasks), list.NextID)
+		}
+
+		features := map[string]bool{}
+		seen := map[string]bool{}
+		for i, task := range list.Tasks {
+			if task.ID != i+1 || task.Description == "" || task.CreatedAt.After(now) {
+				t.Errorf("Seed %d: invalid task %+v", seed, task)
+			}
+			if i > 0 && !task.CreatedAt.After(list.Tasks[i-1].CreatedAt) {
+				t.Errorf("Seed %d: expected creation times to follow IDs", seed)
+			}
+			if seen[task.Description] {
+				t.Errorf("Seed %d: duplicate description %q", seed, task.Description)
+			}
+			seen[task.Description] = true
+
+			features["completed"] = features["completed"] || task.Completed
+			features["pending"] = features["pending"] || !task.Completed
+			features["overdue"] = features["overdue"] || task.IsOverdue(now)
+			features["due soon"] = features["due soon"] || (!task.Completed && task.DueDate != nil && task.DueDate.After(now) && task.DueDate.Before(now.Add(24*time.Hour)))
+			features["no due date"] = features["no due date"] || task.DueDate == nil
+			features["priority "+task.Priority.String()] = true
+		}
+
+		for _, feature := range []string{"completed", "pending", "overdue", "due soon", "no due date"} {
+			if !features[feature] {
+				t.Errorf("Seed %d: no sample task is %s", seed, feature)
+			}
+		}
+		for _, p := range []models.Priority{models.PriorityNone, models.PriorityLow, models.PriorityMedium, models.PriorityHigh} {
+			if !features["priority "+p.String()] {
+				t.Errorf("Seed %d: no sample task has priority %s", seed, p)
+			}
+		}
+	}
+}
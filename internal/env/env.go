@@ -0,0 +1,93 @@
+// Package env is the registry of every environment variable todolist
+// reads. All lookups go through Get or Lookup so that `todolist env` can
+// list them; reading an unregistered variable panics.
+package env
+
+import (
+	"fmt"
+	"os"
+)
+
+// Recognised environment variables
+const (
+	TaskFile             = "TODOLIST_FILE"
+	RemoteToken          = "TODOLIST_REMOTE_TOKEN"
+	SuppressDeprecations = "TODOLIST_SUPPRESS_DEPRECATIONS"
+	NoColor              = "NO_COLOR"
+	XDGConfigHome        = "XDG_CONFIG_HOME"
+	XDGDataHome          = "XDG_DATA_HOME"
+	Editor               = "EDITOR"
+	Shell                = "SHELL"
+	ComSpec              = "COMSPEC"
+)
+
+// Var describes one environment variable todolist consults
+type Var struct {
+	Name string
+	// Effect says what the variable changes
+	Effect string
+	// Secret hides the value in `todolist env`
+	Secret bool
+}
+
+// Registry lists every recognised variable in the order `todolist env` shows them
+var Registry = []Var{
+	{Name: TaskFile, Effect: "Task file to use instead of the configured one; demo sets it"},
+	{Name: RemoteToken, Effect: "Bearer token sent to the --remote server", Secret: true},
+	{Name: SuppressDeprecations, Effect: "Any value silences deprecation warnings"},
+	{Name: NoColor, Effect: "Any value turns colour off unless --color is given"},
+	{Name: XDGConfigHome, Effect: "Directory holding todolist/config.json; ~/.config when unset"},
+	{Name: XDGDataHome, Effect: "Directory holding todolist/todolist.json; ~/.local/share when unset"},
+	{Name: Editor, Effect: "Editor opened by add -i; vi when unset"},
+	{Name: Shell, Effect: "Shell started by demo; /bin/sh when unset"},
+	{Name: ComSpec, Effect: "Shell started by demo on Windows when SHELL is unset"},
+}
+
+// Status is the current state of a variable as reported by `todolist env`
+type Status struct {
+	Name   string `json:"name"`
+	Set    bool   `json:"set"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// secretMask replaces the value of secret variables
+const secretMask = "********"
+
+// Lookup returns the value of a registered variable and whether it is set
+func Lookup(name string) (string, bool) {
+	if _, ok := find(name); !ok {
+		panic(fmt.Sprintf("env: %s is not in the registry", name))
+	}
+	return os.LookupEnv(name)
+}
+
+// Get returns the value of a registered variable, empty if unset
+func Get(name string) string {
+	value, _ := Lookup(name)
+	return value
+}
+
+// Statuses reports every registered variable with its current value,
+// masking secrets
+func Statuses() []Status {
+	statuses := make([]Status, len(Registry))
+	for i, v := range Registry {
+		value, set := Lookup(v.Name)
+		if set && v.Secret {
+			value = secretMask
+		}
+		statuses[i] = Status{Name: v.Name, Set: set, Value: value, Effect: v.Effect}
+	}
+	return statuses
+}
+
+// find returns the registry entry for name
+func find(name string) (Var, bool) {
+	for _, v := range Registry {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Var{}, false
+}
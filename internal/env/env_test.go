@@ -0,0 +1,102 @@
+package env
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStatuses tests set, unset and secret variables
+func TestStatuses(t *testing.T) {
+	saved := Registry
+	t.Cleanup(func() { Registry = saved })
+	Registry = append(append([]Var{}, saved...), Var{Name: "TODOLIST_TEST_SECRET", Effect: "test", Secret: true})
+
+	t.Setenv(Editor, "nano")
+	t.Setenv("TODOLIST_TEST_SECRET", "hunter2")
+	unsetForTest(t, Shell)
+
+	byName := map[string]Status{}
+	for _, s := range Statuses() {
+		byName[s.Name] = s
+	}
+	if s := byName[Editor]; !s.Set || s.Value != "nano" || s.Effect == "" {
+		t.Errorf("Expected EDITOR set to nano with an effect, got %+v", s)
+	}
+	if s := byName[Shell]; s.Set || s.Value != "" {
+		t.Errorf("Expected SHELL unset, got %+v", s)
+	}
+	if s := byName["TODOLIST_TEST_SECRET"]; !s.Set || s.Value != secretMask {
+		t.Errorf("Expected the secret to be masked, got %+v", s)
+	}
+}
+
+// TestLookupUnregisteredPanics tests that every variable must be registered
+func TestLookupUnregisteredPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Get of an unregistered variable to panic")
+		}
+	}()
+	Get("TODOLIST_NOT_REGISTERED")
+}
+
+// TestNoDirectEnvLookups tests that no Go source outside this package reads
+// the environment with os.Getenv or os.LookupEnv, so the registry stays complete
+func TestNoDirectEnvLookups(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatalf("Failed to resolve module root: %v", err)
+	}
+	envDir, _ := filepath.Abs(".")
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == envDir || (path != root && strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Tests set up the environment they run in
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "os" && (sel.Sel.Name == "Getenv" || sel.Sel.Name == "LookupEnv") {
+				t.Errorf("%s: read %s through the env registry instead", fset.Position(sel.Pos()), "os."+sel.Sel.Name)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to scan sources: %v", err)
+	}
+}
+
+// unsetForTest unsets name for the duration of the test
+func unsetForTest(t *testing.T, name string) {
+	// Setenv restores the previous value when the test ends
+	t.Setenv(name, "")
+	if err := os.Unsetenv(name); err != nil {
+		t.Fatalf("Failed to unset %s: %v", name, err)
+	}
+}
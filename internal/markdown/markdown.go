@@ -0,0 +1,118 @@
+// Package markdown reads and writes task lists as Markdown checklists, the
+// "- [ ] task" lines GitHub renders as checkboxes.
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"todolist/pkg/models"
+)
+
+// checkboxPattern matches a checklist item: a bullet, a checkbox and the
+// text after it. Leading indentation is allowed so nested items flatten.
+var checkboxPattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+
+// completedPattern matches the completion date FormatTask appends to a
+// checked item
+var completedPattern = regexp.MustCompile(` \(completed (\d{4}-\d{2}-\d{2})\)$`)
+
+// dateLayout is the format of completion dates
+const dateLayout = "2006-01-02"
+
+// escaper backslash-escapes the characters Markdown would otherwise read
+// as formatting or links
+var escaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`,
+	`[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`,
+)
+
+// Write writes tasks as a Markdown checklist, one item per task
+func Write(w io.Writer, tasks []models.Task) error {
+	for _, task := range tasks {
+		if _, err := fmt.Fprintln(w, FormatTask(task)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatTask renders a single task as a checklist item. Only the
+// description, completion state and completion date are kept; the date
+// follows the description as "(completed YYYY-MM-DD)".
+func FormatTask(task models.Task) string {
+	box := "[ ]"
+	if task.Completed {
+		box = "[x]"
+	}
+	item := "- " + box + " " + escaper.Replace(strings.Join(strings.Fields(task.Description), " "))
+	if task.Completed && task.CompletedAt != nil {
+		item += " (completed " + task.CompletedAt.Format(dateLayout) + ")"
+	}
+	return item
+}
+
+// Parse reads the checklist items in a Markdown document as tasks created
+// at now, taking the completion date of checked items written by FormatTask. Everything else, including items inside fenced code blocks and
+// checkboxes with no text, is ignored.
+func Parse(r io.Reader, now time.Time) ([]models.Task, error) {
+	tasks := []models.Task{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	fence := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		m := checkboxPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		task := models.Task{Completed: m[1] != " ", CreatedAt: now}
+		text := strings.TrimSpace(m[2])
+		if d := completedPattern.FindStringSubmatch(text); d != nil && task.Completed {
+			if completed, err := time.ParseInLocation(dateLayout, d[1], time.Local); err == nil {
+				task.CompletedAt = &completed
+				text = text[:len(text)-len(d[0])]
+			}
+		}
+		task.Description = strings.TrimSpace(unescape(text))
+		if task.Description == "" {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// unescape drops the backslash from escaped ASCII punctuation, as Markdown
+// renderers do; other backslashes are kept
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"todolist/pkg/models"
+)
+
+// TestParseChecklist tests that only checklist items become tasks, nested
+// items are flattened and completion follows the checkbox
+func TestParseChecklist(t *testing.T) {
+	input := strings.Join([]string{
+		"# Release 1.2",
+		"",
+		"Some notes about the release.",
+		"- [ ] Write changelog",
+		"- [x] Tag the release",
+		"  - [X] Nested and done",
+		"    * [ ] Deeply nested with star",
+		"+ [ ] Plus bullet",
+		"- plain bullet",
+		"- [ ]",
+		"-[ ] no space after bullet",
+		"1. [ ] ordered items are not checklists",
+		"```",
+		"- [ ] example inside a code block",
+		"```",
+		"- [ ] C:\\path stays, \\* unescapes",
+	}, "\n")
+
+	tasks, err := Parse(strings.NewReader(input), time.Now())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []struct {
+		description string
+		completed   bool
+	}{
+		{"Write changelog", false},
+		{"Tag the release", true},
+		{"Nested and done", true},
+		{"Deeply nested with star", false},
+		{"Plus bullet", false},
+		{`C:\path stays, * unescapes`, false},
+	}
+	if len(tasks) != len(want) {
+		t.Fatalf("Expected %d tasks, got %d: %+v", len(want), len(tasks), tasks)
+	}
+	for i, w := range want {
+		if tasks[i].Description != w.description || tasks[i].Completed != w.completed {
+			t.Errorf("Task %d: expected %q completed=%v, got %q completed=%v",
+				i, w.description, w.completed, tasks[i].Description, tasks[i].Completed)
+		}
+	}
+}
+
+// TestExportImportRoundTrip tests that descriptions with Markdown
+// characters, completion state and completion dates survive a round trip
+func TestExportImportRoundTrip(t *testing.T) {
+	finished := time.Date(2026, 1, 16, 18, 0, 0, 0, time.Local)
+	original := []models.Task{
+		{ID: 1, Description: "Plain task"},
+		{ID: 2, Description: "Finished task", Completed: true},
+		{ID: 3, Description: "[ ] looks like a checkbox"},
+		{ID: 4, Description: "link [text](http://example.com) and ]["},
+		{ID: 5, Description: "*bold* and **more** and a lone *"},
+		{ID: 6, Description: `back\slash, \* and snake_case`},
+		{ID: 7, Description: "- [x] nested marker", Completed: true},
+		{ID: 8, Description: "Unicode 学习 Go 语言 <tag> `code`"},
+		{ID: 9, Description: "Done (on a day)", Completed: true, CompletedAt: &finished},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, original); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "- [ ] Plain task\n- [x] Finished task\n") ||
+		!strings.HasSuffix(buf.String(), "- [x] Done (on a day) (completed 2026-01-16)\n") {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+
+	tasks, err := Parse(&buf, time.Now())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(tasks) != len(original) {
+		t.Fatalf("Expected %d tasks, got %d", len(original), len(tasks))
+	}
+	for i, task := range tasks {
+		orig := original[i]
+		if task.Description != orig.Description {
+			t.Errorf("Task %d: expected description %q, got %q", orig.ID, orig.Description, task.Description)
+		}
+		if task.Completed != orig.Completed {
+			t.Errorf("Task %d: expected completed %v, got %v", orig.ID, orig.Completed, task.Completed)
+		}
+		if (task.CompletedAt == nil) != (orig.CompletedAt == nil) ||
+			(task.CompletedAt != nil && task.CompletedAt.Format(dateLayout) != orig.CompletedAt.Format(dateLayout)) {
+			t.Errorf("Task %d: expected completed at %v, got %v", orig.ID, orig.CompletedAt, task.CompletedAt)
+		}
+	}
+}
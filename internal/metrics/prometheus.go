@@ -0,0 +1,70 @@
+// Package metrics exposes task counts in the Prometheus text format for
+// todolist serve.
+package metrics
+
+import (
+	"net/http"
+	"todolist/pkg/storage"
+	"todolist/pkg/todolist"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Collector reports task counts as gauges. It has no state of its own:
+// every scrape loads the list from storage, so the numbers follow the file
+// without caching.
+type Collector struct {
+	storage   storage.Storage
+	total     *prometheus.Desc
+	pending   *prometheus.Desc
+	completed *prometheus.Desc
+	overdue   *prometheus.Desc
+}
+
+// NewCollector creates a Collector reading tasks from st
+func NewCollector(st storage.Storage) *Collector {
+	return &Collector{
+		storage:   st,
+		total:     prometheus.NewDesc("todolist_tasks_total", "Number of tasks in the list.", nil, nil),
+		pending:   prometheus.NewDesc("todolist_tasks_pending", "Number of tasks not yet completed.", nil, nil),
+		completed: prometheus.NewDesc("todolist_tasks_completed", "Number of completed tasks.", nil, nil),
+		overdue:   prometheus.NewDesc("todolist_tasks_overdue", "Number of pending tasks past their due date.", nil, nil),
+	}
+}
+
+// Describe sends the descriptions of every gauge
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.total
+	ch <- c.pending
+	ch <- c.completed
+	ch <- c.overdue
+}
+
+// Collect loads the task list and sends the current counts. A list that
+// cannot be loaded fails the scrape rather than reporting stale numbers.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	tl, err := todolist.NewTodoList(c.storage)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.total, err)
+		return
+	}
+	stats := tl.Stats()
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stats.Total))
+	ch <- prometheus.MustNewConstMetric(c.pending, prometheus.GaugeValue, float64(stats.Pending))
+	ch <- prometheus.MustNewConstMetric(c.completed, prometheus.GaugeValue, float64(stats.Completed))
+	ch <- prometheus.MustNewConstMetric(c.overdue, prometheus.GaugeValue, float64(stats.OverdueCount))
+}
+
+// Handler serves the task gauges from st at /metrics. Only they are
+// registered, not the Go runtime metrics, so the output stays about tasks.
+func Handler(st storage.Storage) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewCollector(st))
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.HTTPErrorOnError,
+	}))
+	return mux
+}
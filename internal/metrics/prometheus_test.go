@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+)
+
+// scrape fetches /metrics from h and returns the status and body
+func scrape(t *testing.T, h http.Handler) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Result().Body)
+	return rec.Code, string(body)
+}
+
+// TestHandler tests that every scrape reports the counts in the file as it
+// is at that moment
+func TestHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.json")
+	st := storage.NewFileStorage(path)
+	h := Handler(st)
+
+	code, body := scrape(t, h)
+	if code != http.StatusOK || !strings.Contains(body, "todolist_tasks_total 0\n") {
+		t.Fatalf("Expected zero tasks for a missing file, got %d:\n%s", code, body)
+	}
+
+	past := time.Now().Add(-48 * time.Hour)
+	list := &models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, Description: "pending", CreatedAt: past},
+			{ID: 2, Description: "overdue", CreatedAt: past, DueDate: &past},
+			{ID: 3, Description: "done", CreatedAt: past, Completed: true},
+		},
+		NextID: 4,
+	}
+	if err := st.Save(list); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	code, body = scrape(t, h)
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d:\n%s", code, body)
+	}
+	for _, want := range []string{
+		"# TYPE todolist_tasks_total gauge",
+		"todolist_tasks_total 3\n",
+		"todolist_tasks_pending 2\n",
+		"todolist_tasks_completed 1\n",
+		"todolist_tasks_overdue 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "go_goroutines") {
+		t.Errorf("Expected only task metrics, got:\n%s", body)
+	}
+
+	// A file that cannot be loaded fails the scrape
+	os.WriteFile(path, []byte("{not json"), 0644)
+	if code, body = scrape(t, h); code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for a corrupt file, got %d:\n%s", code, body)
+	}
+}
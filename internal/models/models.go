@@ -1,17 +0,0 @@
-package models
-
-import "time"
-
-// Task represents a single todo item
-type Task struct {
-	ID          int       `json:"id"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-}
-
-// TaskList represents the collection of tasks
-type TaskList struct {
-	Tasks  []Task `json:"tasks"`
-	NextID int    `json:"next_id"`
-}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"os"
+	"todolist/internal/env"
+	"todolist/internal/theme"
+
+	"golang.org/x/term"
+)
+
+// ColorMode is the user's explicit color preference
+type ColorMode int
+
+// Color modes set by --color / --no-color
+const (
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+var (
+	// mode is the color preference from the command line
+	mode = ColorAuto
+	// active is the theme used by Colorize
+	active, _ = theme.Lookup(theme.DefaultName, nil)
+	// stdoutIsTerminal is replaced in tests
+	stdoutIsTerminal = func() bool {
+		return term.IsTerminal(int(os.Stdout.Fd()))
+	}
+)
+
+// SetColorMode records the --color / --no-color preference
+func SetColorMode(m ColorMode) {
+	mode = m
+}
+
+// SetTheme selects the theme used by Colorize
+func SetTheme(t *theme.Theme) {
+	active = t
+}
+
+// ColorEnabled reports whether stdout output should be colored
+func ColorEnabled() bool {
+	return ShouldColor(stdoutIsTerminal(), mode, env.Get(env.NoColor))
+}
+
+// ShouldColor decides whether to color output. Output that is not a
+// terminal is never colored, regardless of flags. Otherwise --no-color
+// disables and --color enables colors, and without either flag colors
+// are on unless NO_COLOR is set to a non-empty value.
+func ShouldColor(isTerminal bool, m ColorMode, noColor string) bool {
+	if !isTerminal {
+		return false
+	}
+	switch m {
+	case ColorNever:
+		return false
+	case ColorAlways:
+		return true
+	default:
+		return noColor == ""
+	}
+}
+
+// Formatter returns a theme formatter honouring ColorEnabled
+func Formatter() *theme.Formatter {
+	return theme.NewFormatter(active, ColorEnabled())
+}
+
+// Colorize styles text with the active theme. code names a theme role
+// such as "status-done" or "overdue"; escape sequences themselves are
+// only ever produced by the theme package.
+func Colorize(text, code string) string {
+	return Formatter().Paint(theme.Role(code), text)
+}
+
+// TerminalWidth returns the width of the terminal on stdout, or 0 when
+// stdout is not a terminal
+func TerminalWidth() int {
+	if !stdoutIsTerminal() {
+		return 0
+	}
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
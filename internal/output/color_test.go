@@ -0,0 +1,77 @@
+package output
+
+import (
+	"testing"
+	"todolist/internal/theme"
+)
+
+// withTerminal sets whether stdout is treated as a terminal for one test
+func withTerminal(t *testing.T, isTerminal bool) {
+	original := stdoutIsTerminal
+	stdoutIsTerminal = func() bool { return isTerminal }
+	t.Cleanup(func() {
+		stdoutIsTerminal = original
+		mode = ColorAuto
+	})
+}
+
+// TestColorEnabled tests the interaction of TTY detection, flags and NO_COLOR
+func TestColorEnabled(t *testing.T) {
+	testCases := []struct {
+		name     string
+		terminal bool
+		mode     ColorMode
+		noColor  string
+		expected bool
+	}{
+		{"terminal, auto", true, ColorAuto, "", true},
+		{"terminal, NO_COLOR", true, ColorAuto, "1", false},
+		{"terminal, --no-color", true, ColorNever, "", false},
+		{"terminal, --color overrides NO_COLOR", true, ColorAlways, "1", true},
+		{"pipe, auto", false, ColorAuto, "", false},
+		{"pipe, --color is ignored", false, ColorAlways, "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withTerminal(t, tc.terminal)
+			t.Setenv("NO_COLOR", tc.noColor)
+			SetColorMode(tc.mode)
+
+			if got := ColorEnabled(); got != tc.expected {
+				t.Errorf("Expected ColorEnabled %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestShouldColor tests the pure color decision with an injected TTY result
+func TestShouldColor(t *testing.T) {
+	if ShouldColor(false, ColorAlways, "") {
+		t.Error("Expected no color when not a terminal, even with --color")
+	}
+	if !ShouldColor(true, ColorAuto, "") {
+		t.Error("Expected color on a terminal by default")
+	}
+	if ShouldColor(true, ColorAuto, "1") {
+		t.Error("Expected NO_COLOR to disable color")
+	}
+	if ShouldColor(true, ColorNever, "") {
+		t.Error("Expected --no-color to disable color")
+	}
+}
+
+// TestColorize tests that Colorize styles text only when colors are enabled
+func TestColorize(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	withTerminal(t, false)
+	if got := Colorize("[✓]", string(theme.RoleStatusDone)); got != "[✓]" {
+		t.Errorf("Expected plain text when piped, got %q", got)
+	}
+
+	withTerminal(t, true)
+	if got := Colorize("[✓]", string(theme.RoleStatusDone)); got == "[✓]" {
+		t.Error("Expected styled text on a terminal")
+	}
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"errors"
+	"io"
+	"syscall"
+)
+
+// PipeWriter wraps stdout so that a reader going away (EPIPE, e.g.
+// `todolist list | head -3`) quietly ends the output instead of failing.
+// Once the pipe is broken every further write is discarded. Other write
+// errors are returned unchanged.
+type PipeWriter struct {
+	w      io.Writer
+	broken bool
+}
+
+// NewPipeWriter wraps w
+func NewPipeWriter(w io.Writer) *PipeWriter {
+	return &PipeWriter{w: w}
+}
+
+// Write writes p, swallowing a broken pipe
+func (pw *PipeWriter) Write(p []byte) (int, error) {
+	if pw.broken {
+		return len(p), nil
+	}
+	n, err := pw.w.Write(p)
+	if errors.Is(err, syscall.EPIPE) {
+		pw.broken = true
+		return len(p), nil
+	}
+	return n, err
+}
+
+// Broken reports whether the reader has gone away
+func (pw *PipeWriter) Broken() bool {
+	return pw.broken
+}
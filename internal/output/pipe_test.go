@@ -0,0 +1,71 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestPipeWriterStopsOnClosedPipe tests that a reader closing early ends output without an error
+func TestPipeWriterStopsOnClosedPipe(t *testing.T) {
+	testCases := []struct {
+		name   string
+		output string
+	}{
+		{"one-shot", strings.Repeat("x", 1<<20)},
+		{"streaming", strings.Repeat("[ ] [1] task (created: 2026-01-15)\n", 20000)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatalf("Pipe failed: %v", err)
+			}
+			defer w.Close()
+
+			// Read a few bytes, then hang up like `head -c 16`
+			done := make(chan []byte)
+			go func() {
+				buf := make([]byte, 16)
+				n, _ := io.ReadFull(r, buf)
+				r.Close()
+				done <- buf[:n]
+			}()
+
+			pw := NewPipeWriter(w)
+			for _, line := range strings.SplitAfter(tc.output, "\n") {
+				if _, err := fmt.Fprint(pw, line); err != nil {
+					t.Fatalf("Expected broken pipe to be swallowed, got %v", err)
+				}
+			}
+			if got := <-done; !bytes.Equal(got, []byte(tc.output[:16])) {
+				t.Errorf("Expected reader to see %q, got %q", tc.output[:16], got)
+			}
+			if !pw.Broken() {
+				t.Error("Expected the pipe to be reported broken")
+			}
+		})
+	}
+}
+
+// failWriter fails every write with err
+type failWriter struct{ err error }
+
+func (fw failWriter) Write(p []byte) (int, error) { return 0, fw.err }
+
+// TestPipeWriterReportsOtherErrors tests that genuine I/O errors still surface
+func TestPipeWriterReportsOtherErrors(t *testing.T) {
+	diskFull := errors.New("no space left on device")
+	pw := NewPipeWriter(failWriter{diskFull})
+	if _, err := pw.Write([]byte("data")); !errors.Is(err, diskFull) {
+		t.Errorf("Expected the write error, got %v", err)
+	}
+	if pw.Broken() {
+		t.Error("Expected a non-EPIPE error not to count as a broken pipe")
+	}
+}
@@ -0,0 +1,222 @@
+// Package remind sends desktop notifications for tasks as they approach and
+// pass their due dates. Each pending task with a due date crosses up to
+// three thresholds; a Reminder notifies about each crossing at most once,
+// remembering what it sent in a state file so restarts do not repeat it.
+package remind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// Threshold is a point in a task's life worth a notification
+type Threshold string
+
+// Thresholds in the order a task crosses them
+const (
+	DueSoon Threshold = "due-in-1h"
+	DueNow  Threshold = "due-now"
+	Overdue Threshold = "overdue-1d"
+)
+
+// thresholds lists every threshold in crossing order
+var thresholds = []Threshold{DueSoon, DueNow, Overdue}
+
+// Threshold timings
+const (
+	// DueSoonWindow is how long before its due date a task is due soon
+	DueSoonWindow = time.Hour
+	// OverdueAfter is how long past its due date a task counts as overdue
+	OverdueAfter = 24 * time.Hour
+)
+
+// Crossed returns the latest threshold task has crossed at now, or "" if
+// it is completed, has no due date or is not due soon yet
+func Crossed(task models.Task, now time.Time) Threshold {
+	switch {
+	case task.IsOverdue(now.Add(-OverdueAfter)):
+		return Overdue
+	case task.IsOverdue(now):
+		return DueNow
+	case task.DueWithin(now, DueSoonWindow):
+		return DueSoon
+	default:
+		return ""
+	}
+}
+
+// Notifier delivers a notification to the user
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// Reminder decides which notifications are due and records the ones sent
+type Reminder struct {
+	notifier  Notifier
+	statePath string
+	// sent maps notificationKey values to when they were sent
+	sent map[string]time.Time
+}
+
+// stateFile is the on-disk format of the reminder state
+type stateFile struct {
+	Sent map[string]time.Time `json:"sent"`
+}
+
+// NewReminder creates a Reminder, loading what was already sent from
+// statePath (a missing file means nothing was sent)
+func NewReminder(notifier Notifier, statePath string) (*Reminder, error) {
+	sent, err := loadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Reminder{notifier: notifier, statePath: statePath, sent: sent}, nil
+}
+
+// loadState reads the sent notifications from statePath; a missing file
+// means none
+func loadState(statePath string) (map[string]time.Time, error) {
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]time.Time{}, nil
+		}
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), statePath)
+	}
+	var state stateFile
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, err), statePath)
+	}
+	if state.Sent == nil {
+		return map[string]time.Time{}, nil
+	}
+	return state.Sent, nil
+}
+
+// State reads the state file of a Reminder without sending anything
+type State struct {
+	statePath string
+}
+
+// NewState creates a State for the file at statePath
+func NewState(statePath string) *State {
+	return &State{statePath: statePath}
+}
+
+// ReferencedIDs returns the IDs of the tasks the state file remembers
+// notifications for, so renumbering tasks can leave them alone
+func (s *State) ReferencedIDs() ([]int, error) {
+	sent, err := loadState(s.statePath)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for key := range sent {
+		id, _, _ := strings.Cut(key, "@")
+		if n, err := strconv.Atoi(id); err == nil {
+			ids = append(ids, n)
+		}
+	}
+	return ids, nil
+}
+
+// notificationKey identifies a threshold of a task for a given due date,
+// so moving the due date re-arms its notifications
+func notificationKey(task models.Task, threshold Threshold) string {
+	return fmt.Sprintf("%d@%s/%s", task.ID, task.DueDate.UTC().Format(time.RFC3339), threshold)
+}
+
+// Check notifies about every task that has crossed a threshold it was not
+// notified about yet and saves the state if it changed. Only the latest
+// crossed threshold is notified, and earlier ones are marked as sent with
+// it, so after a sleep or a clock jump a task gets one notification rather
+// than a burst.
+// Failed notifications are retried on the next check; their errors are
+// returned together.
+func (r *Reminder) Check(tasks []models.Task, now time.Time) error {
+	var failures []error
+	live := map[string]bool{}
+	changed := false
+
+	for _, task := range tasks {
+		if task.Completed || task.DueDate == nil {
+			continue
+		}
+		for _, th := range thresholds {
+			live[notificationKey(task, th)] = true
+		}
+
+		crossed := Crossed(task, now)
+		if crossed == "" {
+			continue
+		}
+		if _, done := r.sent[notificationKey(task, crossed)]; done {
+			continue
+		}
+		title, message := notification(task, crossed)
+		if err := r.notifier.Notify(title, message); err != nil {
+			failures = append(failures, err)
+			continue
+		}
+		for _, th := range thresholds {
+			r.sent[notificationKey(task, th)] = now
+			if th == crossed {
+				break
+			}
+		}
+		changed = true
+	}
+
+	// Forget tasks that were completed, deleted or rescheduled
+	for key := range r.sent {
+		if !live[key] {
+			delete(r.sent, key)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := r.save(); err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// notification formats the title and message for a crossed threshold
+func notification(task models.Task, threshold Threshold) (title, message string) {
+	due := task.DueDate.Format("2006-01-02 15:04")
+	switch threshold {
+	case DueSoon:
+		return "Task due soon", fmt.Sprintf("[%d] %s is due at %s", task.ID, task.Description, due)
+	case DueNow:
+		return "Task due", fmt.Sprintf("[%d] %s was due at %s", task.ID, task.Description, due)
+	default:
+		return "Task overdue", fmt.Sprintf("[%d] %s has been overdue since %s", task.ID, task.Description, due)
+	}
+}
+
+// save writes the state file using the same atomic rename as FileStorage
+func (r *Reminder) save() error {
+	data, err := json.Marshal(stateFile{Sent: r.sent})
+	if err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), r.statePath)
+	}
+
+	tempFile := r.statePath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), r.statePath)
+	}
+	if err := os.Rename(tempFile, r.statePath); err != nil {
+		os.Remove(tempFile)
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), r.statePath)
+	}
+	return nil
+}
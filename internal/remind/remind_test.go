@@ -0,0 +1,308 @@
+package remind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// fakeNotifier records notifications and can be made to fail
+type fakeNotifier struct {
+	mu   sync.Mutex
+	sent []string
+	fail bool
+}
+
+func (n *fakeNotifier) Notify(title, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.fail {
+		return errors.New("notification daemon unavailable")
+	}
+	n.sent = append(n.sent, title+": "+message)
+	return nil
+}
+
+// take returns and clears the recorded notifications
+func (n *fakeNotifier) take() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sent := n.sent
+	n.sent = nil
+	return sent
+}
+
+// fakeClock hands every wait to the test, which decides when and to what
+// time it ends
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits chan chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waits: make(chan chan time.Time)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.waits <- ch
+	return ch
+}
+
+// tick waits for the watcher to sleep, then wakes it at now
+func (c *fakeClock) tick(now time.Time) {
+	ch := <-c.waits
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+	ch <- now
+}
+
+// sync waits until the watcher has finished its current check
+func (c *fakeClock) sync() {
+	ch := <-c.waits
+	go func() { c.waits <- ch }()
+}
+
+// TestCrossed tests which threshold a task is past at various times
+func TestCrossed(t *testing.T) {
+	due := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	task := models.Task{ID: 1, DueDate: &due}
+
+	testCases := []struct {
+		now  time.Time
+		want Threshold
+	}{
+		{due.Add(-2 * time.Hour), ""},
+		{due.Add(-time.Hour), ""},
+		{due.Add(-59 * time.Minute), DueSoon},
+		{due.Add(-time.Minute), DueSoon},
+		{due.Add(time.Second), DueNow},
+		{due.Add(OverdueAfter), DueNow},
+		{due.Add(OverdueAfter + time.Second), Overdue},
+	}
+	for _, tc := range testCases {
+		if got := Crossed(task, tc.now); got != tc.want {
+			t.Errorf("At %v: expected %q, got %q", tc.now, tc.want, got)
+		}
+	}
+
+	task.Completed = true
+	if got := Crossed(task, due.Add(time.Hour)); got != "" {
+		t.Errorf("Expected no threshold for a completed task, got %q", got)
+	}
+}
+
+// TestWatcherNotifiesOncePerThreshold drives the watch loop through a day
+// with a fake clock, a task file edit, a suspend and a restart
+func TestWatcherNotifiesOncePerThreshold(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todos.json")
+	statePath := path + ".reminders"
+	fs := storage.NewFileStorage(path)
+
+	start := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+	due := start.Add(3 * time.Hour)
+	if err := fs.Save(&models.TaskList{Tasks: []models.Task{{ID: 1, Description: "report", DueDate: &due}}, NextID: 2}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	notifier := &fakeNotifier{}
+	clock := newFakeClock(start)
+	reminder, err := NewReminder(notifier, statePath)
+	if err != nil {
+		t.Fatalf("NewReminder failed: %v", err)
+	}
+	w := &Watcher{Storage: fs, Path: path, Reminder: reminder, Clock: clock}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	expect := func(step string, want ...string) {
+		t.Helper()
+		clock.sync()
+		if got := notifier.take(); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: expected %q, got %q", step, want, got)
+		}
+	}
+
+	expect("start")
+	// Every minute inside the due-soon hour notifies only once
+	for minute := 0; minute <= 60; minute++ {
+		clock.tick(due.Add(-time.Hour + time.Duration(minute)*time.Minute))
+		if minute == 1 {
+			expect("due soon", "Task due soon: [1] report is due at 2026-01-15 12:00")
+		}
+	}
+	expect("due soon repeated")
+
+	// Passing the due date crosses the next threshold
+	clock.tick(due.Add(time.Minute))
+	expect("due now", "Task due: [1] report was due at 2026-01-15 12:00")
+
+	// A new task added to the file is picked up without restarting
+	soon := due.Add(30 * time.Minute)
+	if err := fs.Save(&models.TaskList{Tasks: []models.Task{
+		{ID: 1, Description: "report", DueDate: &due},
+		{ID: 2, Description: "call", DueDate: &soon},
+	}, NextID: 3}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	clock.tick(due.Add(2 * time.Minute))
+	expect("reload", "Task due soon: [2] call is due at 2026-01-15 12:30")
+
+	// A two-day suspend skips straight to overdue with one notification per task
+	clock.tick(due.Add(48 * time.Hour))
+	expect("resume",
+		"Task overdue: [1] report has been overdue since 2026-01-15 12:00",
+		"Task overdue: [2] call has been overdue since 2026-01-15 12:30")
+
+	// A clock stepped back does not replay earlier thresholds
+	clock.tick(due.Add(time.Minute))
+	expect("clock stepped back")
+
+	cancel()
+	clock.tick(due.Add(48 * time.Hour))
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+
+	// A restarted watcher remembers what was sent
+	reminder, err = NewReminder(notifier, statePath)
+	if err != nil {
+		t.Fatalf("NewReminder failed: %v", err)
+	}
+	restarted := &Watcher{Storage: fs, Path: path, Reminder: reminder}
+	if err := restarted.Check(due.Add(49 * time.Hour)); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if got := notifier.take(); len(got) != 0 {
+		t.Errorf("Expected no notifications after restart, got %q", got)
+	}
+}
+
+// TestFailedNotificationRetried tests that a notification only counts once delivered
+func TestFailedNotificationRetried(t *testing.T) {
+	due := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	tasks := []models.Task{{ID: 1, Description: "report", DueDate: &due}}
+	notifier := &fakeNotifier{fail: true}
+	reminder, err := NewReminder(notifier, filepath.Join(t.TempDir(), "state"))
+	if err != nil {
+		t.Fatalf("NewReminder failed: %v", err)
+	}
+
+	if err := reminder.Check(tasks, due); err == nil {
+		t.Error("Expected the notification failure to be reported")
+	}
+	notifier.fail = false
+	if err := reminder.Check(tasks, due); err != nil || len(notifier.take()) != 1 {
+		t.Errorf("Expected the notification to be retried, got %v", err)
+	}
+}
+
+// TestRescheduledTaskRearms tests that moving the due date allows new notifications
+func TestRescheduledTaskRearms(t *testing.T) {
+	due := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	notifier := &fakeNotifier{}
+	reminder, err := NewReminder(notifier, filepath.Join(t.TempDir(), "state"))
+	if err != nil {
+		t.Fatalf("NewReminder failed: %v", err)
+	}
+
+	reminder.Check([]models.Task{{ID: 1, DueDate: &due}}, due)
+	later := due.Add(24 * time.Hour)
+	reminder.Check([]models.Task{{ID: 1, DueDate: &later}}, later)
+	if got := notifier.take(); len(got) != 2 {
+		t.Errorf("Expected a notification for each due date, got %q", got)
+	}
+	if _, ok := reminder.sent[notificationKey(models.Task{ID: 1, DueDate: &later}, DueSoon)]; !ok || len(reminder.sent) != 1 {
+		t.Errorf("Expected only state for the new due date, got %v", reminder.sent)
+	}
+}
+
+// TestStateReferencedIDs tests reading the task IDs back from a state file
+func TestStateReferencedIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	if ids, err := NewState(path).ReferencedIDs(); err != nil || len(ids) != 0 {
+		t.Errorf("Expected no IDs without a state file, got %v, %v", ids, err)
+	}
+
+	due := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	reminder, err := NewReminder(&fakeNotifier{}, path)
+	if err != nil {
+		t.Fatalf("NewReminder failed: %v", err)
+	}
+	reminder.Check([]models.Task{{ID: 4, DueDate: &due}, {ID: 9, DueDate: &due}, {ID: 12}}, due)
+
+	ids, err := NewState(path).ReferencedIDs()
+	if err != nil {
+		t.Fatalf("ReferencedIDs failed: %v", err)
+	}
+	found := map[int]bool{}
+	for _, id := range ids {
+		found[id] = true
+	}
+	if len(found) != 2 || !found[4] || !found[9] {
+		t.Errorf("Expected tasks 4 and 9, got %v", ids)
+	}
+}
+
+// Feature: todo-list-cli, Property 16: 每个提醒阈值最多通知一次
+// For any sequence of check times, including backwards jumps, every
+// threshold of a task is notified at most once
+// Validates: remind --watch
+func TestProperty_NotifiedAtMostOncePerThreshold(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("each threshold is notified at most once",
+		prop.ForAll(
+			func(offsets []int) bool {
+				due := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+				tasks := []models.Task{{ID: 1, Description: "x", DueDate: &due}}
+				notifier := &fakeNotifier{}
+				reminder, err := NewReminder(notifier, filepath.Join(t.TempDir(), "state"))
+				if err != nil {
+					return false
+				}
+
+				for _, offset := range offsets {
+					if err := reminder.Check(tasks, due.Add(time.Duration(offset)*time.Minute)); err != nil {
+						return false
+					}
+				}
+
+				seen := map[string]bool{}
+				for _, n := range notifier.take() {
+					if seen[n] {
+						return false
+					}
+					seen[n] = true
+				}
+				return len(seen) <= len(thresholds)
+			},
+			gen.SliceOf(gen.IntRange(-3*60, 3*24*60)),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
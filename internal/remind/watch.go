@@ -0,0 +1,174 @@
+package remind
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+)
+
+// DefaultInterval is how often `remind --watch` checks the task list
+const DefaultInterval = time.Minute
+
+// Clock tells the time and waits; tests replace it with a fake
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the system clock
+type realClock struct{}
+
+// Now returns the wall-clock time without a monotonic reading, so that
+// comparisons with due dates stay correct across suspend and clock changes
+func (realClock) Now() time.Time { return time.Now().Round(0) }
+
+// After waits for d to pass
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SystemClock is the Clock backed by the system time
+var SystemClock Clock = realClock{}
+
+// Watcher checks the task list on a timer until its context is cancelled
+type Watcher struct {
+	// Storage supplies the task list
+	Storage storage.Storage
+	// Path is the task file; the list is reloaded only when it changes.
+	// Empty reloads on every check.
+	Path string
+	// Reminder sends the notifications
+	Reminder *Reminder
+	// Clock defaults to SystemClock
+	Clock Clock
+	// Interval between checks; defaults to DefaultInterval
+	Interval time.Duration
+	// Log receives errors that do not stop the watcher; nil discards them
+	Log io.Writer
+
+	list    *models.TaskList
+	modTime time.Time
+	size    int64
+}
+
+// Run checks immediately and then every Interval until ctx is done, when it
+// returns nil. A check that fails (for example while the task file is
+// being replaced) is logged and retried on the next tick. Because every
+// check compares due dates with the current wall-clock time, a tick that
+// arrives late after a suspend simply catches up.
+func (w *Watcher) Run(ctx context.Context) error {
+	clock := w.clock()
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	for {
+		if err := w.Check(clock.Now()); err != nil && w.Log != nil {
+			fmt.Fprintf(w.Log, "Warning: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-clock.After(interval):
+		}
+	}
+}
+
+// Check reloads the task list if it changed and notifies about crossed thresholds
+func (w *Watcher) Check(now time.Time) error {
+	if err := w.reload(); err != nil {
+		return err
+	}
+	return w.Reminder.Check(w.list.Tasks, now)
+}
+
+// reload loads the task list on the first call and whenever the task
+// file's modification time or size changes
+func (w *Watcher) reload() error {
+	// Stat before loading so a write racing the load is seen next time
+	var modTime time.Time
+	var size int64
+	if w.Path != "" {
+		if info, err := os.Stat(w.Path); err == nil {
+			modTime, size = info.ModTime(), info.Size()
+			if w.list != nil && modTime.Equal(w.modTime) && size == w.size {
+				return nil
+			}
+		}
+	}
+
+	list, err := w.Storage.Load()
+	if err != nil {
+		return err
+	}
+	w.list, w.modTime, w.size = list, modTime, size
+	return nil
+}
+
+// clock returns the watcher's clock, defaulting to SystemClock
+func (w *Watcher) clock() Clock {
+	if w.Clock == nil {
+		return SystemClock
+	}
+	return w.Clock
+}
+
+// commandNotifier shows notifications by running a platform tool
+type commandNotifier struct {
+	name string
+	args func(title, message string) []string
+}
+
+// Notify runs the notification tool
+func (n commandNotifier) Notify(title, message string) error {
+	out, err := exec.Command(n.name, n.args(title, message)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %v: %s", n.name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// writerNotifier prints notifications, for systems without a notification tool
+type writerNotifier struct {
+	w io.Writer
+}
+
+// Notify writes the notification as one line
+func (n writerNotifier) Notify(title, message string) error {
+	_, err := fmt.Fprintf(n.w, "%s: %s\n", title, message)
+	return err
+}
+
+// NewDesktopNotifier returns a Notifier using notify-send on Linux and the
+// BSDs or osascript on macOS. Where neither is available notifications are
+// written to fallback instead.
+func NewDesktopNotifier(fallback io.Writer) Notifier {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("osascript"); err == nil {
+			return commandNotifier{name: "osascript", args: func(title, message string) []string {
+				return []string{"-e", fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))}
+			}}
+		}
+	case "windows":
+		// No notification tool ships with Windows
+	default:
+		if _, err := exec.LookPath("notify-send"); err == nil {
+			return commandNotifier{name: "notify-send", args: func(title, message string) []string {
+				return []string{"--app-name=todolist", title, message}
+			}}
+		}
+	}
+	return writerNotifier{w: fallback}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
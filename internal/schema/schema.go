@@ -0,0 +1,183 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"todolist/pkg/models"
+)
+
+// Draft is the JSON Schema dialect of the generated document
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// descriptions documents every serialized field, keyed by "Type.json_name".
+// Generation fails for fields missing here, so the schema cannot drift from
+// the models silently.
+var descriptions = map[string]string{
+	"TaskList":               "The persisted todo list",
+	"TaskList.version":       "Storage format version the list was saved with",
+	"TaskList.tasks":         "All tasks in display order",
+	"TaskList.next_id":       "ID that will be assigned to the next added task",
+	"TaskList.trash":         "Deleted tasks that can still be restored, oldest deletion first",
+	"TrashedTask":            "A deleted task kept in the trash",
+	"TrashedTask.task":       "The task as it was when deleted, with its original ID",
+	"TrashedTask.deleted_at": "When the task was deleted",
+	"Task":                   "A single todo item",
+	"Task.id":                "Unique positive task identifier",
+	"Task.uid":               "Random UUID identifying the task across lists and devices; kept when IDs change",
+	"Task.description":       "What needs to be done",
+	"Task.completed":         "Whether the task has been completed",
+	"Task.completed_at":      "When the task was completed; absent for pending tasks and tasks completed before this was recorded",
+	"Task.created_at":        "When the task was created",
+	"Task.due_date":          "When the task is due, if it has a deadline",
+	"Task.priority":          "Importance: 0 none, 1 low, 2 medium, 3 high",
+	"Task.notes":             "Free-text details beyond the one-line description",
+	"Task.tags":              "Tags without their #, taken from #tag words of the description when the task was added",
+	"Task.recurrence":        "daily, weekly, monthly or cron:<expr>; completing the task adds the next occurrence",
+	"Task.time_entries":      "Time tracked on the task, oldest first",
+	"Task.estimate_minutes":  "Estimated effort in minutes",
+	"TimeEntry":              "One stretch of time spent on a task",
+	"TimeEntry.start":        "When the timer was started",
+	"TimeEntry.end":          "When the timer was stopped; absent while it is running",
+}
+
+// timeType is special-cased as an RFC 3339 string
+var timeType = reflect.TypeOf(time.Time{})
+
+// Generate returns the JSON Schema document describing models.TaskList
+func Generate() ([]byte, error) {
+	doc, err := Document()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Document builds the JSON Schema as a generic map
+func Document() (map[string]any, error) {
+	g := &generator{defs: map[string]any{}}
+
+	root, err := g.object(reflect.TypeOf(models.TaskList{}))
+	if err != nil {
+		return nil, err
+	}
+
+	root["$schema"] = Draft
+	root["$id"] = fmt.Sprintf("https://todolist.local/schema/v%d/tasklist.json", models.FormatVersion)
+	root["title"] = "TaskList"
+	root["version"] = models.FormatVersion
+	root["$defs"] = g.defs
+
+	return root, nil
+}
+
+// generator accumulates definitions for nested struct types
+type generator struct {
+	defs map[string]any
+}
+
+// object describes a struct type as a JSON Schema object
+func (g *generator) object(t reflect.Type) (map[string]any, error) {
+	properties := map[string]any{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitempty := jsonName(field)
+		if name == "" {
+			continue
+		}
+
+		description, ok := descriptions[t.Name()+"."+name]
+		if !ok {
+			return nil, fmt.Errorf("schema: no description registered for %s.%s", t.Name(), name)
+		}
+
+		prop, err := g.property(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		prop["description"] = description
+		properties[name] = prop
+
+		if !omitempty && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	description, ok := descriptions[t.Name()]
+	if !ok {
+		return nil, fmt.Errorf("schema: no description registered for %s", t.Name())
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"description":          description,
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
+
+// property describes a field type
+func (g *generator) property(t reflect.Type) (map[string]any, error) {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.property(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice:
+		items, err := g.property(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		values, err := g.property(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		if _, done := g.defs[t.Name()]; !done {
+			g.defs[t.Name()] = nil // guard against recursion
+			def, err := g.object(t)
+			if err != nil {
+				return nil, err
+			}
+			g.defs[t.Name()] = def
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}, nil
+	default:
+		return nil, fmt.Errorf("schema: unsupported field type %s", t)
+	}
+}
+
+// jsonName returns the serialized name of a field and whether it is omitempty.
+// An empty name means the field is not serialized.
+func jsonName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty")
+}
@@ -0,0 +1,223 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"todolist/pkg/models"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// validate checks a decoded JSON value against the subset of JSON Schema
+// that Generate emits
+func validate(schema map[string]any, defs map[string]any, value any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: unresolved $ref %s", path, ref)
+		}
+		return validate(def, defs, value, path)
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected object", path)
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for _, name := range schema["required"].([]any) {
+			if _, ok := obj[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %s", path, name)
+			}
+		}
+		for name, v := range obj {
+			if propSchema, ok := properties[name].(map[string]any); ok {
+				if err := validate(propSchema, defs, v, path+"."+name); err != nil {
+					return err
+				}
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: unexpected property %s", path, name)
+				}
+			case map[string]any:
+				if err := validate(additional, defs, v, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected array", path)
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range arr {
+			if err := validate(items, defs, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string", path)
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: invalid date-time %q", path, s)
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("%s: expected integer", path)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected number", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean", path)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported schema type %v", path, schema["type"])
+	}
+	return nil
+}
+
+// loadSchema generates the schema and decodes it like an external consumer would
+func loadSchema(t *testing.T) (map[string]any, map[string]any) {
+	data, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Generated schema is not valid JSON: %v", err)
+	}
+	defs, _ := doc["$defs"].(map[string]any)
+	return doc, defs
+}
+
+// validateJSON validates a raw JSON document against the schema
+func validateJSON(doc, defs map[string]any, data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return validate(doc, defs, value, "$")
+}
+
+// TestGenerateIsDeterministic tests that repeated generation yields identical output
+func TestGenerateIsDeterministic(t *testing.T) {
+	first, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		again, _ := Generate()
+		if string(again) != string(first) {
+			t.Fatal("Generate produced different output on repeated calls")
+		}
+	}
+}
+
+// TestSchemaVersionMatchesFormatVersion tests that the schema is versioned with the storage format
+func TestSchemaVersionMatchesFormatVersion(t *testing.T) {
+	doc, _ := loadSchema(t)
+	if doc["version"] != float64(models.FormatVersion) {
+		t.Errorf("Expected schema version %d, got %v", models.FormatVersion, doc["version"])
+	}
+	if !strings.Contains(doc["$id"].(string), fmt.Sprintf("/v%d/", models.FormatVersion)) {
+		t.Errorf("Expected $id to contain the format version, got %v", doc["$id"])
+	}
+}
+
+// TestEveryDescriptionIsUsed tests that the description registry has no stale entries
+func TestEveryDescriptionIsUsed(t *testing.T) {
+	data, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for _, description := range descriptions {
+		encoded, _ := json.Marshal(description)
+		if !strings.Contains(string(data), string(encoded)) {
+			t.Errorf("Description %q is not used by any field", description)
+		}
+	}
+}
+
+// TestSchemaRejectsInvalidDocuments tests that the schema is strict enough to catch mistakes
+func TestSchemaRejectsInvalidDocuments(t *testing.T) {
+	doc, defs := loadSchema(t)
+
+	testCases := map[string]string{
+		"missing next_id":    `{"tasks": []}`,
+		"id as string":       `{"tasks": [{"id": "1", "description": "x", "completed": false, "created_at": "2026-01-14T10:30:00Z"}], "next_id": 2}`,
+		"bad created_at":     `{"tasks": [{"id": 1, "description": "x", "completed": false, "created_at": "yesterday"}], "next_id": 2}`,
+		"unknown task field": `{"tasks": [{"id": 1, "description": "x", "completed": false, "created_at": "2026-01-14T10:30:00Z", "colour": "red"}], "next_id": 2}`,
+	}
+
+	for name, raw := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if err := validateJSON(doc, defs, []byte(raw)); err == nil {
+				t.Errorf("Expected %s to fail validation", name)
+			}
+		})
+	}
+}
+
+// Feature: todo-list-cli, Property 15: 生成的数据符合 JSON Schema
+// For any task list, its serialized JSON validates against the generated schema
+// Validates: schema command
+func TestProperty_SerializedTaskListMatchesSchema(t *testing.T) {
+	doc, defs := loadSchema(t)
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("serialized task lists validate against the schema",
+		prop.ForAll(
+			func(descriptions []string, completed []bool, dueOffsets []int, nextID int) bool {
+				list := models.TaskList{Tasks: []models.Task{}, NextID: nextID}
+				now := time.Now()
+				for i, description := range descriptions {
+					task := models.Task{
+						ID:          i + 1,
+						Description: description,
+						CreatedAt:   now.Add(time.Duration(i) * time.Minute),
+					}
+					if i < len(completed) {
+						task.Completed = completed[i]
+					}
+					if i < len(dueOffsets) && dueOffsets[i]%2 == 0 {
+						due := now.Add(time.Duration(dueOffsets[i]) * time.Hour)
+						task.DueDate = &due
+					}
+					list.Tasks = append(list.Tasks, task)
+				}
+
+				data, err := json.Marshal(list)
+				if err != nil {
+					return false
+				}
+				return validateJSON(doc, defs, data) == nil
+			},
+			gen.SliceOf(gen.AnyString()),
+			gen.SliceOf(gen.Bool()),
+			gen.SliceOf(gen.IntRange(-1000, 1000)),
+			gen.IntRange(1, 100000),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
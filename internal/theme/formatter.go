@@ -0,0 +1,49 @@
+package theme
+
+// escape is the only place ANSI control sequences are produced.
+// All colored output must go through Formatter.Paint.
+const (
+	escape = "\x1b["
+	reset  = escape + "0m"
+)
+
+// ClearScreen moves the cursor home and clears the terminal, for commands
+// that redraw their output in place
+const ClearScreen = escape + "H" + escape + "2J"
+
+// Formatter renders text for a role using a theme
+type Formatter struct {
+	theme   *Theme
+	enabled bool
+}
+
+// NewFormatter creates a Formatter. When enabled is false, or the theme is
+// nil, Paint returns text unchanged.
+func NewFormatter(t *Theme, enabled bool) *Formatter {
+	return &Formatter{
+		theme:   t,
+		enabled: enabled,
+	}
+}
+
+// Plain returns a Formatter that never emits escape sequences
+func Plain() *Formatter {
+	return &Formatter{}
+}
+
+// Enabled reports whether the formatter emits escape sequences
+func (f *Formatter) Enabled() bool {
+	return f != nil && f.enabled && f.theme != nil
+}
+
+// Paint wraps text in the escape sequences configured for role
+func (f *Formatter) Paint(role Role, text string) string {
+	if !f.Enabled() || text == "" {
+		return text
+	}
+	sgr := f.theme.Style(role).sgr()
+	if sgr == "" {
+		return text
+	}
+	return escape + sgr + "m" + text + reset
+}
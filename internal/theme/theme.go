@@ -0,0 +1,263 @@
+package theme
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Role identifies a semantic piece of output that can be styled
+type Role string
+
+// Styleable output roles
+const (
+	RoleStatusDone    Role = "status-done"
+	RoleStatusPending Role = "status-pending"
+	RoleOverdue       Role = "overdue"
+	RoleDueSoon       Role = "due-soon"
+	RoleHeader        Role = "header"
+	RoleDim           Role = "dim"
+	RolePriorityHigh  Role = "priority-high"
+	RolePriorityLow   Role = "priority-low"
+)
+
+// Roles lists every styleable role in display order
+var Roles = []Role{
+	RoleStatusDone,
+	RoleStatusPending,
+	RoleOverdue,
+	RoleDueSoon,
+	RoleHeader,
+	RoleDim,
+	RolePriorityHigh,
+	RolePriorityLow,
+}
+
+// DefaultName is the theme used when none is configured
+const DefaultName = "default"
+
+// Style describes how a role is rendered on an ANSI terminal
+type Style struct {
+	Bold      bool
+	Dim       bool
+	Underline bool
+	// Foreground is the SGR parameter selecting the foreground color,
+	// e.g. "32", "38;5;208" or "38;2;0;114;178". Empty means terminal default.
+	Foreground string
+}
+
+// sgr returns the SGR parameter list for the style, or "" for a plain style
+func (s Style) sgr() string {
+	var params []string
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Dim {
+		params = append(params, "2")
+	}
+	if s.Underline {
+		params = append(params, "4")
+	}
+	if s.Foreground != "" {
+		params = append(params, s.Foreground)
+	}
+	return strings.Join(params, ";")
+}
+
+// Theme maps every output role to a style
+type Theme struct {
+	Name          string
+	StatusDone    Style
+	StatusPending Style
+	Overdue       Style
+	DueSoon       Style
+	Header        Style
+	Dim           Style
+	PriorityHigh  Style
+	PriorityLow   Style
+}
+
+// Style returns the style configured for a role
+func (t *Theme) Style(role Role) Style {
+	switch role {
+	case RoleStatusDone:
+		return t.StatusDone
+	case RoleStatusPending:
+		return t.StatusPending
+	case RoleOverdue:
+		return t.Overdue
+	case RoleDueSoon:
+		return t.DueSoon
+	case RoleHeader:
+		return t.Header
+	case RoleDim:
+		return t.Dim
+	case RolePriorityHigh:
+		return t.PriorityHigh
+	case RolePriorityLow:
+		return t.PriorityLow
+	default:
+		return Style{}
+	}
+}
+
+// setStyle assigns the style for a role
+func (t *Theme) setStyle(role Role, s Style) error {
+	switch role {
+	case RoleStatusDone:
+		t.StatusDone = s
+	case RoleStatusPending:
+		t.StatusPending = s
+	case RoleOverdue:
+		t.Overdue = s
+	case RoleDueSoon:
+		t.DueSoon = s
+	case RoleHeader:
+		t.Header = s
+	case RoleDim:
+		t.Dim = s
+	case RolePriorityHigh:
+		t.PriorityHigh = s
+	case RolePriorityLow:
+		t.PriorityLow = s
+	default:
+		return fmt.Errorf("unknown theme role %q", role)
+	}
+	return nil
+}
+
+// builtins holds the themes shipped with the program
+var builtins = map[string]Theme{
+	"default": {
+		Name:          "default",
+		StatusDone:    Style{Foreground: "32"},
+		StatusPending: Style{Foreground: "33"},
+		Overdue:       Style{Bold: true, Foreground: "31"},
+		DueSoon:       Style{Foreground: "35"},
+		Header:        Style{Bold: true},
+		Dim:           Style{Dim: true},
+		PriorityHigh:  Style{Bold: true},
+		PriorityLow:   Style{Dim: true},
+	},
+	// colorblind avoids the red/green axis entirely (blue/orange palette)
+	"colorblind": {
+		Name:          "colorblind",
+		StatusDone:    Style{Foreground: "38;5;33"},
+		StatusPending: Style{Foreground: "38;5;214"},
+		Overdue:       Style{Bold: true, Foreground: "38;5;202"},
+		DueSoon:       Style{Underline: true, Foreground: "38;5;220"},
+		Header:        Style{Bold: true},
+		Dim:           Style{Dim: true},
+		PriorityHigh:  Style{Bold: true},
+		PriorityLow:   Style{Dim: true},
+	},
+	"mono": {
+		Name:          "mono",
+		StatusDone:    Style{},
+		StatusPending: Style{Bold: true},
+		Overdue:       Style{Bold: true, Underline: true},
+		DueSoon:       Style{Underline: true},
+		Header:        Style{Bold: true},
+		Dim:           Style{Dim: true},
+		PriorityHigh:  Style{Bold: true},
+		PriorityLow:   Style{Dim: true},
+	},
+}
+
+// BuiltinNames returns the names of the built-in themes in sorted order
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup resolves a theme by name. User-defined themes in custom take
+// precedence over built-ins; roles they omit fall back to the default theme.
+func Lookup(name string, custom map[string]map[string]string) (*Theme, error) {
+	if name == "" {
+		name = DefaultName
+	}
+
+	if roles, ok := custom[name]; ok {
+		return parseCustom(name, roles)
+	}
+
+	if t, ok := builtins[name]; ok {
+		return &t, nil
+	}
+
+	return nil, fmt.Errorf("unknown theme %q (built-in themes: %s)", name, strings.Join(BuiltinNames(), ", "))
+}
+
+// parseCustom builds a user-defined theme on top of the default theme
+func parseCustom(name string, roles map[string]string) (*Theme, error) {
+	t := builtins[DefaultName]
+	t.Name = name
+
+	for role, spec := range roles {
+		s, err := ParseStyle(spec)
+		if err != nil {
+			return nil, fmt.Errorf("theme %q, role %q: %w", name, role, err)
+		}
+		if err := t.setStyle(Role(role), s); err != nil {
+			return nil, fmt.Errorf("theme %q: %w", name, err)
+		}
+	}
+
+	return &t, nil
+}
+
+// ParseStyle parses a style specification from the config file.
+// A spec is a space-separated list of attributes (bold, dim, underline)
+// and at most one color: an ANSI 256 index (0-255) or a truecolor
+// value written as #rrggbb. An empty spec means the terminal default.
+func ParseStyle(spec string) (Style, error) {
+	var s Style
+	for _, token := range strings.Fields(strings.ToLower(spec)) {
+		switch {
+		case token == "bold":
+			s.Bold = true
+		case token == "dim":
+			s.Dim = true
+		case token == "underline":
+			s.Underline = true
+		case strings.HasPrefix(token, "#"):
+			if s.Foreground != "" {
+				return Style{}, fmt.Errorf("more than one color in %q", spec)
+			}
+			fg, err := parseTrueColor(token)
+			if err != nil {
+				return Style{}, err
+			}
+			s.Foreground = fg
+		default:
+			if s.Foreground != "" {
+				return Style{}, fmt.Errorf("more than one color in %q", spec)
+			}
+			n, err := strconv.Atoi(token)
+			if err != nil || n < 0 || n > 255 {
+				return Style{}, fmt.Errorf("invalid color %q: expected 0-255, #rrggbb, bold, dim or underline", token)
+			}
+			s.Foreground = "38;5;" + strconv.Itoa(n)
+		}
+	}
+	return s, nil
+}
+
+// parseTrueColor converts #rrggbb into a 24-bit SGR foreground parameter
+func parseTrueColor(token string) (string, error) {
+	hex := strings.TrimPrefix(token, "#")
+	if len(hex) != 6 {
+		return "", fmt.Errorf("invalid truecolor %q: expected #rrggbb", token)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid truecolor %q: expected #rrggbb", token)
+	}
+	r, g, b := (value>>16)&0xff, (value>>8)&0xff, value&0xff
+	return fmt.Sprintf("38;2;%d;%d;%d", r, g, b), nil
+}
@@ -0,0 +1,197 @@
+package theme
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuiltinThemesResolve tests that every built-in theme can be looked up
+func TestBuiltinThemesResolve(t *testing.T) {
+	for _, name := range []string{"default", "colorblind", "mono"} {
+		th, err := Lookup(name, nil)
+		if err != nil {
+			t.Fatalf("Lookup(%q) failed: %v", name, err)
+		}
+		if th.Name != name {
+			t.Errorf("Expected theme name %q, got %q", name, th.Name)
+		}
+	}
+
+	// Empty name resolves to the default theme
+	th, err := Lookup("", nil)
+	if err != nil {
+		t.Fatalf("Lookup(\"\") failed: %v", err)
+	}
+	if th.Name != DefaultName {
+		t.Errorf("Expected default theme, got %q", th.Name)
+	}
+}
+
+// TestColorblindThemeAvoidsRedGreen tests that the colorblind theme does not use red or green
+func TestColorblindThemeAvoidsRedGreen(t *testing.T) {
+	th, err := Lookup("colorblind", nil)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	for _, role := range Roles {
+		fg := th.Style(role).Foreground
+		if fg == "31" || fg == "32" || fg == "91" || fg == "92" {
+			t.Errorf("Role %s uses red/green foreground %q", role, fg)
+		}
+	}
+}
+
+// TestMonoThemeHasNoColors tests that the mono theme only uses attributes
+func TestMonoThemeHasNoColors(t *testing.T) {
+	th, err := Lookup("mono", nil)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	for _, role := range Roles {
+		if fg := th.Style(role).Foreground; fg != "" {
+			t.Errorf("Role %s has foreground %q in mono theme", role, fg)
+		}
+	}
+}
+
+// TestLookupUnknownTheme tests that an unknown theme name is rejected
+func TestLookupUnknownTheme(t *testing.T) {
+	if _, err := Lookup("neon", nil); err == nil {
+		t.Fatal("Expected error for unknown theme, got nil")
+	}
+}
+
+// TestCustomTheme tests user-defined themes from the config file
+func TestCustomTheme(t *testing.T) {
+	custom := map[string]map[string]string{
+		"mine": {
+			"status-done": "#0072b2",
+			"overdue":     "bold 208",
+		},
+	}
+
+	th, err := Lookup("mine", custom)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if th.StatusDone.Foreground != "38;2;0;114;178" {
+		t.Errorf("Unexpected status-done foreground %q", th.StatusDone.Foreground)
+	}
+	if !th.Overdue.Bold || th.Overdue.Foreground != "38;5;208" {
+		t.Errorf("Unexpected overdue style %+v", th.Overdue)
+	}
+
+	// Roles not given fall back to the default theme
+	if th.Header != builtins[DefaultName].Header {
+		t.Errorf("Expected header to fall back to default, got %+v", th.Header)
+	}
+}
+
+// TestCustomThemeValidation tests that invalid custom themes are rejected
+func TestCustomThemeValidation(t *testing.T) {
+	testCases := []struct {
+		name  string
+		roles map[string]string
+	}{
+		{name: "unknown role", roles: map[string]string{"sparkle": "33"}},
+		{name: "256 index out of range", roles: map[string]string{"header": "256"}},
+		{name: "negative index", roles: map[string]string{"header": "-1"}},
+		{name: "short truecolor", roles: map[string]string{"dim": "#fff"}},
+		{name: "bad hex", roles: map[string]string{"dim": "#gg0000"}},
+		{name: "two colors", roles: map[string]string{"dim": "33 44"}},
+		{name: "unknown attribute", roles: map[string]string{"dim": "blink"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			custom := map[string]map[string]string{"bad": tc.roles}
+			if _, err := Lookup("bad", custom); err == nil {
+				t.Errorf("Expected error for %s, got nil", tc.name)
+			}
+		})
+	}
+}
+
+// TestFormatterPaint tests that Paint only styles output when enabled
+func TestFormatterPaint(t *testing.T) {
+	th, _ := Lookup("default", nil)
+
+	enabled := NewFormatter(th, true)
+	painted := enabled.Paint(RoleStatusDone, "[✓]")
+	if painted != "\x1b[32m[✓]\x1b[0m" {
+		t.Errorf("Unexpected painted output %q", painted)
+	}
+
+	disabled := NewFormatter(th, false)
+	if got := disabled.Paint(RoleStatusDone, "[✓]"); got != "[✓]" {
+		t.Errorf("Expected plain output when disabled, got %q", got)
+	}
+
+	if got := Plain().Paint(RoleHeader, "Your tasks:"); got != "Your tasks:" {
+		t.Errorf("Expected plain output from Plain(), got %q", got)
+	}
+
+	var nilFormatter *Formatter
+	if got := nilFormatter.Paint(RoleHeader, "x"); got != "x" {
+		t.Errorf("Expected nil formatter to return text unchanged, got %q", got)
+	}
+}
+
+// TestNoEscapeLiteralsOutsideTheme tests that colored output can only be
+// produced through this package: no other Go source may contain ANSI escapes
+func TestNoEscapeLiteralsOutsideTheme(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatalf("Failed to resolve module root: %v", err)
+	}
+	themeDir, _ := filepath.Abs(".")
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == themeDir || strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			lit, ok := n.(*ast.BasicLit)
+			if !ok || (lit.Kind != token.STRING && lit.Kind != token.CHAR) {
+				return true
+			}
+			if containsEscape(lit.Value) {
+				t.Errorf("%s: ANSI escape literal outside theme package", fset.Position(lit.Pos()))
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to scan sources: %v", err)
+	}
+}
+
+// containsEscape reports whether a Go literal (as written in source) encodes ESC
+func containsEscape(raw string) bool {
+	lower := strings.ToLower(raw)
+	return strings.Contains(lower, `\x1b`) ||
+		strings.Contains(lower, `\033`) ||
+		strings.Contains(lower, `\u001b`) ||
+		strings.ContainsRune(raw, 0x1b)
+}
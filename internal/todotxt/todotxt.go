@@ -0,0 +1,201 @@
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"todolist/pkg/models"
+)
+
+// dateLayout is the todo.txt date format
+const dateLayout = "2006-01-02"
+
+var (
+	priorityPattern = regexp.MustCompile(`^\(([A-Z])\) `)
+	datePattern     = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}) `)
+)
+
+// letters maps task priorities to todo.txt priority letters
+var letters = map[models.Priority]string{
+	models.PriorityHigh:   "A",
+	models.PriorityMedium: "B",
+	models.PriorityLow:    "C",
+}
+
+// LineIssue describes a problem with one line of a todo.txt file
+type LineIssue struct {
+	Line    int
+	Message string
+}
+
+// String formats the issue for display
+func (li LineIssue) String() string {
+	return fmt.Sprintf("line %d: %s", li.Line, li.Message)
+}
+
+// Result is the outcome of parsing a todo.txt file
+type Result struct {
+	// Tasks holds one task per parsed line; IDs are not assigned
+	Tasks []models.Task
+	// Warnings are lines imported with some information dropped
+	Warnings []LineIssue
+	// Errors are lines that could not be imported at all
+	Errors []LineIssue
+}
+
+// Write writes tasks in todo.txt format, one task per line
+func Write(w io.Writer, tasks []models.Task) error {
+	for _, task := range tasks {
+		if _, err := fmt.Fprintln(w, FormatTask(task)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatTask renders a single task as a todo.txt line
+func FormatTask(task models.Task) string {
+	var parts []string
+
+	if task.Completed {
+		// todo.txt puts no priority after "x"; keep it as a pri: tag instead
+		parts = append(parts, "x")
+		// A creation date may only follow a completion date
+		if task.CompletedAt != nil {
+			parts = append(parts, task.CompletedAt.Format(dateLayout), task.CreatedAt.Format(dateLayout))
+		}
+	} else {
+		if letter, ok := letters[task.Priority]; ok {
+			parts = append(parts, "("+letter+")")
+		}
+		// The creation date also shields descriptions such as "x marks the
+		// spot" from being read back as completion markers
+		parts = append(parts, task.CreatedAt.Format(dateLayout))
+	}
+
+	parts = append(parts, strings.Join(strings.Fields(task.Description), " "))
+
+	if task.DueDate != nil {
+		parts = append(parts, "due:"+task.DueDate.Format(dateLayout))
+	}
+	if letter, ok := letters[task.Priority]; ok && task.Completed {
+		parts = append(parts, "pri:"+letter)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Parse reads a todo.txt file. Lines that cannot be parsed are reported
+// in Result.Errors with their line numbers instead of aborting the import.
+func Parse(r io.Reader, now time.Time) (*Result, error) {
+	result := &Result{Tasks: []models.Task{}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		task, warning, err := parseLine(line, now)
+		if err != nil {
+			result.Errors = append(result.Errors, LineIssue{Line: lineNumber, Message: err.Error()})
+			continue
+		}
+		if warning != "" {
+			result.Warnings = append(result.Warnings, LineIssue{Line: lineNumber, Message: warning})
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// parseLine parses one non-empty todo.txt line
+func parseLine(line string, now time.Time) (models.Task, string, error) {
+	task := models.Task{CreatedAt: now}
+	var warning string
+	rest := line + " "
+
+	if strings.HasPrefix(rest, "x ") {
+		task.Completed = true
+		rest = rest[2:]
+
+		// Optional completion date followed by optional creation date
+		if m := datePattern.FindStringSubmatch(rest); m != nil {
+			completed, err := time.ParseInLocation(dateLayout, m[1], time.Local)
+			if err != nil {
+				return task, "", fmt.Errorf("invalid completion date %q", m[1])
+			}
+			task.CompletedAt = &completed
+			rest = rest[len(m[0]):]
+			if m2 := datePattern.FindStringSubmatch(rest); m2 != nil {
+				created, err := time.ParseInLocation(dateLayout, m2[1], time.Local)
+				if err != nil {
+					return task, "", fmt.Errorf("invalid creation date %q", m2[1])
+				}
+				task.CreatedAt = created
+				rest = rest[len(m2[0]):]
+			}
+		}
+	} else {
+		if m := priorityPattern.FindStringSubmatch(rest); m != nil {
+			task.Priority, warning = priorityFromLetter(m[1])
+			rest = rest[len(m[0]):]
+		}
+		if m := datePattern.FindStringSubmatch(rest); m != nil {
+			created, err := time.ParseInLocation(dateLayout, m[1], time.Local)
+			if err != nil {
+				return task, "", fmt.Errorf("invalid creation date %q", m[1])
+			}
+			task.CreatedAt = created
+			rest = rest[len(m[0]):]
+		}
+	}
+
+	// Pull out the key:value tags we understand, keep everything else
+	var words []string
+	for _, word := range strings.Fields(rest) {
+		key, value, found := strings.Cut(word, ":")
+		switch {
+		case found && key == "due" && value != "":
+			day, err := time.ParseInLocation(dateLayout, value, time.Local)
+			if err != nil {
+				return task, "", fmt.Errorf("invalid due date %q", value)
+			}
+			due := day.Add(24*time.Hour - time.Second)
+			task.DueDate = &due
+		case found && key == "pri" && len(value) == 1:
+			task.Priority, warning = priorityFromLetter(strings.ToUpper(value))
+		default:
+			words = append(words, word)
+		}
+	}
+
+	task.Description = strings.Join(words, " ")
+	if task.Description == "" {
+		return task, "", fmt.Errorf("no task description")
+	}
+
+	return task, warning, nil
+}
+
+// priorityFromLetter maps a todo.txt priority letter to a task priority.
+// Letters beyond C have no equivalent and are dropped with a warning.
+func priorityFromLetter(letter string) (models.Priority, string) {
+	for priority, l := range letters {
+		if l == letter {
+			return priority, ""
+		}
+	}
+	return models.PriorityNone, fmt.Sprintf("priority (%s) has no equivalent and was dropped", letter)
+}
@@ -0,0 +1,156 @@
+package todotxt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"todolist/pkg/models"
+)
+
+// TestParseLines tests mapping of todo.txt markers to task fields
+func TestParseLines(t *testing.T) {
+	now := time.Date(2026, 1, 20, 9, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		line        string
+		description string
+		completed   bool
+		priority    models.Priority
+		created     string
+		due         string
+	}{
+		{"Buy milk", "Buy milk", false, models.PriorityNone, "2026-01-20", ""},
+		{"(A) Call mom", "Call mom", false, models.PriorityHigh, "2026-01-20", ""},
+		{"(B) 2026-01-14 Write report +work @office", "Write report +work @office", false, models.PriorityMedium, "2026-01-14", ""},
+		{"(C) Water plants due:2026-02-01", "Water plants", false, models.PriorityLow, "2026-01-20", "2026-02-01"},
+		{"x Done thing", "Done thing", true, models.PriorityNone, "2026-01-20", ""},
+		{"x 2026-01-18 2026-01-10 Finished", "Finished", true, models.PriorityNone, "2026-01-10", ""},
+		{"x Old urgent thing pri:A", "Old urgent thing", true, models.PriorityHigh, "2026-01-20", ""},
+		{"xylophone practice", "xylophone practice", false, models.PriorityNone, "2026-01-20", ""},
+		{"(a) lowercase is not a priority", "(a) lowercase is not a priority", false, models.PriorityNone, "2026-01-20", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.line, func(t *testing.T) {
+			result, err := Parse(strings.NewReader(tc.line), now)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(result.Tasks) != 1 {
+				t.Fatalf("Expected 1 task, got %d (errors: %v)", len(result.Tasks), result.Errors)
+			}
+			task := result.Tasks[0]
+			if task.Description != tc.description {
+				t.Errorf("Expected description %q, got %q", tc.description, task.Description)
+			}
+			if task.Completed != tc.completed {
+				t.Errorf("Expected completed %v, got %v", tc.completed, task.Completed)
+			}
+			if task.Priority != tc.priority {
+				t.Errorf("Expected priority %v, got %v", tc.priority, task.Priority)
+			}
+			if got := task.CreatedAt.Format(dateLayout); got != tc.created {
+				t.Errorf("Expected created %s, got %s", tc.created, got)
+			}
+			switch {
+			case tc.due == "" && task.DueDate != nil:
+				t.Errorf("Expected no due date, got %v", task.DueDate)
+			case tc.due != "" && (task.DueDate == nil || task.DueDate.Format(dateLayout) != tc.due):
+				t.Errorf("Expected due %s, got %v", tc.due, task.DueDate)
+			}
+		})
+	}
+}
+
+// TestParseReportsBadLinesWithNumbers tests that bad lines are reported without aborting
+func TestParseReportsBadLinesWithNumbers(t *testing.T) {
+	input := strings.Join([]string{
+		"First task",
+		"",
+		"(A) 2026-01-14",
+		"Pay rent due:someday",
+		"(D) Low-value task",
+		"Last task",
+	}, "\n")
+
+	result, err := Parse(strings.NewReader(input), time.Now())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(result.Tasks) != 3 {
+		t.Errorf("Expected 3 tasks, got %d", len(result.Tasks))
+	}
+	if len(result.Errors) != 2 || result.Errors[0].Line != 3 || result.Errors[1].Line != 4 {
+		t.Errorf("Expected errors on lines 3 and 4, got %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Line != 5 {
+		t.Errorf("Expected a priority warning on line 5, got %v", result.Warnings)
+	}
+	if result.Tasks[1].Description != "Low-value task" || result.Tasks[1].Priority != models.PriorityNone {
+		t.Errorf("Expected (D) task imported without priority, got %+v", result.Tasks[1])
+	}
+}
+
+// TestExportImportRoundTrip tests that descriptions, completion state and
+// completion dates survive a round trip
+func TestExportImportRoundTrip(t *testing.T) {
+	created := time.Date(2026, 1, 14, 10, 30, 0, 0, time.Local)
+	due := time.Date(2026, 2, 1, 23, 59, 59, 0, time.Local)
+	finished := time.Date(2026, 1, 16, 18, 0, 0, 0, time.Local)
+
+	original := []models.Task{
+		{ID: 1, Description: "Plain task", CreatedAt: created},
+		{ID: 2, Description: "Finished task", Completed: true, CreatedAt: created},
+		{ID: 3, Description: "x marks the spot", CreatedAt: created},
+		{ID: 4, Description: "(A) looks like a priority", CreatedAt: created},
+		{ID: 5, Description: "2026-01-01 looks like a date", CreatedAt: created},
+		{ID: 6, Description: "Urgent with due", Priority: models.PriorityHigh, DueDate: &due, CreatedAt: created},
+		{ID: 7, Description: "Done and urgent", Completed: true, Priority: models.PriorityHigh, CreatedAt: created},
+		{ID: 8, Description: "Unicode 学习 Go 语言 +project @home", CreatedAt: created},
+		{ID: 9, Description: "Finished on a day", Completed: true, CompletedAt: &finished, CreatedAt: created},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, original); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\nx 2026-01-16 2026-01-14 Finished on a day\n") {
+		t.Errorf("Expected completion and creation dates after x, got:\n%s", buf.String())
+	}
+
+	result, err := Parse(&buf, time.Now())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(result.Errors) != 0 || len(result.Warnings) != 0 {
+		t.Fatalf("Unexpected issues: errors %v, warnings %v", result.Errors, result.Warnings)
+	}
+	if len(result.Tasks) != len(original) {
+		t.Fatalf("Expected %d tasks, got %d", len(original), len(result.Tasks))
+	}
+
+	for i, task := range result.Tasks {
+		orig := original[i]
+		if task.Description != orig.Description {
+			t.Errorf("Task %d: expected description %q, got %q", orig.ID, orig.Description, task.Description)
+		}
+		if task.Completed != orig.Completed {
+			t.Errorf("Task %d: expected completed %v, got %v", orig.ID, orig.Completed, task.Completed)
+		}
+		if task.Priority != orig.Priority {
+			t.Errorf("Task %d: expected priority %v, got %v", orig.ID, orig.Priority, task.Priority)
+		}
+		if (task.DueDate == nil) != (orig.DueDate == nil) || (task.DueDate != nil && !task.DueDate.Equal(*orig.DueDate)) {
+			t.Errorf("Task %d: expected due %v, got %v", orig.ID, orig.DueDate, task.DueDate)
+		}
+		if (task.CompletedAt == nil) != (orig.CompletedAt == nil) ||
+			(task.CompletedAt != nil && task.CompletedAt.Format(dateLayout) != orig.CompletedAt.Format(dateLayout)) {
+			t.Errorf("Task %d: expected completed at %v, got %v", orig.ID, orig.CompletedAt, task.CompletedAt)
+		}
+	}
+	if got := result.Tasks[8].CreatedAt.Format(dateLayout); got != "2026-01-14" {
+		t.Errorf("Expected the creation date after the completion date to be kept, got %s", got)
+	}
+}
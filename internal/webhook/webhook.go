@@ -0,0 +1,126 @@
+// Package webhook posts task changes to a URL as JSON, for CI pipelines,
+// chat bots and other services that react to them. Each request carries an
+// HMAC-SHA256 signature of its body in the X-Signature header so that the
+// receiver can check it came from someone holding the shared secret.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// SignatureHeader holds the signature of the request body
+const SignatureHeader = "X-Signature"
+
+// DefaultTimeout bounds a delivery when NewNotifier is given no client
+const DefaultTimeout = 5 * time.Second
+
+// PingEvent is the event name of test deliveries, which carry no task
+const PingEvent = "ping"
+
+// TaskEvent is the JSON body of a delivery. Event is "task." followed by
+// the models.EventKind, such as task.added.
+type TaskEvent struct {
+	Event     string       `json:"event"`
+	Task      *models.Task `json:"task,omitempty"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// NewTaskEvent returns the delivery for a change recorded as event, task
+// being the task after the change (or as it was, when deleted)
+func NewTaskEvent(event models.Event, task models.Task) TaskEvent {
+	return TaskEvent{Event: "task." + string(event.Kind), Task: &task, Timestamp: event.Time}
+}
+
+// Notifier posts task events to a URL. Notify delivers in the background;
+// Wait blocks until those deliveries are done, which a short-lived process
+// must call before exiting.
+type Notifier struct {
+	url    string
+	secret string
+	client *http.Client
+	// OnError is called with every failed background delivery; nil ignores them
+	OnError func(error)
+
+	wg sync.WaitGroup
+}
+
+// NewNotifier returns a Notifier posting to url, signed with secret. A nil
+// client means one with DefaultTimeout.
+func NewNotifier(url, secret string, client *http.Client) *Notifier {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Notifier{url: url, secret: secret, client: client}
+}
+
+// URL returns the URL events are posted to
+func (n *Notifier) URL() string {
+	return n.url
+}
+
+// Send posts event and waits for the response. Any status other than 2xx
+// is an error.
+func (n *Notifier) Send(event TaskEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return apperrors.WrapWithContext(err, "failed to encode webhook event")
+	}
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return apperrors.WrapWithContext(err, "invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(n.secret, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return apperrors.WrapWithContext(err, "failed to send "+event.Event+" webhook")
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16)) // Lets the connection be reused
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned %s for %s", n.url, resp.Status, event.Event)
+	}
+	return nil
+}
+
+// Notify sends the delivery for a recorded change in the background,
+// reporting a failure to OnError
+func (n *Notifier) Notify(event models.Event, task models.Task) {
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		if err := n.Send(NewTaskEvent(event, task)); err != nil && n.OnError != nil {
+			n.OnError(err)
+		}
+	}()
+}
+
+// Wait blocks until every delivery started by Notify has finished
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+// Sign returns the X-Signature value for body: "sha256=" followed by the
+// hex HMAC-SHA256 of body keyed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the X-Signature of body for secret
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, body)))
+}
@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todolist/pkg/models"
+)
+
+// TestSend tests the body and signature of a delivery
+func TestSend(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected request %s with %q", r.Method, r.Header.Get("Content-Type"))
+		}
+	}))
+	defer server.Close()
+
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	task := models.Task{ID: 3, Description: "Ship it", Priority: models.PriorityHigh}
+	event := models.NewEvent(models.EventAdded, task, at)
+	n := NewNotifier(server.URL, "s3cret", nil)
+	if err := n.Send(NewTaskEvent(event, task)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	var got struct {
+		Event     string      `json:"event"`
+		Task      models.Task `json:"task"`
+		Timestamp time.Time   `json:"timestamp"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("Invalid body %s: %v", body, err)
+	}
+	if got.Event != "task.added" || got.Task.ID != 3 || got.Task.Description != "Ship it" || !got.Timestamp.Equal(at) {
+		t.Errorf("Unexpected body %s", body)
+	}
+	if !strings.HasPrefix(signature, "sha256=") || !Verify("s3cret", body, signature) || Verify("other", body, signature) {
+		t.Errorf("Unexpected signature %q", signature)
+	}
+}
+
+// TestSendFailures tests that error statuses and unreachable URLs fail
+func TestSendFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	ping := TaskEvent{Event: PingEvent, Timestamp: time.Now()}
+	if err := NewNotifier(server.URL, "", nil).Send(ping); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected a 403 error, got %v", err)
+	}
+	server.Close()
+	if err := NewNotifier(server.URL, "", nil).Send(ping); err == nil {
+		t.Error("Expected an error for a closed server")
+	}
+}
+
+// TestNotify tests that Notify delivers in the background, Wait waits for
+// every delivery and failures reach OnError
+func TestNotify(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var got TaskEvent
+		json.NewDecoder(r.Body).Decode(&got)
+		mu.Lock()
+		events = append(events, got.Event)
+		mu.Unlock()
+		if got.Event == "task.deleted" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	n := NewNotifier(server.URL, "", nil)
+	var failures []error
+	n.OnError = func(err error) {
+		mu.Lock()
+		failures = append(failures, err)
+		mu.Unlock()
+	}
+	task := models.Task{ID: 1, Description: "one"}
+	for _, kind := range []models.EventKind{models.EventAdded, models.EventCompleted, models.EventDeleted} {
+		n.Notify(models.NewEvent(kind, task, time.Now()), task)
+	}
+	n.Wait()
+
+	if len(events) != 3 || len(failures) != 1 {
+		t.Errorf("Expected 3 deliveries and 1 failure, got %v and %v", events, failures)
+	}
+}
@@ -0,0 +1,290 @@
+// Package errors defines the sentinel errors returned by the todo list
+// packages, helpers to wrap them with context and to test for them with
+// errors.Is, and the process exit code for each error class.
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Core error types as defined in the design document
+
+// Business logic errors
+var (
+	ErrEmptyDescription = errors.New("task description cannot be empty")
+	ErrTaskNotFound     = errors.New("task not found")
+	ErrInvalidID        = errors.New("invalid task ID")
+	ErrNothingToUndo    = errors.New("nothing to undo")
+	// ErrUndoConflict refuses to undo a change when the tasks were changed
+	// since in a way the undo history did not record
+	ErrUndoConflict      = errors.New("the task list changed since the last recorded change; undo would discard those changes")
+	ErrInvalidPagination = errors.New("offset and limit must not be negative")
+	ErrInvalidDateRange  = errors.New("start of date range is after its end")
+	ErrInvalidRecurrence = errors.New("invalid recurrence")
+	ErrNoActiveTimer     = errors.New("no active timer")
+	ErrTimerRunning      = errors.New("timer already running")
+	// ErrDuplicateDescription rejects a task whose description another task
+	// already has, when duplicates are turned off
+	ErrDuplicateDescription = errors.New("a task with this description already exists")
+	// ErrDescriptionTooLong rejects a description over the length limit
+	ErrDescriptionTooLong = errors.New("task description is too long")
+	// ErrMaxTasksReached rejects an add that would take the list over the
+	// configured task limit
+	ErrMaxTasksReached = errors.New("maximum number of tasks reached")
+	// ErrIDsReferenced refuses to renumber tasks whose IDs something
+	// outside the list still refers to
+	ErrIDsReferenced = errors.New("task IDs are still referenced")
+	// ErrTasksOverdue is returned by the due command when overdue tasks
+	// exist, so scripts can test for them through the exit code
+	ErrTasksOverdue = errors.New("tasks are overdue")
+)
+
+// Storage errors
+var (
+	ErrStorageRead  = errors.New("failed to read from storage")
+	ErrStorageWrite = errors.New("failed to write to storage")
+	ErrInvalidJSON  = errors.New("invalid JSON format")
+	ErrNoBackup     = errors.New("no backup available")
+	// ErrInvalidTaskList marks a list breaking an invariant, refused by Save
+	ErrInvalidTaskList = errors.New("invalid task list")
+	// ErrReadOnlyStorage marks a task file that cannot be written, either
+	// detected or requested
+	ErrReadOnlyStorage = errors.New("task file is read-only")
+	// ErrStorageCorrupted marks a temporary file left by a save that never
+	// finished, so the task file may not hold the last change
+	ErrStorageCorrupted = errors.New("storage left in an inconsistent state")
+	// ErrUnsupportedVersion marks data saved in a newer format than this build reads
+	ErrUnsupportedVersion = errors.New("unsupported storage format version")
+	// ErrConcurrentModification marks a save refused because another
+	// process changed the file after it was read
+	ErrConcurrentModification = errors.New("the todo file changed since it was read; re-run the command")
+	// ErrNotRecorded marks a change that was saved but could not be
+	// recorded in the undo history or the event log
+	ErrNotRecorded = errors.New("change saved but not recorded")
+)
+
+// CLI errors
+var (
+	ErrInvalidCommand = errors.New("invalid command")
+)
+
+// TaskNotFoundError reports a missing task by ID. It matches
+// ErrTaskNotFound with errors.Is.
+type TaskNotFoundError struct {
+	ID int
+}
+
+// Error formats the error with the missing ID
+func (e *TaskNotFoundError) Error() string {
+	return fmt.Sprintf("task %d not found", e.ID)
+}
+
+// Is makes errors.Is(err, ErrTaskNotFound) hold for TaskNotFoundError
+func (e *TaskNotFoundError) Is(target error) bool {
+	return target == ErrTaskNotFound
+}
+
+// Error wrapping utilities for adding context
+
+// WrapWithContext wraps an error with additional context information
+func WrapWithContext(err error, context string) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", context, err)
+}
+
+// WrapStorageReadError wraps a storage read error with context
+func WrapStorageReadError(err error, filepath string) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("failed to read from storage at %s: %w", filepath, err)
+}
+
+// WrapStorageWriteError wraps a storage write error with context
+func WrapStorageWriteError(err error, filepath string) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("failed to write to storage at %s: %w", filepath, err)
+}
+
+// WrapJSONError wraps a JSON parsing error with context
+func WrapJSONError(err error, filepath string) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("invalid JSON format in %s: %w", filepath, err)
+}
+
+// WrapCommandError wraps a command execution error with context
+func WrapCommandError(err error, command string) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("command '%s' failed: %w", command, err)
+}
+
+// IsTaskNotFound checks if an error is ErrTaskNotFound
+func IsTaskNotFound(err error) bool {
+	return errors.Is(err, ErrTaskNotFound)
+}
+
+// IsInvalidID checks if an error is ErrInvalidID
+func IsInvalidID(err error) bool {
+	return errors.Is(err, ErrInvalidID)
+}
+
+// IsEmptyDescription checks if an error is ErrEmptyDescription
+func IsEmptyDescription(err error) bool {
+	return errors.Is(err, ErrEmptyDescription)
+}
+
+// IsDuplicateDescription checks if an error is ErrDuplicateDescription
+func IsDuplicateDescription(err error) bool {
+	return errors.Is(err, ErrDuplicateDescription)
+}
+
+// IsDescriptionTooLong checks if an error is ErrDescriptionTooLong
+func IsDescriptionTooLong(err error) bool {
+	return errors.Is(err, ErrDescriptionTooLong)
+}
+
+// IsMaxTasksReached checks if an error is ErrMaxTasksReached
+func IsMaxTasksReached(err error) bool {
+	return errors.Is(err, ErrMaxTasksReached)
+}
+
+// IsIDsReferenced checks if an error is ErrIDsReferenced
+func IsIDsReferenced(err error) bool {
+	return errors.Is(err, ErrIDsReferenced)
+}
+
+// IsInvalidDateRange checks if an error is ErrInvalidDateRange
+func IsInvalidDateRange(err error) bool {
+	return errors.Is(err, ErrInvalidDateRange)
+}
+
+// IsNothingToUndo checks if an error is ErrNothingToUndo
+func IsNothingToUndo(err error) bool {
+	return errors.Is(err, ErrNothingToUndo)
+}
+
+// IsUndoConflict checks if an error is ErrUndoConflict
+func IsUndoConflict(err error) bool {
+	return errors.Is(err, ErrUndoConflict)
+}
+
+// IsInvalidPagination checks if an error is ErrInvalidPagination
+func IsInvalidPagination(err error) bool {
+	return errors.Is(err, ErrInvalidPagination)
+}
+
+// IsNoBackup checks if an error is ErrNoBackup
+func IsNoBackup(err error) bool {
+	return errors.Is(err, ErrNoBackup)
+}
+
+// IsInvalidRecurrence checks if an error is ErrInvalidRecurrence
+func IsInvalidRecurrence(err error) bool {
+	return errors.Is(err, ErrInvalidRecurrence)
+}
+
+// IsNoActiveTimer checks if an error is ErrNoActiveTimer
+func IsNoActiveTimer(err error) bool {
+	return errors.Is(err, ErrNoActiveTimer)
+}
+
+// IsTimerRunning checks if an error is ErrTimerRunning
+func IsTimerRunning(err error) bool {
+	return errors.Is(err, ErrTimerRunning)
+}
+
+// IsTasksOverdue checks if an error is ErrTasksOverdue
+func IsTasksOverdue(err error) bool {
+	return errors.Is(err, ErrTasksOverdue)
+}
+
+// IsStorageError checks if an error is a storage-related error
+func IsStorageError(err error) bool {
+	return errors.Is(err, ErrStorageRead) || errors.Is(err, ErrStorageWrite)
+}
+
+// IsInvalidJSON checks if an error is ErrInvalidJSON
+func IsInvalidJSON(err error) bool {
+	return errors.Is(err, ErrInvalidJSON)
+}
+
+// IsInvalidTaskList checks if an error is ErrInvalidTaskList
+func IsInvalidTaskList(err error) bool {
+	return errors.Is(err, ErrInvalidTaskList)
+}
+
+// IsReadOnlyStorage checks if an error is ErrReadOnlyStorage
+func IsReadOnlyStorage(err error) bool {
+	return errors.Is(err, ErrReadOnlyStorage)
+}
+
+// IsStorageCorrupted checks if an error is ErrStorageCorrupted
+func IsStorageCorrupted(err error) bool {
+	return errors.Is(err, ErrStorageCorrupted)
+}
+
+// IsConcurrentModification checks if an error is ErrConcurrentModification
+func IsConcurrentModification(err error) bool {
+	return errors.Is(err, ErrConcurrentModification)
+}
+
+// IsNotRecorded checks if an error is ErrNotRecorded
+func IsNotRecorded(err error) bool {
+	return errors.Is(err, ErrNotRecorded)
+}
+
+// IsUnsupportedVersion checks if an error is ErrUnsupportedVersion
+func IsUnsupportedVersion(err error) bool {
+	return errors.Is(err, ErrUnsupportedVersion)
+}
+
+// IsInvalidCommand checks if an error is ErrInvalidCommand
+func IsInvalidCommand(err error) bool {
+	return errors.Is(err, ErrInvalidCommand)
+}
+
+// Process exit codes for each error class
+const (
+	ExitOK       = 0
+	ExitFailure  = 1
+	ExitUsage    = 2
+	ExitNotFound = 3
+	ExitStorage  = 4
+	ExitCorrupt  = 5
+	ExitOverdue  = 6
+)
+
+// ExitCode maps an error to the process exit code for its class: 0 for nil,
+// 2 for usage and parse errors, 3 for missing tasks, 4 for storage errors
+// (including a file changed by another process), 5 for invalid JSON,
+// corrupt data (including a list that fails validation or a leftover
+// temporary file) or a newer storage format, 6 when due finds overdue
+// tasks and 1 for anything else
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case IsTaskNotFound(err):
+		return ExitNotFound
+	case IsTasksOverdue(err):
+		return ExitOverdue
+	case IsInvalidJSON(err), IsUnsupportedVersion(err), IsInvalidTaskList(err), IsStorageCorrupted(err):
+		return ExitCorrupt
+	case IsStorageError(err), IsNoBackup(err), IsReadOnlyStorage(err), IsConcurrentModification(err):
+		return ExitStorage
+	case IsInvalidCommand(err), IsInvalidID(err), IsEmptyDescription(err), IsDuplicateDescription(err), IsDescriptionTooLong(err), IsMaxTasksReached(err),
+		IsInvalidPagination(err), IsInvalidDateRange(err), IsInvalidRecurrence(err):
+		return ExitUsage
+	default:
+		return ExitFailure
+	}
+}
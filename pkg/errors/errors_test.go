@@ -0,0 +1,130 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestExitCode tests the exit code mapping, including wrapped errors
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"unknown", errors.New("boom"), ExitFailure},
+		{"invalid command", WrapCommandError(ErrInvalidCommand, "add"), ExitUsage},
+		{"invalid ID", WrapCommandError(ErrInvalidID, "done"), ExitUsage},
+		{"empty description", WrapCommandError(ErrEmptyDescription, "add"), ExitUsage},
+		{"duplicate description", WrapCommandError(ErrDuplicateDescription, "add"), ExitUsage},
+		{"max tasks reached", WrapCommandError(ErrMaxTasksReached, "add"), ExitUsage},
+		{"invalid date range", WrapCommandError(ErrInvalidDateRange, "list"), ExitUsage},
+		{"invalid pagination", WrapCommandError(ErrInvalidPagination, "list"), ExitUsage},
+		{"invalid recurrence", WrapCommandError(fmt.Errorf("%w: \"hourly\"", ErrInvalidRecurrence), "add"), ExitUsage},
+		{"task not found", WrapCommandError(&TaskNotFoundError{ID: 42}, "done"), ExitNotFound},
+		{"bulk not found", WrapCommandError(fmt.Errorf("2 of 3 tasks failed: %w; %w", &TaskNotFoundError{ID: 4}, &TaskNotFoundError{ID: 5}), "done"), ExitNotFound},
+		{"storage write", WrapCommandError(WrapWithContext(WrapStorageWriteError(errors.Join(ErrStorageWrite, errors.New("disk full")), "/tmp/x.json"), "failed to save"), "add"), ExitStorage},
+		{"storage read", WrapStorageReadError(errors.Join(ErrStorageRead, errors.New("denied")), "/tmp/x.json"), ExitStorage},
+		{"no backup", WrapCommandError(ErrNoBackup, "restore"), ExitStorage},
+		{"invalid JSON", WrapCommandError(WrapJSONError(errors.Join(ErrInvalidJSON, errors.New("bad")), "/tmp/x.json"), "restore"), ExitCorrupt},
+		{"newer format", WrapWithContext(fmt.Errorf("x.json: %w", ErrUnsupportedVersion), "failed to initialize todo list"), ExitCorrupt},
+		{"read-only", WrapCommandError(WrapStorageWriteError(ErrReadOnlyStorage, "x.json"), "add"), ExitStorage},
+		{"concurrent modification", WrapCommandError(WrapStorageWriteError(ErrConcurrentModification, "x.json"), "done"), ExitStorage},
+		{"leftover temp file", WrapStorageReadError(fmt.Errorf("%w: x.json.tmp", ErrStorageCorrupted), "x.json"), ExitCorrupt},
+		{"overdue tasks", WrapCommandError(fmt.Errorf("%w: 2 of them", ErrTasksOverdue), "due"), ExitOverdue},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ExitCode(tc.err); got != tc.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestTaskNotFoundErrorMatchesSentinel tests that errors.Is still sees ErrTaskNotFound
+func TestTaskNotFoundErrorMatchesSentinel(t *testing.T) {
+	err := WrapCommandError(&TaskNotFoundError{ID: 7}, "delete")
+	if !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected %v to match ErrTaskNotFound", err)
+	}
+	if errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected %v not to match ErrInvalidID", err)
+	}
+}
+
+// sentinelName matches the names of the sentinel error variables
+var sentinelName = regexp.MustCompile(`^Err[A-Z]`)
+
+// TestNoSentinelEquality tests that no Go source compares an error with a
+// sentinel using == or !=, which stops matching once the error is wrapped.
+// Is methods implementing errors.Is are the exception.
+func TestNoSentinelEquality(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatalf("Failed to resolve module root: %v", err)
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && fn.Name.Name == "Is" {
+				continue
+			}
+			ast.Inspect(decl, func(n ast.Node) bool {
+				expr, ok := n.(*ast.BinaryExpr)
+				if !ok || (expr.Op != token.EQL && expr.Op != token.NEQ) {
+					return true
+				}
+				if isSentinel(expr.X) || isSentinel(expr.Y) {
+					t.Errorf("%s: compare errors with errors.Is or an apperrors.Is* helper, not %s", fset.Position(expr.OpPos), expr.Op)
+				}
+				return true
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to scan sources: %v", err)
+	}
+}
+
+// isSentinel reports whether expr names a sentinel error such as ErrTaskNotFound
+// or apperrors.ErrTaskNotFound
+func isSentinel(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return sentinelName.MatchString(e.Name)
+	case *ast.SelectorExpr:
+		return sentinelName.MatchString(e.Sel.Name)
+	default:
+		return false
+	}
+}
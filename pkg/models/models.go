@@ -0,0 +1,282 @@
+// Package models defines the task and task list types and their JSON
+// storage format.
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+	apperrors "todolist/pkg/errors"
+)
+
+// FormatVersion is the version of the storage format and of the JSON
+// Schema describing it. Bump it whenever the serialized shape changes,
+// adding a migration from the previous version in the storage package.
+const FormatVersion = 1
+
+// Task represents a single todo item
+type Task struct {
+	ID          int         `json:"id"`
+	UID         string      `json:"uid,omitempty"`
+	Description string      `json:"description"`
+	Completed   bool        `json:"completed"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	DueDate     *time.Time  `json:"due_date,omitempty"`
+	Priority    Priority    `json:"priority,omitempty"`
+	Notes       string      `json:"notes,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Recurrence  string      `json:"recurrence,omitempty"`
+	TimeEntries []TimeEntry `json:"time_entries,omitempty"`
+	// EstimateMinutes is the estimated effort in minutes; 0 means none
+	EstimateMinutes int `json:"estimate_minutes,omitempty"`
+}
+
+// NewUID returns a random version 4 UUID. Unlike the ID, a task keeps its
+// UID when the list is renumbered, merged or imported elsewhere.
+func NewUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// IsUIDPrefix reports whether s could be the start of a UID: one or more
+// hexadecimal digits and dashes
+func IsUIDPrefix(s string) bool {
+	return s != "" && strings.Trim(strings.ToLower(s), "0123456789abcdef-") == ""
+}
+
+// UIDRefPrefix marks a task reference as a UID prefix of any length, as in
+// uid:3f2a
+const UIDRefPrefix = "uid:"
+
+// MinUIDPrefixLength is the length a UID prefix needs to be taken for one
+// without UIDRefPrefix, so that words made of hex letters, such as "ad" or
+// "face", are not
+const MinUIDPrefixLength = 8
+
+// ParseUIDRef returns the UID prefix a task reference names and whether it
+// names one: the part after UIDRefPrefix, or the reference itself when it
+// is a UID prefix of at least MinUIDPrefixLength characters
+func ParseUIDRef(ref string) (string, bool) {
+	if HasUIDRefPrefix(ref) {
+		prefix := ref[len(UIDRefPrefix):]
+		return prefix, IsUIDPrefix(prefix)
+	}
+	return ref, len(ref) >= MinUIDPrefixLength && IsUIDPrefix(ref)
+}
+
+// HasUIDRefPrefix reports whether ref starts with UIDRefPrefix, ignoring case
+func HasUIDRefPrefix(ref string) bool {
+	return len(ref) >= len(UIDRefPrefix) && strings.EqualFold(ref[:len(UIDRefPrefix)], UIDRefPrefix)
+}
+
+// TimeEntry is one stretch of time spent on a task
+type TimeEntry struct {
+	Start time.Time `json:"start"`
+	// End is nil while the timer is running
+	End *time.Time `json:"end,omitempty"`
+}
+
+// Priority ranks how important a task is; higher values are more important
+type Priority int
+
+// Task priorities
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+// priorityNames maps priorities to their command-line names
+var priorityNames = map[Priority]string{
+	PriorityNone:   "none",
+	PriorityLow:    "low",
+	PriorityMedium: "medium",
+	PriorityHigh:   "high",
+}
+
+// String returns the command-line name of the priority
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParsePriority converts a priority name (none, low, medium, high) to a Priority
+func ParsePriority(name string) (Priority, bool) {
+	for p, n := range priorityNames {
+		if n == name {
+			return p, true
+		}
+	}
+	return PriorityNone, false
+}
+
+// IsOverdue reports whether the task is pending and its due date is before now
+func (t Task) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.DueDate != nil && t.DueDate.Before(now)
+}
+
+// DueWithin reports whether the task is pending and due at or after now but
+// less than d from now. Overdue tasks are not due within any window.
+func (t Task) DueWithin(now time.Time, d time.Duration) bool {
+	return !t.Completed && t.DueDate != nil && !t.DueDate.Before(now) && t.DueDate.Before(now.Add(d))
+}
+
+// TimeSpent returns the total time recorded on the task, counting a
+// running timer up to now
+func (t Task) TimeSpent(now time.Time) time.Duration {
+	var total time.Duration
+	for _, entry := range t.TimeEntries {
+		end := now
+		if entry.End != nil {
+			end = *entry.End
+		}
+		total += end.Sub(entry.Start)
+	}
+	return total
+}
+
+// TrackingTime reports whether the task has a running timer
+func (t Task) TrackingTime() bool {
+	for _, entry := range t.TimeEntries {
+		if entry.End == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskList represents the collection of tasks
+type TaskList struct {
+	// Version is the format version the list was saved with; files from
+	// before versions were recorded have none and read as 0
+	Version int    `json:"version"`
+	Tasks   []Task `json:"tasks"`
+	NextID  int    `json:"next_id"`
+	// Trash holds deleted tasks until they are restored or purged, oldest
+	// deletion first
+	Trash []TrashedTask `json:"trash,omitempty"`
+}
+
+// TrashedTask is a deleted task kept in the trash. Task keeps the ID it
+// had when deleted.
+type TrashedTask struct {
+	Task      Task      `json:"task"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Validate checks the invariants every saved list must hold: Tasks is not
+// nil, task IDs are positive and unique, and NextID is above all of them,
+// so the next task added cannot reuse an ID. Violations match
+// ErrInvalidTaskList.
+func (l *TaskList) Validate() error {
+	if l.Tasks == nil {
+		return fmt.Errorf("%w: tasks is nil", apperrors.ErrInvalidTaskList)
+	}
+	maxID := 0
+	seen := make(map[int]bool, len(l.Tasks))
+	for _, task := range l.Tasks {
+		if task.ID <= 0 {
+			return fmt.Errorf("%w: task ID %d is not positive", apperrors.ErrInvalidTaskList, task.ID)
+		}
+		if seen[task.ID] {
+			return fmt.Errorf("%w: task ID %d is used more than once", apperrors.ErrInvalidTaskList, task.ID)
+		}
+		seen[task.ID] = true
+		maxID = max(maxID, task.ID)
+	}
+	if l.NextID <= maxID || l.NextID <= 0 {
+		return fmt.Errorf("%w: next ID %d must be positive and above the highest task ID %d", apperrors.ErrInvalidTaskList, l.NextID, maxID)
+	}
+	return nil
+}
+
+// TaskStats summarizes the state of a task list
+type TaskStats struct {
+	Total        int `json:"total"`
+	Pending      int `json:"pending"`
+	Completed    int `json:"completed"`
+	OverdueCount int `json:"overdue_count"`
+	// Estimated minutes summed over all tasks and over completed ones
+	TotalEstimatedMinutes     int `json:"total_estimated_minutes"`
+	CompletedEstimatedMinutes int `json:"completed_estimated_minutes"`
+}
+
+// CompletionTrend compares the completions of the current week so far
+// with the weekly average of the weeks before it
+type CompletionTrend struct {
+	ThisWeek int `json:"this_week"`
+	// Average is the mean number of completions per week over the
+	// trailing weeks, weeks without any counting as 0
+	Average float64 `json:"average"`
+	// Change is the percentage ThisWeek differs from Average by; nil when
+	// Average is 0, where no percentage would mean anything
+	Change *float64 `json:"change,omitempty"`
+	// BacklogGrowing is set when the pending count grew in each of the
+	// last three full weeks
+	BacklogGrowing bool `json:"backlog_growing"`
+}
+
+// EventKind names a change recorded in the event log
+type EventKind string
+
+// Event kinds
+const (
+	EventAdded     EventKind = "added"
+	EventCompleted EventKind = "completed"
+	EventDeleted   EventKind = "deleted"
+)
+
+// Event records a change to one task. The description and priority are
+// copied so reports can group tasks that have since been deleted.
+type Event struct {
+	Time        time.Time `json:"time"`
+	Kind        EventKind `json:"kind"`
+	TaskID      int       `json:"task_id"`
+	Description string    `json:"description"`
+	Priority    Priority  `json:"priority,omitempty"`
+}
+
+// NewEvent records kind happening to task at t
+func NewEvent(kind EventKind, task Task, t time.Time) Event {
+	return Event{Time: t, Kind: kind, TaskID: task.ID, Description: task.Description, Priority: task.Priority}
+}
+
+// Period is a span of time from Start up to but not including End
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Contains reports whether t falls within the period
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// ReportGroup counts the changes to one group of tasks in a report
+type ReportGroup struct {
+	Name      string `json:"name"`
+	Added     int    `json:"added"`
+	Completed int    `json:"completed"`
+	Deleted   int    `json:"deleted"`
+}
+
+// WeeklyReport summarizes the changes made to a task list in one week,
+// overall and broken down by project and by priority
+type WeeklyReport struct {
+	Period         Period        `json:"period"`
+	TasksAdded     int           `json:"tasks_added"`
+	TasksCompleted int           `json:"tasks_completed"`
+	TasksDeleted   int           `json:"tasks_deleted"`
+	Projects       []ReportGroup `json:"projects"`
+	Priorities     []ReportGroup `json:"priorities"`
+	// Trend compares the week's completions with the weeks before it
+	Trend CompletionTrend `json:"trend"`
+}
@@ -0,0 +1,257 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+	apperrors "todolist/pkg/errors"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// Sample property test to verify gopter is working
+func TestPropertyFramework(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("sample property: string length is non-negative", prop.ForAll(
+		func(s string) bool {
+			return len(s) >= 0
+		},
+		gen.AnyString(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 4: 任务包含创建时间
+// Validates: Requirements 1.4
+func TestProperty_TaskContainsCreationTime(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("任务包含有效的创建时间戳", prop.ForAll(
+		func(id int, description string) bool {
+			// Record the time before creating the task
+			beforeTime := time.Now()
+
+			// Create a task
+			task := Task{
+				ID:          id,
+				Description: description,
+				Completed:   false,
+				CreatedAt:   time.Now(),
+			}
+
+			// Record the time after creating the task
+			afterTime := time.Now()
+
+			// Verify that CreatedAt is not zero
+			if task.CreatedAt.IsZero() {
+				return false
+			}
+
+			// Verify that CreatedAt is within reasonable bounds
+			// (between beforeTime and afterTime, with small tolerance for clock precision)
+			tolerance := 100 * time.Millisecond
+			if task.CreatedAt.Before(beforeTime.Add(-tolerance)) {
+				return false
+			}
+			if task.CreatedAt.After(afterTime.Add(tolerance)) {
+				return false
+			}
+
+			return true
+		},
+		gen.Int(),
+		gen.AnyString(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// TestDueWithin tests the due-soon window boundaries
+func TestDueWithin(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		due := now.Add(d)
+		return &due
+	}
+
+	testCases := []struct {
+		name string
+		task Task
+		want bool
+	}{
+		{"no due date", Task{}, false},
+		{"due now", Task{DueDate: at(0)}, true},
+		{"due inside window", Task{DueDate: at(59 * time.Minute)}, true},
+		{"due at window end", Task{DueDate: at(time.Hour)}, false},
+		{"overdue", Task{DueDate: at(-time.Minute)}, false},
+		{"completed", Task{DueDate: at(time.Minute), Completed: true}, false},
+	}
+	for _, tc := range testCases {
+		if got := tc.task.DueWithin(now, time.Hour); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+// validList builds a list that holds every invariant: IDs increase by the
+// given gaps (at least two tasks) and NextID is extra above the last one
+func validList(gaps []int, extra int) *TaskList {
+	list := &TaskList{Tasks: []Task{}}
+	id := 0
+	for _, gap := range append([]int{1, 1}, gaps...) {
+		id += gap
+		list.Tasks = append(list.Tasks, Task{ID: id, Description: "task"})
+	}
+	list.NextID = id + extra
+	return list
+}
+
+// genGaps and genExtra drive validList
+var (
+	genGaps  = gen.SliceOf(gen.IntRange(1, 100))
+	genExtra = gen.IntRange(1, 100)
+)
+
+// TestNewUID tests that UIDs are distinct version 4 UUIDs that IsUIDPrefix
+// accepts
+func TestNewUID(t *testing.T) {
+	uid := NewUID()
+	if len(uid) != 36 || uid[14] != '4' || !strings.ContainsRune("89ab", rune(uid[19])) {
+		t.Errorf("Expected a version 4 UUID, got %q", uid)
+	}
+	if !IsUIDPrefix(uid) || !IsUIDPrefix(uid[:4]) || !IsUIDPrefix(strings.ToUpper(uid)) {
+		t.Errorf("Expected %q and its prefixes to be accepted", uid)
+	}
+	if NewUID() == uid {
+		t.Error("Expected a different UID on every call")
+	}
+	for _, s := range []string{"", "xyz", "3f2a g", "12.5"} {
+		if IsUIDPrefix(s) {
+			t.Errorf("Expected IsUIDPrefix(%q) to be false", s)
+		}
+	}
+}
+
+// TestParseUIDRef tests that bare UID prefixes need MinUIDPrefixLength
+// characters and uid: prefixes any
+func TestParseUIDRef(t *testing.T) {
+	testCases := []struct {
+		ref    string
+		prefix string
+		ok     bool
+	}{
+		{"3f2a9c01", "3f2a9c01", true},
+		{"uid:3f2a", "3f2a", true},
+		{"UID:ad", "ad", true},
+		{"ad", "ad", false},
+		{"face", "face", false},
+		{"uid:", "", false},
+		{"uid:milk", "milk", false},
+		{"3f2a9c0g", "3f2a9c0g", false},
+	}
+	for _, tc := range testCases {
+		prefix, ok := ParseUIDRef(tc.ref)
+		if prefix != tc.prefix || ok != tc.ok {
+			t.Errorf("ParseUIDRef(%q) = %q, %v; expected %q, %v", tc.ref, prefix, ok, tc.prefix, tc.ok)
+		}
+	}
+}
+
+// TestValidateNilTasks tests that a list without a task slice is invalid
+func TestValidateNilTasks(t *testing.T) {
+	if err := (&TaskList{NextID: 1}).Validate(); !apperrors.IsInvalidTaskList(err) {
+		t.Errorf("Expected ErrInvalidTaskList, got %v", err)
+	}
+	if err := (&TaskList{Tasks: []Task{}, NextID: 1}).Validate(); err != nil {
+		t.Errorf("Expected an empty list to be valid, got %v", err)
+	}
+}
+
+// Feature: todo-list-cli, Property 19: 满足所有约束的任务列表通过校验
+// For any list with positive, unique IDs and NextID above all of them,
+// Validate returns nil
+// Validates: TaskList invariants
+func TestProperty_ValidListPasses(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("valid lists pass", prop.ForAll(
+		func(gaps []int, extra int) bool {
+			return validList(gaps, extra).Validate() == nil
+		},
+		genGaps, genExtra,
+	))
+
+	properties.TestingRun(t)
+}
+
+// Feature: todo-list-cli, Property 20: 非正的任务 ID 被拒绝
+// For any valid list, giving any task a zero or negative ID makes
+// Validate fail with ErrInvalidTaskList
+// Validates: TaskList invariants
+func TestProperty_NonPositiveIDRejected(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("non-positive IDs are rejected", prop.ForAll(
+		func(gaps []int, extra, pick, id int) bool {
+			list := validList(gaps, extra)
+			list.Tasks[pick%len(list.Tasks)].ID = id
+			return apperrors.IsInvalidTaskList(list.Validate())
+		},
+		genGaps, genExtra, gen.IntRange(0, 1000), gen.IntRange(-1000, 0),
+	))
+
+	properties.TestingRun(t)
+}
+
+// Feature: todo-list-cli, Property 21: 重复的任务 ID 被拒绝
+// For any valid list, copying one task's ID onto another task makes
+// Validate fail with ErrInvalidTaskList
+// Validates: TaskList invariants
+func TestProperty_DuplicateIDRejected(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("duplicate IDs are rejected", prop.ForAll(
+		func(gaps []int, extra, from, offset int) bool {
+			list := validList(gaps, extra)
+			n := len(list.Tasks)
+			i := from % n
+			j := (i + 1 + offset%(n-1)) % n // any task other than i
+			list.Tasks[j].ID = list.Tasks[i].ID
+			return apperrors.IsInvalidTaskList(list.Validate())
+		},
+		genGaps, genExtra, gen.IntRange(0, 1000), gen.IntRange(0, 1000),
+	))
+
+	properties.TestingRun(t)
+}
+
+// Feature: todo-list-cli, Property 22: NextID 不大于最大任务 ID 时被拒绝
+// For any valid list, lowering NextID to the highest task ID or below
+// makes Validate fail with ErrInvalidTaskList
+// Validates: TaskList invariants
+func TestProperty_LowNextIDRejected(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("NextID at or below the highest ID is rejected", prop.ForAll(
+		func(gaps []int, extra, below int) bool {
+			list := validList(gaps, extra)
+			list.NextID = list.Tasks[len(list.Tasks)-1].ID - below
+			return apperrors.IsInvalidTaskList(list.Validate())
+		},
+		genGaps, genExtra, gen.IntRange(0, 10000),
+	))
+
+	properties.TestingRun(t)
+}
@@ -0,0 +1,287 @@
+// Package quickadd parses inline tokens out of a one-line task, so that
+// "pay rent due:fri !high #home" can be typed without flags. It is a pure
+// function with no I/O, suitable for launchers that re-parse on every
+// keystroke and highlight the recognized tokens.
+//
+// Recognized tokens are whole whitespace-separated words:
+//
+//	due:<when>   due date: YYYY-MM-DD, YYYY-MM-DDTHH:MM, today, tomorrow,
+//	             a weekday (mon or monday, today or later) or +Nd / +Nw
+//	!<priority>  high, medium or low, or h, m, l
+//	#tag         tag: letters, digits, '-', '_' or '/', not all digits
+//	+project     project: as a tag, starting with a letter
+//	@context     context: as a project
+//
+// Due dates without a time mean the end of that day, like `add --due`.
+// Due and priority tokens are removed from the description; tags,
+// projects and contexts stay in it, as in todo.txt, and are also listed
+// separately. This package is the only definition of a tag: todolist
+// moves #tag words out of every stored description with Tag, before
+// Parse sees them, so `add --parse` stores the same tags as `add`.
+// A leading backslash escapes a word (\#notATag), and words
+// that only look similar ("#12", "+1", "wow!", "bob@example.com") are
+// plain text.
+package quickadd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"unicode"
+)
+
+// Kind identifies what a recognized token sets
+type Kind string
+
+// Token kinds
+const (
+	KindDue      Kind = "due"
+	KindPriority Kind = "priority"
+	KindTag      Kind = "tag"
+	KindProject  Kind = "project"
+	KindContext  Kind = "context"
+)
+
+// Token is a recognized token and where it appears in the input
+type Token struct {
+	Kind Kind
+	// Value is the token without its prefix, e.g. "home" for #home
+	Value string
+	// Start and End are byte offsets of the token in the input; End is exclusive
+	Start, End int
+}
+
+// Result is the outcome of parsing one line
+type Result struct {
+	// Description is the input without due and priority tokens or escapes,
+	// with runs of whitespace collapsed. It may be empty.
+	Description string
+	// Due is the due date, nil if no due: token was given
+	Due *time.Time
+	// Priority is PriorityNone if no priority token was given
+	Priority models.Priority
+	// Tags, Projects and Contexts list each distinct name once, in order
+	Tags     []string
+	Projects []string
+	Contexts []string
+	// Tokens lists every recognized token in input order
+	Tokens []Token
+}
+
+// priorities maps the accepted priority words to priorities
+var priorities = map[string]models.Priority{
+	"high":   models.PriorityHigh,
+	"h":      models.PriorityHigh,
+	"medium": models.PriorityMedium,
+	"m":      models.PriorityMedium,
+	"low":    models.PriorityLow,
+	"l":      models.PriorityLow,
+}
+
+// Parse extracts the inline tokens from input. now anchors relative due
+// dates and its location is used for absolute ones. It fails with
+// ErrInvalidCommand for an unreadable due date or a repeated due date or
+// priority, since guessing which one was meant would be worse.
+func Parse(input string, now time.Time) (Result, error) {
+	var result Result
+	var words []string
+
+	for _, w := range splitWords(input) {
+		if strings.HasPrefix(w.text, `\`) && len(w.text) > 1 {
+			// Escaped: keep the word without its backslash
+			words = append(words, w.text[1:])
+			continue
+		}
+
+		kind, value, ok := classify(w.text)
+		if !ok {
+			words = append(words, w.text)
+			continue
+		}
+
+		switch kind {
+		case KindDue:
+			if result.Due != nil {
+				return Result{}, fmt.Errorf("%w: due date given twice (%q)", apperrors.ErrInvalidCommand, w.text)
+			}
+			due, err := parseDue(value, now)
+			if err != nil {
+				return Result{}, err
+			}
+			result.Due = &due
+		case KindPriority:
+			if result.Priority != models.PriorityNone {
+				return Result{}, fmt.Errorf("%w: priority given twice (%q)", apperrors.ErrInvalidCommand, w.text)
+			}
+			result.Priority = priorities[strings.ToLower(value)]
+		case KindTag:
+			result.Tags = appendUnique(result.Tags, value)
+			words = append(words, w.text)
+		case KindProject:
+			result.Projects = appendUnique(result.Projects, value)
+			words = append(words, w.text)
+		case KindContext:
+			result.Contexts = appendUnique(result.Contexts, value)
+			words = append(words, w.text)
+		}
+		result.Tokens = append(result.Tokens, Token{Kind: kind, Value: value, Start: w.start, End: w.start + len(w.text)})
+	}
+
+	result.Description = strings.Join(words, " ")
+	return result, nil
+}
+
+// word is a whitespace-separated word and its byte offset in the input
+type word struct {
+	text  string
+	start int
+}
+
+// splitWords splits s at whitespace, remembering where each word starts
+func splitWords(s string) []word {
+	var words []word
+	start := -1
+	for i, r := range s {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				words = append(words, word{text: s[start:i], start: start})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, word{text: s[start:], start: start})
+	}
+	return words
+}
+
+// classify reports which kind of token w is, if any, and its value
+func classify(w string) (Kind, string, bool) {
+	if value, ok := strings.CutPrefix(w, "due:"); ok {
+		return KindDue, value, value != ""
+	}
+	if len(w) < 2 {
+		return "", "", false
+	}
+
+	value := w[1:]
+	switch w[0] {
+	case '!':
+		_, ok := priorities[strings.ToLower(value)]
+		return KindPriority, value, ok
+	case '#':
+		return KindTag, value, isName(value) && strings.IndexFunc(value, isNotDigit) >= 0
+	case '+':
+		return KindProject, value, isName(value) && unicode.IsLetter(firstRune(value))
+	case '@':
+		return KindContext, value, isName(value) && unicode.IsLetter(firstRune(value))
+	}
+	return "", "", false
+}
+
+// isName reports whether s only holds characters allowed in tag, project
+// and context names
+func isName(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '/' {
+			return false
+		}
+	}
+	return true
+}
+
+// isNotDigit is the complement of unicode.IsDigit
+func isNotDigit(r rune) bool {
+	return !unicode.IsDigit(r)
+}
+
+// firstRune returns the first rune of s, or 0 if s is empty
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+// appendUnique appends name unless names already holds it
+func appendUnique(names []string, name string) []string {
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
+
+// weekday converts a weekday name or its three-letter form to a weekday
+func weekday(name string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseDue converts the value of a due: token into a due date
+func parseDue(value string, now time.Time) (time.Time, error) {
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	endOf := func(day time.Time) time.Time { return day.Add(24*time.Hour - time.Second) }
+	lower := strings.ToLower(value)
+
+	if t, err := time.ParseInLocation("2006-01-02T15:04", value, loc); err == nil {
+		return t, nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return endOf(day), nil
+	}
+
+	switch lower {
+	case "today":
+		return endOf(today), nil
+	case "tomorrow":
+		return endOf(today.AddDate(0, 0, 1)), nil
+	}
+	if d, ok := weekday(lower); ok {
+		return endOf(today.AddDate(0, 0, (int(d)-int(today.Weekday())+7)%7)), nil
+	}
+	if rest, ok := strings.CutPrefix(lower, "+"); ok && len(rest) > 1 {
+		n, err := strconv.Atoi(rest[:len(rest)-1])
+		if err == nil && n >= 0 {
+			switch rest[len(rest)-1] {
+			case 'd':
+				return endOf(today.AddDate(0, 0, n)), nil
+			case 'w':
+				return endOf(today.AddDate(0, 0, 7*n)), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: due date %q must be YYYY-MM-DD, YYYY-MM-DDTHH:MM, today, tomorrow, a weekday or +Nd/+Nw", apperrors.ErrInvalidCommand, value)
+}
+
+// Tag reports whether the word w is a #tag, by the same rule as Parse,
+// and returns the tag without its #
+func Tag(w string) (string, bool) {
+	kind, value, ok := classify(w)
+	return value, ok && kind == KindTag
+}
+
+// Projects returns the distinct +project names in s, in order. Unlike
+// Parse it never fails, so it can be used on stored descriptions.
+func Projects(s string) []string {
+	var projects []string
+	for _, w := range splitWords(s) {
+		if kind, value, ok := classify(w.text); ok && kind == KindProject {
+			projects = appendUnique(projects, value)
+		}
+	}
+	return projects
+}
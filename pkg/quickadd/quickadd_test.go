@@ -0,0 +1,240 @@
+package quickadd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// now is Wednesday 2026-01-14 10:30
+var now = time.Date(2026, 1, 14, 10, 30, 0, 0, time.UTC)
+
+// endOfDay returns the last second of the given day
+func endOfDay(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 23, 59, 59, 0, time.UTC)
+	return &t
+}
+
+// TestParse tests token combinations, escaping and inputs that only look
+// like tokens
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  Result
+	}{
+		{
+			name:  "plain text",
+			input: "buy milk",
+			want:  Result{Description: "buy milk"},
+		},
+		{
+			name:  "all token kinds",
+			input: "pay rent due:fri !high #home +finance @phone",
+			want: Result{
+				Description: "pay rent #home +finance @phone",
+				Due:         endOfDay(2026, 1, 16),
+				Priority:    models.PriorityHigh,
+				Tags:        []string{"home"},
+				Projects:    []string{"finance"},
+				Contexts:    []string{"phone"},
+				Tokens: []Token{
+					{Kind: KindDue, Value: "fri", Start: 9, End: 16},
+					{Kind: KindPriority, Value: "high", Start: 17, End: 22},
+					{Kind: KindTag, Value: "home", Start: 23, End: 28},
+					{Kind: KindProject, Value: "finance", Start: 29, End: 37},
+					{Kind: KindContext, Value: "phone", Start: 38, End: 44},
+				},
+			},
+		},
+		{
+			name:  "tokens first and extra whitespace",
+			input: "  !l   due:tomorrow\twater plants ",
+			want: Result{
+				Description: "water plants",
+				Due:         endOfDay(2026, 1, 15),
+				Priority:    models.PriorityLow,
+				Tokens: []Token{
+					{Kind: KindPriority, Value: "l", Start: 2, End: 4},
+					{Kind: KindDue, Value: "tomorrow", Start: 7, End: 19},
+				},
+			},
+		},
+		{
+			name:  "repeated tags are listed once",
+			input: "#a x #b #a",
+			want: Result{
+				Description: "#a x #b #a",
+				Tags:        []string{"a", "b"},
+				Tokens: []Token{
+					{Kind: KindTag, Value: "a", Start: 0, End: 2},
+					{Kind: KindTag, Value: "b", Start: 5, End: 7},
+					{Kind: KindTag, Value: "a", Start: 8, End: 10},
+				},
+			},
+		},
+		{
+			name:  "escaped words",
+			input: `\#notATag \!high \due:today \\#x`,
+			want:  Result{Description: `#notATag !high due:today \#x`},
+		},
+		{
+			name:  "look-alikes are text",
+			input: "fix #12 +1 wow! ! # @ bob@example.com !important #a,b due:",
+			want:  Result{Description: "fix #12 +1 wow! ! # @ bob@example.com !important #a,b due:"},
+		},
+		{
+			name:  "case-insensitive values",
+			input: "x !MEDIUM due:Monday",
+			want: Result{
+				Description: "x",
+				Due:         endOfDay(2026, 1, 19),
+				Priority:    models.PriorityMedium,
+				Tokens: []Token{
+					{Kind: KindPriority, Value: "MEDIUM", Start: 2, End: 9},
+					{Kind: KindDue, Value: "Monday", Start: 10, End: 20},
+				},
+			},
+		},
+		{
+			name:  "only tokens",
+			input: "due:today !h",
+			want: Result{
+				Due:      endOfDay(2026, 1, 14),
+				Priority: models.PriorityHigh,
+				Tokens: []Token{
+					{Kind: KindDue, Value: "today", Start: 0, End: 9},
+					{Kind: KindPriority, Value: "h", Start: 10, End: 12},
+				},
+			},
+		},
+		{
+			name:  "offsets count bytes",
+			input: "café #über",
+			want: Result{
+				Description: "café #über",
+				Tags:        []string{"über"},
+				Tokens:      []Token{{Kind: KindTag, Value: "über", Start: 6, End: 12}},
+			},
+		},
+		{
+			name:  "nested names",
+			input: "@home/office +q1-launch #work_items",
+			want: Result{
+				Description: "@home/office +q1-launch #work_items",
+				Tags:        []string{"work_items"},
+				Projects:    []string{"q1-launch"},
+				Contexts:    []string{"home/office"},
+				Tokens: []Token{
+					{Kind: KindContext, Value: "home/office", Start: 0, End: 12},
+					{Kind: KindProject, Value: "q1-launch", Start: 13, End: 23},
+					{Kind: KindTag, Value: "work_items", Start: 24, End: 35},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.input, now)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestParseDue tests every accepted due date form
+func TestParseDue(t *testing.T) {
+	testCases := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2026-02-01", *endOfDay(2026, 2, 1)},
+		{"2026-02-01T09:15", time.Date(2026, 2, 1, 9, 15, 0, 0, time.UTC)},
+		{"today", *endOfDay(2026, 1, 14)},
+		{"tomorrow", *endOfDay(2026, 1, 15)},
+		// The current weekday means today, earlier ones next week
+		{"wed", *endOfDay(2026, 1, 14)},
+		{"tue", *endOfDay(2026, 1, 20)},
+		{"sunday", *endOfDay(2026, 1, 18)},
+		{"+0d", *endOfDay(2026, 1, 14)},
+		{"+3d", *endOfDay(2026, 1, 17)},
+		{"+2w", *endOfDay(2026, 1, 28)},
+	}
+
+	for _, tc := range testCases {
+		got, err := Parse("x due:"+tc.value, now)
+		if err != nil {
+			t.Errorf("due:%s: Parse failed: %v", tc.value, err)
+			continue
+		}
+		if got.Due == nil || !got.Due.Equal(tc.want) {
+			t.Errorf("due:%s: expected %v, got %v", tc.value, tc.want, got.Due)
+		}
+	}
+}
+
+// TestParseErrors tests inputs that are rejected rather than guessed at
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"x due:someday",
+		"x due:2026-13-01",
+		"x due:+3",
+		"x due:+d",
+		"x due:-1d",
+		"x due:today due:tomorrow",
+		"x !high !low",
+		"x !h !h",
+	} {
+		if _, err := Parse(input, now); !apperrors.IsInvalidCommand(err) {
+			t.Errorf("Expected ErrInvalidCommand for %q, got %v", input, err)
+		}
+	}
+}
+
+// TestProjects tests listing the +projects of stored descriptions
+func TestProjects(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  []string
+	}{
+		{"plain", nil},
+		{"+a do +b and +a again", []string{"a", "b"}},
+		{`+1 \+escaped +ok due:someday`, []string{"ok"}},
+	}
+	for _, tc := range testCases {
+		if got := Projects(tc.input); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Projects(%q): expected %v, got %v", tc.input, tc.want, got)
+		}
+	}
+}
+
+// TestTag tests which single words are tags
+func TestTag(t *testing.T) {
+	testCases := []struct {
+		word string
+		tag  string
+		ok   bool
+	}{
+		{"#home", "home", true},
+		{"#front-end", "front-end", true},
+		{"#work/reports", "work/reports", true},
+		{"#家务", "家务", true},
+		{"#12", "", false},
+		{"#", "", false},
+		{"C#", "", false},
+		{`\#escaped`, "", false},
+		{"+project", "", false},
+	}
+	for _, tc := range testCases {
+		if tag, ok := Tag(tc.word); ok != tc.ok || (ok && tag != tc.tag) {
+			t.Errorf("Tag(%q): expected %q %v, got %q %v", tc.word, tc.tag, tc.ok, tag, ok)
+		}
+	}
+}
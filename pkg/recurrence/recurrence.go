@@ -0,0 +1,198 @@
+// Package recurrence computes when a recurring task comes due next. A
+// recurrence spec is daily, weekly, monthly or cron:<expr>, where expr is a
+// standard five-field cron expression (minute hour day-of-month month
+// day-of-week) supporting *, lists, ranges and steps.
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	apperrors "todolist/pkg/errors"
+)
+
+// Named recurrence specs
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+// CronPrefix introduces a cron expression spec
+const CronPrefix = "cron:"
+
+// maxCronSearch bounds the search for the next cron match; expressions
+// that never match (such as February 30th) fail instead of looping
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// NextOccurrence returns the first occurrence of spec strictly after from,
+// in from's location. Monthly keeps the day of month, clamped to the
+// length of shorter months.
+func NextOccurrence(from time.Time, spec string) (time.Time, error) {
+	switch spec {
+	case Daily:
+		return from.AddDate(0, 0, 1), nil
+	case Weekly:
+		return from.AddDate(0, 0, 7), nil
+	case Monthly:
+		return addMonth(from), nil
+	}
+
+	expr, ok := strings.CutPrefix(spec, CronPrefix)
+	if !ok {
+		return time.Time{}, invalid(spec, "must be daily, weekly, monthly or cron:<expr>")
+	}
+	schedule, err := parseCron(expr)
+	if err != nil {
+		return time.Time{}, invalid(spec, err.Error())
+	}
+	return schedule.next(from, spec)
+}
+
+// Validate reports whether spec is a recurrence NextOccurrence accepts
+func Validate(spec string) error {
+	_, err := NextOccurrence(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), spec)
+	return err
+}
+
+// invalid wraps ErrInvalidRecurrence with the offending spec
+func invalid(spec, reason string) error {
+	return fmt.Errorf("%w %q: %s", apperrors.ErrInvalidRecurrence, spec, reason)
+}
+
+// addMonth moves t one calendar month ahead, clamping the day
+func addMonth(t time.Time) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// cronSchedule is a parsed cron expression; each field is the set of
+// allowed values
+type cronSchedule struct {
+	minute, hour, dom, month, dow map[int]bool
+	// domAny and dowAny record unrestricted day fields: when both day
+	// fields are restricted, matching either one is enough
+	domAny, dowAny bool
+}
+
+// cronFields gives the name and allowed range of each cron field in order
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// parseCron parses a five-field cron expression
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression needs %d fields, got %d", len(cronFields), len(fields))
+	}
+
+	sets := make([]map[int]bool, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", cronFields[i].name, err)
+		}
+		sets[i] = set
+	}
+
+	return &cronSchedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a comma-separated list of *, N, A-B, */S, A-B/S or N/S
+func parseCronField(field string, lo, hi int) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		start, end := lo, hi
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var errA, errB error
+			start, errA = strconv.Atoi(a)
+			end, errB = strconv.Atoi(b)
+			if errA != nil || errB != nil || start > end {
+				return nil, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", rangePart)
+			}
+			start = n
+			// N/S means every S starting at N
+			if !hasStep {
+				end = n
+			}
+		}
+		if start < lo || end > hi {
+			return nil, fmt.Errorf("%q is outside %d-%d", rangePart, lo, hi)
+		}
+
+		for v := start; v <= end; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// matchesDay reports whether t's date satisfies the day-of-month and
+// day-of-week fields
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	dom, dow := c.dom[t.Day()], c.dow[int(t.Weekday())]
+	if !c.domAny && !c.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// next finds the first minute after from matching the schedule
+func (c *cronSchedule) next(from time.Time, spec string) (time.Time, error) {
+	loc := from.Location()
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	limit := from.Add(maxCronSearch)
+
+	for t.Before(limit) {
+		switch {
+		case !c.month[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !c.hour[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case !c.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t, nil
+		}
+	}
+	return time.Time{}, invalid(spec, "never matches")
+}
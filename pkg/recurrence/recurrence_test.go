@@ -0,0 +1,69 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+	apperrors "todolist/pkg/errors"
+)
+
+// TestNextOccurrence tests the named specs and cron expressions
+func TestNextOccurrence(t *testing.T) {
+	// Friday 2026-01-30 17:45
+	from := time.Date(2026, 1, 30, 17, 45, 0, 0, time.UTC)
+
+	testCases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"daily", time.Date(2026, 1, 31, 17, 45, 0, 0, time.UTC)},
+		{"weekly", time.Date(2026, 2, 6, 17, 45, 0, 0, time.UTC)},
+		// January 30th has no February counterpart
+		{"monthly", time.Date(2026, 2, 28, 17, 45, 0, 0, time.UTC)},
+		{"cron:0 9 * * *", time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)},
+		{"cron:*/20 * * * *", time.Date(2026, 1, 30, 18, 0, 0, 0, time.UTC)},
+		{"cron:50 17 * * *", time.Date(2026, 1, 30, 17, 50, 0, 0, time.UTC)},
+		// Weekdays only: Friday evening rolls over to Monday
+		{"cron:0 9 * * 1-5", time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC)},
+		{"cron:0 0 1 */3 *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match (the 1st or a Sunday)
+		{"cron:0 12 1 * 0", time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)},
+		{"cron:30 8 15 6 *", time.Date(2026, 6, 15, 8, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := NextOccurrence(from, tc.spec)
+			if err != nil {
+				t.Fatalf("NextOccurrence failed: %v", err)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestMonthlyKeepsDay tests that clamping to a short month does not stick
+func TestMonthlyKeepsDay(t *testing.T) {
+	from := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	got, _ := NextOccurrence(from, Monthly)
+	if want := time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	got, _ = NextOccurrence(time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC), Monthly)
+	if want := time.Date(2026, 5, 15, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestInvalidSpecs tests that bad specs fail with ErrInvalidRecurrence
+func TestInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"", "hourly", "cron:", "cron:* * * *", "cron:60 * * * *", "cron:* * * * 7",
+		"cron:5-1 * * * *", "cron:*/0 * * * *", "cron:x * * * *", "cron:0 0 30 2 *",
+	} {
+		if err := Validate(spec); !apperrors.IsInvalidRecurrence(err) {
+			t.Errorf("Expected ErrInvalidRecurrence for %q, got %v", spec, err)
+		}
+	}
+}
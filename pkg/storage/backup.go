@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	apperrors "todolist/pkg/errors"
+)
+
+// Backup file names look like todolist-2026-01-14T10-30-00.json; the
+// timestamp layout sorts chronologically by name
+const (
+	backupPrefix     = "todolist-"
+	backupSuffix     = ".json"
+	backupTimeLayout = "2006-01-02T15-04-05"
+)
+
+// backupNow is replaced in tests to control backup names
+var backupNow = time.Now
+
+// BackupStorage copies the storage file src into destDir under a
+// timestamped name and returns the path of the created backup
+func BackupStorage(src, destDir string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), src)
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), destDir)
+	}
+
+	dest := filepath.Join(destDir, backupPrefix+backupNow().Format(backupTimeLayout)+backupSuffix)
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), dest)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return "", apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), dest)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dest)
+		return "", apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), dest)
+	}
+
+	return dest, nil
+}
+
+// ListBackups returns the backup files in dir, oldest first
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), dir)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+		if _, err := time.Parse(backupTimeLayout, stamp); err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+	}
+	return paths, nil
+}
+
+// PruneBackups deletes the oldest backups in dir so that at most max
+// remain, and returns the paths it removed
+func PruneBackups(dir string, max int) ([]string, error) {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) <= max {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, path := range backups[:len(backups)-max] {
+		if err := os.Remove(path); err != nil {
+			return removed, apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// EventLog records task changes so reports can cover what is no longer in
+// the task list
+type EventLog interface {
+	Append(events []models.Event) error
+	Events() ([]models.Event, error)
+}
+
+// FileEventLog implements EventLog as a file next to the task file holding
+// one JSON event per line, oldest first. Appending never rewrites earlier
+// events.
+type FileEventLog struct {
+	filepath string
+}
+
+// NewFileEventLog creates a FileEventLog writing to filepath
+func NewFileEventLog(filepath string) *FileEventLog {
+	return &FileEventLog{filepath: filepath}
+}
+
+// Append adds events to the end of the log
+func (fl *FileEventLog) Append(events []models.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fl.filepath)
+		}
+	}
+
+	// A single write keeps the events of one change together
+	file, err := os.OpenFile(fl.filepath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fl.filepath)
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fl.filepath)
+	}
+	if err := file.Close(); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fl.filepath)
+	}
+	return nil
+}
+
+// Events returns every logged event, oldest first. A missing file is an
+// empty log.
+func (fl *FileEventLog) Events() ([]models.Event, error) {
+	file, err := os.Open(fl.filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), fl.filepath)
+	}
+	defer file.Close()
+
+	var events []models.Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var event models.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, fmt.Errorf("line %d: %w", line, err)), fl.filepath)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), fl.filepath)
+	}
+	return events, nil
+}
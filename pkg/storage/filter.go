@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"strings"
+	"time"
+	"todolist/pkg/models"
+)
+
+// StorageFilter selects tasks; nil or zero fields match every task and
+// set fields must all match
+type StorageFilter struct {
+	// Status matches completed tasks when true and pending ones when false
+	Status *bool
+	// DueBefore matches tasks due strictly before the time
+	DueBefore *time.Time
+	// DueAfter matches tasks due strictly after the time
+	DueAfter *time.Time
+	// Priority matches tasks with exactly this priority
+	Priority *models.Priority
+	// TextQuery matches tasks whose description contains it, ignoring case
+	// like SQL's LIKE does
+	TextQuery string
+	// Tag matches tasks with this tag, ignoring case and a leading #
+	Tag string
+}
+
+// Filterer is implemented by storages that can select tasks themselves,
+// so that backends able to query can avoid loading the whole list
+type Filterer interface {
+	ListByFilter(filter StorageFilter) ([]models.Task, error)
+}
+
+// Match reports whether task passes every set field of the filter. Tasks
+// without a due date never match DueBefore or DueAfter.
+func (f StorageFilter) Match(task models.Task) bool {
+	if f.Status != nil && task.Completed != *f.Status {
+		return false
+	}
+	if f.DueBefore != nil && (task.DueDate == nil || !task.DueDate.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.DueAfter != nil && (task.DueDate == nil || !task.DueDate.After(*f.DueAfter)) {
+		return false
+	}
+	if f.Priority != nil && task.Priority != *f.Priority {
+		return false
+	}
+	if f.TextQuery != "" && !strings.Contains(strings.ToLower(task.Description), strings.ToLower(f.TextQuery)) {
+		return false
+	}
+	if f.Tag != "" && !hasTag(task, strings.TrimPrefix(f.Tag, "#")) {
+		return false
+	}
+	return true
+}
+
+// hasTag reports whether task has tag, ignoring case
+func hasTag(task models.Task, tag string) bool {
+	for _, t := range task.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTasks returns the tasks matching filter, keeping their order
+func FilterTasks(tasks []models.Task, filter StorageFilter) []models.Task {
+	matched := []models.Task{}
+	for _, task := range tasks {
+		if filter.Match(task) {
+			matched = append(matched, task)
+		}
+	}
+	return matched
+}
+
+// ListByFilter loads the file and returns the tasks matching filter
+func (fs *FileStorage) ListByFilter(filter StorageFilter) ([]models.Task, error) {
+	list, err := fs.Load()
+	if err != nil {
+		return nil, err
+	}
+	return FilterTasks(list.Tasks, filter), nil
+}
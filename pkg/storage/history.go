@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// History keeps snapshots of earlier task lists so changes can be undone.
+// Each change is recorded with the list after it too, so that undo can
+// tell whether anything changed the list since.
+type History interface {
+	Push(before, after *models.TaskList) error
+	// Pop returns after as nil for changes recorded without it
+	Pop() (before, after *models.TaskList, err error)
+}
+
+// DefaultUndoDepth is the number of snapshots FileHistory keeps
+const DefaultUndoDepth = 20
+
+// historyFile is the on-disk format of FileHistory, oldest snapshot first
+type historyFile struct {
+	Snapshots []historySnapshot `json:"snapshots"`
+}
+
+// historySnapshot is the list before a change, with the list after it;
+// files written before After was kept have none
+type historySnapshot struct {
+	models.TaskList
+	After *models.TaskList `json:"after,omitempty"`
+}
+
+// FileHistory implements History as a JSON file next to the task file
+type FileHistory struct {
+	filepath string
+	depth    int
+}
+
+// NewFileHistory creates a FileHistory keeping DefaultUndoDepth snapshots
+func NewFileHistory(filepath string) *FileHistory {
+	return &FileHistory{
+		filepath: filepath,
+		depth:    DefaultUndoDepth,
+	}
+}
+
+// Push records a change, dropping the oldest ones beyond the depth limit
+func (fh *FileHistory) Push(before, after *models.TaskList) error {
+	history, err := fh.read()
+	if err != nil {
+		return err
+	}
+
+	history.Snapshots = append(history.Snapshots, historySnapshot{TaskList: *before, After: after})
+	if len(history.Snapshots) > fh.depth {
+		history.Snapshots = history.Snapshots[len(history.Snapshots)-fh.depth:]
+	}
+
+	return fh.write(history)
+}
+
+// Pop removes and returns the most recent change. It returns
+// ErrNothingToUndo when the history is empty.
+func (fh *FileHistory) Pop() (before, after *models.TaskList, err error) {
+	history, err := fh.read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(history.Snapshots) == 0 {
+		return nil, nil, apperrors.ErrNothingToUndo
+	}
+
+	last := history.Snapshots[len(history.Snapshots)-1]
+	history.Snapshots = history.Snapshots[:len(history.Snapshots)-1]
+	if err := fh.write(history); err != nil {
+		return nil, nil, err
+	}
+
+	if last.Tasks == nil {
+		last.Tasks = []models.Task{}
+	}
+	return &last.TaskList, last.After, nil
+}
+
+// read loads the history file, treating a missing file as empty history
+func (fh *FileHistory) read() (*historyFile, error) {
+	data, err := os.ReadFile(fh.filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &historyFile{}, nil
+		}
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), fh.filepath)
+	}
+
+	var history historyFile
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, err), fh.filepath)
+	}
+	return &history, nil
+}
+
+// write saves the history file using the same atomic rename as FileStorage
+func (fh *FileHistory) write(history *historyFile) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fh.filepath)
+	}
+
+	tempFile := fh.filepath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fh.filepath)
+	}
+	if err := os.Rename(tempFile, fh.filepath); err != nil {
+		os.Remove(tempFile)
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fh.filepath)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// maxErrorBody limits how much of an error response is quoted in the error
+const maxErrorBody = 512
+
+// HTTPStorage implements Storage against a server that exposes the task
+// list as one JSON document: GET /tasks returns it and PUT /tasks replaces
+// it. The document has the same structure as FileStorage's file.
+type HTTPStorage struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	indent  string
+}
+
+// NewHTTPStorage creates an HTTPStorage for the server at baseURL. A
+// non-empty token is sent as a bearer token; a nil client uses
+// http.DefaultClient.
+func NewHTTPStorage(baseURL, token string, client *http.Client) *HTTPStorage {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPStorage{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  client,
+		indent:  DefaultIndent,
+	}
+}
+
+// SetIndent sets the indentation Save sends JSON with ("" for compact)
+func (hs *HTTPStorage) SetIndent(indent string) {
+	hs.indent = indent
+}
+
+// Indent returns the indentation Save sends JSON with
+func (hs *HTTPStorage) Indent() string {
+	return hs.indent
+}
+
+// URL returns the address of the task list resource
+func (hs *HTTPStorage) URL() string {
+	return hs.baseURL + "/tasks"
+}
+
+// Load fetches the task list. A 404 means the server has no list yet and
+// returns an empty one, like a missing file does for FileStorage.
+func (hs *HTTPStorage) Load() (*models.TaskList, error) {
+	resp, err := hs.do(http.MethodGet, nil)
+	if err != nil {
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), hs.URL())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &models.TaskList{
+			Version: models.FormatVersion,
+			Tasks:   []models.Task{},
+			NextID:  1,
+		}, nil
+	}
+	if err := checkStatus(resp); err != nil {
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), hs.URL())
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), hs.URL())
+	}
+
+	var taskList models.TaskList
+	if err := json.Unmarshal(data, &taskList); err != nil {
+		return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, err), hs.URL())
+	}
+
+	// Ensure Tasks is not nil
+	if taskList.Tasks == nil {
+		taskList.Tasks = []models.Task{}
+	}
+
+	if err := migrate(&taskList, hs.URL()); err != nil {
+		return nil, err
+	}
+
+	return &taskList, nil
+}
+
+// Save replaces the task list on the server
+func (hs *HTTPStorage) Save(list *models.TaskList) error {
+	if err := list.Validate(); err != nil {
+		return apperrors.WrapStorageWriteError(err, hs.URL())
+	}
+
+	data, err := marshalIndent(versioned(list), hs.indent)
+	if err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), hs.URL())
+	}
+
+	resp, err := hs.do(http.MethodPut, data)
+	if err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), hs.URL())
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), hs.URL())
+	}
+	return nil
+}
+
+// do sends a request for the task list resource with an optional JSON body
+func (hs *HTTPStorage) do(method string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, hs.URL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if hs.token != "" {
+		req.Header.Set("Authorization", "Bearer "+hs.token)
+	}
+	return hs.client.Do(req)
+}
+
+// checkStatus turns a non-2xx response into an error quoting the start of
+// its body
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return fmt.Errorf("server returned %s: %s", resp.Status, msg)
+	}
+	return fmt.Errorf("server returned %s", resp.Status)
+}
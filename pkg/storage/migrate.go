@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// migrations upgrade a loaded task list one format version at a time:
+// migrations[v] turns version v into v+1. When the serialized shape
+// changes, bump models.FormatVersion and append the step from the previous
+// version here; old files are then upgraded in memory on Load and written
+// in the new format by the next Save.
+var migrations = []func(list *models.TaskList) error{
+	// 0 to 1: the version field was added; nothing else changed
+	func(list *models.TaskList) error { return nil },
+}
+
+// migrate upgrades list, read from source, to models.FormatVersion. Lists
+// from a newer todolist are rejected rather than misread.
+func migrate(list *models.TaskList, source string) error {
+	if list.Version < 0 {
+		return apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, fmt.Errorf("invalid format version %d", list.Version)), source)
+	}
+	if list.Version > models.FormatVersion {
+		return fmt.Errorf("%s has format version %d and was created by a newer todolist (this one reads up to version %d): %w",
+			source, list.Version, models.FormatVersion, apperrors.ErrUnsupportedVersion)
+	}
+
+	for list.Version < models.FormatVersion {
+		if err := migrations[list.Version](list); err != nil {
+			return apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON,
+				fmt.Errorf("upgrading from format version %d: %w", list.Version, err)), source)
+		}
+		list.Version++
+	}
+	return nil
+}
+
+// versioned returns list marked with the current format version for saving,
+// leaving list itself unchanged
+func versioned(list *models.TaskList) *models.TaskList {
+	out := *list
+	out.Version = models.FormatVersion
+	return &out
+}
+
+// Upgrade records that Save rewrote the task file in a newer format
+// version, so that the user can be told once
+type Upgrade struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	// Backup is the file as it was before the upgrade, which older
+	// todolists can still read
+	Backup string    `json:"backup"`
+	At     time.Time `json:"at"`
+	// Notified is set once the user has been told about the upgrade
+	Notified bool `json:"notified"`
+}
+
+// PreMigrationPath returns where the file is kept before it is upgraded
+// from format version from
+func (fs *FileStorage) PreMigrationPath(from int) string {
+	return fmt.Sprintf("%s.v%d.bak", fs.filepath, from)
+}
+
+// UpgradePath returns the file recording the last format upgrade
+func (fs *FileStorage) UpgradePath() string {
+	return fs.filepath + ".upgrade"
+}
+
+// prepareUpgrade is called by Save before it replaces a file last loaded
+// in an older format version. It copies the file to PreMigrationPath,
+// unless an earlier attempt already did, and records the upgrade. It
+// reports whether it did so; the record is removed again if the save
+// fails.
+func (fs *FileStorage) prepareUpgrade() (bool, error) {
+	fs.mu.Lock()
+	outdated := fs.outdated
+	fs.mu.Unlock()
+	if !outdated {
+		return false, nil
+	}
+
+	// Another process may have upgraded the file since (see SetForceSave)
+	current, err := os.ReadFile(fs.filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(current, &header); err != nil || header.Version >= models.FormatVersion {
+		return false, nil
+	}
+
+	backup := fs.PreMigrationPath(header.Version)
+	if _, err := os.Lstat(backup); os.IsNotExist(err) {
+		if err := writeFile(backup, current, 0644); err != nil {
+			os.Remove(backup)
+			return false, err
+		}
+	}
+
+	upgrade := Upgrade{From: header.Version, To: models.FormatVersion, Backup: backup, At: backupNow()}
+	if err := fs.writeUpgrade(upgrade); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeUpgrade saves upgrade to UpgradePath
+func (fs *FileStorage) writeUpgrade(upgrade Upgrade) error {
+	data, err := json.MarshalIndent(upgrade, "", DefaultIndent)
+	if err != nil {
+		return err
+	}
+	return writeFile(fs.UpgradePath(), data, 0644)
+}
+
+// PendingUpgrade returns the last format upgrade of the file if the user
+// has not been told about it yet, or nil
+func (fs *FileStorage) PendingUpgrade() (*Upgrade, error) {
+	data, err := os.ReadFile(fs.UpgradePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), fs.UpgradePath())
+	}
+	var upgrade Upgrade
+	if err := json.Unmarshal(data, &upgrade); err != nil {
+		return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, err), fs.UpgradePath())
+	}
+	if upgrade.Notified {
+		return nil, nil
+	}
+	return &upgrade, nil
+}
+
+// MarkUpgradeNotified records that the user was told about the last
+// format upgrade, so that PendingUpgrade no longer returns it
+func (fs *FileStorage) MarkUpgradeNotified() error {
+	upgrade, err := fs.PendingUpgrade()
+	if err != nil || upgrade == nil {
+		return err
+	}
+	upgrade.Notified = true
+	if err := fs.writeUpgrade(*upgrade); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.UpgradePath())
+	}
+	return nil
+}
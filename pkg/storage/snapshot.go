@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// snapshotSuffix is appended to a snapshot name to form its file name
+const snapshotSuffix = ".json"
+
+// SnapshotInfo describes a stored snapshot
+type SnapshotInfo struct {
+	Name string
+	// Created is when the snapshot file was written
+	Created time.Time
+	Tasks   int
+}
+
+// ValidateSnapshotName rejects names that cannot be used as a file name in
+// the snapshot directory. Spaces are allowed; path separators and names
+// starting with a dot are not.
+func ValidateSnapshotName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("%w: snapshot name cannot be empty", apperrors.ErrInvalidCommand)
+	case strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, "."):
+		return fmt.Errorf("%w: invalid snapshot name %q (no slashes or leading dot)", apperrors.ErrInvalidCommand, name)
+	}
+	return nil
+}
+
+// snapshotPath returns the file of the snapshot name in dir
+func snapshotPath(name, dir string) (string, error) {
+	if err := ValidateSnapshotName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+snapshotSuffix), nil
+}
+
+// SaveSnapshot writes list to <dir>/<name>.json, creating dir if needed,
+// as JSON indented with indent ("" for compact). An existing snapshot of
+// the same name is not replaced.
+func SaveSnapshot(name, dir string, list *models.TaskList, indent string) error {
+	path, err := snapshotPath(name, dir)
+	if err != nil {
+		return err
+	}
+	data, err := marshalIndent(versioned(list), indent)
+	if err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), dir)
+	}
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			err = fmt.Errorf("snapshot %q already exists", name)
+		}
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+	if _, err := out.Write(data); err != nil {
+		out.Close()
+		os.Remove(path)
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(path)
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+	return nil
+}
+
+// LoadSnapshot reads the snapshot name from dir, upgrading older formats
+// like Load does
+func LoadSnapshot(name, dir string) (*models.TaskList, error) {
+	path, err := snapshotPath(name, dir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), path)
+	}
+
+	var list models.TaskList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, err), path)
+	}
+	if list.Tasks == nil {
+		list.Tasks = []models.Task{}
+	}
+	if err := migrate(&list, path); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ListSnapshots returns the snapshots in dir, oldest first. A directory
+// that does not exist yet holds no snapshots.
+func ListSnapshots(dir string) ([]SnapshotInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SnapshotInfo{}, nil
+		}
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), dir)
+	}
+
+	snapshots := []SnapshotInfo{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), snapshotSuffix)
+		if entry.IsDir() || !ok || ValidateSnapshotName(name) != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), filepath.Join(dir, entry.Name()))
+		}
+		list, err := LoadSnapshot(name, dir)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, SnapshotInfo{Name: name, Created: info.ModTime(), Tasks: len(list.Tasks)})
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.Before(snapshots[j].Created)
+	})
+	return snapshots, nil
+}
+
+// DeleteSnapshot removes the snapshot name from dir
+func DeleteSnapshot(name, dir string) error {
+	path, err := snapshotPath(name, dir)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("snapshot %q not found", name)
+		}
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), path)
+	}
+	return nil
+}
@@ -0,0 +1,518 @@
+// Package storage persists task lists. FileStorage keeps them in a JSON
+// file with rotating backups; optional interfaces such as Restorer,
+// Backuper and Filterer expose what a backend supports beyond Load and Save.
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// Storage defines the interface for data persistence
+type Storage interface {
+	Load() (*models.TaskList, error)
+	Save(list *models.TaskList) error
+}
+
+// Restorer is implemented by storages that keep previous versions of the data
+type Restorer interface {
+	RestoreBackup() error
+}
+
+// Backuper is implemented by storages that can copy their data to a
+// timestamped backup
+type Backuper interface {
+	Backup() (string, error)
+}
+
+// ChangeDetector is implemented by storages that can tell whether their
+// data was changed by someone else since they last loaded or saved it
+type ChangeDetector interface {
+	Modified() (bool, error)
+}
+
+// Indenter is implemented by storages that save JSON with a configurable
+// indentation, so that exports and snapshots can be written the same way
+type Indenter interface {
+	Indent() string
+}
+
+// DefaultBackupDepth is the number of backups FileStorage keeps by default
+const DefaultBackupDepth = 3
+
+// DefaultIndent is the JSON indentation NewFileStorage saves with
+const DefaultIndent = "  "
+
+// FileStorageOptions configures a FileStorage created with
+// NewFileStorageWithOptions
+type FileStorageOptions struct {
+	// Indent is repeated once per nesting level of the saved JSON, as in
+	// json.MarshalIndent; "" saves compact JSON on a single line
+	Indent string
+}
+
+// FileStorage implements Storage interface using file-based persistence
+type FileStorage struct {
+	filepath    string
+	backupDepth int
+	indent      string
+	readOnly    bool
+	forceSave   bool
+
+	// mu guards stamp and known, which Load and Save may set concurrently
+	mu    sync.Mutex
+	stamp fileStamp
+	// known is set once the file has been loaded or saved, so that Save
+	// can tell whether it changed since
+	known bool
+	// outdated is set when the file was last loaded in an older format
+	// version, so that Save keeps a copy before upgrading it
+	outdated bool
+}
+
+// fileStamp identifies a version of the task file by its modification time
+// and size, and by its content for Save; the zero value stands for a
+// missing file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+	sum     [sha256.Size]byte
+}
+
+// stat returns the stamp of the task file as it is now
+func (fs *FileStorage) stat() (fileStamp, error) {
+	info, err := os.Stat(fs.filepath)
+	if os.IsNotExist(err) {
+		return fileStamp{}, nil
+	}
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size(), exists: true}, nil
+}
+
+// setStamp records the version of the file last loaded or saved, whose
+// content was data
+func (fs *FileStorage) setStamp(stamp fileStamp, data []byte) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	stamp.sum = sha256.Sum256(data)
+	fs.stamp, fs.known = stamp, true
+}
+
+// SetForceSave makes Save overwrite the file even if another process
+// changed it since it was loaded
+func (fs *FileStorage) SetForceSave(force bool) {
+	fs.forceSave = force
+}
+
+// checkUnchanged returns ErrConcurrentModification if the file's content is
+// not what this FileStorage last loaded or saved, so that saving would
+// overwrite someone else's change. Saves without an earlier Load or Save
+// are not checked. The check and the write that follows are not atomic: a
+// write landing in between is still lost.
+func (fs *FileStorage) checkUnchanged() error {
+	fs.mu.Lock()
+	stamp, known := fs.stamp, fs.known
+	fs.mu.Unlock()
+	if !known || fs.forceSave {
+		return nil
+	}
+
+	current, err := os.ReadFile(fs.filepath)
+	if os.IsNotExist(err) {
+		if stamp.exists {
+			return apperrors.ErrConcurrentModification
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Join(apperrors.ErrStorageWrite, err)
+	}
+	if !stamp.exists || sha256.Sum256(current) != stamp.sum {
+		return apperrors.ErrConcurrentModification
+	}
+	return nil
+}
+
+// Modified reports whether the file's modification time or size changed
+// since this FileStorage last loaded or saved it, which means another
+// process wrote it. Before the first Load, an existing file counts as
+// modified.
+func (fs *FileStorage) Modified() (bool, error) {
+	current, err := fs.stat()
+	if err != nil {
+		return false, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), fs.filepath)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return !current.modTime.Equal(fs.stamp.modTime) || current.size != fs.stamp.size, nil
+}
+
+// NewFileStorage creates a new FileStorage instance saving with
+// DefaultIndent
+func NewFileStorage(filepath string) *FileStorage {
+	return NewFileStorageWithOptions(filepath, FileStorageOptions{Indent: DefaultIndent})
+}
+
+// NewFileStorageWithOptions creates a FileStorage configured by opts
+func NewFileStorageWithOptions(filepath string, opts FileStorageOptions) *FileStorage {
+	return &FileStorage{
+		filepath:    filepath,
+		backupDepth: DefaultBackupDepth,
+		indent:      opts.Indent,
+	}
+}
+
+// Indent returns the indentation Save writes JSON with ("" for compact)
+func (fs *FileStorage) Indent() string {
+	return fs.indent
+}
+
+// SetBackupDepth sets how many rotated backups Save keeps (0 disables backups)
+func (fs *FileStorage) SetBackupDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	fs.backupDepth = depth
+}
+
+// SetReadOnly makes Save and CheckWritable fail with ErrReadOnlyStorage
+// without touching the file
+func (fs *FileStorage) SetReadOnly(readOnly bool) {
+	fs.readOnly = readOnly
+}
+
+// CheckWritable reports whether Save could replace the task file. It fails
+// with ErrReadOnlyStorage if the storage is set read-only, the file cannot
+// be opened for writing or its directory does not accept new files, such
+// as on a read-only mount. A directory that does not exist yet is left for
+// Save to create.
+func (fs *FileStorage) CheckWritable() error {
+	if fs.readOnly {
+		return apperrors.WrapStorageWriteError(apperrors.ErrReadOnlyStorage, fs.filepath)
+	}
+
+	file, err := os.OpenFile(fs.filepath, os.O_WRONLY|os.O_APPEND, 0)
+	if err == nil {
+		file.Close()
+	} else if !os.IsNotExist(err) {
+		return apperrors.WrapStorageWriteError(fmt.Errorf("%w: %w", apperrors.ErrReadOnlyStorage, err), fs.filepath)
+	}
+
+	// Save writes a temporary file next to the task file and renames it
+	probe, err := os.CreateTemp(filepath.Dir(fs.filepath), filepath.Base(fs.filepath)+".probe-*")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return apperrors.WrapStorageWriteError(fmt.Errorf("%w: %w", apperrors.ErrReadOnlyStorage, err), fs.filepath)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
+// Path returns the location of the task file
+func (fs *FileStorage) Path() string {
+	return fs.filepath
+}
+
+// Backup copies the task file to a timestamped backup in the same
+// directory and returns its path. It does nothing if the file does not exist.
+func (fs *FileStorage) Backup() (string, error) {
+	if _, err := os.Stat(fs.filepath); os.IsNotExist(err) {
+		return "", nil
+	}
+	return BackupStorage(fs.filepath, filepath.Dir(fs.filepath))
+}
+
+// BackupPath returns the path of the n-th backup (1 is the most recent)
+func (fs *FileStorage) BackupPath(n int) string {
+	return fmt.Sprintf("%s.bak.%d", fs.filepath, n)
+}
+
+// Load reads the task list from the file
+func (fs *FileStorage) Load() (*models.TaskList, error) {
+	// A temporary file means an earlier save stopped before the rename,
+	// so the task file may be missing its last change
+	if _, err := os.Lstat(fs.TempPath()); err == nil {
+		return nil, apperrors.WrapStorageReadError(fmt.Errorf(
+			"%w: %s was left by an interrupted save; inspect it and delete it before continuing",
+			apperrors.ErrStorageCorrupted, fs.TempPath()), fs.filepath)
+	}
+
+	// Stat before reading: a write in between then shows up as modified
+	// later rather than being missed
+	stamp, statErr := fs.stat()
+
+	// Read file content
+	data, err := os.ReadFile(fs.filepath)
+	if err != nil {
+		// If file doesn't exist, return empty list
+		if os.IsNotExist(err) {
+			fs.setStamp(fileStamp{}, nil)
+			return &models.TaskList{
+				Version: models.FormatVersion,
+				Tasks:   []models.Task{},
+				NextID:  1,
+			}, nil
+		}
+		// Other read errors
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), fs.filepath)
+	}
+
+	taskList, from, err := decode(data, fs.filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	if statErr == nil {
+		fs.setStamp(stamp, data)
+	}
+	fs.mu.Lock()
+	fs.outdated = from < models.FormatVersion
+	fs.mu.Unlock()
+	return taskList, nil
+}
+
+// Decode reads a task list in the storage format from r, upgrading older
+// format versions, as Load does for the file. name identifies r in errors.
+func Decode(r io.Reader, name string) (*models.TaskList, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), name)
+	}
+	list, _, err := decode(data, name)
+	return list, err
+}
+
+// decode parses a task list read from source and upgrades it, returning
+// the format version it was read in as well
+func decode(data []byte, source string) (*models.TaskList, int, error) {
+	// Parse JSON
+	var taskList models.TaskList
+	if err := json.Unmarshal(data, &taskList); err != nil {
+		return nil, 0, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, err), source)
+	}
+
+	// Ensure Tasks is not nil
+	if taskList.Tasks == nil {
+		taskList.Tasks = []models.Task{}
+	}
+
+	from := taskList.Version
+	if err := migrate(&taskList, source); err != nil {
+		return nil, 0, err
+	}
+	return &taskList, from, nil
+}
+
+// Encode writes list to w in the storage format, as JSON indented with
+// indent ("" for compact) and marked with the current format version
+func Encode(w io.Writer, list *models.TaskList, indent string) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", indent)
+	if err := encoder.Encode(versioned(list)); err != nil {
+		return errors.Join(apperrors.ErrStorageWrite, err)
+	}
+	return nil
+}
+
+// marshalIndent encodes v as JSON indented with indent, or compact JSON
+// when indent is ""
+func marshalIndent(v any, indent string) ([]byte, error) {
+	if indent == "" {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", indent)
+}
+
+// writeFile is replaced in tests to simulate failed writes
+var writeFile = os.WriteFile
+
+// Save writes the task list to the file using atomic write
+func (fs *FileStorage) Save(list *models.TaskList) error {
+	if fs.readOnly {
+		return apperrors.WrapStorageWriteError(apperrors.ErrReadOnlyStorage, fs.filepath)
+	}
+	if err := list.Validate(); err != nil {
+		return apperrors.WrapStorageWriteError(err, fs.filepath)
+	}
+
+	// Serialize to JSON, indented for readability unless compact JSON
+	// was asked for
+	data, err := marshalIndent(versioned(list), fs.indent)
+	if err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.filepath)
+	}
+
+	// Refuse to overwrite a change made by another process
+	if err := fs.checkUnchanged(); err != nil {
+		return apperrors.WrapStorageWriteError(err, fs.filepath)
+	}
+
+	// A file in an older format is kept as it was, for older todolists,
+	// before it is replaced in the current one
+	upgraded, err := fs.prepareUpgrade()
+	if err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.filepath)
+	}
+
+	// Keep the previous version around before replacing it
+	if err := fs.rotateBackups(data); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.filepath)
+	}
+
+	// Create missing parent directories, e.g. for a configured storage_path
+	if err := os.MkdirAll(filepath.Dir(fs.filepath), 0755); err != nil {
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.filepath)
+	}
+
+	// Use atomic write: write to temp file then rename
+	tempFile := fs.TempPath()
+
+	// Write to temporary file; a partial one left behind by a failed
+	// write (a full disk, say) would stop Load until it is removed
+	if err := writeFile(tempFile, data, 0644); err != nil {
+		os.Remove(tempFile)
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.filepath)
+	}
+
+	// Rename temp file to actual file (atomic operation)
+	if err := os.Rename(tempFile, fs.filepath); err != nil {
+		// Clean up temp file on error
+		os.Remove(tempFile)
+		if upgraded {
+			// The file was not upgraded after all
+			os.Remove(fs.UpgradePath())
+		}
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.filepath)
+	}
+
+	if stamp, err := fs.stat(); err == nil {
+		fs.setStamp(stamp, data)
+	}
+	fs.mu.Lock()
+	fs.outdated = false
+	fs.mu.Unlock()
+	return nil
+}
+
+// rotateBackups shifts existing backups by one and copies the current file
+// to the first backup slot. Nothing happens if the file does not exist yet
+// or its content equals the data about to be written.
+func (fs *FileStorage) rotateBackups(data []byte) error {
+	if fs.backupDepth == 0 {
+		return nil
+	}
+
+	current, err := os.ReadFile(fs.filepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Unchanged content does not need a new backup
+	if bytes.Equal(current, data) {
+		return nil
+	}
+
+	// Drop the oldest backup, then shift the rest up by one
+	if err := os.Remove(fs.BackupPath(fs.backupDepth)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for n := fs.backupDepth - 1; n >= 1; n-- {
+		if err := os.Rename(fs.BackupPath(n), fs.BackupPath(n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return os.WriteFile(fs.BackupPath(1), current, 0644)
+}
+
+// RestoreBackup swaps the most recent backup with the current file, so
+// running it twice returns to the original state
+func (fs *FileStorage) RestoreBackup() error {
+	backup := fs.BackupPath(1)
+	if _, err := os.Stat(backup); err != nil {
+		if os.IsNotExist(err) {
+			return apperrors.ErrNoBackup
+		}
+		return apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), backup)
+	}
+
+	// Park the current file (if any) so it can become the new backup
+	parked := fs.filepath + ".restore"
+	hasCurrent := true
+	if err := os.Rename(fs.filepath, parked); err != nil {
+		if !os.IsNotExist(err) {
+			return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.filepath)
+		}
+		hasCurrent = false
+	}
+
+	if err := os.Rename(backup, fs.filepath); err != nil {
+		// Put the original file back
+		if hasCurrent {
+			os.Rename(parked, fs.filepath)
+		}
+		return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.filepath)
+	}
+
+	if hasCurrent {
+		if err := os.Rename(parked, backup); err != nil {
+			return apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), backup)
+		}
+	}
+
+	return nil
+}
+
+// TempPath returns the temporary file Save writes before renaming it over
+// the task file
+func (fs *FileStorage) TempPath() string {
+	return fs.filepath + ".tmp"
+}
+
+// RemoveTemp deletes a temporary file left by an interrupted save. It
+// reports whether there was one.
+func (fs *FileStorage) RemoveTemp() (bool, error) {
+	if err := os.Remove(fs.TempPath()); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.TempPath())
+	}
+	return true, nil
+}
+
+// SetAsideCorrupt renames the task file to <path>.corrupt-<timestamp> so a
+// file that no longer loads (for example one truncated by an interrupted
+// write) is kept for inspection while the next Load starts an empty list.
+// It returns the new path of the file.
+func (fs *FileStorage) SetAsideCorrupt() (string, error) {
+	dest := fs.filepath + ".corrupt-" + backupNow().Format(backupTimeLayout)
+	if _, err := os.Lstat(dest); err == nil {
+		return "", apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, os.ErrExist), dest)
+	}
+	if err := os.Rename(fs.filepath, dest); err != nil {
+		return "", apperrors.WrapStorageWriteError(errors.Join(apperrors.ErrStorageWrite, err), fs.filepath)
+	}
+	return dest, nil
+}
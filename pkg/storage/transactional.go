@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"sync"
+	"todolist/pkg/models"
+)
+
+// TransactionalStorage wraps a Storage so that several changes can be
+// written as one. Used directly it passes Load and Save through; Begin
+// starts a Tx that holds saves in memory until Commit.
+type TransactionalStorage struct {
+	backend Storage
+}
+
+// NewTransactionalStorage creates a TransactionalStorage over backend
+func NewTransactionalStorage(backend Storage) *TransactionalStorage {
+	return &TransactionalStorage{backend: backend}
+}
+
+// Load loads the task list from the backend
+func (ts *TransactionalStorage) Load() (*models.TaskList, error) {
+	return ts.backend.Load()
+}
+
+// Save saves list to the backend
+func (ts *TransactionalStorage) Save(list *models.TaskList) error {
+	return ts.backend.Save(list)
+}
+
+// Begin starts a transaction on the backend
+func (ts *TransactionalStorage) Begin() *Tx {
+	return &Tx{backend: ts.backend}
+}
+
+// Tx is a transaction started by TransactionalStorage.Begin. It implements
+// Storage: Save only keeps the latest list in memory and Load returns it,
+// or the backend's list if nothing was saved yet. Commit writes the kept
+// list to the backend with a single Save; Rollback drops it. Either ends
+// the transaction, and the Tx can then be used for the next one.
+type Tx struct {
+	backend Storage
+
+	mu      sync.Mutex
+	pending *models.TaskList
+}
+
+// Load returns the list saved in the transaction, otherwise loads from the backend
+func (tx *Tx) Load() (*models.TaskList, error) {
+	tx.mu.Lock()
+	pending := tx.pending
+	tx.mu.Unlock()
+
+	if pending != nil {
+		return copyTaskList(pending), nil
+	}
+	return tx.backend.Load()
+}
+
+// Save keeps list as the state to commit; nothing is written
+func (tx *Tx) Save(list *models.TaskList) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.pending = copyTaskList(list)
+	return nil
+}
+
+// Pending reports whether the transaction has a list to commit
+func (tx *Tx) Pending() bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	return tx.pending != nil
+}
+
+// Commit writes the list saved in the transaction to the backend. Nothing
+// is written if nothing was saved. On failure the list stays pending, so
+// Commit can be retried or the transaction rolled back.
+func (tx *Tx) Commit() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.pending == nil {
+		return nil
+	}
+	if err := tx.backend.Save(tx.pending); err != nil {
+		return err
+	}
+	tx.pending = nil
+	return nil
+}
+
+// Rollback drops the list saved in the transaction without writing it
+func (tx *Tx) Rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.pending = nil
+}
+
+// copyTaskList returns a copy of list that shares no slice with it
+func copyTaskList(list *models.TaskList) *models.TaskList {
+	tasks := make([]models.Task, len(list.Tasks))
+	copy(tasks, list.Tasks)
+	return &models.TaskList{
+		Version: list.Version,
+		Tasks:   tasks,
+		NextID:  list.NextID,
+		Trash:   append([]models.TrashedTask(nil), list.Trash...),
+	}
+}
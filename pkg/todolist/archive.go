@@ -0,0 +1,85 @@
+package todolist
+
+import (
+	"errors"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+)
+
+// Archive moves every completed task to the archive file at archivePath,
+// appending to the tasks already there, and returns the number moved.
+// Archived tasks keep their IDs unless the archive already has a task with
+// the same ID, in which case they get a new one. If either file cannot be
+// saved, both are left as they were. Archiving is not recorded in the undo history, since
+// undo could only restore the active list and the tasks would end up in
+// both files.
+func (tl *TodoList) Archive(archivePath string) (moved int, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("archive", 0, "", time.Now(), &err)
+
+	return tl.archive(storage.NewFileStorage(archivePath))
+}
+
+// archive moves the completed tasks to archiveStorage. The caller holds the
+// write lock.
+func (tl *TodoList) archive(archiveStorage storage.Storage) (int, error) {
+	archived, err := archiveStorage.Load()
+	if err != nil {
+		return 0, apperrors.WrapWithContext(err, "failed to load archive")
+	}
+
+	// IDs restart after DeleteAll, so a task may come back with an ID the
+	// archive already holds; it then gets a new one above every ID in use
+	used := make(map[int]bool, len(archived.Tasks))
+	nextID := max(archived.NextID, tl.list.NextID)
+	for _, task := range archived.Tasks {
+		used[task.ID] = true
+		nextID = max(nextID, task.ID+1)
+	}
+
+	before := tl.snapshot()
+	remaining := make([]models.Task, 0, len(tl.list.Tasks))
+	moved := 0
+	updated := &models.TaskList{Version: archived.Version, Tasks: append([]models.Task(nil), archived.Tasks...)}
+	for _, task := range tl.list.Tasks {
+		if !task.Completed {
+			remaining = append(remaining, task)
+			continue
+		}
+		if used[task.ID] {
+			task.ID = nextID
+			nextID++
+		}
+		used[task.ID] = true
+		updated.Tasks = append(updated.Tasks, task)
+		moved++
+	}
+	updated.NextID = nextID
+	if moved == 0 {
+		return 0, nil
+	}
+	if tl.dryRun {
+		tl.setList(&models.TaskList{Version: tl.list.Version, Tasks: remaining, NextID: tl.list.NextID, Trash: tl.list.Trash})
+		return moved, nil
+	}
+
+	// Save the archive first so a failure never loses tasks
+	if err := archiveStorage.Save(updated); err != nil {
+		return 0, apperrors.WrapWithContext(err, "failed to save archive")
+	}
+
+	tl.setList(&models.TaskList{Version: tl.list.Version, Tasks: remaining, NextID: tl.list.NextID, Trash: tl.list.Trash})
+	if err := tl.save(tl.list); err != nil {
+		// Rollback both files on save failure
+		tl.setList(before)
+		if restoreErr := archiveStorage.Save(archived); restoreErr != nil {
+			err = errors.Join(err, apperrors.WrapWithContext(restoreErr, "failed to restore archive"))
+		}
+		return 0, apperrors.WrapWithContext(err, "failed to save task list after archiving")
+	}
+
+	return moved, nil
+}
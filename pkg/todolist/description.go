@@ -0,0 +1,61 @@
+package todolist
+
+import (
+	"fmt"
+	"strings"
+	apperrors "todolist/pkg/errors"
+	"unicode"
+)
+
+// DefaultMaxDescriptionLength is the longest description accepted, in
+// bytes, unless SetMaxDescriptionLength sets another limit
+const DefaultMaxDescriptionLength = 4096
+
+// NormalizeDescription makes a description fit on one line: every run of
+// line breaks (including U+2028 and U+2029), tabs and other control
+// whitespace becomes a single space, and the remaining control characters
+// are dropped. Ordinary spaces and printable characters are kept as they are.
+func NormalizeDescription(description string) string {
+	var b strings.Builder
+	b.Grow(len(description))
+	inBreak := false
+	for _, r := range description {
+		if !unicode.IsControl(r) && r != '\u2028' && r != '\u2029' {
+			b.WriteRune(r)
+			inBreak = false
+			continue
+		}
+		if unicode.IsSpace(r) && !inBreak {
+			b.WriteByte(' ')
+			inBreak = true
+		}
+	}
+	return b.String()
+}
+
+// SetMaxDescriptionLength sets the longest description, in bytes, that
+// adding or editing a task accepts; longer ones fail with
+// ErrDescriptionTooLong. A limit of 0 or less restores the default.
+func (tl *TodoList) SetMaxDescriptionLength(limit int) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.maxDescriptionLength = limit
+}
+
+// checkDescription returns description normalized with NormalizeDescription,
+// or ErrEmptyDescription or ErrDescriptionTooLong. The caller holds the lock.
+func (tl *TodoList) checkDescription(description string) (string, error) {
+	description = NormalizeDescription(description)
+	if strings.TrimSpace(description) == "" {
+		return "", apperrors.ErrEmptyDescription
+	}
+	limit := tl.maxDescriptionLength
+	if limit <= 0 {
+		limit = DefaultMaxDescriptionLength
+	}
+	if len(description) > limit {
+		return "", fmt.Errorf("%w: %d bytes, the limit is %d", apperrors.ErrDescriptionTooLong, len(description), limit)
+	}
+	return description, nil
+}
@@ -0,0 +1,84 @@
+package todolist
+
+import (
+	"todolist/pkg/models"
+)
+
+// GroupKey selects how tasks are split into groups
+type GroupKey string
+
+// Group keys accepted by list --group
+const (
+	GroupByStatus GroupKey = "status"
+)
+
+// TaskGroup is a named run of tasks
+type TaskGroup struct {
+	Name  string
+	Tasks []models.Task
+}
+
+// grouper assigns each task a group name. Groups are shown in order, and
+// groups not listed there follow in the order their first task appears.
+type grouper struct {
+	order []string
+	name  func(task models.Task) string
+}
+
+// groupers holds the grouping for each group key
+var groupers = map[GroupKey]grouper{
+	GroupByStatus: {
+		order: []string{"Pending", "Completed"},
+		name: func(task models.Task) string {
+			if task.Completed {
+				return "Completed"
+			}
+			return "Pending"
+		},
+	},
+}
+
+// ParseGroupKey converts a group key name to a GroupKey
+func ParseGroupKey(name string) (GroupKey, bool) {
+	key := GroupKey(name)
+	_, ok := groupers[key]
+	return key, ok
+}
+
+// GroupTasks splits tasks into groups by key, keeping the input order
+// within each group, so sorting first orders every group. Empty groups are
+// left out. Unknown keys put every task in a single unnamed group.
+func GroupTasks(tasks []models.Task, key GroupKey) []TaskGroup {
+	g, ok := groupers[key]
+	if !ok {
+		return []TaskGroup{{Tasks: tasks}}
+	}
+
+	names := append([]string(nil), g.order...)
+	byName := map[string][]models.Task{}
+	for _, task := range tasks {
+		name := g.name(task)
+		if _, seen := byName[name]; !seen && !contains(g.order, name) {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], task)
+	}
+
+	groups := []TaskGroup{}
+	for _, name := range names {
+		if len(byName[name]) > 0 {
+			groups = append(groups, TaskGroup{Name: name, Tasks: byName[name]})
+		}
+	}
+	return groups
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
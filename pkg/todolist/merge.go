@@ -0,0 +1,175 @@
+package todolist
+
+import (
+	"errors"
+	"strings"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// MergeResult lists what MergeTasks changed, or would change in dry-run mode
+type MergeResult struct {
+	// Added holds the tasks found only in the other list, with their IDs here
+	Added []models.Task
+	// Updated holds the tasks here that the merge marked as completed
+	Updated []models.Task
+	// Conflicts holds the tasks found in both lists whose details differ
+	Conflicts []MergeConflict
+}
+
+// MergeConflict is a task found in both lists with different details.
+// Ours is kept, apart from completion.
+type MergeConflict struct {
+	Ours   models.Task
+	Theirs models.Task
+}
+
+// contentKey matches tasks without a common UID: a task copied to another
+// list keeps its description and creation time
+type contentKey struct {
+	description string
+	createdAt   int64
+}
+
+// keyOf returns the content key of task
+func keyOf(task models.Task) contentKey {
+	return contentKey{task.Description, task.CreatedAt.UnixNano()}
+}
+
+// MergeTasks folds the tasks of another list into this one in a single
+// save. A task is found in both lists if it has the same UID, or else the
+// same description and creation time; each task here matches at most one
+// task there. A match completed in either list ends up completed, without
+// adding the next occurrence of a recurring task, since the other list
+// already has it if it was added there. Tasks only in the other list are
+// appended. They keep their ID unless it is taken here or used twice, in
+// which case they get the next free one, and their UID unless they have
+// none or it is taken. Nothing is saved if nothing changes, and the list
+// is left as it was if the save fails.
+func (tl *TodoList) MergeTasks(tasks []models.Task) (result MergeResult, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("merge", 0, "", time.Now(), &err)
+
+	byUID := make(map[string]int, len(tl.list.Tasks))
+	byContent := make(map[contentKey][]int, len(tl.list.Tasks))
+	for i, task := range tl.list.Tasks {
+		if task.UID != "" {
+			byUID[task.UID] = i
+		}
+		byContent[keyOf(task)] = append(byContent[keyOf(task)], i)
+	}
+
+	matched := make(map[int]bool)
+	// complete holds the tasks to complete here with their matches there
+	type match struct {
+		index  int
+		theirs models.Task
+	}
+	var complete []match
+	var unmatched []models.Task
+	for _, theirs := range tasks {
+		i, ok := byUID[theirs.UID]
+		if theirs.UID == "" || !ok || matched[i] {
+			// Lists saved before UIDs were added got theirs separately,
+			// so the same task may have different UIDs
+			ok = false
+			for _, j := range byContent[keyOf(theirs)] {
+				if !matched[j] {
+					i, ok = j, true
+					break
+				}
+			}
+		}
+		if !ok {
+			unmatched = append(unmatched, copyTask(theirs))
+			continue
+		}
+
+		matched[i] = true
+		ours := tl.list.Tasks[i]
+		if theirs.Completed && !ours.Completed {
+			complete = append(complete, match{i, theirs})
+		}
+		if !sameDetails(ours, theirs) {
+			result.Conflicts = append(result.Conflicts, MergeConflict{Ours: copyTask(ours), Theirs: copyTask(theirs)})
+		}
+	}
+	if len(complete) == 0 && len(unmatched) == 0 {
+		return result, nil
+	}
+
+	// Keep the IDs that are free here, then number the rest after them
+	used := make(map[int]bool, len(tl.index)+len(unmatched))
+	for id := range tl.index {
+		used[id] = true
+	}
+	nextID := tl.list.NextID
+	keep := make([]bool, len(unmatched))
+	for i, task := range unmatched {
+		if task.ID > 0 && !used[task.ID] {
+			keep[i] = true
+			used[task.ID] = true
+			nextID = max(nextID, task.ID+1)
+		}
+	}
+	for i := range unmatched {
+		if !keep[i] {
+			unmatched[i].ID = nextID
+			nextID++
+		}
+		// A UID seen twice in the other list stays unique here
+		if _, taken := byUID[unmatched[i].UID]; taken || unmatched[i].UID == "" {
+			unmatched[i].UID = models.NewUID()
+		}
+		byUID[unmatched[i].UID] = -1
+	}
+
+	now := tl.now()
+	before := tl.snapshot()
+	var events []models.Event
+	for _, m := range complete {
+		i := m.index
+		// Keep when it was completed there, if that was recorded
+		completedAt := now
+		if m.theirs.CompletedAt != nil {
+			completedAt = *m.theirs.CompletedAt
+		}
+		tl.list.Tasks[i].Completed = true
+		tl.list.Tasks[i].CompletedAt = &completedAt
+		result.Updated = append(result.Updated, copyTask(tl.list.Tasks[i]))
+		events = append(events, models.NewEvent(models.EventCompleted, tl.list.Tasks[i], now))
+	}
+	for _, task := range unmatched {
+		tl.list.Tasks = append(tl.list.Tasks, task)
+		tl.index[task.ID] = len(tl.list.Tasks) - 1
+		result.Added = append(result.Added, copyTask(task))
+		events = append(events, models.NewEvent(models.EventAdded, task, now))
+	}
+	tl.list.NextID = nextID
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return MergeResult{}, apperrors.WrapWithContext(err, "failed to save task list after merging")
+	}
+
+	return result, errors.Join(tl.recordHistory(before), tl.recordEvents(events))
+}
+
+// sameDetails reports whether two tasks agree on everything a user edits,
+// leaving out IDs, completion and tracked time
+func sameDetails(a, b models.Task) bool {
+	if a.Description != b.Description || a.Priority != b.Priority || a.Notes != b.Notes || a.Recurrence != b.Recurrence {
+		return false
+	}
+	if strings.Join(a.Tags, " ") != strings.Join(b.Tags, " ") {
+		return false
+	}
+	if a.DueDate == nil || b.DueDate == nil {
+		return a.DueDate == nil && b.DueDate == nil
+	}
+	return a.DueDate.Equal(*b.DueDate)
+}
@@ -0,0 +1,121 @@
+package todolist
+
+import (
+	"sort"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/quickadd"
+)
+
+// NoProject is the report group for tasks without a +project
+const NoProject = "(none)"
+
+// WeeklyReport summarizes the seven days starting at from: the tasks
+// added, completed and deleted, overall and grouped by +project (a task
+// with several counts in each) and by priority. Changes are read from the
+// event log. Without one, or for weeks before it was set up, only tasks
+// still in the list are counted, as added when they were created. The
+// completion trend is taken as of the end of the week, or now for the
+// current week, with weeks beginning on from's weekday.
+func (tl *TodoList) WeeklyReport(from time.Time) (models.WeeklyReport, error) {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	report := models.WeeklyReport{
+		Period: models.Period{Start: from, End: from.AddDate(0, 0, 7)},
+	}
+	at := tl.now()
+	if !at.Before(report.Period.End) {
+		at = report.Period.End.Add(-time.Nanosecond)
+	} else if at.Before(from) {
+		at = from
+	}
+	report.Trend = CompletionTrend(tl.list.Tasks, at.In(from.Location()), from.Weekday())
+
+	var logged []models.Event
+	if tl.events != nil {
+		var err error
+		if logged, err = tl.events.Events(); err != nil {
+			return models.WeeklyReport{}, apperrors.WrapWithContext(err, "failed to read event log")
+		}
+	}
+
+	var events []models.Event
+	added := map[int]bool{}
+	for _, event := range logged {
+		if report.Period.Contains(event.Time) {
+			events = append(events, event)
+			if event.Kind == models.EventAdded {
+				added[event.TaskID] = true
+			}
+		}
+	}
+	// Fill in additions the log does not know about
+	for _, task := range tl.list.Tasks {
+		if report.Period.Contains(task.CreatedAt) && !added[task.ID] {
+			events = append(events, models.NewEvent(models.EventAdded, task, task.CreatedAt))
+		}
+	}
+
+	projects := map[string]*models.ReportGroup{}
+	priorities := map[models.Priority]*models.ReportGroup{}
+	for _, event := range events {
+		count(&report.TasksAdded, &report.TasksCompleted, &report.TasksDeleted, event.Kind)
+
+		names := quickadd.Projects(event.Description)
+		if len(names) == 0 {
+			names = []string{NoProject}
+		}
+		for _, name := range names {
+			if projects[name] == nil {
+				projects[name] = &models.ReportGroup{Name: name}
+			}
+			countGroup(projects[name], event.Kind)
+		}
+
+		if priorities[event.Priority] == nil {
+			priorities[event.Priority] = &models.ReportGroup{Name: event.Priority.String()}
+		}
+		countGroup(priorities[event.Priority], event.Kind)
+	}
+
+	report.Projects = []models.ReportGroup{}
+	for _, group := range projects {
+		report.Projects = append(report.Projects, *group)
+	}
+	// Projects by name, with tasks without one last
+	sort.Slice(report.Projects, func(i, j int) bool {
+		a, b := report.Projects[i].Name, report.Projects[j].Name
+		if (a == NoProject) != (b == NoProject) {
+			return b == NoProject
+		}
+		return a < b
+	})
+
+	report.Priorities = []models.ReportGroup{}
+	for p := models.PriorityHigh; p >= models.PriorityNone; p-- {
+		if group := priorities[p]; group != nil {
+			report.Priorities = append(report.Priorities, *group)
+		}
+	}
+
+	return report, nil
+}
+
+// count increments the counter matching kind
+func count(added, completed, deleted *int, kind models.EventKind) {
+	switch kind {
+	case models.EventAdded:
+		*added++
+	case models.EventCompleted:
+		*completed++
+	case models.EventDeleted:
+		*deleted++
+	}
+}
+
+// countGroup counts an event of kind in group
+func countGroup(group *models.ReportGroup, kind models.EventKind) {
+	count(&group.Added, &group.Completed, &group.Deleted, kind)
+}
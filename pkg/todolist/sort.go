@@ -0,0 +1,68 @@
+package todolist
+
+import (
+	"sort"
+	"strings"
+	"todolist/pkg/models"
+)
+
+// SortKey selects the field tasks are ordered by
+type SortKey string
+
+// Sort keys accepted by list --sort and default_sort
+const (
+	SortByManual      SortKey = "manual"
+	SortByCreated     SortKey = "created"
+	SortByID          SortKey = "id"
+	SortByDescription SortKey = "description"
+	SortByStatus      SortKey = "status"
+)
+
+// sortLess holds the ordering for each sort key
+var sortLess = map[SortKey]func(a, b models.Task) bool{
+	// Keep the list order set by MoveTask
+	SortByManual: func(a, b models.Task) bool {
+		return false
+	},
+	SortByCreated: func(a, b models.Task) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	},
+	SortByID: func(a, b models.Task) bool {
+		return a.ID < b.ID
+	},
+	SortByDescription: func(a, b models.Task) bool {
+		return strings.ToLower(a.Description) < strings.ToLower(b.Description)
+	},
+	// Pending tasks come before completed ones
+	SortByStatus: func(a, b models.Task) bool {
+		return !a.Completed && b.Completed
+	},
+}
+
+// ParseSortKey converts a sort key name to a SortKey
+func ParseSortKey(name string) (SortKey, bool) {
+	key := SortKey(strings.ToLower(name))
+	_, ok := sortLess[key]
+	return key, ok
+}
+
+// SortTasks returns a copy of tasks ordered by key, descending if desc.
+// The sort is stable: tasks with equal keys keep their input order, which
+// is list order for the output of ListTasks. Unknown keys keep the
+// input order.
+func SortTasks(tasks []models.Task, key SortKey, desc bool) []models.Task {
+	sorted := make([]models.Task, len(tasks))
+	copy(sorted, tasks)
+
+	less, ok := sortLess[key]
+	if !ok {
+		return sorted
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if desc {
+			return less(sorted[j], sorted[i])
+		}
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
@@ -0,0 +1,38 @@
+package todolist
+
+import (
+	"sort"
+	"time"
+	"todolist/pkg/models"
+)
+
+// TaskAge returns how long ago a task was created
+func TaskAge(task models.Task) time.Duration {
+	return time.Since(task.CreatedAt)
+}
+
+// TasksByAgeDescending returns a copy of all tasks, oldest first. Tasks
+// created at the same time keep their list order.
+func (tl *TodoList) TasksByAgeDescending() []models.Task {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	tasks := tl.listTasks()
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+	return tasks
+}
+
+// ListStale returns the pending tasks created at least olderThan ago,
+// oldest first
+func (tl *TodoList) ListStale(olderThan time.Duration) []models.Task {
+	cutoff := tl.now().Add(-olderThan)
+	stale := []models.Task{}
+	for _, task := range tl.TasksByAgeDescending() {
+		if !task.Completed && !task.CreatedAt.After(cutoff) {
+			stale = append(stale, task)
+		}
+	}
+	return stale
+}
@@ -0,0 +1,31 @@
+package todolist
+
+import (
+	"strings"
+	"todolist/pkg/quickadd"
+)
+
+// ExtractTags removes the tags from description and returns what is left,
+// with runs of whitespace collapsed, and the tags without their # in order,
+// each once. What counts as a tag is decided by quickadd.Tag: a whole word
+// of # and letters, digits, '-', '_' or '/', not only digits, so "C#",
+// "a#b" and "#12" stay in the description. A description without tags is
+// returned as is.
+func ExtractTags(description string) (cleanDescription string, tags []string) {
+	words := strings.Fields(description)
+	kept := make([]string, 0, len(words))
+	for _, word := range words {
+		tag, ok := quickadd.Tag(word)
+		if !ok {
+			kept = append(kept, word)
+			continue
+		}
+		if !contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return description, nil
+	}
+	return strings.Join(kept, " "), tags
+}
@@ -0,0 +1,85 @@
+package todolist
+
+import (
+	"fmt"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// StartTimeTracking starts a timer on a task. A task has at most one
+// running timer; starting a second returns ErrTimerRunning.
+func (tl *TodoList) StartTimeTracking(id int) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("start_timer", id, tl.describe(id), time.Now(), &err)
+
+	return tl.updateTimeEntries(id, "starting timer on", func(entries []models.TimeEntry, now time.Time) ([]models.TimeEntry, error) {
+		for _, entry := range entries {
+			if entry.End == nil {
+				return nil, fmt.Errorf("%w on task %d since %s", apperrors.ErrTimerRunning, id, entry.Start.Format("2006-01-02 15:04"))
+			}
+		}
+		return append(entries, models.TimeEntry{Start: now}), nil
+	})
+}
+
+// StopTimeTracking stops the running timer on a task, or returns
+// ErrNoActiveTimer if there is none
+func (tl *TodoList) StopTimeTracking(id int) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("stop_timer", id, tl.describe(id), time.Now(), &err)
+
+	return tl.updateTimeEntries(id, "stopping timer on", func(entries []models.TimeEntry, now time.Time) ([]models.TimeEntry, error) {
+		for i := range entries {
+			if entries[i].End == nil {
+				entries[i].End = &now
+				return entries, nil
+			}
+		}
+		return nil, fmt.Errorf("%w on task %d", apperrors.ErrNoActiveTimer, id)
+	})
+}
+
+// TaskTimeSpent returns the total time tracked on a task, counting a
+// running timer up to now. Unknown tasks have no time spent.
+func (tl *TodoList) TaskTimeSpent(id int) time.Duration {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	index, err := tl.indexOf(id)
+	if err != nil {
+		return 0
+	}
+	return tl.list.Tasks[index].TimeSpent(tl.now())
+}
+
+// updateTimeEntries replaces a task's time entries with the result of fn,
+// which gets a copy it may modify, then saves. The caller holds the write
+// lock; action completes the save error message.
+func (tl *TodoList) updateTimeEntries(id int, action string, fn func(entries []models.TimeEntry, now time.Time) ([]models.TimeEntry, error)) error {
+	index, err := tl.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	// The snapshot shares the entries' backing array, so work on a copy
+	entries := append([]models.TimeEntry(nil), tl.list.Tasks[index].TimeEntries...)
+	entries, err = fn(entries, tl.now().Round(0))
+	if err != nil {
+		return err
+	}
+
+	before := tl.snapshot()
+	tl.list.Tasks[index].TimeEntries = entries
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return apperrors.WrapWithContext(err, "failed to save task after "+action+" it")
+	}
+
+	return tl.recordHistory(before)
+}
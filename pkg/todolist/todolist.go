@@ -0,0 +1,1776 @@
+// Package todolist is the todo list engine: TodoList loads a list from a
+// storage.Storage, applies changes with rollback on save failure and keeps
+// undo history. The todolist CLI is one client of this package.
+package todolist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/quickadd"
+	"todolist/pkg/recurrence"
+	"todolist/pkg/storage"
+)
+
+// TodoList manages the core business logic for todo items. It is safe for
+// concurrent use: every method holds an internal lock for its whole run,
+// so each change (including its save and rollback) is atomic with respect
+// to other calls, and the slices returned by the List methods are copies
+// that later changes do not affect.
+type TodoList struct {
+	// mu guards every field below
+	mu sync.RWMutex
+
+	list *models.TaskList
+	// index maps every task ID to its position in list.Tasks. Changes that
+	// replace the whole list go through setList; the rest update it in place.
+	index   map[int]int
+	storage storage.Storage
+	history storage.History
+	events  storage.EventLog
+	now     func() time.Time
+	dryRun  bool
+	logger  *slog.Logger
+	// noDuplicates makes every add refuse descriptions already in the list
+	noDuplicates bool
+	// maxDescriptionLength is the limit set by SetMaxDescriptionLength
+	maxDescriptionLength int
+	// maxTasks is the limit set by SetMaxTasks; 0 means none
+	maxTasks int
+	notifier ChangeNotifier
+	// referrers are set by SetIDReferrer, keyed by name
+	referrers map[string]IDReferrer
+}
+
+// TaskOption sets an optional field on a task being added; an error
+// rejects the task
+type TaskOption func(*models.Task) error
+
+// WithDueDate sets the due date of a new task
+func WithDueDate(due time.Time) TaskOption {
+	return func(task *models.Task) error {
+		task.DueDate = &due
+		return nil
+	}
+}
+
+// NewTodoList creates a new TodoList instance and loads initial data from storage
+func NewTodoList(storage storage.Storage) (*TodoList, error) {
+	list, err := storage.Load()
+	if err != nil {
+		return nil, apperrors.WrapWithContext(err, "failed to initialize todo list")
+	}
+
+	tl := &TodoList{
+		storage: storage,
+		now:     time.Now,
+	}
+	assignUIDs(list)
+	purgeTrash(list, tl.now())
+	tl.setList(list)
+	return tl, nil
+}
+
+// assignUIDs gives every task without a UID a new one, so that lists saved
+// before UIDs existed get them on their next save
+func assignUIDs(list *models.TaskList) {
+	for i := range list.Tasks {
+		if list.Tasks[i].UID == "" {
+			list.Tasks[i].UID = models.NewUID()
+		}
+	}
+}
+
+// Reload replaces the in-memory list with the one in storage, picking up
+// changes made by other processes since it was loaded. The list is left
+// alone if loading fails.
+func (tl *TodoList) Reload() error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.reload()
+}
+
+// reload replaces the in-memory list with the one in storage. The caller
+// must hold the write lock.
+func (tl *TodoList) reload() error {
+	list, err := tl.storage.Load()
+	if err != nil {
+		return apperrors.WrapWithContext(err, "failed to reload todo list")
+	}
+	assignUIDs(list)
+	purgeTrash(list, tl.now())
+	tl.setList(list)
+	return nil
+}
+
+// Stale reports whether storage changed since the list was last loaded or
+// saved. Storages that cannot tell are never stale.
+func (tl *TodoList) Stale() (bool, error) {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	detector, ok := tl.storage.(storage.ChangeDetector)
+	if !ok {
+		return false, nil
+	}
+	return detector.Modified()
+}
+
+// LoadFromReader replaces the task list with one read from r in the
+// storage format (see storage.Decode) and saves it. The list must pass
+// Validate. The previous list is recorded in the undo history and kept if
+// the save fails.
+func (tl *TodoList) LoadFromReader(r io.Reader) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("load", 0, "", time.Now(), &err)
+
+	list, err := storage.Decode(r, "input")
+	if err != nil {
+		return err
+	}
+	if err := list.Validate(); err != nil {
+		return err
+	}
+
+	before := tl.snapshot()
+	assignUIDs(list)
+	tl.setList(list)
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return apperrors.WrapWithContext(err, "failed to save task list after loading")
+	}
+
+	return tl.recordHistory(before)
+}
+
+// SaveToWriter writes the task list to w in the storage format, indented
+// as storage saves it (see JSONIndent)
+func (tl *TodoList) SaveToWriter(w io.Writer) error {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	return storage.Encode(w, tl.list, tl.JSONIndent())
+}
+
+// JSONIndent returns the indentation storage saves JSON with, or
+// storage.DefaultIndent if the storage does not say
+func (tl *TodoList) JSONIndent() string {
+	if indenter, ok := tl.storage.(storage.Indenter); ok {
+		return indenter.Indent()
+	}
+	return storage.DefaultIndent
+}
+
+// eventTask returns the task an event is about as it is now, or the fields
+// the event kept of it once it has been deleted. The caller holds the lock.
+func (tl *TodoList) eventTask(event models.Event) models.Task {
+	if i, ok := tl.index[event.TaskID]; ok && event.Kind != models.EventDeleted {
+		return copyTask(tl.list.Tasks[i])
+	}
+	return models.Task{ID: event.TaskID, Description: event.Description, Priority: event.Priority}
+}
+
+// setList replaces the task list and rebuilds the ID index for it
+func (tl *TodoList) setList(list *models.TaskList) {
+	tl.list = list
+	tl.index = make(map[int]int, len(list.Tasks))
+	for i, task := range list.Tasks {
+		tl.index[task.ID] = i
+	}
+}
+
+// SetHistory enables undo by recording a snapshot before every change
+func (tl *TodoList) SetHistory(history storage.History) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.history = history
+}
+
+// ChangeNotifier is told about every saved addition, completion and
+// deletion, for example to post it to a webhook
+type ChangeNotifier interface {
+	// Notify is called with the lock held, so it must not block. task is
+	// the task after the change, or what the event kept of a deleted one.
+	Notify(event models.Event, task models.Task)
+}
+
+// SetNotifier passes every recorded event to notifier; nil stops it. Dry
+// runs notify nothing.
+func (tl *TodoList) SetNotifier(notifier ChangeNotifier) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.notifier = notifier
+}
+
+// SetEventLog records every addition, completion and deletion in log,
+// which WeeklyReport reads
+func (tl *TodoList) SetEventLog(log storage.EventLog) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.events = log
+}
+
+// IDReferrer is something kept outside the task list that refers to tasks
+// by ID, such as the reminder state
+type IDReferrer interface {
+	// ReferencedIDs returns the task IDs it refers to
+	ReferencedIDs() ([]int, error)
+}
+
+// SetIDReferrer makes RenumberIDs refuse to change any ID referrer refers
+// to; name says what it is in the error. A nil referrer removes name.
+func (tl *TodoList) SetIDReferrer(name string, referrer IDReferrer) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if referrer == nil {
+		delete(tl.referrers, name)
+		return
+	}
+	if tl.referrers == nil {
+		tl.referrers = map[string]IDReferrer{}
+	}
+	tl.referrers[name] = referrer
+}
+
+// SetDryRun makes mutating methods change only the in-memory list: nothing
+// is saved and no undo history is recorded
+func (tl *TodoList) SetDryRun(dryRun bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.dryRun = dryRun
+}
+
+// SetNoDuplicates makes AddTask, and every other method adding tasks, return
+// ErrDuplicateDescription when a task, pending or completed, already has
+// the description, ignoring case and surrounding whitespace. Tasks added
+// together are also checked against each other.
+func (tl *TodoList) SetNoDuplicates(noDuplicates bool) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.noDuplicates = noDuplicates
+}
+
+// SetMaxTasks caps the number of tasks in the list: adding beyond maxTasks
+// fails with ErrMaxTasksReached. 0 or less means no limit.
+func (tl *TodoList) SetMaxTasks(maxTasks int) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.maxTasks = max(maxTasks, 0)
+}
+
+// checkRoom returns ErrMaxTasksReached unless n more tasks fit under the
+// limit set by SetMaxTasks. The caller holds the lock.
+func (tl *TodoList) checkRoom(n int) error {
+	if tl.maxTasks > 0 && len(tl.list.Tasks)+n > tl.maxTasks {
+		return fmt.Errorf("%w: the list holds %d of at most %d tasks", apperrors.ErrMaxTasksReached, len(tl.list.Tasks), tl.maxTasks)
+	}
+	return nil
+}
+
+// hasDescription reports whether a task has the description, ignoring case
+// and surrounding whitespace
+func (tl *TodoList) hasDescription(description string) bool {
+	description = strings.TrimSpace(description)
+	for _, task := range tl.list.Tasks {
+		if strings.EqualFold(strings.TrimSpace(task.Description), description) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDuplicate returns ErrDuplicateDescription when duplicates are off
+// and description is already in the list or in adding, the descriptions of
+// the tasks being added with it so far (keyed by descriptionKey). The
+// caller holds the lock.
+func (tl *TodoList) checkDuplicate(description string, adding map[string]bool) error {
+	if !tl.noDuplicates {
+		return nil
+	}
+	if tl.hasDescription(description) || adding[descriptionKey(description)] {
+		return fmt.Errorf("%w: %q", apperrors.ErrDuplicateDescription, strings.TrimSpace(description))
+	}
+	return nil
+}
+
+// descriptionKey is what descriptions are compared by for duplicates
+func descriptionKey(description string) string {
+	return strings.ToLower(strings.TrimSpace(description))
+}
+
+// SetLogger logs every change to the list through logger at Info level,
+// with its duration and error. A nil logger, the default, logs nothing.
+func (tl *TodoList) SetLogger(logger *slog.Logger) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.logger = logger
+}
+
+// describe returns the description of task id for logging, or "" when
+// there is no logger or no such task
+func (tl *TodoList) describe(id int) string {
+	if tl.logger == nil {
+		return ""
+	}
+	if i, ok := tl.index[id]; ok {
+		return tl.list.Tasks[i].Description
+	}
+	return ""
+}
+
+// logChange logs a change that started at start once it has finished with
+// *err. Callers defer it with a named error result; id and description
+// are left out for changes to the whole list (id 0).
+func (tl *TodoList) logChange(op string, id int, description string, start time.Time, err *error) {
+	if tl.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{slog.String("op", op)}
+	if id != 0 {
+		attrs = append(attrs, slog.Int("task_id", id), slog.String("description", description))
+	}
+	attrs = append(attrs, slog.Int64("elapsed_ms", time.Since(start).Milliseconds()))
+	if *err != nil {
+		attrs = append(attrs, slog.Any("error", *err))
+	}
+	tl.logger.LogAttrs(context.Background(), slog.LevelInfo, "task list changed", attrs...)
+}
+
+// save writes list to storage unless running dry
+func (tl *TodoList) save(list *models.TaskList) error {
+	if tl.dryRun {
+		return nil
+	}
+	return tl.storage.Save(list)
+}
+
+// snapshot returns a copy of the current task list and its trash
+func (tl *TodoList) snapshot() *models.TaskList {
+	tasks := make([]models.Task, len(tl.list.Tasks))
+	copy(tasks, tl.list.Tasks)
+	return &models.TaskList{
+		Version: tl.list.Version,
+		Tasks:   tasks,
+		NextID:  tl.list.NextID,
+		Trash:   append([]models.TrashedTask(nil), tl.list.Trash...),
+	}
+}
+
+// recordHistory stores the pre-change snapshot once a change has been
+// saved, along with the list as the change left it
+func (tl *TodoList) recordHistory(before *models.TaskList) error {
+	if tl.history == nil || tl.dryRun {
+		return nil
+	}
+	if err := tl.history.Push(before, tl.snapshot()); err != nil {
+		return fmt.Errorf("%w in the undo history: %w", apperrors.ErrNotRecorded, err)
+	}
+	return nil
+}
+
+// recordEvents appends the events of a change once it has been saved and
+// passes them to the notifier
+func (tl *TodoList) recordEvents(events []models.Event) error {
+	if tl.dryRun {
+		return nil
+	}
+	if tl.notifier != nil {
+		for _, event := range events {
+			tl.notifier.Notify(event, tl.eventTask(event))
+		}
+	}
+	if tl.events == nil {
+		return nil
+	}
+	if err := tl.events.Append(events); err != nil {
+		return fmt.Errorf("%w in the event log: %w", apperrors.ErrNotRecorded, err)
+	}
+	return nil
+}
+
+// Undo reverts the most recent recorded change. Calling it again steps
+// further back in history. Returns ErrNothingToUndo when there is no history,
+// and ErrUndoConflict, leaving the history as it was, when the tasks are no
+// longer as that change left them: restoring the list from before it would
+// also throw away whatever changed them since. In dry-run mode the list is
+// reverted in memory only and the change stays in the history.
+func (tl *TodoList) Undo() (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("undo", 0, "", time.Now(), &err)
+
+	if tl.history == nil {
+		return apperrors.ErrNothingToUndo
+	}
+
+	previous, after, err := tl.history.Pop()
+	if err != nil {
+		return err
+	}
+
+	if after == nil || !sameTasks(after, tl.list) {
+		err := apperrors.ErrUndoConflict
+		if pushErr := tl.history.Push(previous, after); pushErr != nil {
+			err = errors.Join(err, pushErr)
+		}
+		return err
+	}
+	if err := tl.save(previous); err != nil {
+		// Keep the snapshot available for another attempt
+		tl.history.Push(previous, after)
+		return apperrors.WrapWithContext(err, "failed to save task list after undo")
+	}
+	if tl.dryRun {
+		if err := tl.history.Push(previous, after); err != nil {
+			return err
+		}
+	}
+	tl.setList(previous)
+
+	return nil
+}
+
+// sameTasks reports whether two lists hold the same tasks, as they would be
+// saved, and the same NextID
+func sameTasks(a, b *models.TaskList) bool {
+	if a.NextID != b.NextID || len(a.Tasks) != len(b.Tasks) {
+		return false
+	}
+	if len(a.Tasks) == 0 {
+		return true
+	}
+	encodedA, errA := json.Marshal(a.Tasks)
+	encodedB, errB := json.Marshal(b.Tasks)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+// WithPriority sets the priority of a new task
+func WithPriority(priority models.Priority) TaskOption {
+	return func(task *models.Task) error {
+		task.Priority = priority
+		return nil
+	}
+}
+
+// WithRecurrence makes a new task recurring; see package recurrence for specs
+func WithRecurrence(spec string) TaskOption {
+	return func(task *models.Task) error {
+		task.Recurrence = spec
+		return nil
+	}
+}
+
+// WithEstimate sets the estimated effort of a new task in minutes
+func WithEstimate(minutes int) TaskOption {
+	return func(task *models.Task) error {
+		task.EstimateMinutes = minutes
+		return nil
+	}
+}
+
+// WithNotes sets the free-text notes of a new task
+func WithNotes(notes string) TaskOption {
+	return func(task *models.Task) error {
+		task.Notes = notes
+		return nil
+	}
+}
+
+// WithQuickAdd parses inline tokens such as due:fri and !high out of the
+// description (see package quickadd), relative to now. Parsed tags are
+// added to the task's own, and parsed fields are overridden by options
+// given after it.
+func WithQuickAdd(now time.Time) TaskOption {
+	return func(task *models.Task) error {
+		result, err := quickadd.Parse(task.Description, now)
+		if err != nil {
+			return err
+		}
+		task.Description = result.Description
+		if result.Due != nil {
+			task.DueDate = result.Due
+		}
+		if result.Priority != models.PriorityNone {
+			task.Priority = result.Priority
+		}
+		for _, tag := range result.Tags {
+			if !contains(task.Tags, tag) {
+				task.Tags = append(task.Tags, tag)
+			}
+		}
+		return nil
+	}
+}
+
+// AddTask adds a new task to the list. Tags written as #tag words are
+// moved from the description to Tags (see ExtractTags).
+func (tl *TodoList) AddTask(description string, opts ...TaskOption) (added *models.Task, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer func(start time.Time) {
+		if added != nil {
+			tl.logChange("add", added.ID, added.Description, start, &err)
+		} else {
+			tl.logChange("add", 0, "", start, &err)
+		}
+	}(time.Now())
+
+	// Create new task; #tag words become tags, leaving the rest for the
+	// empty description check
+	task := models.Task{
+		ID:        tl.list.NextID,
+		Completed: false,
+		CreatedAt: tl.now(),
+	}
+	task.Description, task.Tags = ExtractTags(NormalizeDescription(description))
+	for _, opt := range opts {
+		if err := opt(&task); err != nil {
+			return nil, err
+		}
+	}
+	return tl.addTask(task)
+}
+
+// addTask validates task, appends it under the next ID and saves, undoing
+// the append if the save fails. The caller holds the lock.
+func (tl *TodoList) addTask(task models.Task) (*models.Task, error) {
+	description, err := tl.checkDescription(task.Description)
+	if err != nil {
+		return nil, err
+	}
+	if err := tl.checkAdd(description); err != nil {
+		return nil, err
+	}
+	task.Description = description
+	if task.Recurrence != "" {
+		if err := recurrence.Validate(task.Recurrence); err != nil {
+			return nil, err
+		}
+	}
+
+	task.UID = models.NewUID()
+	before, err := tl.appendAndSave(&task)
+	if apperrors.IsConcurrentModification(err) {
+		// An add cannot clash with the other change, so pick it up and
+		// append to that list instead, as long as the task still fits it
+		if reloadErr := tl.reload(); reloadErr != nil {
+			return nil, errors.Join(err, reloadErr)
+		}
+		if err := tl.checkAdd(description); err != nil {
+			return nil, err
+		}
+		before, err = tl.appendAndSave(&task)
+	}
+	if err != nil {
+		return nil, apperrors.WrapWithContext(err, "failed to save task after adding")
+	}
+
+	events := []models.Event{models.NewEvent(models.EventAdded, task, task.CreatedAt)}
+	if err := errors.Join(tl.recordHistory(before), tl.recordEvents(events)); err != nil {
+		return &task, err
+	}
+	return &task, nil
+}
+
+// checkAdd returns the error adding one task with description to the list
+// as it is now would break max_tasks or no_duplicates with
+func (tl *TodoList) checkAdd(description string) error {
+	if err := tl.checkRoom(1); err != nil {
+		return err
+	}
+	return tl.checkDuplicate(description, nil)
+}
+
+// appendAndSave gives task the next ID, appends it and saves the list,
+// returning the list as it was before. The append is rolled back if the
+// save fails.
+func (tl *TodoList) appendAndSave(task *models.Task) (*models.TaskList, error) {
+	task.ID = tl.list.NextID
+	before := tl.snapshot()
+
+	tl.list.Tasks = append(tl.list.Tasks, *task)
+	tl.index[task.ID] = len(tl.list.Tasks) - 1
+	tl.list.NextID++
+
+	if err := tl.save(tl.list); err != nil {
+		tl.list.Tasks = tl.list.Tasks[:len(tl.list.Tasks)-1]
+		delete(tl.index, task.ID)
+		tl.list.NextID--
+		return nil, err
+	}
+	return before, nil
+}
+
+// DuplicateTask adds a pending copy of the task with the given ID, keeping
+// its description, priority, notes, tags and estimate but with a new ID and
+// creation time. It fails with ErrDuplicateDescription when duplicates are
+// off (see SetNoDuplicates).
+func (tl *TodoList) DuplicateTask(id int) (duplicate *models.Task, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("copy", id, tl.describe(id), time.Now(), &err)
+
+	taskIndex, err := tl.indexOf(id)
+	if err != nil {
+		return nil, err
+	}
+	original := tl.list.Tasks[taskIndex]
+
+	return tl.addTask(models.Task{
+		Description: original.Description,
+		Completed:   false,
+		CreatedAt:   tl.now(),
+		Priority:    original.Priority,
+		Notes:       original.Notes,
+		Tags:        append([]string(nil), original.Tags...),
+		// Same work, same effort
+		EstimateMinutes: original.EstimateMinutes,
+	})
+}
+
+// AddTasks adds a task for every description in a single save, extracting
+// tags like AddTask. Nothing is added if any description is blank or a
+// duplicate refused by SetNoDuplicates, the tasks do not all fit under
+// SetMaxTasks or the save fails.
+func (tl *TodoList) AddTasks(descriptions []string, opts ...TaskOption) (added []models.Task, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("add_all", 0, "", time.Now(), &err)
+
+	if len(descriptions) == 0 {
+		return nil, nil
+	}
+	tasks := make([]models.Task, len(descriptions))
+	for i, description := range descriptions {
+		tasks[i].Description, tasks[i].Tags = ExtractTags(NormalizeDescription(description))
+		for _, opt := range opts {
+			if err := opt(&tasks[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tl.importTasks(tasks)
+}
+
+// BulkAddTasks adds a task for every valid description in a single save,
+// extracting tags like AddTask, and skips the invalid ones, duplicates
+// refused by SetNoDuplicates and those past SetMaxTasks instead of
+// rejecting the batch. The returned slices are parallel to descriptions: a
+// created task and a nil error, or a nil task and the reason it was
+// skipped. If the save fails nothing is added and every would-be task
+// reports the save error. As with AddTask, tasks that were saved may still
+// come with an error from recording the undo history or events.
+func (tl *TodoList) BulkAddTasks(descriptions []string) ([]*models.Task, []error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tasks := make([]*models.Task, len(descriptions))
+	errs := make([]error, len(descriptions))
+	valid := make([]models.Task, 0, len(descriptions))
+	positions := make([]int, 0, len(descriptions))
+	adding := make(map[string]bool, len(descriptions))
+	defer func(start time.Time) {
+		// One record per description, as if each had been added alone
+		for i := range descriptions {
+			if tasks[i] != nil {
+				tl.logChange("add", tasks[i].ID, tasks[i].Description, start, &errs[i])
+			} else {
+				tl.logChange("add", 0, "", start, &errs[i])
+			}
+		}
+	}(time.Now())
+	for i, description := range descriptions {
+		description, tags := ExtractTags(NormalizeDescription(description))
+		description, err := tl.checkDescription(description)
+		if err == nil {
+			err = tl.checkDuplicate(description, adding)
+		}
+		if err == nil {
+			// Descriptions past the task limit are skipped like invalid ones
+			err = tl.checkRoom(len(valid) + 1)
+		}
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		adding[descriptionKey(description)] = true
+		valid = append(valid, models.Task{Description: description, Tags: tags})
+		positions = append(positions, i)
+	}
+	if len(valid) == 0 {
+		return tasks, errs
+	}
+
+	added, err := tl.importTasks(valid)
+	for j, i := range positions {
+		if added != nil {
+			tasks[i] = &added[j]
+		}
+		errs[i] = err
+	}
+	return tasks, errs
+}
+
+// ImportTasks adds already-built tasks (for example parsed from an import
+// file) with fresh IDs in a single save. Description, completion, priority,
+// dates and UID are kept; a zero CreatedAt is set to now and a missing UID
+// is generated. Tasks whose UID is already in the list, or earlier in
+// tasks, are skipped as duplicates. Nothing is added if any description is
+// blank or a duplicate refused by SetNoDuplicates, the tasks do not all fit
+// under SetMaxTasks or the save fails.
+func (tl *TodoList) ImportTasks(tasks []models.Task) (imported []models.Task, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("import", 0, "", time.Now(), &err)
+
+	seen := make(map[string]bool, len(tl.list.Tasks)+len(tasks))
+	for _, task := range tl.list.Tasks {
+		seen[task.UID] = true
+	}
+	fresh := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.UID != "" && seen[task.UID] {
+			continue
+		}
+		seen[task.UID] = true
+		fresh = append(fresh, task)
+	}
+	if len(fresh) == 0 {
+		return []models.Task{}, nil
+	}
+	return tl.importTasks(fresh)
+}
+
+// importTasks implements ImportTasks; the caller holds the write lock
+func (tl *TodoList) importTasks(tasks []models.Task) ([]models.Task, error) {
+	if err := tl.checkRoom(len(tasks)); err != nil {
+		return nil, err
+	}
+	adding := make(map[string]bool, len(tasks))
+	for i := range tasks {
+		description, err := tl.checkDescription(tasks[i].Description)
+		if err != nil {
+			return nil, err
+		}
+		if err := tl.checkDuplicate(description, adding); err != nil {
+			return nil, err
+		}
+		adding[descriptionKey(description)] = true
+		tasks[i].Description = description
+	}
+
+	before := tl.snapshot()
+	imported := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		task.ID = tl.list.NextID
+		if task.CreatedAt.IsZero() {
+			task.CreatedAt = tl.now()
+		}
+		if task.UID == "" {
+			task.UID = models.NewUID()
+		}
+		tl.list.Tasks = append(tl.list.Tasks, task)
+		tl.index[task.ID] = len(tl.list.Tasks) - 1
+		tl.list.NextID++
+		imported = append(imported, task)
+	}
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return nil, apperrors.WrapWithContext(err, "failed to save tasks after importing")
+	}
+
+	events := make([]models.Event, len(imported))
+	for i, task := range imported {
+		events[i] = models.NewEvent(models.EventAdded, task, tl.now())
+	}
+	if err := errors.Join(tl.recordHistory(before), tl.recordEvents(events)); err != nil {
+		return imported, err
+	}
+	return imported, nil
+}
+
+// TaskList returns a copy of the whole list, including NextID, for saving
+// elsewhere such as in a snapshot
+func (tl *TodoList) TaskList() *models.TaskList {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+	return tl.snapshot()
+}
+
+// ListTasks returns a copy of all tasks in list order: the order they were
+// added in, unless MoveTask changed it
+func (tl *TodoList) ListTasks() []models.Task {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+	return tl.listTasks()
+}
+
+// listTasks implements ListTasks; the caller holds the lock
+func (tl *TodoList) listTasks() []models.Task {
+	// Create a copy of the tasks slice
+	tasks := make([]models.Task, len(tl.list.Tasks))
+	copy(tasks, tl.list.Tasks)
+
+	// Tasks are kept in list order; MoveTask is the only thing that
+	// reorders them
+
+	return tasks
+}
+
+// ListTasksInRange returns the tasks created at or after since and before
+// until, in list order. A zero since or until leaves that end open.
+func (tl *TodoList) ListTasksInRange(since, until time.Time) []models.Task {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, task := range tl.list.Tasks {
+		if !since.IsZero() && task.CreatedAt.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !task.CreatedAt.Before(until) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// ListTasksPaginated returns up to limit tasks starting at offset, in
+// creation order, along with the total number of tasks. A limit of 0
+// returns everything from offset on.
+func (tl *TodoList) ListTasksPaginated(offset, limit int) ([]models.Task, int, error) {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	tasks := tl.listTasks()
+	page, err := Paginate(tasks, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return page, len(tasks), nil
+}
+
+// Paginate returns the window of tasks selected by offset and limit (0 means
+// no limit). An offset past the end yields an empty slice. Returns
+// ErrInvalidPagination if either is negative.
+func Paginate(tasks []models.Task, offset, limit int) ([]models.Task, error) {
+	if offset < 0 || limit < 0 {
+		return nil, apperrors.ErrInvalidPagination
+	}
+	if offset > len(tasks) {
+		offset = len(tasks)
+	}
+	end := len(tasks)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return tasks[offset:end], nil
+}
+
+// ListByFilter returns the tasks matching filter in creation order. Storages
+// implementing storage.Filterer select them themselves; otherwise, and in
+// dry-run mode where the storage is behind, the loaded list is filtered.
+func (tl *TodoList) ListByFilter(filter storage.StorageFilter) ([]models.Task, error) {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	if f, ok := tl.storage.(storage.Filterer); ok && !tl.dryRun {
+		return f.ListByFilter(filter)
+	}
+	return storage.FilterTasks(tl.list.Tasks, filter), nil
+}
+
+// ListOverdue returns incomplete tasks whose due date has passed
+func (tl *TodoList) ListOverdue() []models.Task {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+	return tl.listOverdue()
+}
+
+// listOverdue implements ListOverdue; the caller holds the lock
+func (tl *TodoList) listOverdue() []models.Task {
+	now := tl.now()
+	overdue := []models.Task{}
+	for _, task := range tl.list.Tasks {
+		if task.IsOverdue(now) {
+			overdue = append(overdue, task)
+		}
+	}
+	return overdue
+}
+
+// CountOverdue returns the number of overdue tasks
+func (tl *TodoList) CountOverdue() int {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+	return len(tl.listOverdue())
+}
+
+// DueBefore returns the pending tasks due before t, earliest first. With t
+// the start of tomorrow, these are the tasks due today or earlier.
+func (tl *TodoList) DueBefore(t time.Time) []models.Task {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+	return tl.dueBetween(time.Time{}, t)
+}
+
+// DueBetween returns the pending tasks due at or after from and before to,
+// earliest first
+func (tl *TodoList) DueBetween(from, to time.Time) []models.Task {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+	return tl.dueBetween(from, to)
+}
+
+// dueBetween implements DueBefore and DueBetween; the caller holds the lock
+func (tl *TodoList) dueBetween(from, to time.Time) []models.Task {
+	due := []models.Task{}
+	for _, task := range tl.list.Tasks {
+		if !task.Completed && task.DueDate != nil && !task.DueDate.Before(from) && task.DueDate.Before(to) {
+			due = append(due, task)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].DueDate.Before(*due[j].DueDate)
+	})
+	return due
+}
+
+// Stats returns task counts for the whole list
+func (tl *TodoList) Stats() models.TaskStats {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	stats := models.TaskStats{}
+	stats.Total, stats.Pending, stats.Completed = tl.countTasks()
+	stats.OverdueCount = len(tl.listOverdue())
+	for _, task := range tl.list.Tasks {
+		stats.TotalEstimatedMinutes += task.EstimateMinutes
+		if task.Completed {
+			stats.CompletedEstimatedMinutes += task.EstimateMinutes
+		}
+	}
+	return stats
+}
+
+// Count returns the number of tasks matching filter, counted from the list
+// in memory without loading or saving
+func (tl *TodoList) Count(filter storage.StorageFilter) int {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	count := 0
+	for _, task := range tl.list.Tasks {
+		if filter.Match(task) {
+			count++
+		}
+	}
+	return count
+}
+
+// countTasks counts all, pending and completed tasks for Stats; the caller
+// holds the lock
+func (tl *TodoList) countTasks() (total, pending, completed int) {
+	for _, task := range tl.list.Tasks {
+		if task.Completed {
+			completed++
+		} else {
+			pending++
+		}
+	}
+	return len(tl.list.Tasks), pending, completed
+}
+
+// indexOf validates id and returns the position of its task in the list
+func (tl *TodoList) indexOf(id int) (int, error) {
+	if id <= 0 {
+		return -1, apperrors.ErrInvalidID
+	}
+	if i, ok := tl.index[id]; ok {
+		return i, nil
+	}
+	return -1, &apperrors.TaskNotFoundError{ID: id}
+}
+
+// GetTask returns a copy of the task with the given ID
+func (tl *TodoList) GetTask(id int) (models.Task, error) {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	taskIndex, err := tl.indexOf(id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	return copyTask(tl.list.Tasks[taskIndex]), nil
+}
+
+// GetTaskByDescription returns a copy of the first task, in list order,
+// whose description is exactly desc (case-sensitive), or ErrTaskNotFound.
+// When duplicates are allowed several tasks can match; the first is
+// returned and the others are reported as a warning to the logger.
+func (tl *TodoList) GetTaskByDescription(desc string) (*models.Task, error) {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	var found *models.Task
+	matches := 0
+	for _, task := range tl.list.Tasks {
+		if task.Description != desc {
+			continue
+		}
+		if matches++; found == nil {
+			task := copyTask(task)
+			found = &task
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: no task has the description %q", apperrors.ErrTaskNotFound, desc)
+	}
+	if matches > 1 && tl.logger != nil {
+		tl.logger.LogAttrs(context.Background(), slog.LevelWarn, "several tasks have the description",
+			slog.String("description", desc), slog.Int("matches", matches), slog.Int("task_id", found.ID))
+	}
+	return found, nil
+}
+
+// FindPending returns the pending tasks whose description contains text,
+// ignoring case, in list order
+func (tl *TodoList) FindPending(text string) []models.Task {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	text = strings.ToLower(text)
+	found := []models.Task{}
+	for _, task := range tl.list.Tasks {
+		if !task.Completed && strings.Contains(strings.ToLower(task.Description), text) {
+			found = append(found, copyTask(task))
+		}
+	}
+	return found
+}
+
+// ResolveID returns the ID a task reference stands for: the reference
+// itself if it is a number, or else the ID of the one task whose UID starts
+// with the prefix it names (see models.ParseUIDRef), ignoring case. A
+// prefix matching several tasks is rejected with ErrInvalidID, one matching
+// none with ErrTaskNotFound.
+func (tl *TodoList) ResolveID(ref string) (int, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return id, nil
+	}
+	prefix, ok := models.ParseUIDRef(ref)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q is neither a number nor a UID prefix of %d or more characters (write %s%s for a shorter one)",
+			apperrors.ErrInvalidID, ref, models.MinUIDPrefixLength, models.UIDRefPrefix, ref)
+	}
+
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	prefix = strings.ToLower(prefix)
+	id, matches := 0, 0
+	for _, task := range tl.list.Tasks {
+		if strings.HasPrefix(strings.ToLower(task.UID), prefix) {
+			id = task.ID
+			matches++
+		}
+	}
+	switch matches {
+	case 0:
+		return 0, fmt.Errorf("%w: no task has a UID starting with %s", apperrors.ErrTaskNotFound, prefix)
+	case 1:
+		return id, nil
+	default:
+		return 0, fmt.Errorf("%w: UID prefix %s matches %d tasks", apperrors.ErrInvalidID, prefix, matches)
+	}
+}
+
+// UpdateTask applies fn to a copy of the task with the given ID and, if fn
+// succeeds and the result is valid, stores and saves it. The result must
+// keep its ID and UID and have a non-blank description. Nothing changes if fn or
+// the validation fails; the change is rolled back if the save fails.
+func (tl *TodoList) UpdateTask(id int, fn func(*models.Task) error) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("update", id, tl.describe(id), time.Now(), &err)
+
+	taskIndex, err := tl.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	updated := copyTask(tl.list.Tasks[taskIndex])
+	if err := fn(&updated); err != nil {
+		return err
+	}
+	if updated.ID != id {
+		return fmt.Errorf("%w: task %d cannot change its ID to %d", apperrors.ErrInvalidID, id, updated.ID)
+	}
+	if updated.UID != tl.list.Tasks[taskIndex].UID {
+		return fmt.Errorf("%w: task %d cannot change its UID", apperrors.ErrInvalidID, id)
+	}
+	if updated.Description, err = tl.checkDescription(updated.Description); err != nil {
+		return err
+	}
+	if updated.Recurrence != "" {
+		if err := recurrence.Validate(updated.Recurrence); err != nil {
+			return err
+		}
+	}
+	if reflect.DeepEqual(updated, tl.list.Tasks[taskIndex]) {
+		return nil
+	}
+
+	before := tl.snapshot()
+	tl.list.Tasks[taskIndex] = updated
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return apperrors.WrapWithContext(err, "failed to save task after updating")
+	}
+
+	return tl.recordHistory(before)
+}
+
+// SetNote replaces the notes of the task with the given ID, or with appendNote
+// adds note on a new line after the existing notes. Replacing with an empty
+// note clears it. The change is rolled back if the save fails.
+func (tl *TodoList) SetNote(id int, note string, appendNote bool) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("note", id, tl.describe(id), time.Now(), &err)
+
+	taskIndex, err := tl.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	notes := note
+	if current := tl.list.Tasks[taskIndex].Notes; appendNote && current != "" {
+		notes = current
+		if note != "" {
+			notes += "\n" + note
+		}
+	}
+	if notes == tl.list.Tasks[taskIndex].Notes {
+		return nil
+	}
+
+	before := tl.snapshot()
+	tl.list.Tasks[taskIndex].Notes = notes
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return apperrors.WrapWithContext(err, "failed to save task after setting its note")
+	}
+
+	return tl.recordHistory(before)
+}
+
+// copyTask returns a copy of task that shares no memory with it
+func copyTask(task models.Task) models.Task {
+	if task.DueDate != nil {
+		due := *task.DueDate
+		task.DueDate = &due
+	}
+	if task.CompletedAt != nil {
+		completedAt := *task.CompletedAt
+		task.CompletedAt = &completedAt
+	}
+	if task.Tags != nil {
+		task.Tags = append([]string(nil), task.Tags...)
+	}
+	if task.TimeEntries != nil {
+		task.TimeEntries = append([]models.TimeEntry(nil), task.TimeEntries...)
+		for i, entry := range task.TimeEntries {
+			if entry.End != nil {
+				end := *entry.End
+				task.TimeEntries[i].End = &end
+			}
+		}
+	}
+	return task
+}
+
+// CompleteTask marks a task as completed
+func (tl *TodoList) CompleteTask(id int) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("complete", id, tl.describe(id), time.Now(), &err)
+
+	taskIndex, err := tl.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	// Mark as completed
+	before := tl.snapshot()
+	events, err := tl.completeAt(taskIndex)
+	if err != nil {
+		return err
+	}
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return apperrors.WrapWithContext(err, "failed to save task after completing")
+	}
+
+	return errors.Join(tl.recordHistory(before), tl.recordEvents(events))
+}
+
+// completeAt marks the task at index completed now. A pending recurring task
+// also gets its next occurrence appended, due at the first occurrence after
+// now counted from its due date (or from now without one), with the same
+// description, priority, notes, tags and recurrence. It returns the events to
+// log for the change; the caller saves.
+func (tl *TodoList) completeAt(index int) ([]models.Event, error) {
+	task := tl.list.Tasks[index]
+	if task.Completed {
+		return nil, nil
+	}
+	now := tl.now()
+	events := []models.Event{models.NewEvent(models.EventCompleted, task, now)}
+
+	if task.Recurrence != "" {
+		from := now
+		if task.DueDate != nil {
+			from = *task.DueDate
+		}
+		due, err := recurrence.NextOccurrence(from, task.Recurrence)
+		for err == nil && !due.After(now) {
+			due, err = recurrence.NextOccurrence(due, task.Recurrence)
+		}
+		if err != nil {
+			return nil, apperrors.WrapWithContext(err, fmt.Sprintf("task %d", task.ID))
+		}
+
+		next := models.Task{
+			ID:          tl.list.NextID,
+			UID:         models.NewUID(),
+			Description: task.Description,
+			CreatedAt:   now,
+			DueDate:     &due,
+			Priority:    task.Priority,
+			Notes:       task.Notes,
+			Tags:        append([]string(nil), task.Tags...),
+			Recurrence:  task.Recurrence,
+			// Each occurrence takes the same effort
+			EstimateMinutes: task.EstimateMinutes,
+		}
+		tl.list.Tasks = append(tl.list.Tasks, next)
+		tl.index[next.ID] = len(tl.list.Tasks) - 1
+		tl.list.NextID++
+		events = append(events, models.NewEvent(models.EventAdded, next, now))
+	}
+
+	tl.list.Tasks[index].Completed = true
+	tl.list.Tasks[index].CompletedAt = &now
+	return events, nil
+}
+
+// CompleteAll marks every pending task as completed in a single save and
+// returns how many tasks changed
+func (tl *TodoList) CompleteAll() (completed int, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("complete_all", 0, "", time.Now(), &err)
+
+	before := tl.snapshot()
+
+	// Next occurrences appended along the way stay pending
+	count := 0
+	var events []models.Event
+	for i, n := 0, len(tl.list.Tasks); i < n; i++ {
+		if tl.list.Tasks[i].Completed {
+			continue
+		}
+		completed, err := tl.completeAt(i)
+		if err != nil {
+			tl.setList(before)
+			return 0, err
+		}
+		events = append(events, completed...)
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return 0, apperrors.WrapWithContext(err, "failed to save task list after completing all tasks")
+	}
+
+	return count, errors.Join(tl.recordHistory(before), tl.recordEvents(events))
+}
+
+// DeleteTask moves a task to the trash, from which RestoreTask can bring
+// it back
+func (tl *TodoList) DeleteTask(id int) error {
+	return tl.deleteTask(id, false)
+}
+
+// PurgeTask deletes a task permanently, bypassing the trash
+func (tl *TodoList) PurgeTask(id int) error {
+	return tl.deleteTask(id, true)
+}
+
+// deleteTask removes a task from the list, keeping it in the trash unless
+// purge is set
+func (tl *TodoList) deleteTask(id int, purge bool) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("delete", id, tl.describe(id), time.Now(), &err)
+
+	taskIndex, err := tl.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	before := tl.snapshot()
+	deletedTask := tl.list.Tasks[taskIndex]
+
+	// Remove task from list; the tasks after it move up by one
+	tl.list.Tasks = append(tl.list.Tasks[:taskIndex], tl.list.Tasks[taskIndex+1:]...)
+	delete(tl.index, id)
+	for i := taskIndex; i < len(tl.list.Tasks); i++ {
+		tl.index[tl.list.Tasks[i].ID] = i
+	}
+	if !purge {
+		tl.list.Trash = append(tl.list.Trash, models.TrashedTask{Task: deletedTask, DeletedAt: tl.now()})
+	}
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure. The removal shifted tasks within the
+		// shared backing array, so restore the snapshot rather than
+		// re-inserting into the mutated slice.
+		tl.setList(before)
+		return apperrors.WrapWithContext(err, "failed to save task after deleting")
+	}
+
+	events := []models.Event{models.NewEvent(models.EventDeleted, deletedTask, tl.now())}
+	return errors.Join(tl.recordHistory(before), tl.recordEvents(events))
+}
+
+// DeleteAll moves every task to the trash and restarts IDs at 1, returning
+// the number of tasks deleted
+func (tl *TodoList) DeleteAll() (int, error) {
+	return tl.deleteAll(false)
+}
+
+// PurgeAll deletes every task permanently, bypassing the trash, and
+// restarts IDs at 1
+func (tl *TodoList) PurgeAll() (int, error) {
+	return tl.deleteAll(true)
+}
+
+// deleteAll removes every task, keeping them in the trash unless purge is
+// set
+func (tl *TodoList) deleteAll(purge bool) (deleted int, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("delete_all", 0, "", time.Now(), &err)
+
+	before := tl.snapshot()
+	count := len(tl.list.Tasks)
+
+	if !purge {
+		now := tl.now()
+		for _, task := range tl.list.Tasks {
+			tl.list.Trash = append(tl.list.Trash, models.TrashedTask{Task: task, DeletedAt: now})
+		}
+	}
+	tl.list.Tasks = []models.Task{}
+	tl.index = map[int]int{}
+	tl.list.NextID = 1
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return 0, apperrors.WrapWithContext(err, "failed to save task list after deleting all tasks")
+	}
+
+	events := make([]models.Event, len(before.Tasks))
+	for i, task := range before.Tasks {
+		events[i] = models.NewEvent(models.EventDeleted, task, tl.now())
+	}
+	return count, errors.Join(tl.recordHistory(before), tl.recordEvents(events))
+}
+
+// PruneCompleted deletes every task completed before cutoff in a single
+// save and returns how many were deleted. Tasks completed before
+// completion times were recorded use their creation time instead.
+func (tl *TodoList) PruneCompleted(cutoff time.Time) (pruned int, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("prune", 0, "", time.Now(), &err)
+
+	now := tl.now()
+	remaining := make([]models.Task, 0, len(tl.list.Tasks))
+	var events []models.Event
+	for _, task := range tl.list.Tasks {
+		completedAt := task.CreatedAt
+		if task.CompletedAt != nil {
+			completedAt = *task.CompletedAt
+		}
+		if task.Completed && completedAt.Before(cutoff) {
+			events = append(events, models.NewEvent(models.EventDeleted, task, now))
+			continue
+		}
+		remaining = append(remaining, task)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	before := tl.snapshot()
+	tl.setList(&models.TaskList{Version: tl.list.Version, Tasks: remaining, NextID: tl.list.NextID, Trash: tl.list.Trash})
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return 0, apperrors.WrapWithContext(err, "failed to save task list after pruning")
+	}
+
+	return len(events), errors.Join(tl.recordHistory(before), tl.recordEvents(events))
+}
+
+// RenumberIDs reassigns task IDs as 1, 2, 3, ... in list order and resets
+// NextID to follow the last one. Undo snapshots hold whole lists, so undo
+// restores the old IDs together with the tasks. The event log and any
+// IDReferrer refer to tasks by ID and cannot follow the change, so it is
+// refused with ErrIDsReferenced if either refers to an ID a task would
+// give up or take.
+func (tl *TodoList) RenumberIDs() (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("renumber", 0, "", time.Now(), &err)
+
+	tasks := make([]models.Task, len(tl.list.Tasks))
+	changing := map[int]bool{}
+	for i, task := range tl.list.Tasks {
+		if task.ID != i+1 {
+			changing[task.ID] = true
+			changing[i+1] = true
+		}
+		task.ID = i + 1
+		tasks[i] = task
+	}
+	if len(changing) == 0 && tl.list.NextID == len(tasks)+1 {
+		return nil
+	}
+	if err := tl.checkReferences(changing); err != nil {
+		return err
+	}
+
+	before := tl.snapshot()
+	tl.setList(&models.TaskList{Version: tl.list.Version, Tasks: tasks, NextID: len(tasks) + 1, Trash: tl.list.Trash})
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return apperrors.WrapWithContext(err, "failed to save task list after renumbering")
+	}
+
+	return tl.recordHistory(before)
+}
+
+// checkReferences returns ErrIDsReferenced naming the first source, the
+// event log or a referrer, that refers to any of ids
+func (tl *TodoList) checkReferences(ids map[int]bool) error {
+	sources := map[string]func() ([]int, error){}
+	if tl.events != nil {
+		sources["the event log"] = func() ([]int, error) {
+			logged, err := tl.events.Events()
+			if err != nil {
+				return nil, err
+			}
+			referenced := make([]int, len(logged))
+			for i, event := range logged {
+				referenced[i] = event.TaskID
+			}
+			return referenced, nil
+		}
+	}
+	for name, referrer := range tl.referrers {
+		sources[name] = referrer.ReferencedIDs
+	}
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		referenced, err := sources[name]()
+		if err != nil {
+			return apperrors.WrapWithContext(err, "failed to read "+name)
+		}
+		var found []int
+		seen := map[int]bool{}
+		for _, id := range referenced {
+			if ids[id] && !seen[id] {
+				seen[id] = true
+				found = append(found, id)
+			}
+		}
+		if len(found) > 0 {
+			sort.Ints(found)
+			list := make([]string, len(found))
+			for i, id := range found {
+				list[i] = strconv.Itoa(id)
+			}
+			return fmt.Errorf("%w: %s refers to task %s", apperrors.ErrIDsReferenced, name, strings.Join(list, ", "))
+		}
+	}
+	return nil
+}
+
+// MoveTask moves task id to just before task beforeID in the list order, or
+// to the end when beforeID is 0. IDs and creation dates do not change.
+func (tl *TodoList) MoveTask(id, beforeID int) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("move", id, tl.describe(id), time.Now(), &err)
+
+	from, err := tl.indexOf(id)
+	if err != nil {
+		return err
+	}
+	if beforeID != 0 {
+		if _, err := tl.indexOf(beforeID); err != nil {
+			return err
+		}
+	}
+	if id == beforeID {
+		return fmt.Errorf("%w: task %d cannot be moved before itself", apperrors.ErrInvalidID, id)
+	}
+	before := tl.snapshot()
+
+	// Take the task out, then insert it before beforeID or at the end
+	tasks := make([]models.Task, 0, len(tl.list.Tasks))
+	tasks = append(tasks, tl.list.Tasks[:from]...)
+	tasks = append(tasks, tl.list.Tasks[from+1:]...)
+	to := len(tasks)
+	for i, task := range tasks {
+		if task.ID == beforeID {
+			to = i
+			break
+		}
+	}
+	tasks = append(tasks[:to], append([]models.Task{tl.list.Tasks[from]}, tasks[to:]...)...)
+	tl.setList(&models.TaskList{Version: tl.list.Version, Tasks: tasks, NextID: tl.list.NextID, Trash: tl.list.Trash})
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return apperrors.WrapWithContext(err, "failed to save tasks after moving")
+	}
+
+	return tl.recordHistory(before)
+}
+
+// MoveTaskTo moves task id to index newIndex (0 being the top) in the list
+// order. An index out of range moves it to the top or the end instead.
+func (tl *TodoList) MoveTaskTo(id, newIndex int) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("move", id, tl.describe(id), time.Now(), &err)
+
+	from, err := tl.indexOf(id)
+	if err != nil {
+		return err
+	}
+	return tl.moveIndex(from, newIndex)
+}
+
+// MoveTaskBy moves task id offset places down the list order, or up for a
+// negative offset, stopping at the top or the end
+func (tl *TodoList) MoveTaskBy(id, offset int) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("move", id, tl.describe(id), time.Now(), &err)
+
+	from, err := tl.indexOf(id)
+	if err != nil {
+		return err
+	}
+	return tl.moveIndex(from, from+offset)
+}
+
+// moveIndex moves the task at index from to index to, clamped to the list.
+// The caller holds the write lock.
+func (tl *TodoList) moveIndex(from, to int) error {
+	to = max(0, min(to, len(tl.list.Tasks)-1))
+	if to == from {
+		return nil
+	}
+	before := tl.snapshot()
+
+	tasks := make([]models.Task, 0, len(tl.list.Tasks))
+	tasks = append(tasks, tl.list.Tasks[:from]...)
+	tasks = append(tasks, tl.list.Tasks[from+1:]...)
+	tasks = append(tasks[:to], append([]models.Task{tl.list.Tasks[from]}, tasks[to:]...)...)
+	tl.setList(&models.TaskList{Version: tl.list.Version, Tasks: tasks, NextID: tl.list.NextID, Trash: tl.list.Trash})
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return apperrors.WrapWithContext(err, "failed to save tasks after moving")
+	}
+
+	return tl.recordHistory(before)
+}
+
+// RestoreBackup swaps the storage's most recent backup back in and reloads
+// the task list from it. The previous list is recorded in the undo history.
+func (tl *TodoList) RestoreBackup() (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("restore", 0, "", time.Now(), &err)
+
+	restorer, ok := tl.storage.(storage.Restorer)
+	if !ok {
+		return apperrors.ErrNoBackup
+	}
+
+	if err := restorer.RestoreBackup(); err != nil {
+		return apperrors.WrapWithContext(err, "failed to restore backup")
+	}
+
+	before := tl.snapshot()
+	list, err := tl.storage.Load()
+	if err != nil {
+		return apperrors.WrapWithContext(err, "failed to load restored backup")
+	}
+	tl.setList(list)
+
+	return tl.recordHistory(before)
+}
+
+// RestoreFromFile validates that backupPath holds a task list and writes it
+// to active, creating a pre-restore backup first when active supports it.
+// Returns ErrInvalidJSON if the file is not a valid task list.
+func RestoreFromFile(backupPath string, active storage.Storage) error {
+	list, err := readTaskListFile(backupPath)
+	if err != nil {
+		return err
+	}
+
+	if backuper, ok := active.(storage.Backuper); ok {
+		if _, err := backuper.Backup(); err != nil {
+			return apperrors.WrapWithContext(err, "failed to create pre-restore backup")
+		}
+	}
+
+	if err := active.Save(list); err != nil {
+		return apperrors.WrapWithContext(err, "failed to save restored task list")
+	}
+	return nil
+}
+
+// readTaskListFile strictly parses a task list file: unknown fields, a
+// non-positive next_id or a task ID at or above next_id are rejected
+func readTaskListFile(path string) (*models.TaskList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, apperrors.WrapStorageReadError(errors.Join(apperrors.ErrStorageRead, err), path)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var list models.TaskList
+	if err := decoder.Decode(&list); err != nil {
+		return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, err), path)
+	}
+
+	if list.NextID < 1 {
+		return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, errors.New("next_id must be positive")), path)
+	}
+	for _, task := range list.Tasks {
+		if task.ID <= 0 || task.ID >= list.NextID {
+			return nil, apperrors.WrapJSONError(errors.Join(apperrors.ErrInvalidJSON, errors.New("task IDs must be positive and below next_id")), path)
+		}
+	}
+	if list.Tasks == nil {
+		list.Tasks = []models.Task{}
+	}
+
+	return &list, nil
+}
+
+// RestoreFromFile replaces the task list with the contents of a backup
+// file (see the package-level RestoreFromFile) and reloads it. The previous
+// list is recorded in the undo history.
+func (tl *TodoList) RestoreFromFile(backupPath string) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("restore", 0, "", time.Now(), &err)
+
+	before := tl.snapshot()
+
+	if tl.dryRun {
+		list, err := readTaskListFile(backupPath)
+		if err != nil {
+			return err
+		}
+		tl.setList(list)
+		return nil
+	}
+
+	if err := RestoreFromFile(backupPath, tl.storage); err != nil {
+		return err
+	}
+
+	list, err := tl.storage.Load()
+	if err != nil {
+		return apperrors.WrapWithContext(err, "failed to load restored task list")
+	}
+	tl.setList(list)
+
+	return tl.recordHistory(before)
+}
@@ -0,0 +1,4321 @@
+package todolist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	_ "time/tzdata" // Fixed zones for the daylight saving trend tests
+	"todolist/internal/config"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/recurrence"
+	"todolist/pkg/storage"
+	"unicode"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// Test file for core business logic
+func TestPlaceholder(t *testing.T) {
+	// Placeholder test to verify test framework is working
+	t.Log("Test framework is set up correctly")
+}
+
+// Basic integration test to verify core functionality
+func TestTodoListBasicOperations(t *testing.T) {
+	// Create storage and todolist
+	storage := &mockStorage{data: nil}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	// Test AddTask
+	task1, err := tl.AddTask("Test task 1")
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if task1.ID != 1 {
+		t.Errorf("Expected task ID 1, got %d", task1.ID)
+	}
+	if task1.Description != "Test task 1" {
+		t.Errorf("Expected description 'Test task 1', got '%s'", task1.Description)
+	}
+	if task1.Completed {
+		t.Error("New task should not be completed")
+	}
+
+	// Test AddTask with empty description
+	_, err = tl.AddTask("   ")
+	if !apperrors.IsEmptyDescription(err) {
+		t.Errorf("Expected apperrors.ErrEmptyDescription, got %v", err)
+	}
+
+	// Add another task
+	task2, err := tl.AddTask("Test task 2")
+	if err != nil {
+		t.Fatalf("Failed to add second task: %v", err)
+	}
+	if task2.ID != 2 {
+		t.Errorf("Expected task ID 2, got %d", task2.ID)
+	}
+
+	// Test ListTasks
+	tasks := tl.ListTasks()
+	if len(tasks) != 2 {
+		t.Errorf("Expected 2 tasks, got %d", len(tasks))
+	}
+
+	// Test CompleteTask
+	err = tl.CompleteTask(1)
+	if err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+
+	// Verify task is completed
+	tasks = tl.ListTasks()
+	if !tasks[0].Completed {
+		t.Error("Task 1 should be completed")
+	}
+
+	// Test CompleteTask with invalid ID
+	err = tl.CompleteTask(999)
+	if !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected apperrors.ErrTaskNotFound, got %v", err)
+	}
+
+	// Test DeleteTask
+	err = tl.DeleteTask(1)
+	if err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+
+	// Verify task is deleted
+	tasks = tl.ListTasks()
+	if len(tasks) != 1 {
+		t.Errorf("Expected 1 task after deletion, got %d", len(tasks))
+	}
+	if tasks[0].ID != 2 {
+		t.Errorf("Expected remaining task to have ID 2, got %d", tasks[0].ID)
+	}
+
+	// Test DeleteTask with invalid ID
+	err = tl.DeleteTask(999)
+	if !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected apperrors.ErrTaskNotFound, got %v", err)
+	}
+}
+
+// mockStorage is a simple in-memory storage for testing
+type mockStorage struct {
+	data *models.TaskList
+}
+
+func (ms *mockStorage) Load() (*models.TaskList, error) {
+	if ms.data == nil {
+		return &models.TaskList{
+			Tasks:  []models.Task{},
+			NextID: 1,
+		}, nil
+	}
+	return ms.data, nil
+}
+
+func (ms *mockStorage) Save(list *models.TaskList) error {
+	// Deep copy to simulate persistence
+	tasks := make([]models.Task, len(list.Tasks))
+	copy(tasks, list.Tasks)
+	ms.data = &models.TaskList{
+		Version: list.Version,
+		Tasks:   tasks,
+		NextID:  list.NextID,
+		Trash:   append([]models.TrashedTask(nil), list.Trash...),
+	}
+	return nil
+}
+
+// Feature: todo-list-cli, Property 1: 添加任务增加列表长度
+// For any valid (non-empty) task description, adding it to the task list should increase the list length by 1
+// Validates: Requirements 1.1
+func TestProperty_AddTaskIncreasesLength(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("adding valid task increases list length by 1",
+		prop.ForAll(
+			func(description string) bool {
+				// Create fresh storage and todolist for each test
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+
+				// Get initial length
+				initialLength := len(tl.ListTasks())
+
+				// Add task
+				task, err := tl.AddTask(description)
+				if err != nil {
+					return false
+				}
+
+				// Verify task was created
+				if task == nil {
+					return false
+				}
+
+				// Get new length
+				newLength := len(tl.ListTasks())
+
+				// Verify length increased by exactly 1
+				return newLength == initialLength+1
+			},
+			// Generate non-empty strings (after trimming whitespace)
+			gen.AnyString().SuchThat(func(s string) bool {
+				return strings.TrimSpace(NormalizeDescription(s)) != ""
+			}),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 2: 空白任务被拒绝
+// For any string composed entirely of whitespace characters, attempting to add it as a task should be rejected and the task list should remain unchanged
+// Validates: Requirements 1.2
+func TestProperty_BlankTasksRejected(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("blank tasks are rejected and list remains unchanged",
+		prop.ForAll(
+			func(whitespaceStr string) bool {
+				// Create fresh storage and todolist for each test
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+
+				// Get initial length
+				initialLength := len(tl.ListTasks())
+
+				// Attempt to add blank task
+				task, err := tl.AddTask(whitespaceStr)
+
+				// Verify error is returned
+				if !apperrors.IsEmptyDescription(err) {
+					return false
+				}
+
+				// Verify no task was created
+				if task != nil {
+					return false
+				}
+
+				// Verify list length unchanged
+				newLength := len(tl.ListTasks())
+				if newLength != initialLength {
+					return false
+				}
+
+				return true
+			},
+			// Generate strings composed entirely of whitespace characters
+			gen.OneGenOf(
+				gen.Const(""),         // Empty string
+				gen.Const(" "),        // Single space
+				gen.Const("  "),       // Multiple spaces
+				gen.Const("\t"),       // Tab
+				gen.Const("\n"),       // Newline
+				gen.Const("\r"),       // Carriage return
+				gen.Const("   \t  "),  // Mixed spaces and tabs
+				gen.Const("\n\n"),     // Multiple newlines
+				gen.Const(" \t\n\r "), // All whitespace types
+				gen.Const("     "),    // Many spaces
+				gen.Const("\t\t\t"),   // Many tabs
+			),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 3: 任务 ID 唯一性
+// For any sequence of tasks, each task added to the list should have a unique ID, and IDs should be incrementing
+// Validates: Requirements 1.3
+func TestProperty_TaskIDUniqueness(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("each task has unique and incrementing ID",
+		prop.ForAll(
+			func(count int) bool {
+				// Create fresh storage and todolist for each test
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+
+				// Track all IDs we've seen
+				seenIDs := make(map[int]bool)
+				var previousID int = 0
+
+				// Add tasks and verify ID uniqueness and incrementing
+				for i := 0; i < count; i++ {
+					desc := "Task " + string(rune('A'+i%26))
+					task, err := tl.AddTask(desc)
+					if err != nil {
+						return false
+					}
+
+					// Check ID is unique
+					if seenIDs[task.ID] {
+						return false // Duplicate ID found
+					}
+					seenIDs[task.ID] = true
+
+					// Check ID is incrementing (greater than previous)
+					if task.ID <= previousID {
+						return false // ID not incrementing
+					}
+					previousID = task.ID
+				}
+
+				// Verify all tasks in the list have unique IDs
+				listedTasks := tl.ListTasks()
+				listIDs := make(map[int]bool)
+				for _, task := range listedTasks {
+					if listIDs[task.ID] {
+						return false // Duplicate ID in list
+					}
+					listIDs[task.ID] = true
+				}
+
+				return true
+			},
+			// Generate a count between 1 and 20
+			gen.IntRange(1, 20),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 6: 列表操作返回所有任务
+// For any task list state, calling ListTasks should return all tasks in the list with complete information
+// Validates: Requirements 2.1, 2.2
+func TestProperty_ListTasksReturnsAllTasks(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("ListTasks returns all tasks with complete information",
+		prop.ForAll(
+			func(descriptions []string) bool {
+				// Create fresh storage and todolist for each test
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+
+				// Add all tasks and track them
+				addedTasks := make([]*models.Task, 0, len(descriptions))
+				for _, desc := range descriptions {
+					task, err := tl.AddTask(desc)
+					if err != nil {
+						return false
+					}
+					addedTasks = append(addedTasks, task)
+				}
+
+				// Get list of tasks
+				listedTasks := tl.ListTasks()
+
+				// Verify count matches
+				if len(listedTasks) != len(addedTasks) {
+					return false
+				}
+
+				// Verify each added task appears in the list with complete information
+				for i, addedTask := range addedTasks {
+					listedTask := listedTasks[i]
+
+					// Check all fields are preserved
+					if listedTask.ID != addedTask.ID {
+						return false
+					}
+					if listedTask.Description != addedTask.Description {
+						return false
+					}
+					if listedTask.Completed != addedTask.Completed {
+						return false
+					}
+					// Check CreatedAt is preserved (allowing for minor time differences due to copying)
+					if !listedTask.CreatedAt.Equal(addedTask.CreatedAt) {
+						return false
+					}
+				}
+
+				return true
+			},
+			// Generate slices of non-empty strings
+			gen.SliceOf(
+				gen.AnyString().SuchThat(func(s string) bool {
+					return strings.TrimSpace(NormalizeDescription(s)) != ""
+				}),
+			),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Unit test for empty list edge case
+// Tests that an empty list returns an empty slice (not nil)
+// Validates: Requirements 2.3
+func TestEmptyListReturnsEmptySlice(t *testing.T) {
+	// Create storage and todolist with no tasks
+	storage := &mockStorage{data: nil}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	// Get list of tasks from empty list
+	tasks := tl.ListTasks()
+
+	// Verify it returns an empty slice, not nil
+	if tasks == nil {
+		t.Error("ListTasks should return an empty slice, not nil")
+	}
+
+	// Verify the length is 0
+	if len(tasks) != 0 {
+		t.Errorf("Expected empty list to have length 0, got %d", len(tasks))
+	}
+
+	// Verify we can safely iterate over it
+	count := 0
+	for range tasks {
+		count++
+	}
+	if count != 0 {
+		t.Errorf("Expected 0 iterations over empty list, got %d", count)
+	}
+}
+
+// Feature: todo-list-cli, Property 7: 任务按创建时间排序
+// For any task list, ListTasks should return tasks sorted by creation time in ascending order
+// Validates: Requirements 2.4
+func TestProperty_TasksSortedByCreationTime(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("tasks are sorted by creation time in ascending order",
+		prop.ForAll(
+			func(descriptions []string) bool {
+				// Skip empty lists
+				if len(descriptions) == 0 {
+					return true
+				}
+
+				// Create fresh storage and todolist for each test
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+
+				// Add all tasks
+				for _, desc := range descriptions {
+					_, err := tl.AddTask(desc)
+					if err != nil {
+						return false
+					}
+				}
+
+				// Get list of tasks
+				listedTasks := tl.ListTasks()
+
+				// Verify tasks are sorted by creation time (ascending)
+				for i := 0; i < len(listedTasks)-1; i++ {
+					currentTask := listedTasks[i]
+					nextTask := listedTasks[i+1]
+
+					// Current task's creation time should be before or equal to next task's creation time
+					if currentTask.CreatedAt.After(nextTask.CreatedAt) {
+						return false // Not sorted correctly
+					}
+				}
+
+				return true
+			},
+			// Generate slices of non-empty strings
+			gen.SliceOf(
+				gen.AnyString().SuchThat(func(s string) bool {
+					return strings.TrimSpace(NormalizeDescription(s)) != ""
+				}),
+			).SuchThat(func(s []string) bool {
+				// Generate lists with at least 2 tasks to make sorting meaningful
+				return len(s) >= 2
+			}),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 8: 完成任务更新状态
+// For any existing task ID, calling CompleteTask should set the task's Completed field to true
+// Validates: Requirements 3.1
+func TestProperty_CompleteTaskUpdatesStatus(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("completing a task sets its Completed field to true",
+		prop.ForAll(
+			func(descriptions []string, taskIndex int) bool {
+				// Skip empty lists
+				if len(descriptions) == 0 {
+					return true
+				}
+
+				// Create fresh storage and todolist for each test
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+
+				// Add all tasks
+				addedTasks := make([]*models.Task, 0, len(descriptions))
+				for _, desc := range descriptions {
+					task, err := tl.AddTask(desc)
+					if err != nil {
+						return false
+					}
+					addedTasks = append(addedTasks, task)
+				}
+
+				// Select a task to complete (using modulo to ensure valid index)
+				selectedTask := addedTasks[taskIndex%len(addedTasks)]
+
+				// Verify task is initially not completed
+				if selectedTask.Completed {
+					return false
+				}
+
+				// Complete the task
+				err = tl.CompleteTask(selectedTask.ID)
+				if err != nil {
+					return false
+				}
+
+				// Get the updated task list
+				listedTasks := tl.ListTasks()
+
+				// Find the completed task in the list
+				var completedTask *models.Task
+				for i := range listedTasks {
+					if listedTasks[i].ID == selectedTask.ID {
+						completedTask = &listedTasks[i]
+						break
+					}
+				}
+
+				// Verify task was found
+				if completedTask == nil {
+					return false
+				}
+
+				// Verify the task is now marked as completed
+				if !completedTask.Completed {
+					return false
+				}
+
+				// Verify other fields remain unchanged
+				if completedTask.ID != selectedTask.ID {
+					return false
+				}
+				if completedTask.Description != selectedTask.Description {
+					return false
+				}
+				if !completedTask.CreatedAt.Equal(selectedTask.CreatedAt) {
+					return false
+				}
+
+				return true
+			},
+			// Generate slices of non-empty strings (at least 1 task)
+			gen.SliceOf(
+				gen.AnyString().SuchThat(func(s string) bool {
+					return strings.TrimSpace(NormalizeDescription(s)) != ""
+				}),
+			).SuchThat(func(s []string) bool {
+				return len(s) >= 1
+			}),
+			// Generate a task index to select which task to complete
+			gen.IntRange(0, 1000),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 9: 完成操作幂等性
+// For any task, calling CompleteTask multiple times should produce the same result (task remains completed)
+// Validates: Requirements 3.4
+func TestProperty_CompleteTaskIdempotent(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("completing a task multiple times is idempotent",
+		prop.ForAll(
+			func(descriptions []string, taskIndex int, repeatCount int) bool {
+				// Skip empty lists
+				if len(descriptions) == 0 {
+					return true
+				}
+
+				// Create fresh storage and todolist for each test
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+
+				// Add all tasks
+				addedTasks := make([]*models.Task, 0, len(descriptions))
+				for _, desc := range descriptions {
+					task, err := tl.AddTask(desc)
+					if err != nil {
+						return false
+					}
+					addedTasks = append(addedTasks, task)
+				}
+
+				// Select a task to complete (using modulo to ensure valid index)
+				selectedTask := addedTasks[taskIndex%len(addedTasks)]
+
+				// Complete the task the first time
+				err = tl.CompleteTask(selectedTask.ID)
+				if err != nil {
+					return false
+				}
+
+				// Get the task state after first completion
+				tasksAfterFirst := tl.ListTasks()
+				var taskAfterFirst *models.Task
+				for i := range tasksAfterFirst {
+					if tasksAfterFirst[i].ID == selectedTask.ID {
+						taskAfterFirst = &tasksAfterFirst[i]
+						break
+					}
+				}
+
+				if taskAfterFirst == nil || !taskAfterFirst.Completed {
+					return false
+				}
+
+				// Complete the task multiple more times
+				for i := 0; i < repeatCount; i++ {
+					err = tl.CompleteTask(selectedTask.ID)
+					if err != nil {
+						return false // Should not error on already completed task
+					}
+				}
+
+				// Get the task state after multiple completions
+				tasksAfterMultiple := tl.ListTasks()
+				var taskAfterMultiple *models.Task
+				for i := range tasksAfterMultiple {
+					if tasksAfterMultiple[i].ID == selectedTask.ID {
+						taskAfterMultiple = &tasksAfterMultiple[i]
+						break
+					}
+				}
+
+				if taskAfterMultiple == nil {
+					return false
+				}
+
+				// Verify the task is still completed (idempotent)
+				if !taskAfterMultiple.Completed {
+					return false
+				}
+
+				// Verify all other fields remain unchanged
+				if taskAfterMultiple.ID != taskAfterFirst.ID {
+					return false
+				}
+				if taskAfterMultiple.Description != taskAfterFirst.Description {
+					return false
+				}
+				if !taskAfterMultiple.CreatedAt.Equal(taskAfterFirst.CreatedAt) {
+					return false
+				}
+
+				// Verify the total number of tasks hasn't changed
+				if len(tasksAfterMultiple) != len(addedTasks) {
+					return false
+				}
+
+				return true
+			},
+			// Generate slices of non-empty strings (at least 1 task)
+			gen.SliceOf(
+				gen.AnyString().SuchThat(func(s string) bool {
+					return strings.TrimSpace(NormalizeDescription(s)) != ""
+				}),
+			).SuchThat(func(s []string) bool {
+				return len(s) >= 1
+			}),
+			// Generate a task index to select which task to complete
+			gen.IntRange(0, 1000),
+			// Generate number of times to repeat the complete operation (1-10 times)
+			gen.IntRange(1, 10),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 10: 无效 ID 操作返回错误
+// For any non-existent task ID, attempting to complete or delete that task should return an error and the task list should remain unchanged
+// Validates: Requirements 3.2, 4.2
+func TestProperty_InvalidIDOperationsReturnError(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("operations with invalid IDs return errors and don't modify list",
+		prop.ForAll(
+			func(descriptions []string, invalidID int) bool {
+				// Create fresh storage and todolist for each test
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+
+				// Add all tasks
+				addedTasks := make([]*models.Task, 0, len(descriptions))
+				for _, desc := range descriptions {
+					task, err := tl.AddTask(desc)
+					if err != nil {
+						return false
+					}
+					addedTasks = append(addedTasks, task)
+				}
+
+				// Get initial state
+				initialTasks := tl.ListTasks()
+				initialCount := len(initialTasks)
+
+				// Ensure invalidID doesn't exist in the list
+				for _, task := range addedTasks {
+					if task.ID == invalidID {
+						// Skip this test case if the generated ID happens to exist
+						return true
+					}
+				}
+
+				// Test CompleteTask with invalid ID
+				err = tl.CompleteTask(invalidID)
+
+				// Verify appropriate error is returned
+				if invalidID <= 0 {
+					// Negative or zero IDs should return apperrors.ErrInvalidID
+					if !apperrors.IsInvalidID(err) {
+						return false
+					}
+				} else {
+					// Positive non-existent IDs should return apperrors.ErrTaskNotFound
+					if !apperrors.IsTaskNotFound(err) {
+						return false
+					}
+				}
+
+				// Verify list is unchanged after failed CompleteTask
+				tasksAfterComplete := tl.ListTasks()
+				if len(tasksAfterComplete) != initialCount {
+					return false
+				}
+
+				// Verify all tasks remain unchanged
+				for i, task := range tasksAfterComplete {
+					if task.ID != initialTasks[i].ID {
+						return false
+					}
+					if task.Description != initialTasks[i].Description {
+						return false
+					}
+					if task.Completed != initialTasks[i].Completed {
+						return false
+					}
+					if !task.CreatedAt.Equal(initialTasks[i].CreatedAt) {
+						return false
+					}
+				}
+
+				// Test DeleteTask with invalid ID
+				err = tl.DeleteTask(invalidID)
+
+				// Verify appropriate error is returned
+				if invalidID <= 0 {
+					// Negative or zero IDs should return apperrors.ErrInvalidID
+					if !apperrors.IsInvalidID(err) {
+						return false
+					}
+				} else {
+					// Positive non-existent IDs should return apperrors.ErrTaskNotFound
+					if !apperrors.IsTaskNotFound(err) {
+						return false
+					}
+				}
+
+				// Verify list is unchanged after failed DeleteTask
+				tasksAfterDelete := tl.ListTasks()
+				if len(tasksAfterDelete) != initialCount {
+					return false
+				}
+
+				// Verify all tasks remain unchanged
+				for i, task := range tasksAfterDelete {
+					if task.ID != initialTasks[i].ID {
+						return false
+					}
+					if task.Description != initialTasks[i].Description {
+						return false
+					}
+					if task.Completed != initialTasks[i].Completed {
+						return false
+					}
+					if !task.CreatedAt.Equal(initialTasks[i].CreatedAt) {
+						return false
+					}
+				}
+
+				return true
+			},
+			// Generate slices of non-empty strings (can be empty list too)
+			gen.SliceOf(
+				gen.AnyString().SuchThat(func(s string) bool {
+					return strings.TrimSpace(NormalizeDescription(s)) != ""
+				}),
+			),
+			// Generate invalid IDs: negative numbers, zero, and large positive numbers unlikely to exist
+			gen.OneGenOf(
+				gen.IntRange(-1000, 0),    // Negative and zero IDs
+				gen.IntRange(1000, 10000), // Large positive IDs unlikely to exist
+			),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 11: 删除任务移除任务
+// For any existing task ID, calling DeleteTask should remove the task from the list, and subsequent queries for that ID should fail
+// Validates: Requirements 4.1
+func TestProperty_DeleteTaskRemovesTask(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("deleting a task removes it from the list",
+		prop.ForAll(
+			func(descriptions []string, taskIndex int) bool {
+				// Skip empty lists
+				if len(descriptions) == 0 {
+					return true
+				}
+
+				// Create fresh storage and todolist for each test
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+
+				// Add all tasks
+				addedTasks := make([]*models.Task, 0, len(descriptions))
+				for _, desc := range descriptions {
+					task, err := tl.AddTask(desc)
+					if err != nil {
+						return false
+					}
+					addedTasks = append(addedTasks, task)
+				}
+
+				// Get initial count
+				initialCount := len(tl.ListTasks())
+
+				// Select a task to delete (using modulo to ensure valid index)
+				selectedTask := addedTasks[taskIndex%len(addedTasks)]
+				deletedID := selectedTask.ID
+
+				// Delete the task
+				err = tl.DeleteTask(deletedID)
+				if err != nil {
+					return false
+				}
+
+				// Get the updated task list
+				listedTasks := tl.ListTasks()
+
+				// Verify the list is now one task shorter
+				if len(listedTasks) != initialCount-1 {
+					return false
+				}
+
+				// Verify the deleted task is not in the list
+				for _, task := range listedTasks {
+					if task.ID == deletedID {
+						return false // Deleted task still in list!
+					}
+				}
+
+				// Verify subsequent operations on the deleted ID fail
+				err = tl.CompleteTask(deletedID)
+				if !apperrors.IsTaskNotFound(err) {
+					return false // Should return apperrors.ErrTaskNotFound
+				}
+
+				// Verify deleting the same ID again also fails
+				err = tl.DeleteTask(deletedID)
+				if !apperrors.IsTaskNotFound(err) {
+					return false // Should return apperrors.ErrTaskNotFound
+				}
+
+				// Verify all other tasks are still present
+				for _, originalTask := range addedTasks {
+					if originalTask.ID == deletedID {
+						continue // Skip the deleted task
+					}
+
+					// Find this task in the list
+					found := false
+					for _, listedTask := range listedTasks {
+						if listedTask.ID == originalTask.ID {
+							found = true
+							// Verify all fields are preserved
+							if listedTask.Description != originalTask.Description {
+								return false
+							}
+							if listedTask.Completed != originalTask.Completed {
+								return false
+							}
+							if !listedTask.CreatedAt.Equal(originalTask.CreatedAt) {
+								return false
+							}
+							break
+						}
+					}
+
+					if !found {
+						return false // A non-deleted task is missing!
+					}
+				}
+
+				return true
+			},
+			// Generate slices of non-empty strings (at least 1 task)
+			gen.SliceOf(
+				gen.AnyString().SuchThat(func(s string) bool {
+					return strings.TrimSpace(NormalizeDescription(s)) != ""
+				}),
+			).SuchThat(func(s []string) bool {
+				return len(s) >= 1
+			}),
+			// Generate a task index to select which task to delete
+			gen.IntRange(0, 1000),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// TestListOverdue tests that only pending tasks with a past due date are overdue
+func TestListOverdue(t *testing.T) {
+	storage := &mockStorage{data: nil}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	tl.now = func() time.Time { return now }
+
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	overdue, _ := tl.AddTask("overdue", WithDueDate(past))
+	tl.AddTask("due later", WithDueDate(future))
+	tl.AddTask("no due date")
+	done, _ := tl.AddTask("done but past due", WithDueDate(past))
+	if err := tl.CompleteTask(done.ID); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+
+	tasks := tl.ListOverdue()
+	if len(tasks) != 1 || tasks[0].ID != overdue.ID {
+		t.Fatalf("Expected only task %d to be overdue, got %+v", overdue.ID, tasks)
+	}
+	if tl.CountOverdue() != 1 {
+		t.Errorf("Expected CountOverdue 1, got %d", tl.CountOverdue())
+	}
+
+	stats := tl.Stats()
+	if stats.Total != 4 || stats.Pending != 3 || stats.Completed != 1 || stats.OverdueCount != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+// TestListByFilter tests filtering through a Filterer storage and in memory
+func TestListByFilter(t *testing.T) {
+	active := storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json"))
+	tl, err := NewTodoList(active)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("low", WithPriority(models.PriorityLow))
+	tl.AddTask("high", WithPriority(models.PriorityHigh))
+	tl.CompleteTask(2)
+
+	done := true
+	tasks, err := tl.ListByFilter(storage.StorageFilter{Status: &done})
+	if err != nil || len(tasks) != 1 || tasks[0].ID != 2 {
+		t.Fatalf("Expected task 2 from the storage, got %+v (%v)", tasks, err)
+	}
+
+	// Dry-run changes exist only in memory, so the storage is bypassed
+	tl.SetDryRun(true)
+	tl.CompleteTask(1)
+	if tasks, _ := tl.ListByFilter(storage.StorageFilter{Status: &done}); len(tasks) != 2 {
+		t.Errorf("Expected both tasks completed in dry-run, got %+v", tasks)
+	}
+
+	// Storages without Filterer are filtered in memory
+	mem, err := NewTodoList(&mockStorage{data: nil})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	mem.AddTask("low", WithPriority(models.PriorityLow))
+	mem.AddTask("high", WithPriority(models.PriorityHigh))
+	high := models.PriorityHigh
+	if tasks, _ := mem.ListByFilter(storage.StorageFilter{Priority: &high}); len(tasks) != 1 || tasks[0].Description != "high" {
+		t.Errorf("Expected only the high-priority task, got %+v", tasks)
+	}
+}
+
+// Feature: todo-list-cli, Property 14: 逾期任务是待办任务的子集
+// For any task list, every overdue task is a pending task with a due date before now
+// Validates: overdue command
+func TestProperty_OverdueTasksSubsetOfPending(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("overdue tasks are a subset of pending tasks",
+		prop.ForAll(
+			func(offsets []int, completeMask []bool) bool {
+				storage := &mockStorage{data: nil}
+				tl, err := NewTodoList(storage)
+				if err != nil {
+					return false
+				}
+				now := time.Now()
+				tl.now = func() time.Time { return now }
+
+				// Offsets are due dates in hours relative to now
+				for i, offset := range offsets {
+					task, err := tl.AddTask("task", WithDueDate(now.Add(time.Duration(offset)*time.Hour)))
+					if err != nil {
+						return false
+					}
+					if i < len(completeMask) && completeMask[i] {
+						if err := tl.CompleteTask(task.ID); err != nil {
+							return false
+						}
+					}
+				}
+
+				pending := map[int]bool{}
+				for _, task := range tl.ListTasks() {
+					if !task.Completed {
+						pending[task.ID] = true
+					}
+				}
+
+				overdue := tl.ListOverdue()
+				for _, task := range overdue {
+					if !pending[task.ID] || task.DueDate == nil || !task.DueDate.Before(now) {
+						return false
+					}
+				}
+
+				return tl.Stats().OverdueCount == len(overdue)
+			},
+			gen.SliceOf(gen.IntRange(-100, 100)),
+			gen.SliceOf(gen.Bool()),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// mockHistory is a simple in-memory undo history for testing
+type mockHistory struct {
+	snapshots [][2]*models.TaskList
+}
+
+func (mh *mockHistory) Push(before, after *models.TaskList) error {
+	mh.snapshots = append(mh.snapshots, [2]*models.TaskList{before, after})
+	return nil
+}
+
+func (mh *mockHistory) Pop() (before, after *models.TaskList, err error) {
+	if len(mh.snapshots) == 0 {
+		return nil, nil, apperrors.ErrNothingToUndo
+	}
+	last := mh.snapshots[len(mh.snapshots)-1]
+	mh.snapshots = mh.snapshots[:len(mh.snapshots)-1]
+	return last[0], last[1], nil
+}
+
+// TestUndoRevertsOperations tests undoing add, done and delete, stepping further back each time
+func TestUndoRevertsOperations(t *testing.T) {
+	storage := &mockStorage{data: nil}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.SetHistory(&mockHistory{})
+
+	if err := tl.Undo(); !apperrors.IsNothingToUndo(err) {
+		t.Fatalf("Expected ErrNothingToUndo, got %v", err)
+	}
+
+	tl.AddTask("first")
+	tl.AddTask("second")
+	tl.AddTask("third")
+	if err := tl.CompleteTask(1); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+	if err := tl.DeleteTask(2); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+
+	// Undo delete: task 2 is back in its original position with its ID
+	if err := tl.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	tasks := tl.ListTasks()
+	if len(tasks) != 3 || tasks[1].ID != 2 || tasks[1].Description != "second" {
+		t.Fatalf("Expected task 2 restored at index 1, got %+v", tasks)
+	}
+
+	// Undo done: task 1 is pending again
+	if err := tl.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if tl.ListTasks()[0].Completed {
+		t.Error("Expected task 1 to be pending after undoing done")
+	}
+
+	// Undo add: task 3 is gone
+	if err := tl.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	tasks = tl.ListTasks()
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks after undoing add, got %d", len(tasks))
+	}
+
+	// The undone state is persisted
+	if len(storage.data.Tasks) != 2 {
+		t.Errorf("Expected storage to hold 2 tasks, got %d", len(storage.data.Tasks))
+	}
+}
+
+// TestUndoRefusesUnrecordedChanges tests that undo refuses, keeping the
+// tasks and the history, when the list was changed by something that did
+// not record history since the change being undone
+func TestUndoRefusesUnrecordedChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	history := storage.NewFileHistory(path + ".undo")
+	open := func() *TodoList {
+		tl, err := NewTodoList(storage.NewFileStorage(path))
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+		return tl
+	}
+	tl := open()
+	tl.SetHistory(history)
+	tl.AddTask("first")
+	tl.AddTask("second")
+
+	// The list read back from the file matches the one recorded in memory
+	reopened := open()
+	reopened.SetHistory(history)
+	if err := reopened.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	// Another process adds a task without keeping history
+	open().AddTask("third")
+
+	reopened = open()
+	reopened.SetHistory(history)
+	if err := reopened.Undo(); !apperrors.IsUndoConflict(err) {
+		t.Fatalf("Expected ErrUndoConflict, got %v", err)
+	}
+	if tasks := open().ListTasks(); len(tasks) != 2 || tasks[1].Description != "third" {
+		t.Errorf("Expected first and third kept, got %+v", tasks)
+	}
+	if before, _, err := history.Pop(); err != nil || len(before.Tasks) != 0 {
+		t.Errorf("Expected the add of first still in the history, got %+v, %v", before, err)
+	}
+}
+
+// TestUndoDryRun tests that a dry-run undo reverts only the list in memory
+// and keeps the change in the history
+func TestUndoDryRun(t *testing.T) {
+	storage := &failingStorage{}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	history := &mockHistory{}
+	tl.SetHistory(history)
+	tl.AddTask("first")
+	storage.saves = 0
+
+	tl.SetDryRun(true)
+	if err := tl.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if len(tl.ListTasks()) != 0 || storage.saves != 0 || len(history.snapshots) != 1 {
+		t.Errorf("Expected the add undone in memory only, got %d tasks, %d saves, %d snapshots",
+			len(tl.ListTasks()), storage.saves, len(history.snapshots))
+	}
+}
+
+// failingStorage wraps mockStorage and fails every Save while fail is set
+type failingStorage struct {
+	mockStorage
+	fail  bool
+	saves int
+}
+
+func (fs *failingStorage) Save(list *models.TaskList) error {
+	if fs.fail {
+		return apperrors.ErrStorageWrite
+	}
+	fs.saves++
+	return fs.mockStorage.Save(list)
+}
+
+// TestImportTasks tests that imported tasks get fresh IDs in a single save
+func TestImportTasks(t *testing.T) {
+	storage := &failingStorage{}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("existing")
+	storage.saves = 0
+
+	created := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	imported, err := tl.ImportTasks([]models.Task{
+		{ID: 99, Description: "from file", Completed: true, CreatedAt: created},
+		{Description: "no date"},
+	})
+	if err != nil {
+		t.Fatalf("ImportTasks failed: %v", err)
+	}
+
+	if storage.saves != 1 {
+		t.Errorf("Expected exactly 1 save, got %d", storage.saves)
+	}
+	if imported[0].ID != 2 || imported[1].ID != 3 {
+		t.Errorf("Expected fresh IDs 2 and 3, got %d and %d", imported[0].ID, imported[1].ID)
+	}
+	if !imported[0].Completed || !imported[0].CreatedAt.Equal(created) {
+		t.Errorf("Expected completion and creation date to be kept, got %+v", imported[0])
+	}
+	if imported[1].CreatedAt.IsZero() {
+		t.Error("Expected missing creation date to be set")
+	}
+
+	// A blank description rejects the whole batch
+	if _, err := tl.ImportTasks([]models.Task{{Description: "ok"}, {Description: "  "}}); !apperrors.IsEmptyDescription(err) {
+		t.Errorf("Expected ErrEmptyDescription, got %v", err)
+	}
+
+	// A failed save leaves the list untouched
+	storage.fail = true
+	if _, err := tl.ImportTasks([]models.Task{{Description: "lost"}}); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if len(tl.ListTasks()) != 3 || tl.list.NextID != 4 {
+		t.Errorf("Expected rollback to 3 tasks and NextID 4, got %d tasks and NextID %d", len(tl.ListTasks()), tl.list.NextID)
+	}
+}
+
+// TestWithTransaction tests that the changes made in a transaction are
+// saved once, undone in one step and dropped entirely if anything fails
+func TestWithTransaction(t *testing.T) {
+	st := &failingStorage{}
+	tl, err := NewTodoList(st)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("existing")
+	history := &mockHistory{}
+	tl.SetHistory(history)
+	events := storage.NewFileEventLog(filepath.Join(t.TempDir(), "todos.json.events"))
+	tl.SetEventLog(events)
+	st.saves = 0
+
+	err = tl.WithTransaction(func(tx *TodoList) error {
+		if _, err := tx.AddTask("first"); err != nil {
+			return err
+		}
+		if _, err := tx.AddTask("second"); err != nil {
+			return err
+		}
+		return tx.CompleteTask(1)
+	})
+	if err != nil {
+		t.Fatalf("WithTransaction failed: %v", err)
+	}
+	if st.saves != 1 {
+		t.Errorf("Expected exactly 1 save, got %d", st.saves)
+	}
+	tasks := tl.ListTasks()
+	if len(tasks) != 3 || !tasks[0].Completed || tasks[2].Description != "second" {
+		t.Errorf("Expected the transaction's changes in the list, got %+v", tasks)
+	}
+	if len(st.data.Tasks) != 3 {
+		t.Errorf("Expected 3 tasks saved, got %+v", st.data.Tasks)
+	}
+	if len(history.snapshots) != 1 {
+		t.Errorf("Expected one undo step, got %d", len(history.snapshots))
+	}
+	if logged, err := events.Events(); err != nil || len(logged) != 3 {
+		t.Errorf("Expected 3 events recorded, got %d, %v", len(logged), err)
+	}
+	if diff := indexDiverges(tl); diff != "" {
+		t.Error(diff)
+	}
+
+	// An error from fn drops everything fn did
+	st.saves = 0
+	err = tl.WithTransaction(func(tx *TodoList) error {
+		tx.AddTask("dropped")
+		return tx.DeleteTask(42)
+	})
+	if !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound from fn, got %v", err)
+	}
+	if st.saves != 0 || len(tl.ListTasks()) != 3 {
+		t.Errorf("Expected nothing saved or changed, got %d saves and %d tasks", st.saves, len(tl.ListTasks()))
+	}
+
+	// So does a failed save
+	st.fail = true
+	err = tl.WithTransaction(func(tx *TodoList) error {
+		return tx.DeleteTask(2)
+	})
+	if !apperrors.IsStorageError(err) {
+		t.Errorf("Expected a storage error, got %v", err)
+	}
+	if len(tl.ListTasks()) != 3 || len(history.snapshots) != 1 {
+		t.Errorf("Expected the list and history unchanged, got %d tasks and %d undo steps", len(tl.ListTasks()), len(history.snapshots))
+	}
+}
+
+// TestTaskUIDs tests that UIDs are backfilled on load, kept by imports that
+// skip UIDs already present, and resolved from unambiguous prefixes
+func TestTaskUIDs(t *testing.T) {
+	storage := &failingStorage{mockStorage: mockStorage{data: &models.TaskList{
+		Tasks:  []models.Task{{ID: 1, Description: "old"}, {ID: 2, Description: "older", UID: "aaaa0000-0000-4000-8000-000000000000"}},
+		NextID: 3,
+	}}}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tasks := tl.ListTasks()
+	if tasks[0].UID == "" || tasks[1].UID != "aaaa0000-0000-4000-8000-000000000000" {
+		t.Errorf("Expected a UID backfilled and an existing one kept, got %+v", tasks)
+	}
+	added, _ := tl.AddTask("new")
+	if added.UID == "" || added.UID == tasks[0].UID {
+		t.Errorf("Expected a fresh UID on add, got %q", added.UID)
+	}
+	if saved := storage.data.Tasks; saved[0].UID != tasks[0].UID {
+		t.Errorf("Expected the backfilled UID to be saved, got %+v", saved[0])
+	}
+
+	// Tasks already in the list are skipped; new ones keep their UID
+	imported, err := tl.ImportTasks([]models.Task{
+		{Description: "again", UID: tasks[1].UID},
+		{Description: "elsewhere", UID: "aaaa1111-0000-4000-8000-000000000000"},
+		{Description: "elsewhere twice", UID: "aaaa1111-0000-4000-8000-000000000000"},
+	})
+	if err != nil {
+		t.Fatalf("ImportTasks failed: %v", err)
+	}
+	if len(imported) != 1 || imported[0].Description != "elsewhere" || imported[0].UID != "aaaa1111-0000-4000-8000-000000000000" {
+		t.Errorf("Expected only the new task to be imported, got %+v", imported)
+	}
+
+	if id, err := tl.ResolveID("uid:AAAA1"); err != nil || id != imported[0].ID {
+		t.Errorf("Expected the prefix to resolve to %d, got %d, %v", imported[0].ID, id, err)
+	}
+	if id, err := tl.ResolveID("aaaa1111"); err != nil || id != imported[0].ID {
+		t.Errorf("Expected a bare 8-character prefix to resolve to %d, got %d, %v", imported[0].ID, id, err)
+	}
+	if id, err := tl.ResolveID("7"); err != nil || id != 7 {
+		t.Errorf("Expected a number to be returned as is, got %d, %v", id, err)
+	}
+	if _, err := tl.ResolveID("uid:aaaa"); !apperrors.IsInvalidID(err) {
+		t.Errorf("Expected ErrInvalidID for an ambiguous prefix, got %v", err)
+	}
+	if _, err := tl.ResolveID("UID:bbbb"); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound for an unknown prefix, got %v", err)
+	}
+	if _, err := tl.ResolveID("aaaa1"); !apperrors.IsInvalidID(err) {
+		t.Errorf("Expected ErrInvalidID for a short prefix without uid:, got %v", err)
+	}
+	if _, err := tl.ResolveID("soon"); !apperrors.IsInvalidID(err) {
+		t.Errorf("Expected ErrInvalidID for a non-hex reference, got %v", err)
+	}
+
+	if err := tl.UpdateTask(1, func(task *models.Task) error {
+		task.UID = "changed"
+		return nil
+	}); !apperrors.IsInvalidID(err) {
+		t.Errorf("Expected ErrInvalidID when changing a UID, got %v", err)
+	}
+}
+
+// TestAddTasks tests that a batch of descriptions is added with a single save
+func TestAddTasks(t *testing.T) {
+	storage := &failingStorage{}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	added, err := tl.AddTasks([]string{"one", "two", "three"}, WithPriority(models.PriorityHigh))
+	if err != nil {
+		t.Fatalf("AddTasks failed: %v", err)
+	}
+	if storage.saves != 1 {
+		t.Errorf("Expected exactly 1 save, got %d", storage.saves)
+	}
+	if len(added) != 3 || added[0].ID != 1 || added[2].ID != 3 || added[1].Description != "two" {
+		t.Errorf("Expected tasks 1-3 in order, got %+v", added)
+	}
+	if added[2].Priority != models.PriorityHigh || added[0].CreatedAt.IsZero() {
+		t.Errorf("Expected options and creation date to be applied, got %+v", added[2])
+	}
+
+	// No descriptions is not an error and does not save
+	if added, err := tl.AddTasks(nil); err != nil || added != nil || storage.saves != 1 {
+		t.Errorf("Expected no-op for empty input, got %v, %v after %d saves", added, err, storage.saves)
+	}
+}
+
+// TestBulkAddTasks tests that valid descriptions are added in one save
+// while invalid ones are reported in place, and that a failed save adds
+// nothing
+func TestBulkAddTasks(t *testing.T) {
+	storage := &failingStorage{}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	tasks, errs := tl.BulkAddTasks([]string{"one", "  ", "two", "", "three"})
+	if len(tasks) != 5 || len(errs) != 5 {
+		t.Fatalf("Expected parallel slices of 5, got %d tasks and %d errors", len(tasks), len(errs))
+	}
+	if storage.saves != 1 {
+		t.Errorf("Expected exactly 1 save, got %d", storage.saves)
+	}
+	for i, want := range []string{"one", "", "two", "", "three"} {
+		if want == "" {
+			if tasks[i] != nil || !apperrors.IsEmptyDescription(errs[i]) {
+				t.Errorf("Item %d: expected ErrEmptyDescription and no task, got %+v, %v", i, tasks[i], errs[i])
+			}
+			continue
+		}
+		if errs[i] != nil || tasks[i] == nil || tasks[i].Description != want {
+			t.Errorf("Item %d: expected task %q, got %+v, %v", i, want, tasks[i], errs[i])
+		}
+	}
+	if tasks[0].ID != 1 || tasks[2].ID != 2 || tasks[4].ID != 3 || tl.list.NextID != 4 {
+		t.Errorf("Expected IDs 1-3 and NextID 4, got %d %d %d and %d", tasks[0].ID, tasks[2].ID, tasks[4].ID, tl.list.NextID)
+	}
+
+	// Only invalid descriptions: nothing to save
+	if _, errs := tl.BulkAddTasks([]string{""}); storage.saves != 1 || !apperrors.IsEmptyDescription(errs[0]) {
+		t.Errorf("Expected no save and ErrEmptyDescription, got %v after %d saves", errs, storage.saves)
+	}
+
+	// A failed save rolls back every task and reports the error for each
+	storage.fail = true
+	tasks, errs = tl.BulkAddTasks([]string{"four", "", "five"})
+	if tasks[0] != nil || tasks[2] != nil || !apperrors.IsStorageError(errs[0]) || !apperrors.IsStorageError(errs[2]) {
+		t.Errorf("Expected storage errors and no tasks, got %+v, %v", tasks, errs)
+	}
+	if !apperrors.IsEmptyDescription(errs[1]) {
+		t.Errorf("Expected ErrEmptyDescription for the blank item, got %v", errs[1])
+	}
+	if len(tl.ListTasks()) != 3 || tl.list.NextID != 4 {
+		t.Errorf("Expected the list unchanged, got %d tasks and NextID %d", len(tl.ListTasks()), tl.list.NextID)
+	}
+	if _, err := tl.GetTask(4); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected task 4 to be rolled back, got %v", err)
+	}
+}
+
+// TestExtractTags tests which words are taken as tags
+func TestExtractTags(t *testing.T) {
+	testCases := []struct {
+		description string
+		clean       string
+		tags        []string
+	}{
+		{"Buy milk #groceries #errand", "Buy milk", []string{"groceries", "errand"}},
+		{"#home  fix   the #tap #home", "fix the", []string{"home", "tap"}},
+		{"#groceries #errand", "", []string{"groceries", "errand"}},
+		{"Learn C# and F#", "Learn C# and F#", nil},
+		{"fix issue#12 and #12", "fix issue#12 and #12", nil},
+		{"email bob#work  today", "email bob#work  today", nil},
+		{"tidy #living-room", "tidy", []string{"living-room"}},
+		{"file #work/reports \\#literal", "file \\#literal", []string{"work/reports"}},
+		{"# heading", "# heading", nil},
+		{"sort #v2_ideas", "sort", []string{"v2_ideas"}},
+		{"交房租 #家务", "交房租", []string{"家务"}},
+	}
+	for _, tc := range testCases {
+		clean, tags := ExtractTags(tc.description)
+		if clean != tc.clean || fmt.Sprint(tags) != fmt.Sprint(tc.tags) {
+			t.Errorf("ExtractTags(%q): expected %q %v, got %q %v", tc.description, tc.clean, tc.tags, clean, tags)
+		}
+	}
+}
+
+// TestAddTaskTags tests that AddTask stores tags apart from the description
+// and rejects a description made only of tags
+func TestAddTaskTags(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	task, err := tl.AddTask("Buy milk #groceries #errand")
+	if err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	if task.Description != "Buy milk" || fmt.Sprint(task.Tags) != "[groceries errand]" {
+		t.Errorf("Expected the tags to be extracted, got %q %v", task.Description, task.Tags)
+	}
+	if task, _ := tl.AddTask("Learn C#"); task.Description != "Learn C#" || task.Tags != nil {
+		t.Errorf("Expected no tags, got %q %v", task.Description, task.Tags)
+	}
+
+	if _, err := tl.AddTask("#groceries #errand"); !apperrors.IsEmptyDescription(err) {
+		t.Errorf("Expected ErrEmptyDescription for a tag-only description, got %v", err)
+	}
+	if _, err := tl.AddTasks([]string{"fine", "#only #tags"}); !apperrors.IsEmptyDescription(err) {
+		t.Errorf("Expected ErrEmptyDescription for a tag-only line, got %v", err)
+	}
+	if len(tl.ListTasks()) != 2 {
+		t.Errorf("Expected 2 tasks, got %d", len(tl.ListTasks()))
+	}
+
+	// Bulk adds extract tags too
+	tasks, errs := tl.BulkAddTasks([]string{"Call mum #family", "#only"})
+	if tasks[0] == nil || tasks[0].Description != "Call mum" || fmt.Sprint(tasks[0].Tags) != "[family]" {
+		t.Errorf("Expected the tags extracted by BulkAddTasks, got %+v", tasks[0])
+	}
+	if !apperrors.IsEmptyDescription(errs[1]) {
+		t.Errorf("Expected ErrEmptyDescription for a tag-only line, got %v", errs[1])
+	}
+	if len(tl.ListTasks()) != 3 {
+		t.Errorf("Expected 3 tasks, got %d", len(tl.ListTasks()))
+	}
+
+	// Copies keep their own tags
+	duplicate, err := tl.DuplicateTask(1)
+	if err != nil {
+		t.Fatalf("DuplicateTask failed: %v", err)
+	}
+	duplicate.Tags[0] = "changed"
+	if original, _ := tl.GetTask(1); original.Tags[0] != "groceries" {
+		t.Errorf("Expected the original's tags to be unaffected, got %v", original.Tags)
+	}
+}
+
+// TestAddTaskQuickAdd tests inline token parsing and that later options win
+func TestAddTaskQuickAdd(t *testing.T) {
+	storage := &failingStorage{}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	now := time.Date(2026, 1, 14, 10, 30, 0, 0, time.Local)
+
+	task, err := tl.AddTask("pay rent due:tomorrow !high #home", WithQuickAdd(now))
+	if err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	want := time.Date(2026, 1, 15, 23, 59, 59, 0, time.Local)
+	if task.Description != "pay rent" || fmt.Sprint(task.Tags) != "[home]" || task.Priority != models.PriorityHigh || !task.DueDate.Equal(want) {
+		t.Errorf("Expected the tokens to be applied, got %+v", task)
+	}
+
+	task, err = tl.AddTask("call !high", WithQuickAdd(now), WithPriority(models.PriorityLow))
+	if err != nil || task.Priority != models.PriorityLow {
+		t.Errorf("Expected the later option to win, got %+v, %v", task, err)
+	}
+
+	// Tags the option parses are added to those the task already has
+	quick := models.Task{Description: "tidy #front-end #home", Tags: []string{"home"}}
+	if err := WithQuickAdd(now)(&quick); err != nil || fmt.Sprint(quick.Tags) != "[home front-end]" {
+		t.Errorf("Expected the parsed tags to be merged, got %v, %v", quick.Tags, err)
+	}
+
+	// Rejected input adds nothing
+	saves := storage.saves
+	if _, err := tl.AddTask("x due:someday", WithQuickAdd(now)); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected ErrInvalidCommand, got %v", err)
+	}
+	if _, err := tl.AddTask("due:today !low", WithQuickAdd(now)); !apperrors.IsEmptyDescription(err) {
+		t.Errorf("Expected ErrEmptyDescription when only tokens are given, got %v", err)
+	}
+	if _, err := tl.AddTasks([]string{"a", "b !h !l"}, WithQuickAdd(now)); !apperrors.IsInvalidCommand(err) {
+		t.Errorf("Expected ErrInvalidCommand from AddTasks, got %v", err)
+	}
+	if len(tl.ListTasks()) != 2 || storage.saves != saves {
+		t.Errorf("Expected rejected tasks not to be added, got %d tasks", len(tl.ListTasks()))
+	}
+}
+
+// TestRestoreFromFile tests validation, the pre-restore backup and the reload
+func TestRestoreFromFile(t *testing.T) {
+	dir := t.TempDir()
+	active := storage.NewFileStorage(filepath.Join(dir, "todos.json"))
+	tl, err := NewTodoList(active)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("current")
+
+	invalid := []string{
+		`not json`,
+		`{"tasks": [], "next_id": 0}`,
+		`{"tasks": [{"id": 5, "description": "x"}], "next_id": 2}`,
+		`{"tasks": [], "next_id": 1, "extra": true}`,
+	}
+	for _, content := range invalid {
+		path := filepath.Join(dir, "bad.json")
+		os.WriteFile(path, []byte(content), 0644)
+		if err := tl.RestoreFromFile(path); !apperrors.IsInvalidJSON(err) {
+			t.Errorf("Expected ErrInvalidJSON for %s, got %v", content, err)
+		}
+	}
+	if tasks := tl.ListTasks(); len(tasks) != 1 || tasks[0].Description != "current" {
+		t.Fatalf("Expected failed restores to leave the list alone, got %+v", tasks)
+	}
+
+	good := filepath.Join(dir, "good.json")
+	os.WriteFile(good, []byte(`{"tasks": [{"id": 1, "description": "restored", "completed": false, "created_at": "2026-01-01T00:00:00Z"}], "next_id": 2}`), 0644)
+	if err := tl.RestoreFromFile(good); err != nil {
+		t.Fatalf("RestoreFromFile failed: %v", err)
+	}
+	if tasks := tl.ListTasks(); len(tasks) != 1 || tasks[0].Description != "restored" {
+		t.Errorf("Expected restored task, got %+v", tasks)
+	}
+
+	backups, err := storage.ListBackups(dir)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Expected one pre-restore backup, got %v (%v)", backups, err)
+	}
+	pre, err := storage.NewFileStorage(backups[0]).Load()
+	if err != nil || len(pre.Tasks) != 1 || pre.Tasks[0].Description != "current" {
+		t.Errorf("Expected pre-restore backup to hold the old list, got %+v (%v)", pre, err)
+	}
+}
+
+// TestTaskNotFoundErrorCarriesID tests that missing tasks are reported by ID
+func TestTaskNotFoundErrorCarriesID(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	for _, op := range []func(int) error{tl.CompleteTask, tl.DeleteTask} {
+		err := op(42)
+		if !apperrors.IsTaskNotFound(err) {
+			t.Errorf("Expected ErrTaskNotFound, got %v", err)
+		}
+		var notFound *apperrors.TaskNotFoundError
+		if !errors.As(err, &notFound) || notFound.ID != 42 {
+			t.Errorf("Expected TaskNotFoundError for ID 42, got %v", err)
+		}
+		if err.Error() != "task 42 not found" {
+			t.Errorf("Expected message naming the ID, got %q", err.Error())
+		}
+	}
+}
+
+// TestGetTask tests fetching a single task by ID
+func TestGetTask(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	added, _ := tl.AddTask("find me", WithPriority(models.PriorityLow))
+
+	task, err := tl.GetTask(added.ID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if task.Description != "find me" || task.Priority != models.PriorityLow {
+		t.Errorf("Expected the added task, got %+v", task)
+	}
+
+	// The result is a copy
+	task.Description = "changed"
+	if tl.ListTasks()[0].Description != "find me" {
+		t.Error("Expected GetTask to return a copy")
+	}
+
+	if _, err := tl.GetTask(0); !apperrors.IsInvalidID(err) {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+	if _, err := tl.GetTask(99); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+// TestSortTasks tests each sort key, reversal and stability for equal keys
+func TestSortTasks(t *testing.T) {
+	base := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	tasks := []models.Task{
+		{ID: 3, Description: "banana", CreatedAt: base},
+		{ID: 1, Description: "Apple", Completed: true, CreatedAt: base.Add(time.Hour)},
+		{ID: 4, Description: "apple", CreatedAt: base.Add(2 * time.Hour)},
+		{ID: 2, Description: "cherry", Completed: true, CreatedAt: base.Add(3 * time.Hour)},
+	}
+
+	testCases := []struct {
+		key      SortKey
+		desc     bool
+		expected []int
+	}{
+		{SortByManual, false, []int{3, 1, 4, 2}},
+		{SortByCreated, false, []int{3, 1, 4, 2}},
+		{SortByCreated, true, []int{2, 4, 1, 3}},
+		{SortByID, false, []int{1, 2, 3, 4}},
+		{SortByID, true, []int{4, 3, 2, 1}},
+		// Case-insensitive, and "Apple"/"apple" keep creation order either way
+		{SortByDescription, false, []int{1, 4, 3, 2}},
+		{SortByDescription, true, []int{2, 3, 1, 4}},
+		{SortByStatus, false, []int{3, 4, 1, 2}},
+		{SortByStatus, true, []int{1, 2, 3, 4}},
+		{SortKey("bogus"), false, []int{3, 1, 4, 2}},
+	}
+
+	for _, tc := range testCases {
+		sorted := SortTasks(tasks, tc.key, tc.desc)
+		ids := make([]int, len(sorted))
+		for i, task := range sorted {
+			ids[i] = task.ID
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tc.expected) {
+			t.Errorf("SortTasks(%s, desc=%v): expected %v, got %v", tc.key, tc.desc, tc.expected, ids)
+		}
+	}
+
+	// The input is left untouched
+	if tasks[0].ID != 3 {
+		t.Error("Expected SortTasks not to modify its input")
+	}
+
+	// Every key accepted by the config sorts
+	for _, name := range config.SortKeys {
+		if _, ok := ParseSortKey(name); !ok {
+			t.Errorf("Config sort key %q is not a SortKey", name)
+		}
+	}
+}
+
+// TestGroupTasks tests splitting tasks by status in their input order
+func TestGroupTasks(t *testing.T) {
+	tasks := []models.Task{
+		{ID: 3, Description: "c"},
+		{ID: 1, Description: "a", Completed: true},
+		{ID: 2, Description: "b"},
+	}
+
+	groups := GroupTasks(tasks, GroupByStatus)
+	if len(groups) != 2 || groups[0].Name != "Pending" || groups[1].Name != "Completed" {
+		t.Fatalf("Expected Pending then Completed, got %+v", groups)
+	}
+	if len(groups[0].Tasks) != 2 || groups[0].Tasks[0].ID != 3 || groups[0].Tasks[1].ID != 2 {
+		t.Errorf("Expected pending tasks 3, 2 in input order, got %+v", groups[0].Tasks)
+	}
+
+	// Empty groups are left out
+	groups = GroupTasks(tasks[1:2], GroupByStatus)
+	if len(groups) != 1 || groups[0].Name != "Completed" {
+		t.Errorf("Expected only the Completed group, got %+v", groups)
+	}
+	if groups := GroupTasks(nil, GroupByStatus); len(groups) != 0 {
+		t.Errorf("Expected no groups for no tasks, got %+v", groups)
+	}
+
+	if _, ok := ParseGroupKey("status"); !ok {
+		t.Error("Expected status to be a group key")
+	}
+	if _, ok := ParseGroupKey("colour"); ok {
+		t.Error("Expected colour not to be a group key")
+	}
+}
+
+// TestListTasksPaginated tests offset and limit windows and their validation
+// TestListTasksInRange tests the half-open creation time window and its
+// open ends
+func TestListTasksInRange(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	tl, err := NewTodoList(&mockStorage{data: &models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, Description: "first", CreatedAt: day(1)},
+			{ID: 2, Description: "second", CreatedAt: day(5).Add(23 * time.Hour)},
+			{ID: 3, Description: "third", CreatedAt: day(10)},
+		},
+		NextID: 4,
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	testCases := []struct {
+		since, until time.Time
+		expected     []int
+	}{
+		{time.Time{}, time.Time{}, []int{1, 2, 3}},
+		{day(1), day(10), []int{1, 2}},
+		{day(2), time.Time{}, []int{2, 3}},
+		{time.Time{}, day(5), []int{1}},
+		{day(5), day(6), []int{2}},
+		{day(11), time.Time{}, []int{}},
+	}
+	for _, tc := range testCases {
+		ids := []int{}
+		for _, task := range tl.ListTasksInRange(tc.since, tc.until) {
+			ids = append(ids, task.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tc.expected) {
+			t.Errorf("ListTasksInRange(%v, %v): expected %v, got %v", tc.since, tc.until, tc.expected, ids)
+		}
+	}
+}
+
+// TestMergeTasks tests matching by UID and by content, completion winning,
+// conflicts, ID collisions and rollback
+func TestMergeTasks(t *testing.T) {
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	finished := created.Add(48 * time.Hour)
+	storage := &failingStorage{mockStorage: mockStorage{data: &models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, UID: "uid-1", Description: "shared", CreatedAt: created},
+			{ID: 2, UID: "uid-2", Description: "renamed here", CreatedAt: created},
+			{ID: 3, UID: "local-3", Description: "twin", CreatedAt: created},
+			{ID: 5, UID: "uid-5", Description: "only here", CreatedAt: created},
+		},
+		NextID: 6,
+	}}}
+	tl, err := NewTodoList(storage)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	theirs := []models.Task{
+		// Same UID, completed there
+		{ID: 1, UID: "uid-1", Description: "shared", CreatedAt: created, Completed: true, CompletedAt: &finished},
+		// Same UID, different description
+		{ID: 2, UID: "uid-2", Description: "renamed there", CreatedAt: created},
+		// Different UID but same content: a separately backfilled copy
+		{ID: 3, UID: "remote-3", Description: "twin", CreatedAt: created, Completed: true},
+		// Same content again: the only local twin is taken, so it is added
+		{ID: 4, UID: "remote-4", Description: "twin", CreatedAt: created},
+		// Free ID is kept, taken IDs are renumbered after the highest kept
+		{ID: 9, UID: "remote-9", Description: "only there", CreatedAt: created},
+		{ID: 5, Description: "collides", CreatedAt: created},
+	}
+	storage.saves = 0
+	result, err := tl.MergeTasks(theirs)
+	if err != nil {
+		t.Fatalf("MergeTasks failed: %v", err)
+	}
+	if storage.saves != 1 {
+		t.Errorf("Expected exactly 1 save, got %d", storage.saves)
+	}
+
+	ids := func(tasks []models.Task) string {
+		s := []int{}
+		for _, task := range tasks {
+			s = append(s, task.ID)
+		}
+		return fmt.Sprint(s)
+	}
+	if got := ids(result.Updated); got != "[1 3]" {
+		t.Errorf("Expected tasks 1 and 3 completed, got %s", got)
+	}
+	if got := ids(result.Added); got != "[4 9 10]" {
+		t.Errorf("Expected tasks added as 4, 9 and 10, got %s", got)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Ours.ID != 2 || result.Conflicts[0].Theirs.Description != "renamed there" {
+		t.Errorf("Expected a conflict on task 2, got %+v", result.Conflicts)
+	}
+	if task, _ := tl.GetTask(1); task.CompletedAt == nil || !task.CompletedAt.Equal(finished) {
+		t.Errorf("Expected task 1 to keep its completion time from the other list, got %v", task.CompletedAt)
+	}
+	if task, _ := tl.GetTask(3); task.CompletedAt == nil {
+		t.Error("Expected task 3 to get a completion time")
+	}
+	if task, _ := tl.GetTask(2); task.Description != "renamed here" {
+		t.Errorf("Expected our version of a conflict to be kept, got %q", task.Description)
+	}
+	if task, _ := tl.GetTask(10); task.Description != "collides" || task.UID == "" {
+		t.Errorf("Expected the colliding task to get ID 10 and a UID, got %+v", task)
+	}
+	if task, _ := tl.GetTask(4); task.UID != "remote-4" {
+		t.Errorf("Expected the added task to keep its UID, got %q", task.UID)
+	}
+	if tl.list.NextID != 11 {
+		t.Errorf("Expected NextID 11, got %d", tl.list.NextID)
+	}
+	if diff := indexDiverges(tl); diff != "" {
+		t.Errorf("Index diverges after merging: %s", diff)
+	}
+
+	// Merging the same tasks again changes nothing and saves nothing
+	storage.saves = 0
+	again, err := tl.MergeTasks(theirs)
+	if err != nil {
+		t.Fatalf("MergeTasks failed: %v", err)
+	}
+	if storage.saves != 0 || len(again.Added) != 0 || len(again.Updated) != 0 {
+		t.Errorf("Expected a repeated merge to change nothing, got %+v after %d saves", again, storage.saves)
+	}
+
+	// A failed save leaves the list untouched
+	storage.fail = true
+	if _, err := tl.MergeTasks([]models.Task{{ID: 1, Description: "lost", CreatedAt: created}}); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if len(tl.ListTasks()) != 7 || tl.list.NextID != 11 {
+		t.Errorf("Expected rollback to 7 tasks and NextID 11, got %d tasks and NextID %d", len(tl.ListTasks()), tl.list.NextID)
+	}
+}
+
+func TestListTasksPaginated(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		tl.AddTask(fmt.Sprintf("task %d", i))
+	}
+
+	testCases := []struct {
+		offset, limit int
+		expected      []int
+	}{
+		{0, 0, []int{1, 2, 3, 4, 5}},
+		{0, 2, []int{1, 2}},
+		{2, 2, []int{3, 4}},
+		{4, 10, []int{5}},
+		{3, 0, []int{4, 5}},
+		{5, 1, []int{}},
+		{50, 0, []int{}},
+	}
+
+	for _, tc := range testCases {
+		page, total, err := tl.ListTasksPaginated(tc.offset, tc.limit)
+		if err != nil {
+			t.Fatalf("ListTasksPaginated(%d, %d) failed: %v", tc.offset, tc.limit, err)
+		}
+		ids := []int{}
+		for _, task := range page {
+			ids = append(ids, task.ID)
+		}
+		if total != 5 || fmt.Sprint(ids) != fmt.Sprint(tc.expected) {
+			t.Errorf("ListTasksPaginated(%d, %d): expected %v of 5, got %v of %d", tc.offset, tc.limit, tc.expected, ids, total)
+		}
+	}
+
+	for _, bad := range [][2]int{{-1, 0}, {0, -1}} {
+		if _, _, err := tl.ListTasksPaginated(bad[0], bad[1]); !apperrors.IsInvalidPagination(err) {
+			t.Errorf("Expected ErrInvalidPagination for offset %d, limit %d, got %v", bad[0], bad[1], err)
+		}
+	}
+}
+
+// TestDeleteAll tests clearing the list, resetting IDs and rolling back on save failure
+func TestDeleteAll(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("one")
+	tl.AddTask("two")
+
+	// A failed save keeps every task and the ID counter
+	fs.fail = true
+	if _, err := tl.DeleteAll(); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if len(tl.ListTasks()) != 2 || tl.list.NextID != 3 {
+		t.Errorf("Expected rollback to 2 tasks and NextID 3, got %d tasks and NextID %d", len(tl.ListTasks()), tl.list.NextID)
+	}
+
+	fs.fail = false
+	fs.saves = 0
+	count, err := tl.DeleteAll()
+	if err != nil {
+		t.Fatalf("DeleteAll failed: %v", err)
+	}
+	if count != 2 || len(tl.ListTasks()) != 0 || fs.saves != 1 {
+		t.Errorf("Expected 2 tasks deleted in one save, got %d deleted, %d left, %d saves", count, len(tl.ListTasks()), fs.saves)
+	}
+
+	task, _ := tl.AddTask("fresh start")
+	if task.ID != 1 {
+		t.Errorf("Expected IDs to restart at 1, got %d", task.ID)
+	}
+}
+
+// TestTrash tests that deleted tasks go to the trash, come back with
+// RestoreTask, are purged by EmptyTrash and after TrashRetention, and that
+// a failed save rolls back both the tasks and the trash
+func TestTrash(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("one")
+	tl.AddTask("two")
+	tl.AddTask("three")
+
+	if err := tl.DeleteTask(2); err != nil {
+		t.Fatalf("DeleteTask failed: %v", err)
+	}
+	if err := tl.PurgeTask(3); err != nil {
+		t.Fatalf("PurgeTask failed: %v", err)
+	}
+	trash := tl.Trash()
+	if len(trash) != 1 || trash[0].Task.ID != 2 || trash[0].DeletedAt.IsZero() || len(fs.data.Trash) != 1 {
+		t.Fatalf("Expected only task 2 in the saved trash, got %+v", trash)
+	}
+
+	// A failed restore keeps the task in the trash and out of the list
+	fs.fail = true
+	if _, err := tl.RestoreTask(2); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if len(tl.Trash()) != 1 || len(tl.ListTasks()) != 1 {
+		t.Errorf("Expected rollback of both the list and the trash, got %d tasks and %d trashed", len(tl.ListTasks()), len(tl.Trash()))
+	}
+	fs.fail = false
+
+	restored, err := tl.RestoreTask(2)
+	if err != nil || restored.ID != 2 || restored.Description != "two" || len(tl.Trash()) != 0 {
+		t.Fatalf("Expected task 2 restored, got %+v, %v", restored, err)
+	}
+	if _, err := tl.RestoreTask(2); !errors.Is(err, apperrors.ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound restoring twice, got %v", err)
+	}
+	if msg := indexDiverges(tl); msg != "" {
+		t.Error(msg)
+	}
+
+	// IDs restart after DeleteAll, so a restored task whose ID was taken
+	// gets a new one
+	if _, err := tl.DeleteAll(); err != nil {
+		t.Fatalf("DeleteAll failed: %v", err)
+	}
+	tl.AddTask("fresh start")
+	restored, err = tl.RestoreTask(1)
+	if err != nil || restored.ID != 2 || restored.Description != "one" {
+		t.Errorf("Expected task 1 restored as task 2, got %+v, %v", restored, err)
+	}
+
+	fs.fail = true
+	if _, err := tl.EmptyTrash(); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if len(tl.Trash()) != 1 {
+		t.Errorf("Expected the trash to be kept on save failure, got %d", len(tl.Trash()))
+	}
+	fs.fail = false
+	if count, err := tl.EmptyTrash(); err != nil || count != 1 || len(tl.Trash()) != 0 {
+		t.Errorf("Expected 1 task purged, got %d, %v", count, err)
+	}
+
+	// Entries past the retention period are dropped on load
+	now := time.Now()
+	fs.data.Trash = []models.TrashedTask{
+		{Task: models.Task{ID: 7, Description: "old"}, DeletedAt: now.Add(-TrashRetention - time.Hour)},
+		{Task: models.Task{ID: 8, Description: "recent"}, DeletedAt: now.Add(-time.Hour)},
+	}
+	tl, err = NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to reload TodoList: %v", err)
+	}
+	if trash := tl.Trash(); len(trash) != 1 || trash[0].Task.ID != 8 {
+		t.Errorf("Expected only the recent entry to survive, got %+v", trash)
+	}
+}
+
+// TestMoveTask tests reordering tasks in place with rollback on save failure
+func TestMoveTask(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	for _, desc := range []string{"one", "two", "three", "four"} {
+		tl.AddTask(desc)
+	}
+	order := func() []int {
+		var ids []int
+		for _, task := range tl.ListTasks() {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		id, beforeID int
+		expected     []int
+	}{
+		{4, 1, []int{4, 1, 2, 3}},
+		{1, 3, []int{4, 2, 1, 3}},
+		{4, 0, []int{2, 1, 3, 4}},
+		{3, 2, []int{3, 2, 1, 4}},
+	}
+	for _, tc := range testCases {
+		if err := tl.MoveTask(tc.id, tc.beforeID); err != nil {
+			t.Fatalf("MoveTask(%d, %d) failed: %v", tc.id, tc.beforeID, err)
+		}
+		if got := order(); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("MoveTask(%d, %d): expected order %v, got %v", tc.id, tc.beforeID, tc.expected, got)
+		}
+	}
+
+	// IDs still resolve after moving
+	if task, err := tl.GetTask(1); err != nil || task.Description != "one" {
+		t.Errorf("Expected task 1 to still be 'one', got %v, %v", task, err)
+	}
+
+	if err := tl.MoveTask(9, 1); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound for a missing task, got %v", err)
+	}
+	if err := tl.MoveTask(1, 9); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound for a missing before task, got %v", err)
+	}
+	if err := tl.MoveTask(1, 1); !apperrors.IsInvalidID(err) {
+		t.Errorf("Expected ErrInvalidID when moving a task before itself, got %v", err)
+	}
+
+	// A failed save keeps the old order
+	fs.fail = true
+	if err := tl.MoveTask(4, 3); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if got := order(); !reflect.DeepEqual(got, []int{3, 2, 1, 4}) {
+		t.Errorf("Expected rollback to order [3 2 1 4], got %v", got)
+	}
+}
+
+// TestMoveTaskTo tests moving tasks to an index and by an offset, clamping
+// out-of-range targets, with rollback on save failure
+func TestMoveTaskTo(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	for _, desc := range []string{"one", "two", "three", "four"} {
+		tl.AddTask(desc)
+	}
+	order := func() []int {
+		var ids []int
+		for _, task := range tl.ListTasks() {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		move     func() error
+		expected []int
+	}{
+		{func() error { return tl.MoveTaskTo(4, 1) }, []int{1, 4, 2, 3}},
+		{func() error { return tl.MoveTaskTo(1, 99) }, []int{4, 2, 3, 1}},
+		{func() error { return tl.MoveTaskTo(3, -5) }, []int{3, 4, 2, 1}},
+		{func() error { return tl.MoveTaskBy(2, -1) }, []int{3, 2, 4, 1}},
+		{func() error { return tl.MoveTaskBy(4, 1) }, []int{3, 2, 1, 4}},
+		{func() error { return tl.MoveTaskBy(3, -1) }, []int{3, 2, 1, 4}},
+	}
+	for i, tc := range testCases {
+		if err := tc.move(); err != nil {
+			t.Fatalf("Move %d failed: %v", i, err)
+		}
+		if got := order(); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Move %d: expected order %v, got %v", i, tc.expected, got)
+		}
+	}
+	if msg := indexDiverges(tl); msg != "" {
+		t.Error(msg)
+	}
+
+	if err := tl.MoveTaskTo(9, 0); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound for a missing task, got %v", err)
+	}
+
+	// Moving a task to where it already is does not save
+	fs.saves = 0
+	if err := tl.MoveTaskTo(3, 0); err != nil || fs.saves != 0 {
+		t.Errorf("Expected no save for a move in place, got %d saves, %v", fs.saves, err)
+	}
+
+	fs.fail = true
+	if err := tl.MoveTaskBy(3, 2); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if got := order(); !reflect.DeepEqual(got, []int{3, 2, 1, 4}) {
+		t.Errorf("Expected rollback to order [3 2 1 4], got %v", got)
+	}
+}
+
+// TestSetNote tests replacing, appending to and clearing notes with rollback
+func TestSetNote(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("call the landlord")
+
+	steps := []struct {
+		note       string
+		appendNote bool
+		expected   string
+	}{
+		{"ask about rent", true, "ask about rent"},
+		{"and the boiler", true, "ask about rent\nand the boiler"},
+		{"", true, "ask about rent\nand the boiler"},
+		{"line one\nline two", false, "line one\nline two"},
+		{"", false, ""},
+	}
+	for _, step := range steps {
+		if err := tl.SetNote(1, step.note, step.appendNote); err != nil {
+			t.Fatalf("SetNote(%q, %v) failed: %v", step.note, step.appendNote, err)
+		}
+		if task, _ := tl.GetTask(1); task.Notes != step.expected {
+			t.Errorf("SetNote(%q, %v): expected notes %q, got %q", step.note, step.appendNote, step.expected, task.Notes)
+		}
+	}
+
+	// Notes with newlines survive a JSON round trip
+	tl.SetNote(1, "first\nsecond", false)
+	file := storage.NewFileStorage(filepath.Join(t.TempDir(), "todos.json"))
+	if err := file.Save(tl.TaskList()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reloaded, err := NewTodoList(file)
+	if err != nil {
+		t.Fatalf("Failed to reload TodoList: %v", err)
+	}
+	if task, _ := reloaded.GetTask(1); task.Notes != "first\nsecond" {
+		t.Errorf("Expected notes to survive a reload, got %q", task.Notes)
+	}
+
+	if err := tl.SetNote(5, "x", false); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+
+	fs.fail = true
+	if err := tl.SetNote(1, "lost", false); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if task, _ := tl.GetTask(1); task.Notes != "first\nsecond" {
+		t.Errorf("Expected rollback to the old notes, got %q", task.Notes)
+	}
+}
+
+// TestDuplicateTask tests that a copy keeps the content but not the identity or status
+func TestDuplicateTask(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	due := time.Now().Add(24 * time.Hour)
+	tl.AddTask("review PR", WithPriority(models.PriorityHigh), WithNotes("for Sam"), WithDueDate(due), WithEstimate(90))
+	tl.CompleteTask(1)
+
+	duplicate, err := tl.DuplicateTask(1)
+	if err != nil {
+		t.Fatalf("DuplicateTask failed: %v", err)
+	}
+	if duplicate.ID != 2 || duplicate.Completed || duplicate.DueDate != nil {
+		t.Errorf("Expected pending task 2 without a due date, got %+v", duplicate)
+	}
+	if duplicate.Description != "review PR" || duplicate.Priority != models.PriorityHigh || duplicate.Notes != "for Sam" || duplicate.EstimateMinutes != 90 {
+		t.Errorf("Expected description, priority, notes and estimate to be copied, got %+v", duplicate)
+	}
+	if stats := tl.Stats(); stats.TotalEstimatedMinutes != 180 || stats.CompletedEstimatedMinutes != 90 {
+		t.Errorf("Expected 180 estimated minutes, 90 of them completed, got %+v", stats)
+	}
+	if stored, _ := tl.GetTask(2); !reflect.DeepEqual(stored, *duplicate) {
+		t.Errorf("Expected the copy to be stored, got %+v", stored)
+	}
+
+	if _, err := tl.DuplicateTask(7); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+
+	fs.fail = true
+	if _, err := tl.DuplicateTask(1); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if len(tl.ListTasks()) != 2 || tl.list.NextID != 3 {
+		t.Errorf("Expected rollback to 2 tasks and NextID 3, got %d tasks and NextID %d", len(tl.ListTasks()), tl.list.NextID)
+	}
+}
+
+// TestPruneCompleted tests deleting old completed tasks in one save with rollback
+func TestPruneCompleted(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	for _, desc := range []string{"done early", "pending", "done late", "done untimed"} {
+		tl.AddTask(desc)
+	}
+	tl.CompleteTask(1)
+	tl.CompleteTask(3)
+	tl.CompleteTask(4)
+	cutoff := time.Now().Add(time.Hour)
+	// Created long ago but completed after the cutoff: kept
+	completed := cutoff.Add(time.Minute)
+	tl.list.Tasks[2].CreatedAt = cutoff.AddDate(-1, 0, 0)
+	tl.list.Tasks[2].CompletedAt = &completed
+	// No completion time: goes by its creation before the cutoff, so pruned
+	tl.list.Tasks[3].CompletedAt = nil
+
+	fs.fail = true
+	if _, err := tl.PruneCompleted(cutoff); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if len(tl.ListTasks()) != 4 {
+		t.Errorf("Expected rollback to 4 tasks, got %d", len(tl.ListTasks()))
+	}
+
+	fs.fail = false
+	fs.saves = 0
+	count, err := tl.PruneCompleted(cutoff)
+	if err != nil {
+		t.Fatalf("PruneCompleted failed: %v", err)
+	}
+	if count != 2 || fs.saves != 1 {
+		t.Errorf("Expected 2 tasks pruned in one save, got %d pruned in %d saves", count, fs.saves)
+	}
+	for _, id := range []int{1, 4} {
+		if _, err := tl.GetTask(id); !apperrors.IsTaskNotFound(err) {
+			t.Errorf("Expected task %d to be pruned, got %v", id, err)
+		}
+	}
+	if task, err := tl.GetTask(3); err != nil || task.Description != "done late" {
+		t.Errorf("Expected the recently completed task 3 to be kept, got %+v, %v", task, err)
+	}
+
+	// Nothing left to prune: no save at all
+	if count, err := tl.PruneCompleted(cutoff); err != nil || count != 0 || fs.saves != 1 {
+		t.Errorf("Expected no-op prune, got %d, %v with %d saves", count, err, fs.saves)
+	}
+}
+
+// TestRenumberIDs tests compacting IDs, undoing it and rolling back on save failure
+func TestRenumberIDs(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.SetHistory(storage.NewFileHistory(filepath.Join(t.TempDir(), "undo")))
+	for _, desc := range []string{"one", "two", "three", "four", "five"} {
+		tl.AddTask(desc)
+	}
+	tl.DeleteTask(2)
+	tl.DeleteTask(4)
+
+	fs.fail = true
+	if err := tl.RenumberIDs(); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if task, _ := tl.GetTask(5); task.Description != "five" || tl.list.NextID != 6 {
+		t.Errorf("Expected rollback to the old IDs, got %+v with NextID %d", task, tl.list.NextID)
+	}
+
+	fs.fail = false
+	fs.saves = 0
+	if err := tl.RenumberIDs(); err != nil {
+		t.Fatalf("RenumberIDs failed: %v", err)
+	}
+	for i, desc := range []string{"one", "three", "five"} {
+		if task, err := tl.GetTask(i + 1); err != nil || task.Description != desc {
+			t.Errorf("Expected task %d to be %q, got %+v, %v", i+1, desc, task, err)
+		}
+	}
+	if tl.list.NextID != 4 || fs.saves != 1 {
+		t.Errorf("Expected NextID 4 after one save, got %d after %d saves", tl.list.NextID, fs.saves)
+	}
+
+	// Already contiguous: no save
+	if err := tl.RenumberIDs(); err != nil || fs.saves != 1 {
+		t.Errorf("Expected a no-op, got %v with %d saves", err, fs.saves)
+	}
+
+	// Undo brings the old IDs back with their tasks
+	if err := tl.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if task, _ := tl.GetTask(5); task.Description != "five" {
+		t.Errorf("Expected undo to restore task 5, got %+v", task)
+	}
+}
+
+// fakeReferrer is an IDReferrer holding a fixed set of IDs
+type fakeReferrer []int
+
+func (r fakeReferrer) ReferencedIDs() ([]int, error) { return r, nil }
+
+// TestRenumberIDsReferenced tests that renumbering leaves IDs the event log
+// or a referrer still refers to alone
+func TestRenumberIDsReferenced(t *testing.T) {
+	newList := func() (*TodoList, *failingStorage) {
+		fs := &failingStorage{}
+		tl, err := NewTodoList(fs)
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+		for _, desc := range []string{"one", "two", "three"} {
+			tl.AddTask(desc)
+		}
+		tl.DeleteTask(1)
+		fs.saves = 0
+		return tl, fs
+	}
+
+	// The addition logged for task 4 would point at the wrong task
+	tl, fs := newList()
+	tl.SetEventLog(storage.NewFileEventLog(filepath.Join(t.TempDir(), "todos.json.events")))
+	tl.AddTask("four")
+	fs.saves = 0
+	err := tl.RenumberIDs()
+	if !apperrors.IsIDsReferenced(err) || !strings.Contains(err.Error(), "the event log refers to task 4") {
+		t.Errorf("Expected the event log to block renumbering, got %v", err)
+	}
+	if task, _ := tl.GetTask(4); task.Description != "four" || fs.saves != 0 {
+		t.Errorf("Expected the IDs unchanged and nothing saved, got %+v after %d saves", task, fs.saves)
+	}
+
+	// Reminders sent for task 3 would be lost, and task 2 taking ID 1 is fine
+	tl, fs = newList()
+	tl.SetIDReferrer("the reminder state", fakeReferrer{3, 7})
+	err = tl.RenumberIDs()
+	if !apperrors.IsIDsReferenced(err) || !strings.Contains(err.Error(), "the reminder state refers to task 3") {
+		t.Errorf("Expected the reminder state to block renumbering, got %v", err)
+	}
+	if task, _ := tl.GetTask(3); task.Description != "three" || fs.saves != 0 {
+		t.Errorf("Expected the IDs unchanged and nothing saved, got %+v after %d saves", task, fs.saves)
+	}
+
+	// A transaction is held to the same referrers
+	err = tl.WithTransaction(func(tx *TodoList) error { return tx.RenumberIDs() })
+	if !apperrors.IsIDsReferenced(err) || fs.saves != 0 {
+		t.Errorf("Expected the reminder state to block renumbering in a transaction, got %v after %d saves", err, fs.saves)
+	}
+
+	// IDs nothing refers to can change
+	tl.SetIDReferrer("the reminder state", fakeReferrer{7})
+	if err := tl.RenumberIDs(); err != nil || fs.saves != 1 {
+		t.Errorf("Expected renumbering to succeed, got %v after %d saves", err, fs.saves)
+	}
+	if task, _ := tl.GetTask(2); task.Description != "three" {
+		t.Errorf("Expected task 3 to become task 2, got %+v", task)
+	}
+}
+
+// TestSetLogger tests the structured record logged for each change
+func TestSetLogger(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	// Nothing is logged without a logger
+	tl.AddTask("quiet")
+
+	var buf bytes.Buffer
+	tl.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	tl.AddTask("write report")
+	tl.CompleteTask(2)
+	fs.fail = true
+	tl.DeleteTask(1)
+	fs.fail = false
+	tl.BulkAddTasks([]string{"bulk", " "})
+	tl.DeleteAll()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected a JSON record, got %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 6 {
+		t.Fatalf("Expected 6 records, got %d:\n%s", len(records), buf.String())
+	}
+
+	expected := []struct {
+		op          string
+		id          float64
+		description string
+		failed      bool
+	}{
+		{"add", 2, "write report", false},
+		{"complete", 2, "write report", false},
+		{"delete", 1, "quiet", true},
+		{"add", 3, "bulk", false},
+		{"add", 0, "", true},
+		{"delete_all", 0, "", false},
+	}
+	for i, want := range expected {
+		record := records[i]
+		if record["op"] != want.op || record["level"] != "INFO" {
+			t.Errorf("Record %d: expected op %q at INFO, got %v", i, want.op, record)
+		}
+		if _, ok := record["elapsed_ms"]; !ok {
+			t.Errorf("Record %d: expected elapsed_ms, got %v", i, record)
+		}
+		if want.id == 0 {
+			if _, ok := record["task_id"]; ok {
+				t.Errorf("Record %d: expected no task_id for a whole-list change, got %v", i, record)
+			}
+		} else if record["task_id"] != want.id || record["description"] != want.description {
+			t.Errorf("Record %d: expected task %v %q, got %v", i, want.id, want.description, record)
+		}
+		if _, failed := record["error"]; failed != want.failed {
+			t.Errorf("Record %d: expected error logged=%v, got %v", i, want.failed, record)
+		}
+	}
+}
+
+// TestReload tests picking up changes another process made to the file
+// TestConcurrentModification tests that adds are merged onto a list saved
+// by another process, while other changes are refused
+func TestConcurrentModification(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	tl, err := NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("mine")
+
+	other, err := NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create second TodoList: %v", err)
+	}
+	other.AddTask("theirs")
+
+	// Our add picks up their task and takes the next ID
+	task, err := tl.AddTask("mine too")
+	if err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	if task.ID != 3 {
+		t.Errorf("Expected the merged task to get ID 3, got %d", task.ID)
+	}
+	if tasks := tl.ListTasks(); len(tasks) != 3 || tasks[1].Description != "theirs" {
+		t.Errorf("Expected their task to be kept, got %+v", tasks)
+	}
+	if diff := indexDiverges(tl); diff != "" {
+		t.Errorf("Index diverges after merging: %s", diff)
+	}
+
+	// Completing a task they may have changed is refused and rolled back
+	if err := other.CompleteTask(1); !apperrors.IsConcurrentModification(err) {
+		t.Fatalf("Expected ErrConcurrentModification, got %v", err)
+	}
+	if task, _ := other.GetTask(1); task.Completed {
+		t.Error("Expected the refused change to be rolled back")
+	}
+	saved, err := storage.NewFileStorage(path).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(saved.Tasks) != 3 || saved.Tasks[0].Completed {
+		t.Errorf("Expected the file to be left alone, got %+v", saved.Tasks)
+	}
+}
+
+// TestConcurrentAddRechecksLimits tests that an add merged onto a list
+// saved by another process is checked against max_tasks and no_duplicates
+// again, and nothing is saved when it no longer fits
+func TestConcurrentAddRechecksLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	tl, err := NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.SetMaxTasks(3)
+	tl.SetNoDuplicates(true)
+	tl.AddTask("mine")
+
+	other, err := NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create second TodoList: %v", err)
+	}
+	other.AddTask("shared")
+
+	// They added the same description first
+	if _, err := tl.AddTask("Shared"); !apperrors.IsDuplicateDescription(err) {
+		t.Fatalf("Expected ErrDuplicateDescription, got %v", err)
+	}
+
+	// They filled the list first
+	other.AddTask("theirs")
+	if _, err := tl.AddTask("one more"); !apperrors.IsMaxTasksReached(err) {
+		t.Fatalf("Expected ErrMaxTasksReached, got %v", err)
+	}
+
+	saved, err := storage.NewFileStorage(path).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(saved.Tasks) != 3 || saved.Tasks[1].Description != "shared" || saved.Tasks[2].Description != "theirs" {
+		t.Errorf("Expected only their tasks to be added, got %+v", saved.Tasks)
+	}
+	if diff := indexDiverges(tl); diff != "" {
+		t.Errorf("Index diverges after the refused merge: %s", diff)
+	}
+}
+
+// TestNoDuplicates tests that duplicate descriptions are refused only when
+// turned off, including those of completed tasks
+func TestNoDuplicates(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("Buy milk")
+	if _, err := tl.AddTask("buy milk"); err != nil {
+		t.Fatalf("Expected duplicates to be allowed by default, got %v", err)
+	}
+
+	tl.SetNoDuplicates(true)
+	tl.CompleteTask(1)
+	for _, description := range []string{"Buy milk", "  BUY MILK ", "buy milk"} {
+		if _, err := tl.AddTask(description); !apperrors.IsDuplicateDescription(err) {
+			t.Errorf("AddTask(%q): expected ErrDuplicateDescription, got %v", description, err)
+		}
+	}
+	if _, err := tl.AddTask("Buy milk and eggs"); err != nil {
+		t.Errorf("Expected a different description to be added, got %v", err)
+	}
+	if tasks := tl.ListTasks(); len(tasks) != 3 {
+		t.Errorf("Expected 3 tasks, got %d", len(tasks))
+	}
+}
+
+// TestNoDuplicatesEveryAddPath tests that copies, batches and imports are
+// checked against the list and against each other when duplicates are off
+func TestNoDuplicatesEveryAddPath(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.SetNoDuplicates(true)
+	tl.AddTask("Buy milk")
+	fs.saves = 0
+
+	if _, err := tl.DuplicateTask(1); !apperrors.IsDuplicateDescription(err) {
+		t.Errorf("Expected ErrDuplicateDescription from DuplicateTask, got %v", err)
+	}
+	for _, batch := range [][]string{{"Call mum", "buy milk"}, {"Call mum", " CALL MUM"}} {
+		if _, err := tl.AddTasks(batch); !apperrors.IsDuplicateDescription(err) {
+			t.Errorf("AddTasks(%q): expected ErrDuplicateDescription, got %v", batch, err)
+		}
+	}
+	for _, batch := range [][]models.Task{
+		{{Description: "Call mum"}, {Description: "BUY MILK"}},
+		{{Description: "Call mum"}, {Description: "call mum"}},
+	} {
+		if _, err := tl.ImportTasks(batch); !apperrors.IsDuplicateDescription(err) {
+			t.Errorf("ImportTasks(%+v): expected ErrDuplicateDescription, got %v", batch, err)
+		}
+	}
+	if len(tl.ListTasks()) != 1 || fs.saves != 0 {
+		t.Fatalf("Expected nothing added or saved, got %d tasks after %d saves", len(tl.ListTasks()), fs.saves)
+	}
+
+	// BulkAddTasks skips the duplicates and adds the rest
+	tasks, errs := tl.BulkAddTasks([]string{"Buy milk", "Call mum", "call mum", "Water plants"})
+	if tasks[0] != nil || !apperrors.IsDuplicateDescription(errs[0]) || tasks[1] == nil ||
+		tasks[2] != nil || !apperrors.IsDuplicateDescription(errs[2]) || tasks[3] == nil {
+		t.Errorf("Expected the two duplicates skipped, got %v, %v", tasks, errs)
+	}
+	if len(tl.ListTasks()) != 3 {
+		t.Errorf("Expected 3 tasks, got %+v", tl.ListTasks())
+	}
+}
+
+// TestMaxTasks tests that every way of adding tasks stops at the limit set
+// by SetMaxTasks without saving
+func TestMaxTasks(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.SetMaxTasks(3)
+	tl.AddTask("one")
+
+	// A batch that does not fit adds nothing
+	if _, err := tl.AddTasks([]string{"two", "three", "four"}); !apperrors.IsMaxTasksReached(err) {
+		t.Errorf("Expected ErrMaxTasksReached from AddTasks, got %v", err)
+	}
+	if _, err := tl.ImportTasks([]models.Task{{Description: "a"}, {Description: "b"}, {Description: "c"}}); !apperrors.IsMaxTasksReached(err) {
+		t.Errorf("Expected ErrMaxTasksReached from ImportTasks, got %v", err)
+	}
+
+	// BulkAddTasks adds what fits and skips the rest
+	tasks, errs := tl.BulkAddTasks([]string{"two", "", "three", "four"})
+	if tasks[0] == nil || tasks[2] == nil || tasks[3] != nil || !apperrors.IsEmptyDescription(errs[1]) || !apperrors.IsMaxTasksReached(errs[3]) {
+		t.Errorf("Expected two and three added and four skipped, got %v, %v", tasks, errs)
+	}
+
+	fs.saves = 0
+	if _, err := tl.AddTask("five"); !apperrors.IsMaxTasksReached(err) {
+		t.Errorf("Expected ErrMaxTasksReached from AddTask, got %v", err)
+	}
+	if _, err := tl.DuplicateTask(1); !apperrors.IsMaxTasksReached(err) {
+		t.Errorf("Expected ErrMaxTasksReached from DuplicateTask, got %v", err)
+	}
+	if tasks, _ := tl.BulkAddTasks([]string{"six"}); tasks[0] != nil {
+		t.Errorf("Expected BulkAddTasks to add nothing at the limit, got %+v", tasks[0])
+	}
+	err = tl.WithTransaction(func(tx *TodoList) error {
+		_, err := tx.AddTask("nine")
+		return err
+	})
+	if !apperrors.IsMaxTasksReached(err) {
+		t.Errorf("Expected ErrMaxTasksReached from a transaction, got %v", err)
+	}
+	if len(tl.ListTasks()) != 3 || fs.saves != 0 {
+		t.Errorf("Expected 3 tasks and no saves at the limit, got %d tasks, %d saves", len(tl.ListTasks()), fs.saves)
+	}
+
+	// Deleting makes room again, and 0 lifts the limit
+	tl.DeleteTask(1)
+	if _, err := tl.AddTask("seven"); err != nil {
+		t.Errorf("Expected room after a delete, got %v", err)
+	}
+	tl.SetMaxTasks(0)
+	if _, err := tl.AddTask("eight"); err != nil {
+		t.Errorf("Expected no limit after SetMaxTasks(0), got %v", err)
+	}
+}
+
+// Feature: todo-list-cli, Property 28: 任务数上限
+// For any limit N, adding tasks one at a time succeeds N times and the
+// (N+1)th and later attempts fail with ErrMaxTasksReached
+// Validates: SetMaxTasks
+func TestProperty_MaxTasksRejectsAddBeyondLimit(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("the (N+1)th add fails",
+		prop.ForAll(
+			func(limit, extra int) bool {
+				tl, err := NewTodoList(&mockStorage{})
+				if err != nil {
+					return false
+				}
+				tl.SetMaxTasks(limit)
+				for i := 0; i < limit+extra; i++ {
+					_, err := tl.AddTask(fmt.Sprintf("task %d", i))
+					if (i < limit) != (err == nil) || (i >= limit && !apperrors.IsMaxTasksReached(err)) {
+						return false
+					}
+				}
+				return len(tl.ListTasks()) == limit
+			},
+			gen.IntRange(1, 30),
+			gen.IntRange(1, 5),
+		))
+
+	properties.TestingRun(t)
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	tl, err := NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("mine")
+	if stale, err := tl.Stale(); err != nil || stale {
+		t.Errorf("Expected a fresh list after our own save, got %v, %v", stale, err)
+	}
+
+	// Another process adds a task and completes ours
+	other, err := NewTodoList(storage.NewFileStorage(path))
+	if err != nil {
+		t.Fatalf("Failed to create second TodoList: %v", err)
+	}
+	other.AddTask("theirs")
+	other.CompleteTask(1)
+
+	if stale, err := tl.Stale(); err != nil || !stale {
+		t.Errorf("Expected the list to be stale, got %v, %v", stale, err)
+	}
+	if err := tl.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if stale, err := tl.Stale(); err != nil || stale {
+		t.Errorf("Expected a fresh list after reloading, got %v, %v", stale, err)
+	}
+	tasks := tl.ListTasks()
+	if len(tasks) != 2 || !tasks[0].Completed || tasks[1].Description != "theirs" {
+		t.Errorf("Expected both changes after reloading, got %+v", tasks)
+	}
+
+	// The next ID follows the reloaded list
+	if task, _ := tl.AddTask("after"); task.ID != 3 {
+		t.Errorf("Expected ID 3 after reloading, got %d", task.ID)
+	}
+
+	// A file that cannot be read leaves the list as it was
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := tl.Reload(); !apperrors.IsInvalidJSON(err) {
+		t.Errorf("Expected ErrInvalidJSON, got %v", err)
+	}
+	if len(tl.ListTasks()) != 3 {
+		t.Errorf("Expected the 3 tasks to be kept, got %d", len(tl.ListTasks()))
+	}
+
+	// Storages that cannot detect changes are never stale
+	if stale, err := (&TodoList{storage: &mockStorage{}}).Stale(); err != nil || stale {
+		t.Errorf("Expected mock storage never to be stale, got %v, %v", stale, err)
+	}
+}
+
+// TestLoadFromReaderSaveToWriter tests replacing the list from a stream and
+// writing it back out
+func TestLoadFromReaderSaveToWriter(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("old")
+
+	input := `{"version": 1, "tasks": [{"id": 4, "description": "piped", "completed": true, "created_at": "2026-01-02T00:00:00Z"}], "next_id": 9}`
+	if err := tl.LoadFromReader(strings.NewReader(input)); err != nil {
+		t.Fatalf("LoadFromReader failed: %v", err)
+	}
+	if task, err := tl.GetTask(4); err != nil || task.Description != "piped" || tl.list.NextID != 9 {
+		t.Errorf("Expected the piped list, got %+v, %v with NextID %d", task, err, tl.list.NextID)
+	}
+	if len(fs.data.Tasks) != 1 || fs.data.NextID != 9 {
+		t.Errorf("Expected the piped list to be saved, got %+v", fs.data)
+	}
+
+	var out bytes.Buffer
+	if err := tl.SaveToWriter(&out); err != nil {
+		t.Fatalf("SaveToWriter failed: %v", err)
+	}
+	var written models.TaskList
+	if err := json.Unmarshal(out.Bytes(), &written); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", out.String(), err)
+	}
+	if written.Version != models.FormatVersion || written.NextID != 9 || len(written.Tasks) != 1 || written.Tasks[0].ID != 4 {
+		t.Errorf("Expected the list with its version, got %+v", written)
+	}
+
+	// Invalid input and failed saves leave the list alone
+	for _, bad := range []string{"{", `{"tasks": [{"id": 3}], "next_id": 2}`, `{"version": 99, "tasks": [], "next_id": 1}`} {
+		if err := tl.LoadFromReader(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected LoadFromReader(%q) to fail", bad)
+		}
+	}
+	fs.fail = true
+	if err := tl.LoadFromReader(strings.NewReader(`{"tasks": [], "next_id": 1}`)); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if _, err := tl.GetTask(4); err != nil {
+		t.Errorf("Expected the list to be kept, got %v", err)
+	}
+}
+
+// TestCompleteAll tests completing every pending task in one save with rollback
+func TestCompleteAll(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("one")
+	tl.AddTask("two")
+	tl.AddTask("three")
+	tl.CompleteTask(2)
+
+	fs.fail = true
+	if _, err := tl.CompleteAll(); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if stats := tl.Stats(); stats.Pending != 2 {
+		t.Errorf("Expected rollback to 2 pending tasks, got %d", stats.Pending)
+	}
+
+	fs.fail = false
+	fs.saves = 0
+	count, err := tl.CompleteAll()
+	if err != nil {
+		t.Fatalf("CompleteAll failed: %v", err)
+	}
+	if count != 2 || fs.saves != 1 || tl.Stats().Pending != 0 {
+		t.Errorf("Expected 2 tasks completed in one save, got %d completed, %d saves, %d pending", count, fs.saves, tl.Stats().Pending)
+	}
+
+	// Nothing left to do: no save at all
+	count, err = tl.CompleteAll()
+	if err != nil || count != 0 || fs.saves != 1 {
+		t.Errorf("Expected no-op when everything is done, got %d, %v, %d saves", count, err, fs.saves)
+	}
+}
+
+// TestCompleteRecurringTask tests that completing a recurring task adds its next occurrence
+func TestCompleteRecurringTask(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	tl.now = func() time.Time { return now }
+
+	if _, err := tl.AddTask("bad", WithRecurrence("hourly")); !apperrors.IsInvalidRecurrence(err) {
+		t.Fatalf("Expected ErrInvalidRecurrence, got %v", err)
+	}
+
+	// Due three weeks ago: missed occurrences are skipped
+	due := now.AddDate(0, 0, -21)
+	weekly, _ := tl.AddTask("review #team", WithDueDate(due), WithPriority(models.PriorityHigh), WithNotes("agenda"), WithRecurrence("weekly"))
+	daily, _ := tl.AddTask("standup", WithRecurrence("daily"))
+
+	fs.fail = true
+	if err := tl.CompleteTask(weekly.ID); err == nil {
+		t.Fatal("Expected error when save fails")
+	}
+	if len(tl.ListTasks()) != 2 || tl.list.NextID != 3 || tl.list.Tasks[0].Completed || tl.list.Tasks[0].CompletedAt != nil {
+		t.Fatalf("Expected a full rollback, got %+v", tl.list)
+	}
+
+	fs.fail = false
+	if err := tl.CompleteTask(weekly.ID); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+	next, err := tl.GetTask(3)
+	if err != nil {
+		t.Fatalf("Expected the next occurrence as task 3: %v", err)
+	}
+	if want := due.AddDate(0, 0, 28); !next.DueDate.Equal(want) {
+		t.Errorf("Expected next due %v, got %v", want, next.DueDate)
+	}
+	if next.Completed || next.CompletedAt != nil || next.Description != "review" || next.Priority != models.PriorityHigh ||
+		next.Notes != "agenda" || next.Recurrence != "weekly" || !next.CreatedAt.Equal(now) {
+		t.Errorf("Expected a pending copy of the task, got %+v", next)
+	}
+	if fmt.Sprint(next.Tags) != "[team]" {
+		t.Errorf("Expected the next occurrence to keep the tags, got %v", next.Tags)
+	}
+	tl.list.Tasks[tl.index[next.ID]].Tags[0] = "changed"
+	if done, _ := tl.GetTask(weekly.ID); done.Tags[0] != "team" {
+		t.Errorf("Expected the occurrences not to share tags, got %v", done.Tags)
+	}
+	if done, _ := tl.GetTask(weekly.ID); done.CompletedAt == nil || !done.CompletedAt.Equal(now) {
+		t.Errorf("Expected the task completed at %v, got %v", now, done.CompletedAt)
+	}
+
+	// Completing again does not spawn another occurrence
+	tl.CompleteTask(weekly.ID)
+	if len(tl.ListTasks()) != 3 {
+		t.Errorf("Expected no new task for an already completed task, got %d tasks", len(tl.ListTasks()))
+	}
+
+	// CompleteAll completes tasks 2 and 3 but not the occurrences it adds
+	count, err := tl.CompleteAll()
+	if err != nil || count != 2 {
+		t.Fatalf("Expected 2 tasks completed, got %d (%v)", count, err)
+	}
+	tasks := tl.ListTasks()
+	if len(tasks) != 5 || tasks[3].Completed || tasks[4].Completed || tasks[3].Description != daily.Description {
+		t.Fatalf("Expected pending next occurrences, got %+v", tasks)
+	}
+	if want := now.AddDate(0, 0, 1); !tasks[3].DueDate.Equal(want) {
+		t.Errorf("Expected a task without due date to recur from now, got %v", tasks[3].DueDate)
+	}
+}
+
+// TestConcurrentUse tests that adds, completes and lists from many goroutines
+// lose no task and keep IDs unique; run with -race to check synchronization
+func TestConcurrentUse(t *testing.T) {
+	ms := &mockStorage{data: nil}
+	tl, err := NewTodoList(ms)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	const workers, perWorker = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				task, err := tl.AddTask(fmt.Sprintf("worker %d task %d", w, i))
+				if err != nil {
+					errs <- err
+					continue
+				}
+				if i%2 == 0 {
+					if err := tl.CompleteTask(task.ID); err != nil {
+						errs <- err
+					}
+				}
+				tl.ListTasks()
+				tl.Stats()
+				if _, err := tl.GetTask(task.ID); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent operation failed: %v", err)
+	}
+
+	tasks := tl.ListTasks()
+	if len(tasks) != workers*perWorker {
+		t.Fatalf("Expected %d tasks, got %d", workers*perWorker, len(tasks))
+	}
+	ids := map[int]bool{}
+	for _, task := range tasks {
+		if ids[task.ID] {
+			t.Errorf("Duplicate task ID %d", task.ID)
+		}
+		ids[task.ID] = true
+	}
+	if stats := tl.Stats(); stats.Completed != workers*((perWorker+1)/2) {
+		t.Errorf("Expected %d completed tasks, got %d", workers*((perWorker+1)/2), stats.Completed)
+	}
+	if len(ms.data.Tasks) != len(tasks) {
+		t.Errorf("Expected the last save to hold every task, got %d", len(ms.data.Tasks))
+	}
+}
+
+// TestUpdateTask tests applying, validating and rolling back an update
+func TestUpdateTask(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.AddTask("first")
+	due := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	tl.AddTask("second", WithDueDate(due))
+	fs.saves = 0
+
+	err = tl.UpdateTask(2, func(task *models.Task) error {
+		task.Description = "second, edited"
+		task.Priority = models.PriorityHigh
+		*task.DueDate = task.DueDate.AddDate(0, 0, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateTask failed: %v", err)
+	}
+	task, _ := tl.GetTask(2)
+	if task.Description != "second, edited" || task.Priority != models.PriorityHigh || !task.DueDate.Equal(due.AddDate(0, 0, 1)) {
+		t.Errorf("Expected the update to be applied, got %+v", task)
+	}
+	if fs.saves != 1 || fs.data.Tasks[1].Description != "second, edited" {
+		t.Errorf("Expected the update to be saved once, got %d saves", fs.saves)
+	}
+
+	// Changing the returned copy does not touch the list
+	*task.DueDate = due
+	if stored, _ := tl.GetTask(2); stored.DueDate.Equal(due) {
+		t.Error("Expected GetTask to return a deep copy")
+	}
+
+	unchanged := tl.ListTasks()
+	rejected := []struct {
+		name string
+		fn   func(*models.Task) error
+		is   func(error) bool
+	}{
+		{"fn error", func(task *models.Task) error {
+			task.Description = "half done"
+			return apperrors.ErrInvalidCommand
+		}, apperrors.IsInvalidCommand},
+		{"ID change", func(task *models.Task) error { task.ID = 7; return nil }, apperrors.IsInvalidID},
+		{"blank description", func(task *models.Task) error { task.Description = " "; return nil }, apperrors.IsEmptyDescription},
+		{"bad recurrence", func(task *models.Task) error { task.Recurrence = "hourly"; return nil }, apperrors.IsInvalidRecurrence},
+	}
+	for _, tc := range rejected {
+		if err := tl.UpdateTask(1, tc.fn); !tc.is(err) {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+	}
+	if !reflect.DeepEqual(tl.ListTasks(), unchanged) || fs.saves != 1 {
+		t.Errorf("Expected rejected updates to change nothing, got %+v after %d saves", tl.ListTasks(), fs.saves)
+	}
+
+	// An update that changes nothing does not save
+	if err := tl.UpdateTask(1, func(*models.Task) error { return nil }); err != nil || fs.saves != 1 {
+		t.Errorf("Expected a no-op update to skip the save, got %v after %d saves", err, fs.saves)
+	}
+
+	if err := tl.UpdateTask(99, func(*models.Task) error { return nil }); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+// Feature: todo-list-cli, Property 17: 保存失败时更新完全回滚
+// For any task list and any update, a failed save leaves the list exactly as
+// it was, and a successful save changes only the updated task
+// Validates: UpdateTask rollback semantics
+func TestProperty_UpdateTaskRollsBackOnSaveFailure(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("failed updates leave the list unchanged",
+		prop.ForAll(
+			func(count int, pick int, description string, priority int, completed bool, fail bool) bool {
+				fs := &failingStorage{}
+				tl, err := NewTodoList(fs)
+				if err != nil {
+					return false
+				}
+				for i := 0; i < count; i++ {
+					tl.AddTask(fmt.Sprintf("task %d", i), WithDueDate(time.Now().Add(time.Duration(i)*time.Hour)))
+				}
+				id := pick%count + 1
+				before := tl.ListTasks()
+				fs.fail = fail
+
+				err = tl.UpdateTask(id, func(task *models.Task) error {
+					task.Description = description
+					task.Priority = models.Priority(priority)
+					task.Completed = completed
+					*task.DueDate = task.DueDate.Add(time.Minute)
+					return nil
+				})
+				after := tl.ListTasks()
+
+				if fail || err != nil {
+					// Either the save failed or the update was rejected
+					return err != nil && reflect.DeepEqual(before, after)
+				}
+				for i := range after {
+					if after[i].ID == id {
+						if after[i].Description != NormalizeDescription(description) || after[i].Completed != completed ||
+							!after[i].DueDate.Equal(before[i].DueDate.Add(time.Minute)) {
+							return false
+						}
+					} else if !reflect.DeepEqual(after[i], before[i]) {
+						return false
+					}
+				}
+				return len(after) == len(before)
+			},
+			gen.IntRange(1, 10),
+			gen.IntRange(0, 100),
+			gen.AnyString(),
+			gen.IntRange(0, 3),
+			gen.Bool(),
+			gen.Bool(),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// TestTimeTracking tests starting and stopping timers and the time they add up to
+func TestTimeTracking(t *testing.T) {
+	fs := &failingStorage{}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	now := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+	tl.now = func() time.Time { return now }
+	tl.AddTask("write report")
+
+	if err := tl.StopTimeTracking(1); !apperrors.IsNoActiveTimer(err) {
+		t.Errorf("Expected ErrNoActiveTimer before starting, got %v", err)
+	}
+	if err := tl.StartTimeTracking(1); err != nil {
+		t.Fatalf("StartTimeTracking failed: %v", err)
+	}
+	if err := tl.StartTimeTracking(1); !apperrors.IsTimerRunning(err) {
+		t.Errorf("Expected ErrTimerRunning when starting twice, got %v", err)
+	}
+
+	now = now.Add(30 * time.Minute)
+	if spent := tl.TaskTimeSpent(1); spent != 30*time.Minute {
+		t.Errorf("Expected a running timer to count up to now, got %v", spent)
+	}
+	if err := tl.StopTimeTracking(1); err != nil {
+		t.Fatalf("StopTimeTracking failed: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	tl.StartTimeTracking(1)
+	now = now.Add(15 * time.Minute)
+	tl.StopTimeTracking(1)
+	now = now.Add(time.Hour)
+	if spent := tl.TaskTimeSpent(1); spent != 45*time.Minute {
+		t.Errorf("Expected 45m across two entries, got %v", spent)
+	}
+	if task, _ := tl.GetTask(1); len(task.TimeEntries) != 2 || task.TrackingTime() {
+		t.Errorf("Expected two stopped entries, got %+v", task.TimeEntries)
+	}
+	if len(fs.data.Tasks[0].TimeEntries) != 2 {
+		t.Errorf("Expected the entries to be saved, got %+v", fs.data.Tasks[0])
+	}
+
+	// A failed save leaves the timer as it was
+	tl.StartTimeTracking(1)
+	fs.fail = true
+	now = now.Add(time.Minute)
+	if err := tl.StopTimeTracking(1); !apperrors.IsStorageError(err) {
+		t.Errorf("Expected a storage error, got %v", err)
+	}
+	if task, _ := tl.GetTask(1); !task.TrackingTime() {
+		t.Error("Expected the timer to keep running after a failed stop")
+	}
+
+	if err := tl.StartTimeTracking(99); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+	if spent := tl.TaskTimeSpent(99); spent != 0 {
+		t.Errorf("Expected no time for an unknown task, got %v", spent)
+	}
+}
+
+// indexDiverges returns a description of the first difference between the
+// ID index and the task slice, or "" if they agree
+func indexDiverges(tl *TodoList) string {
+	if len(tl.index) != len(tl.list.Tasks) {
+		return fmt.Sprintf("index has %d entries for %d tasks", len(tl.index), len(tl.list.Tasks))
+	}
+	for i, task := range tl.list.Tasks {
+		if j, ok := tl.index[task.ID]; !ok || j != i {
+			return fmt.Sprintf("task %d is at %d but indexed at %d (%v)", task.ID, i, j, ok)
+		}
+	}
+	return ""
+}
+
+// Feature: todo-list-cli, Property 18: ID 索引始终与任务列表一致
+// For any sequence of adds, imports, completions (including recurring
+// tasks), deletions, undos and failed saves, the ID index maps every task
+// ID to its position in the list and holds nothing else
+// Validates: ID lookup index
+func TestProperty_IndexMatchesTasks(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("the index never diverges from the task slice",
+		prop.ForAll(
+			func(ops []int, ids []int, failures []bool) bool {
+				fs := &failingStorage{}
+				tl, err := NewTodoList(fs)
+				if err != nil {
+					return false
+				}
+				tl.SetHistory(storage.NewFileHistory(filepath.Join(t.TempDir(), "undo")))
+
+				for i, op := range ops {
+					id := ids[i%len(ids)]
+					fs.fail = failures[i%len(failures)]
+					switch op {
+					case 0:
+						tl.AddTask(fmt.Sprintf("task %d", i))
+					case 1:
+						tl.AddTask("recurring", WithRecurrence(recurrence.Daily))
+					case 2:
+						tl.ImportTasks([]models.Task{{Description: "a"}, {Description: "b"}})
+					case 3:
+						tl.CompleteTask(id)
+					case 4:
+						tl.CompleteAll()
+					case 5:
+						tl.DeleteTask(id)
+					case 6:
+						tl.DeleteAll()
+					case 7:
+						tl.Undo()
+					case 8:
+						tl.UpdateTask(id, func(task *models.Task) error { task.Description += "!"; return nil })
+					}
+					if diff := indexDiverges(tl); diff != "" {
+						t.Logf("after op %d on %d: %s", op, id, diff)
+						return false
+					}
+				}
+				return true
+			},
+			gen.SliceOf(gen.IntRange(0, 8)),
+			gen.SliceOfN(5, gen.IntRange(1, 12)),
+			gen.SliceOfN(5, gen.Bool()),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 23: 重新编号后 ID 连续
+// For any sequence of adds, deletions and moves, RenumberIDs leaves the IDs
+// as 1..N in list order with NextID N+1, keeping every task's content
+// Validates: RenumberIDs
+func TestProperty_RenumberIDsContiguous(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("renumbered IDs are contiguous from 1",
+		prop.ForAll(
+			func(ops []int, ids []int) bool {
+				tl, err := NewTodoList(&mockStorage{})
+				if err != nil {
+					return false
+				}
+				for i, op := range ops {
+					id := ids[i%len(ids)]
+					switch op {
+					case 0, 1:
+						tl.AddTask(fmt.Sprintf("task %d", i))
+					case 2:
+						tl.DeleteTask(id)
+					case 3:
+						tl.MoveTask(id, 0)
+					}
+				}
+				before := tl.ListTasks()
+
+				if err := tl.RenumberIDs(); err != nil {
+					return false
+				}
+				after := tl.ListTasks()
+				if len(after) != len(before) || tl.list.NextID != len(after)+1 || indexDiverges(tl) != "" {
+					return false
+				}
+				for i, task := range after {
+					if task.ID != i+1 || task.Description != before[i].Description {
+						return false
+					}
+				}
+				return true
+			},
+			gen.SliceOf(gen.IntRange(0, 3)),
+			gen.SliceOfN(5, gen.IntRange(1, 12)),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// Feature: todo-list-cli, Property 27: 反复移动不破坏顺序
+// For any sequence of MoveTaskTo and MoveTaskBy calls, the list keeps the
+// same tasks, each moved task ends at its target clamped to the list, and
+// the other tasks keep their relative order
+// Validates: MoveTaskTo, MoveTaskBy
+func TestProperty_RepeatedMovesKeepOrder(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("moves are permutations landing on the clamped target",
+		prop.ForAll(
+			func(size int, ids []int, targets []int) bool {
+				tl, err := NewTodoList(&mockStorage{})
+				if err != nil {
+					return false
+				}
+				for i := 0; i < size; i++ {
+					tl.AddTask(fmt.Sprintf("task %d", i))
+				}
+				for i, target := range targets {
+					id := ids[i%len(ids)]%size + 1
+					before := tl.ListTasks()
+					if i%2 == 0 {
+						err = tl.MoveTaskTo(id, target)
+					} else {
+						err = tl.MoveTaskBy(id, target)
+					}
+					if err != nil {
+						return false
+					}
+
+					after := tl.ListTasks()
+					if len(after) != size || indexDiverges(tl) != "" {
+						return false
+					}
+					from, to := -1, -1
+					for j := range after {
+						if before[j].ID == id {
+							from = j
+						}
+						if after[j].ID == id {
+							to = j
+						}
+					}
+					want := target
+					if i%2 == 1 {
+						want = from + target
+					}
+					if to != max(0, min(want, size-1)) {
+						return false
+					}
+					// The other tasks keep their relative order
+					var rest, restAfter []int
+					for j := range before {
+						if before[j].ID != id {
+							rest = append(rest, before[j].ID)
+						}
+						if after[j].ID != id {
+							restAfter = append(restAfter, after[j].ID)
+						}
+					}
+					if !reflect.DeepEqual(rest, restAfter) {
+						return false
+					}
+				}
+				return true
+			},
+			gen.IntRange(1, 10),
+			gen.SliceOfN(5, gen.IntRange(0, 20)),
+			gen.SliceOf(gen.IntRange(-12, 12)),
+		))
+
+	properties.TestingRun(t)
+}
+
+// Feature: todo-list-cli, Property 24: 禁止重复描述
+// For any description, with duplicates turned off, adding it a second time
+// in any letter case fails with ErrDuplicateDescription and adds nothing
+// Validates: SetNoDuplicates
+func TestProperty_NoDuplicatesRejectsSecondAdd(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("second add of a description fails",
+		prop.ForAll(
+			func(description string, upper bool) bool {
+				tl, err := NewTodoList(&mockStorage{})
+				if err != nil {
+					return false
+				}
+				tl.SetNoDuplicates(true)
+				if _, err := tl.AddTask(description); err != nil {
+					return false
+				}
+				again := description
+				if upper {
+					again = strings.ToUpper(description)
+				}
+				_, err = tl.AddTask(again)
+				return apperrors.IsDuplicateDescription(err) && len(tl.ListTasks()) == 1
+			},
+			gen.AlphaString().SuchThat(func(s string) bool { return s != "" }),
+			gen.Bool(),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// BenchmarkLookup compares finding the last of 20,000 tasks through the ID
+// index with the linear scan it replaced
+func BenchmarkLookup(b *testing.B) {
+	tasks := make([]models.Task, 20000)
+	for i := range tasks {
+		tasks[i] = models.Task{ID: i + 1, Description: "task"}
+	}
+	tl, err := NewTodoList(&mockStorage{data: &models.TaskList{Tasks: tasks, NextID: len(tasks) + 1}})
+	if err != nil {
+		b.Fatalf("Failed to create TodoList: %v", err)
+	}
+	last := len(tasks)
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := tl.indexOf(last); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j, task := range tl.list.Tasks {
+				if task.ID == last {
+					_ = j
+					break
+				}
+			}
+		}
+	})
+}
+
+// TestWeeklyReport tests that logged changes are counted in their week and
+// grouped by project and priority
+func TestWeeklyReport(t *testing.T) {
+	dir := t.TempDir()
+	tl, err := NewTodoList(storage.NewFileStorage(filepath.Join(dir, "todos.json")))
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	tl.SetEventLog(storage.NewFileEventLog(filepath.Join(dir, "todos.json.events")))
+
+	// Monday 2026-01-12
+	week := time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)
+	now := week.Add(-24 * time.Hour)
+	tl.now = func() time.Time { return now }
+
+	// The week before: one task added and completed
+	tl.ImportTasks([]models.Task{{Description: "old +home", CreatedAt: now}})
+	tl.CompleteTask(1)
+
+	// During the week
+	now = week.Add(10 * time.Hour)
+	tl.ImportTasks([]models.Task{
+		{Description: "pay rent +home +finance", Priority: models.PriorityHigh, CreatedAt: now},
+		{Description: "call plumber +home", CreatedAt: now},
+		{Description: "read book", Priority: models.PriorityLow, CreatedAt: now},
+		{Description: "standup", Recurrence: "daily", CreatedAt: now},
+	})
+	now = now.Add(24 * time.Hour)
+	tl.CompleteTask(2)
+	tl.CompleteTask(5) // adds the next standup
+	tl.DeleteTask(4)
+	tl.DeleteTask(1)
+
+	report, err := tl.WeeklyReport(week)
+	if err != nil {
+		t.Fatalf("WeeklyReport failed: %v", err)
+	}
+	if !report.Period.Start.Equal(week) || !report.Period.End.Equal(week.AddDate(0, 0, 7)) {
+		t.Errorf("Unexpected period %+v", report.Period)
+	}
+	if report.TasksAdded != 5 || report.TasksCompleted != 2 || report.TasksDeleted != 2 {
+		t.Errorf("Expected 5 added, 2 completed and 2 deleted, got %+v", report)
+	}
+
+	wantProjects := []models.ReportGroup{
+		{Name: "finance", Added: 1, Completed: 1},
+		{Name: "home", Added: 2, Completed: 1, Deleted: 1},
+		{Name: NoProject, Added: 3, Completed: 1, Deleted: 1},
+	}
+	if !reflect.DeepEqual(report.Projects, wantProjects) {
+		t.Errorf("Expected projects %+v, got %+v", wantProjects, report.Projects)
+	}
+	wantPriorities := []models.ReportGroup{
+		{Name: "high", Added: 1, Completed: 1},
+		{Name: "low", Added: 1, Deleted: 1},
+		{Name: "none", Added: 3, Completed: 1, Deleted: 1},
+	}
+	if !reflect.DeepEqual(report.Priorities, wantPriorities) {
+		t.Errorf("Expected priorities %+v, got %+v", wantPriorities, report.Priorities)
+	}
+
+	// Without a log only tasks still in the list count, as added
+	plain, _ := NewTodoList(storage.NewFileStorage(filepath.Join(dir, "todos.json")))
+	report, err = plain.WeeklyReport(week)
+	if err != nil || report.TasksAdded != 4 || report.TasksCompleted != 0 || report.TasksDeleted != 0 {
+		t.Errorf("Expected 4 additions from creation dates, got %+v, %v", report, err)
+	}
+
+	// Dry runs are not logged
+	now = week.AddDate(0, 0, 8)
+	tl.SetDryRun(true)
+	tl.AddTask("preview")
+	if report, _ := tl.WeeklyReport(week); report.TasksAdded != 5 {
+		t.Errorf("Expected a dry run not to be logged, got %d additions", report.TasksAdded)
+	}
+}
+
+// TestDeleteTaskRollback tests that a failed save restores the list exactly,
+// whichever position the task was deleted from
+func TestDeleteTaskRollback(t *testing.T) {
+	for position := 1; position <= 5; position++ {
+		fs := &failingStorage{}
+		tl, err := NewTodoList(fs)
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+		for i := 1; i <= 5; i++ {
+			tl.AddTask(fmt.Sprintf("task %d", i), WithPriority(models.Priority(i%4)))
+		}
+		// Leave spare capacity so the removal shifts elements in place
+		tl.list.Tasks = append(make([]models.Task, 0, 16), tl.list.Tasks...)
+
+		original, _ := json.Marshal(tl.list)
+		fs.fail = true
+		if err := tl.DeleteTask(position); !apperrors.IsStorageError(err) {
+			t.Fatalf("Expected a storage error, got %v", err)
+		}
+
+		restored, _ := json.Marshal(tl.list)
+		if !bytes.Equal(original, restored) {
+			t.Errorf("Deleting task %d: expected the list to be restored exactly\nbefore: %s\nafter:  %s", position, original, restored)
+		}
+		if diff := indexDiverges(tl); diff != "" {
+			t.Errorf("Deleting task %d: %s", position, diff)
+		}
+	}
+}
+
+// TestArchive tests moving completed tasks to the archive and rolling back
+// both files when either save fails
+func TestArchive(t *testing.T) {
+	newList := func() (*TodoList, *failingStorage) {
+		fs := &failingStorage{}
+		tl, err := NewTodoList(fs)
+		if err != nil {
+			t.Fatalf("Failed to create TodoList: %v", err)
+		}
+		for i := 1; i <= 4; i++ {
+			tl.AddTask(fmt.Sprintf("task %d", i))
+		}
+		tl.CompleteTask(1)
+		tl.CompleteTask(3)
+		return tl, fs
+	}
+
+	t.Run("moves completed tasks", func(t *testing.T) {
+		tl, _ := newList()
+		archive := &failingStorage{}
+		archive.Save(&models.TaskList{Tasks: []models.Task{{ID: 9, Description: "old", Completed: true}}, NextID: 10})
+
+		count, err := tl.archive(archive)
+		if err != nil || count != 2 {
+			t.Fatalf("Expected 2 tasks archived, got %d, %v", count, err)
+		}
+		if tasks := tl.ListTasks(); len(tasks) != 2 || tasks[0].ID != 2 || tasks[1].ID != 4 {
+			t.Errorf("Expected tasks 2 and 4 to remain, got %+v", tasks)
+		}
+		if diff := indexDiverges(tl); diff != "" {
+			t.Error(diff)
+		}
+		archived, _ := archive.Load()
+		if len(archived.Tasks) != 3 || archived.Tasks[0].ID != 9 || archived.Tasks[1].ID != 1 || archived.Tasks[2].ID != 3 {
+			t.Errorf("Expected the completed tasks appended to the archive, got %+v", archived.Tasks)
+		}
+		if task, _ := tl.AddTask("next"); task.ID != 5 {
+			t.Errorf("Expected IDs to continue at 5, got %d", task.ID)
+		}
+	})
+
+	t.Run("IDs already in the archive", func(t *testing.T) {
+		tl, _ := newList()
+		archive := &failingStorage{}
+		archive.Save(&models.TaskList{Tasks: []models.Task{{ID: 3, Description: "old", Completed: true}}, NextID: 4})
+
+		if _, err := tl.archive(archive); err != nil {
+			t.Fatalf("archive failed: %v", err)
+		}
+		archived, _ := archive.Load()
+		if err := archived.Validate(); err != nil {
+			t.Errorf("Expected a valid archive, got %v", err)
+		}
+		if len(archived.Tasks) != 3 || archived.Tasks[1].ID != 1 || archived.Tasks[2].ID != 5 || archived.NextID != 6 {
+			t.Errorf("Expected task 3 renumbered to 5, got %+v next %d", archived.Tasks, archived.NextID)
+		}
+	})
+
+	t.Run("archive save fails", func(t *testing.T) {
+		tl, fs := newList()
+		original, _ := json.Marshal(tl.list)
+		saves := fs.saves
+
+		if _, err := tl.archive(&failingStorage{fail: true}); !apperrors.IsStorageError(err) {
+			t.Fatalf("Expected a storage error, got %v", err)
+		}
+		if restored, _ := json.Marshal(tl.list); !bytes.Equal(original, restored) || fs.saves != saves {
+			t.Errorf("Expected the active list untouched, got %s", restored)
+		}
+	})
+
+	t.Run("active save fails", func(t *testing.T) {
+		tl, fs := newList()
+		original, _ := json.Marshal(tl.list)
+		archive := &failingStorage{}
+		archive.Save(&models.TaskList{Tasks: []models.Task{}, NextID: 1})
+
+		fs.fail = true
+		if _, err := tl.archive(archive); !apperrors.IsStorageError(err) {
+			t.Fatalf("Expected a storage error, got %v", err)
+		}
+		if restored, _ := json.Marshal(tl.list); !bytes.Equal(original, restored) {
+			t.Errorf("Expected the active list restored, got %s", restored)
+		}
+		if diff := indexDiverges(tl); diff != "" {
+			t.Error(diff)
+		}
+		if archived, _ := archive.Load(); len(archived.Tasks) != 0 {
+			t.Errorf("Expected the archive restored, got %+v", archived.Tasks)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		tl, fs := newList()
+		saves := fs.saves
+		archive := &failingStorage{}
+		tl.SetDryRun(true)
+
+		if count, err := tl.archive(archive); err != nil || count != 2 {
+			t.Fatalf("Expected 2 tasks archived, got %d, %v", count, err)
+		}
+		if fs.saves != saves || archive.saves != 0 {
+			t.Errorf("Expected nothing saved, got %d and %d saves", fs.saves-saves, archive.saves)
+		}
+	})
+}
+
+// TestDueBeforeAndBetween tests the due date windows at midnight boundaries
+// and across time zones
+func TestDueBeforeAndBetween(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	// Boundaries are instants: 2026-01-15 00:00 in Tokyo is 2026-01-14 15:00 UTC
+	tokyo := time.FixedZone("JST", 9*60*60)
+	midnight := time.Date(2026, 1, 15, 0, 0, 0, 0, tokyo)
+	at := func(d time.Duration) TaskOption { return WithDueDate(midnight.Add(d).UTC()) }
+
+	tl.AddTask("last second of the 14th", at(-time.Second))
+	tl.AddTask("midnight", at(0))
+	tl.AddTask("no due date")
+	tl.AddTask("done", at(-time.Hour))
+	tl.CompleteTask(4)
+	tl.AddTask("a week ago", at(-7*24*time.Hour))
+	tl.AddTask("end of the 15th", at(24*time.Hour-time.Second))
+
+	ids := func(tasks []models.Task) []int {
+		out := []int{}
+		for _, task := range tasks {
+			out = append(out, task.ID)
+		}
+		return out
+	}
+
+	// Earliest first, completed and undated tasks excluded
+	if got, want := ids(tl.DueBefore(midnight)), []int{5, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DueBefore(midnight): expected %v, got %v", want, got)
+	}
+	if got, want := ids(tl.DueBetween(midnight, midnight.AddDate(0, 0, 1))), []int{2, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DueBetween(the 15th): expected %v, got %v", want, got)
+	}
+	// The same instant in UTC selects the same tasks
+	if got, want := ids(tl.DueBefore(midnight.UTC())), []int{5, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DueBefore(midnight UTC): expected %v, got %v", want, got)
+	}
+	if got := tl.DueBetween(midnight, midnight); len(got) != 0 {
+		t.Errorf("Expected an empty window to select nothing, got %v", ids(got))
+	}
+}
+
+// loadCountingStorage counts Load calls
+type loadCountingStorage struct {
+	mockStorage
+	loads int
+}
+
+func (ls *loadCountingStorage) Load() (*models.TaskList, error) {
+	ls.loads++
+	return ls.mockStorage.Load()
+}
+
+// TestCount tests that Count reports the in-memory list without reloading it
+func TestCount(t *testing.T) {
+	ls := &loadCountingStorage{}
+	tl, err := NewTodoList(ls)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	pending, completed := false, true
+	if total := tl.Count(storage.StorageFilter{}); total != 0 {
+		t.Errorf("Expected 0 for an empty list, got %d", total)
+	}
+
+	for i := 1; i <= 5; i++ {
+		tl.AddTask(fmt.Sprintf("task %d", i))
+	}
+	tl.CompleteTask(2)
+	tl.CompleteTask(4)
+
+	total := tl.Count(storage.StorageFilter{})
+	open := tl.Count(storage.StorageFilter{Status: &pending})
+	done := tl.Count(storage.StorageFilter{Status: &completed})
+	if total != 5 || open != 3 || done != 2 {
+		t.Errorf("Expected 5, 3, 2, got %d, %d, %d", total, open, done)
+	}
+	if ls.loads != 1 {
+		t.Errorf("Expected only the initial Load, got %d", ls.loads)
+	}
+}
+
+// TestListStale tests that ListStale returns old pending tasks oldest first
+// and TasksByAgeDescending orders every task by age
+func TestListStale(t *testing.T) {
+	now := time.Now()
+	tl, err := NewTodoList(&mockStorage{data: &models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, Description: "new", CreatedAt: now.Add(-time.Hour)},
+			{ID: 2, Description: "old", CreatedAt: now.AddDate(0, 0, -8)},
+			{ID: 3, Description: "older", CreatedAt: now.AddDate(0, 0, -30)},
+			{ID: 4, Description: "old but done", Completed: true, CreatedAt: now.AddDate(0, 0, -40)},
+		},
+		NextID: 5,
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	var ids []int
+	for _, task := range tl.TasksByAgeDescending() {
+		ids = append(ids, task.ID)
+	}
+	if fmt.Sprint(ids) != "[4 3 2 1]" {
+		t.Errorf("Expected tasks oldest first, got %v", ids)
+	}
+
+	stale := tl.ListStale(7 * 24 * time.Hour)
+	if len(stale) != 2 || stale[0].ID != 3 || stale[1].ID != 2 {
+		t.Errorf("Expected tasks 3 and 2, got %+v", stale)
+	}
+	if got := TaskAge(stale[0]); got < 30*24*time.Hour-time.Hour {
+		t.Errorf("Expected an age of about 30 days, got %v", got)
+	}
+	if stale := tl.ListStale(0); len(stale) != 3 {
+		t.Errorf("Expected every pending task with a zero age, got %+v", stale)
+	}
+}
+
+// Feature: todo-list-cli, Property 25: 搁置任务的年龄不小于阈值
+// For any tasks created at any time in the past and any threshold,
+// ListStale returns only pending tasks at least that old, oldest first
+// Validates: ListStale, TaskAge
+func TestProperty_StaleTasksAreOldEnough(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("stale tasks are at least olderThan old",
+		prop.ForAll(
+			func(ageHours []int, completed []bool, thresholdHours int) bool {
+				now := time.Now()
+				list := &models.TaskList{Tasks: []models.Task{}, NextID: 1}
+				for i, hours := range ageHours {
+					list.Tasks = append(list.Tasks, models.Task{
+						ID:          list.NextID,
+						Description: fmt.Sprintf("task %d", i),
+						Completed:   completed[i%len(completed)],
+						CreatedAt:   now.Add(-time.Duration(hours) * time.Hour),
+					})
+					list.NextID++
+				}
+				tl, err := NewTodoList(&mockStorage{data: list})
+				if err != nil {
+					return false
+				}
+
+				olderThan := time.Duration(thresholdHours) * time.Hour
+				stale := tl.ListStale(olderThan)
+				for i, task := range stale {
+					if task.Completed || time.Since(task.CreatedAt) < olderThan || TaskAge(task) < olderThan {
+						return false
+					}
+					if i > 0 && stale[i-1].CreatedAt.After(task.CreatedAt) {
+						return false
+					}
+				}
+
+				// Every pending task old enough is included
+				want := 0
+				for _, task := range list.Tasks {
+					if !task.Completed && now.Sub(task.CreatedAt) >= olderThan {
+						want++
+					}
+				}
+				return len(stale) == want
+			},
+			gen.SliceOf(gen.IntRange(0, 24*400)),
+			gen.SliceOfN(3, gen.Bool()),
+			gen.IntRange(0, 24*60),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// TestDescriptionLimits tests that descriptions are put on one line, lose
+// their control characters and are refused over the length limit wherever
+// tasks are added or edited
+func TestDescriptionLimits(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+
+	task, err := tl.AddTask("first line\r\n\tsecond\x00 line\x01[31m #red end")
+	if err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	if task.Description != "first line second line[31m end" || len(task.Tags) != 1 || task.Tags[0] != "red" {
+		t.Errorf("Unexpected normalized task: %q %v", task.Description, task.Tags)
+	}
+	if _, err := tl.AddTask("\x00\x07\n"); !apperrors.IsEmptyDescription(err) {
+		t.Errorf("Expected ErrEmptyDescription for control characters only, got %v", err)
+	}
+
+	long := strings.Repeat("x", DefaultMaxDescriptionLength+1)
+	if _, err := tl.AddTask(long); !apperrors.IsDescriptionTooLong(err) || apperrors.ExitCode(err) != apperrors.ExitUsage {
+		t.Errorf("Expected ErrDescriptionTooLong, got %v", err)
+	}
+	if _, err := tl.AddTask(long[1:]); err != nil {
+		t.Errorf("Expected a description at the limit to be accepted, got %v", err)
+	}
+
+	tl.SetMaxDescriptionLength(10)
+	if _, err := tl.AddTasks([]string{"short", "far too long"}); !apperrors.IsDescriptionTooLong(err) {
+		t.Errorf("Expected AddTasks to refuse the batch, got %v", err)
+	}
+	if _, errs := tl.BulkAddTasks([]string{"short", "far too long"}); errs[0] != nil || !apperrors.IsDescriptionTooLong(errs[1]) {
+		t.Errorf("Expected only the long item to be refused, got %v", errs)
+	}
+	if _, err := tl.ImportTasks([]models.Task{{Description: "far too long"}}); !apperrors.IsDescriptionTooLong(err) {
+		t.Errorf("Expected ImportTasks to refuse it, got %v", err)
+	}
+	err = tl.UpdateTask(task.ID, func(task *models.Task) error {
+		task.Description = "far too long"
+		return nil
+	})
+	if !apperrors.IsDescriptionTooLong(err) {
+		t.Errorf("Expected UpdateTask to refuse it, got %v", err)
+	}
+	err = tl.UpdateTask(task.ID, func(task *models.Task) error {
+		task.Description = "two\nlines"
+		return nil
+	})
+	if got, _ := tl.GetTask(task.ID); err != nil || got.Description != "two lines" {
+		t.Errorf("Expected UpdateTask to normalize the description, got %+v, %v", got, err)
+	}
+}
+
+// Feature: todo-list-cli, Property 26: 描述中不含控制字符
+// For any description mixing printable text with control characters, an
+// added task's description has no control characters or line breaks, keeps
+// the printable text, and is never longer than the limit
+// Validates: AddTask, NormalizeDescription, SetMaxDescriptionLength
+func TestProperty_DescriptionsHaveNoControlCharacters(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+
+	properties := gopter.NewProperties(parameters)
+
+	controls := []rune{'\x00', '\x07', '\t', '\n', '\v', '\f', '\r', '\x1f', '\x7f', '\u0085', '\u2028', '\u2029'}
+	properties.Property("added descriptions have no control characters",
+		prop.ForAll(
+			func(words []string, picks []int, limit int) bool {
+				tl, err := NewTodoList(&mockStorage{})
+				if err != nil {
+					return false
+				}
+				tl.SetMaxDescriptionLength(limit)
+
+				var b strings.Builder
+				for i, word := range words {
+					b.WriteString(word)
+					b.WriteRune(controls[picks[i%len(picks)]%len(controls)])
+				}
+				printable := strings.Map(func(r rune) rune {
+					if unicode.IsControl(r) || unicode.IsSpace(r) {
+						return -1
+					}
+					return r
+				}, b.String())
+
+				task, err := tl.AddTask(b.String())
+				switch {
+				case printable == "":
+					return apperrors.IsEmptyDescription(err)
+				case apperrors.IsDescriptionTooLong(err):
+					return len(NormalizeDescription(b.String())) > limit
+				case err != nil:
+					return false
+				}
+				for _, r := range task.Description {
+					if unicode.IsControl(r) || r == '\u2028' || r == '\u2029' {
+						return false
+					}
+				}
+				return len(task.Description) <= limit && strings.ReplaceAll(task.Description, " ", "") == printable
+			},
+			gen.SliceOf(gen.AlphaString()),
+			gen.SliceOfN(4, gen.IntRange(0, 100)),
+			gen.IntRange(1, 200),
+		))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// recordingNotifier remembers the events it is told about
+type recordingNotifier struct {
+	events []models.Event
+	tasks  []models.Task
+}
+
+func (n *recordingNotifier) Notify(event models.Event, task models.Task) {
+	n.events = append(n.events, event)
+	n.tasks = append(n.tasks, task)
+}
+
+// TestNotifier tests that saved changes reach the notifier with their
+// task, and that dry runs and rolled back transactions do not
+func TestNotifier(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	n := &recordingNotifier{}
+	tl.SetNotifier(n)
+
+	task, _ := tl.AddTask("Write report", WithPriority(models.PriorityHigh))
+	tl.CompleteTask(task.ID)
+	tl.DeleteTask(task.ID)
+	if len(n.events) != 3 || n.events[0].Kind != models.EventAdded || n.events[1].Kind != models.EventCompleted ||
+		n.events[2].Kind != models.EventDeleted {
+		t.Fatalf("Expected added, completed and deleted events, got %+v", n.events)
+	}
+	if n.tasks[0].UID == "" || !n.tasks[1].Completed || n.tasks[1].CompletedAt == nil ||
+		n.tasks[2].ID != task.ID || n.tasks[2].Description != "Write report" || n.tasks[2].Priority != models.PriorityHigh {
+		t.Errorf("Unexpected tasks %+v", n.tasks)
+	}
+
+	tl.SetDryRun(true)
+	tl.AddTask("only a preview")
+	tl.SetDryRun(false)
+	tl.WithTransaction(func(tx *TodoList) error {
+		tx.AddTask("rolled back")
+		return errors.New("give up")
+	})
+	if len(n.events) != 3 {
+		t.Errorf("Expected no events from a dry run or a failed transaction, got %+v", n.events[3:])
+	}
+	tl.WithTransaction(func(tx *TodoList) error {
+		_, err := tx.AddTask("committed")
+		return err
+	})
+	if len(n.events) != 4 || n.tasks[3].Description != "committed" {
+		t.Errorf("Expected the committed add once, got %+v", n.events)
+	}
+}
+
+// TestGetTaskByDescription tests exact-match lookup and the warning when
+// several tasks match
+func TestGetTaskByDescription(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	var logs bytes.Buffer
+	tl.SetLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	tl.AddTask("Call Sam")
+	tl.AddTask("Call sam")
+	tl.AddTask("Call Sam")
+
+	task, err := tl.GetTaskByDescription("Call sam")
+	if err != nil || task.ID != 2 {
+		t.Errorf("Expected task 2, got %+v, %v", task, err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected no warning for a single match, got %s", logs.String())
+	}
+	task, err = tl.GetTaskByDescription("Call Sam")
+	if err != nil || task.ID != 1 {
+		t.Errorf("Expected the first of two matches, got %+v, %v", task, err)
+	}
+	if !strings.Contains(logs.String(), `"level":"WARN"`) || !strings.Contains(logs.String(), `"matches":2`) {
+		t.Errorf("Expected a warning about 2 matches, got %s", logs.String())
+	}
+	if _, err := tl.GetTaskByDescription("Call"); !apperrors.IsTaskNotFound(err) {
+		t.Errorf("Expected ErrTaskNotFound for a partial description, got %v", err)
+	}
+
+	// The returned task is a copy
+	task.Description = "changed"
+	if again, _ := tl.GetTaskByDescription("Call Sam"); again.Description != "Call Sam" {
+		t.Errorf("Expected the list to be unchanged, got %+v", again)
+	}
+}
+
+// TestWeekStartOf tests that weeks begin at midnight on the configured
+// weekday, across daylight saving changes and year boundaries
+func TestWeekStartOf(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation failed: %v", err)
+	}
+	testCases := []struct {
+		name  string
+		t     time.Time
+		start time.Weekday
+		want  time.Time
+	}{
+		{"monday itself", time.Date(2026, 3, 9, 0, 0, 0, 0, ny), time.Monday, time.Date(2026, 3, 9, 0, 0, 0, 0, ny)},
+		{"after spring forward", time.Date(2026, 3, 10, 1, 0, 0, 0, ny), time.Sunday, time.Date(2026, 3, 8, 0, 0, 0, 0, ny)},
+		{"after fall back", time.Date(2026, 11, 1, 23, 59, 0, 0, ny), time.Monday, time.Date(2026, 10, 26, 0, 0, 0, 0, ny)},
+		{"across new year", time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC), time.Monday, time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)},
+		{"saturday start", time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC), time.Saturday, time.Date(2025, 12, 27, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		if got := WeekStartOf(tc.t, tc.start); !got.Equal(tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+// TestCompletionTrend tests the completion trend and backlog flag over
+// edge weeks: new lists, empty weeks, daylight saving changes and the
+// year boundary
+func TestCompletionTrend(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation failed: %v", err)
+	}
+	done := func(at time.Time) models.Task {
+		return models.Task{Description: "done", Completed: true, CreatedAt: at.AddDate(0, -6, 0), CompletedAt: &at}
+	}
+	pending := func(created time.Time) models.Task {
+		return models.Task{Description: "pending", CreatedAt: created}
+	}
+	utc := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 12, 0, 0, 0, time.UTC)
+	}
+	pendingSince := []models.Task{pending(utc(2, 20)), pending(utc(2, 25)), pending(utc(3, 4)), pending(utc(3, 11))}
+	flat := append([]models.Task(nil), pendingSince...)
+	finished := utc(3, 12)
+	flat[3].Completed, flat[3].CompletedAt = true, &finished
+
+	testCases := []struct {
+		name     string
+		tasks    []models.Task
+		now      time.Time
+		start    time.Weekday
+		thisWeek int
+		average  float64
+		change   string
+		growing  bool
+	}{
+		{"new list", nil, utc(3, 11), time.Monday, 0, 0, "<nil>", false},
+		{"nothing before", []models.Task{done(utc(3, 10))}, utc(3, 11), time.Monday, 1, 0, "<nil>", false},
+		{"nothing this week", []models.Task{done(utc(2, 10)), done(utc(2, 17)), done(utc(2, 24)), done(utc(3, 3))},
+			utc(3, 11), time.Monday, 0, 1, "-100", false},
+		{"completed before times were recorded", []models.Task{{Description: "old", Completed: true, CreatedAt: utc(1, 1)}},
+			utc(3, 11), time.Monday, 0, 0, "<nil>", false},
+		{"spring forward", []models.Task{
+			done(time.Date(2026, 3, 2, 0, 30, 0, 0, ny)),
+			done(time.Date(2026, 3, 8, 23, 30, 0, 0, ny)),
+			done(time.Date(2026, 3, 9, 0, 30, 0, 0, ny)),
+		}, time.Date(2026, 3, 10, 10, 0, 0, 0, ny), time.Monday, 1, 0.5, "100", false},
+		{"fall back", []models.Task{
+			done(time.Date(2026, 11, 1, 23, 30, 0, 0, ny)),
+			done(time.Date(2026, 11, 2, 0, 15, 0, 0, ny)),
+		}, time.Date(2026, 11, 2, 8, 0, 0, 0, ny), time.Monday, 1, 0.25, "300", false},
+		{"year boundary", []models.Task{
+			done(time.Date(2025, 11, 30, 12, 0, 0, 0, time.UTC)),
+			done(time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)),
+			done(time.Date(2025, 12, 28, 12, 0, 0, 0, time.UTC)),
+			done(time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC)),
+		}, utc(1, 2), time.Monday, 1, 0.5, "100", false},
+		{"year boundary from sunday", []models.Task{
+			done(time.Date(2025, 11, 30, 12, 0, 0, 0, time.UTC)),
+			done(time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)),
+			done(time.Date(2025, 12, 28, 12, 0, 0, 0, time.UTC)),
+			done(time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC)),
+		}, utc(1, 2), time.Sunday, 2, 0.5, "300", false},
+		{"backlog grew three weeks", pendingSince, utc(3, 18), time.Monday, 0, 0, "<nil>", true},
+		{"backlog flat one week", flat, utc(3, 18), time.Monday, 0, 0.25, "-100", false},
+	}
+	for _, tc := range testCases {
+		trend := CompletionTrend(tc.tasks, tc.now, tc.start)
+		change := "<nil>"
+		if trend.Change != nil {
+			change = fmt.Sprintf("%.0f", *trend.Change)
+		}
+		if trend.ThisWeek != tc.thisWeek || trend.Average != tc.average || change != tc.change || trend.BacklogGrowing != tc.growing {
+			t.Errorf("%s: expected %d, %v, %s, %v, got %d, %v, %s, %v", tc.name, tc.thisWeek, tc.average, tc.change, tc.growing,
+				trend.ThisWeek, trend.Average, change, trend.BacklogGrowing)
+		}
+	}
+}
+
+// TestCreatedAtUsesClock tests that every way of adding a task stamps it
+// with the list's clock
+func TestCreatedAtUsesClock(t *testing.T) {
+	tl, err := NewTodoList(&mockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	tl.now = func() time.Time { return now }
+
+	added, err := tl.AddTask("added")
+	if err != nil {
+		t.Fatalf("AddTask failed: %v", err)
+	}
+	copied, err := tl.DuplicateTask(added.ID)
+	if err != nil {
+		t.Fatalf("DuplicateTask failed: %v", err)
+	}
+	if _, err := tl.ImportTasks([]models.Task{{Description: "imported"}}); err != nil {
+		t.Fatalf("ImportTasks failed: %v", err)
+	}
+	if _, err := tl.AddTasks([]string{"batch"}); err != nil {
+		t.Fatalf("AddTasks failed: %v", err)
+	}
+	tl.BulkAddTasks([]string{"bulk"})
+
+	if copied.CreatedAt != now {
+		t.Errorf("Expected the copy to be created at %v, got %v", now, copied.CreatedAt)
+	}
+	for _, task := range tl.ListTasks() {
+		if !task.CreatedAt.Equal(now) {
+			t.Errorf("Expected %q to be created at %v, got %v", task.Description, now, task.CreatedAt)
+		}
+	}
+}
+
+// TestListsKeepVersion tests that undo snapshots, archives and rollbacks
+// keep the format version the list was loaded with
+func TestListsKeepVersion(t *testing.T) {
+	fs := &failingStorage{mockStorage: mockStorage{data: &models.TaskList{
+		Version: models.FormatVersion, Tasks: []models.Task{}, NextID: 1,
+	}}}
+	tl, err := NewTodoList(fs)
+	if err != nil {
+		t.Fatalf("Failed to create TodoList: %v", err)
+	}
+	history := &mockHistory{}
+	tl.SetHistory(history)
+
+	tl.AddTask("first")
+	tl.CompleteTask(1)
+	if before := history.snapshots[0][0]; before.Version != models.FormatVersion {
+		t.Errorf("Expected the undo snapshot to keep version %d, got %d", models.FormatVersion, before.Version)
+	}
+
+	archive := &failingStorage{}
+	archive.Save(&models.TaskList{Version: models.FormatVersion, Tasks: []models.Task{}, NextID: 1})
+	fs.fail = true
+	if _, err := tl.archive(archive); err == nil {
+		t.Fatal("Expected archive to fail when the list cannot be saved")
+	}
+	if tl.list.Version != models.FormatVersion {
+		t.Errorf("Expected the rollback to keep version %d, got %d", models.FormatVersion, tl.list.Version)
+	}
+
+	fs.fail = false
+	if _, err := tl.archive(archive); err != nil {
+		t.Fatalf("archive failed: %v", err)
+	}
+	if tl.list.Version != models.FormatVersion || fs.data.Version != models.FormatVersion {
+		t.Errorf("Expected the list to keep version %d, got %d in memory and %d saved", models.FormatVersion, tl.list.Version, fs.data.Version)
+	}
+	if archived, _ := archive.Load(); archived.Version != models.FormatVersion {
+		t.Errorf("Expected the archive to keep version %d, got %d", models.FormatVersion, archived.Version)
+	}
+}
@@ -0,0 +1,87 @@
+package todolist
+
+import (
+	"errors"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+	"todolist/pkg/storage"
+)
+
+// WithTransaction runs fn on a copy of the list whose saves are held in a
+// storage.Tx, then writes the result with a single save. fn can call any
+// method on the TodoList it is given; the list it was called on is locked
+// until the transaction ends, so other callers see all of fn's changes or
+// none. If fn returns an error or the save fails, nothing is written and
+// the list is left as it was. The whole transaction is one undo step, and
+// its events are recorded once it is saved.
+func (tl *TodoList) WithTransaction(fn func(*TodoList) error) (err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("transaction", 0, "", time.Now(), &err)
+
+	tx := storage.NewTransactionalStorage(tl.storage).Begin()
+	events := &eventBuffer{log: tl.events}
+	inner := &TodoList{
+		storage:              tx,
+		events:               events,
+		now:                  tl.now,
+		dryRun:               tl.dryRun,
+		noDuplicates:         tl.noDuplicates,
+		maxDescriptionLength: tl.maxDescriptionLength,
+		maxTasks:             tl.maxTasks,
+		referrers:            tl.referrers,
+	}
+	before := tl.snapshot()
+	inner.setList(tl.snapshot())
+
+	if err := fn(inner); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	inner.mu.RLock()
+	after := inner.snapshot()
+	inner.mu.RUnlock()
+
+	// Dry-run changes never reach the Tx but still apply in memory
+	if tl.dryRun {
+		tl.setList(after)
+		return nil
+	}
+	if !tx.Pending() {
+		return nil
+	}
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return apperrors.WrapWithContext(err, "failed to save task list after transaction")
+	}
+	tl.setList(after)
+
+	return errors.Join(tl.recordHistory(before), tl.recordEvents(events.events))
+}
+
+// eventBuffer holds the events of a transaction until it is saved. Events
+// returns those already in log followed by the held ones.
+type eventBuffer struct {
+	log    storage.EventLog
+	events []models.Event
+}
+
+// Append holds events back
+func (b *eventBuffer) Append(events []models.Event) error {
+	b.events = append(b.events, events...)
+	return nil
+}
+
+// Events returns the logged events followed by the held ones
+func (b *eventBuffer) Events() ([]models.Event, error) {
+	var logged []models.Event
+	if b.log != nil {
+		var err error
+		if logged, err = b.log.Events(); err != nil {
+			return nil, err
+		}
+	}
+	return append(logged, b.events...), nil
+}
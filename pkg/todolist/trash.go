@@ -0,0 +1,108 @@
+package todolist
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	apperrors "todolist/pkg/errors"
+	"todolist/pkg/models"
+)
+
+// TrashRetention is how long deleted tasks stay in the trash. Older ones
+// are purged when the list is loaded.
+const TrashRetention = 30 * 24 * time.Hour
+
+// Trash returns a copy of the deleted tasks, oldest deletion first
+func (tl *TodoList) Trash() []models.TrashedTask {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	trash := make([]models.TrashedTask, len(tl.list.Trash))
+	for i, entry := range tl.list.Trash {
+		trash[i] = models.TrashedTask{Task: copyTask(entry.Task), DeletedAt: entry.DeletedAt}
+	}
+	return trash
+}
+
+// RestoreTask moves the most recently deleted task with ID id out of the
+// trash and back to the end of the list. It keeps its ID unless another
+// task has taken it since, in which case it gets the next free one.
+func (tl *TodoList) RestoreTask(id int) (restored *models.Task, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("restore", id, "", time.Now(), &err)
+
+	at := -1
+	for i, entry := range tl.list.Trash {
+		if entry.Task.ID == id {
+			at = i
+		}
+	}
+	if at < 0 {
+		return nil, fmt.Errorf("%w: task %d is not in the trash", apperrors.ErrTaskNotFound, id)
+	}
+
+	before := tl.snapshot()
+	task := tl.list.Trash[at].Task
+	if _, taken := tl.index[task.ID]; taken {
+		task.ID = tl.list.NextID
+	}
+	trash := make([]models.TrashedTask, 0, len(tl.list.Trash)-1)
+	trash = append(trash, tl.list.Trash[:at]...)
+	tl.list.Trash = append(trash, tl.list.Trash[at+1:]...)
+	tl.list.Tasks = append(tl.list.Tasks, task)
+	tl.index[task.ID] = len(tl.list.Tasks) - 1
+	tl.list.NextID = max(tl.list.NextID, task.ID+1)
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback both the list and the trash on save failure
+		tl.setList(before)
+		return nil, apperrors.WrapWithContext(err, "failed to save task list after restoring")
+	}
+
+	restored = &task
+	events := []models.Event{models.NewEvent(models.EventAdded, task, tl.now())}
+	return restored, errors.Join(tl.recordHistory(before), tl.recordEvents(events))
+}
+
+// EmptyTrash deletes every task in the trash permanently and returns how
+// many there were
+func (tl *TodoList) EmptyTrash() (purged int, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	defer tl.logChange("empty_trash", 0, "", time.Now(), &err)
+
+	count := len(tl.list.Trash)
+	if count == 0 {
+		return 0, nil
+	}
+
+	before := tl.snapshot()
+	tl.list.Trash = nil
+
+	// Save to storage
+	if err := tl.save(tl.list); err != nil {
+		// Rollback on save failure
+		tl.setList(before)
+		return 0, apperrors.WrapWithContext(err, "failed to save task list after emptying the trash")
+	}
+
+	return count, tl.recordHistory(before)
+}
+
+// purgeTrash drops the tasks deleted more than TrashRetention before now.
+// The list is saved without them on its next change.
+func purgeTrash(list *models.TaskList, now time.Time) {
+	cutoff := now.Add(-TrashRetention)
+	kept := list.Trash[:0]
+	for _, entry := range list.Trash {
+		if entry.DeletedAt.After(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	list.Trash = kept
+}
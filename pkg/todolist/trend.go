@@ -0,0 +1,110 @@
+package todolist
+
+import (
+	"time"
+	"todolist/pkg/models"
+)
+
+const (
+	// TrendWeeks is the number of full weeks the current week's
+	// completions are compared with
+	TrendWeeks = 4
+	// BacklogGrowthWeeks is the number of consecutive full weeks the
+	// pending count must grow in before the trend flags it
+	BacklogGrowthWeeks = 3
+)
+
+// WeekStartOf returns midnight, in t's location, on the most recent
+// weekday start at or before t. Days are counted on the calendar, so a
+// week across a daylight saving change still starts at midnight.
+func WeekStartOf(t time.Time, start time.Weekday) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) - int(start) + 7) % 7))
+}
+
+// CompletionsByWeek counts the tasks completed in each of the n weeks up
+// to and including the one containing now, oldest first. Weeks begin on
+// start. Tasks completed before completion times were recorded are not
+// counted.
+func CompletionsByWeek(tasks []models.Task, now time.Time, start time.Weekday, n int) []int {
+	current := WeekStartOf(now, start)
+	first := current.AddDate(0, 0, -7*(n-1))
+	counts := make([]int, n)
+	for _, task := range tasks {
+		if !task.Completed || task.CompletedAt == nil {
+			continue
+		}
+		at := task.CompletedAt.In(now.Location())
+		if at.Before(first) || at.After(now) {
+			continue
+		}
+		// Count whole weeks by their start dates, which AddDate keeps at
+		// midnight across daylight saving changes
+		week := 0
+		for week < n-1 && !at.Before(first.AddDate(0, 0, 7*(week+1))) {
+			week++
+		}
+		counts[week]++
+	}
+	return counts
+}
+
+// BacklogAt returns the number of tasks that were pending at t: created
+// by then and not completed by then. Tasks completed before completion
+// times were recorded count as completed all along.
+func BacklogAt(tasks []models.Task, t time.Time) int {
+	pending := 0
+	for _, task := range tasks {
+		if task.CreatedAt.After(t) {
+			continue
+		}
+		if task.Completed && (task.CompletedAt == nil || !task.CompletedAt.After(t)) {
+			continue
+		}
+		pending++
+	}
+	return pending
+}
+
+// CompletionTrend compares the completions of the week containing now
+// with the average of the TrendWeeks full weeks before it, and flags a
+// backlog that grew in each of the last BacklogGrowthWeeks full weeks.
+// Weeks begin on start. Empty weeks count as 0; with no completions at
+// all in the trailing weeks there is no percentage change.
+func CompletionTrend(tasks []models.Task, now time.Time, start time.Weekday) models.CompletionTrend {
+	counts := CompletionsByWeek(tasks, now, start, TrendWeeks+1)
+	trend := models.CompletionTrend{ThisWeek: counts[TrendWeeks]}
+
+	total := 0
+	for _, n := range counts[:TrendWeeks] {
+		total += n
+	}
+	trend.Average = float64(total) / TrendWeeks
+	if trend.Average > 0 {
+		change := (float64(trend.ThisWeek) - trend.Average) / trend.Average * 100
+		trend.Change = &change
+	}
+
+	// The pending count at the start of the current week and of each of
+	// the full weeks before it
+	current := WeekStartOf(now, start)
+	trend.BacklogGrowing = true
+	previous := BacklogAt(tasks, current.AddDate(0, 0, -7*BacklogGrowthWeeks))
+	for week := BacklogGrowthWeeks - 1; week >= 0; week-- {
+		backlog := BacklogAt(tasks, current.AddDate(0, 0, -7*week))
+		if backlog <= previous {
+			trend.BacklogGrowing = false
+		}
+		previous = backlog
+	}
+	return trend
+}
+
+// CompletionTrend returns the trend of the list's completions as of now
+// (see the CompletionTrend function), with weeks beginning on start
+func (tl *TodoList) CompletionTrend(start time.Weekday) models.CompletionTrend {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	return CompletionTrend(tl.list.Tasks, tl.now(), start)
+}